@@ -0,0 +1,207 @@
+package accounting
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+
+	"github.com/c9s/bbgo/types"
+)
+
+// Report is the report that can be sent to the notifiers
+type Report interface {
+	Print()
+	SlackAttachment() slack.Attachment
+}
+
+// AssetValuation is the value of a currency balance in the valuation currency
+type AssetValuation struct {
+	Currency string
+	Total    float64
+	Value    float64
+
+	// Priced is false when the price of the currency is not found, the value is not counted
+	Priced bool
+}
+
+// ValuationReport is the total value of the account balances
+type ValuationReport struct {
+	Time     time.Time
+	Currency string
+	Total    float64
+	Assets   []AssetValuation
+}
+
+// NewValuationReport converts the balances (available + locked) to the valuation currency by the price source
+func NewValuationReport(now time.Time, balances map[string]types.Balance, currency string, source PriceSource) *ValuationReport {
+	report := &ValuationReport{
+		Time:     now,
+		Currency: currency,
+	}
+
+	for _, balance := range balances {
+		total := balance.Available + balance.Locked
+		if total == 0.0 {
+			continue
+		}
+
+		asset := AssetValuation{Currency: balance.Currency, Total: total}
+		asset.Value, asset.Priced = ConvertCurrency(source, total, balance.Currency, currency)
+		if asset.Priced {
+			report.Total += asset.Value
+		}
+
+		report.Assets = append(report.Assets, asset)
+	}
+
+	sort.Slice(report.Assets, func(i, j int) bool {
+		return report.Assets[i].Value > report.Assets[j].Value
+	})
+
+	return report
+}
+
+func (report ValuationReport) Print() {
+	logrus.Infof("account valuation at %s: %s", report.Time.Format(time.RFC822), types.FormatMoney(report.Currency, report.Total))
+	for _, asset := range report.Assets {
+		if asset.Priced {
+			logrus.Infof(" - %s: %f (%s)", asset.Currency, asset.Total, types.FormatMoney(report.Currency, asset.Value))
+		} else {
+			logrus.Infof(" - %s: %f (price not found)", asset.Currency, asset.Total)
+		}
+	}
+}
+
+func (report ValuationReport) SlackAttachment() slack.Attachment {
+	var fields []slack.AttachmentField
+	for _, asset := range report.Assets {
+		var value = "price not found"
+		if asset.Priced {
+			value = types.FormatMoney(report.Currency, asset.Value)
+		}
+
+		fields = append(fields, slack.AttachmentField{
+			Title: asset.Currency,
+			Value: strconv.FormatFloat(asset.Total, 'f', 8, 64) + " (" + value + ")",
+			Short: true,
+		})
+	}
+
+	return slack.Attachment{
+		Title:  "Account Valuation",
+		Text:   "Total " + types.FormatMoney(report.Currency, report.Total),
+		Fields: fields,
+		Footer: report.Time.Format(time.RFC822),
+	}
+}
+
+// BalanceChange is the change of a currency balance (available + locked)
+type BalanceChange struct {
+	Currency string
+	Before   float64
+	After    float64
+
+	// NetInflow is the deposited amount minus the withdrawn amount in the period
+	NetInflow float64
+}
+
+func (c BalanceChange) Delta() float64 {
+	return c.After - c.Before
+}
+
+// Performance is the balance change that is not caused by the deposits and the withdraws
+func (c BalanceChange) Performance() float64 {
+	return c.Delta() - c.NetInflow
+}
+
+// BalanceChangeReport summarizes the balance changes between two balance snapshots
+type BalanceChangeReport struct {
+	StartTime time.Time
+	EndTime   time.Time
+	Changes   []BalanceChange
+}
+
+// NewBalanceChangeReport compares the balance snapshots, the unchanged balances are not included
+func NewBalanceChangeReport(startTime time.Time, before map[string]types.Balance, endTime time.Time, after map[string]types.Balance) *BalanceChangeReport {
+	report := &BalanceChangeReport{StartTime: startTime, EndTime: endTime}
+
+	var currencies = map[string]struct{}{}
+	for currency := range before {
+		currencies[currency] = struct{}{}
+	}
+	for currency := range after {
+		currencies[currency] = struct{}{}
+	}
+
+	for currency := range currencies {
+		change := BalanceChange{
+			Currency: currency,
+			Before:   before[currency].Available + before[currency].Locked,
+			After:    after[currency].Available + after[currency].Locked,
+		}
+
+		if int64(change.Delta()*1e8) == 0 {
+			continue
+		}
+
+		report.Changes = append(report.Changes, change)
+	}
+
+	sort.Slice(report.Changes, func(i, j int) bool {
+		return report.Changes[i].Currency < report.Changes[j].Currency
+	})
+
+	return report
+}
+
+// SetNetInflows sets the net inflows of the changed currencies, see NetInflow
+func (report *BalanceChangeReport) SetNetInflows(inflows map[string]float64) {
+	for i := range report.Changes {
+		report.Changes[i].NetInflow = inflows[report.Changes[i].Currency]
+	}
+}
+
+func (report BalanceChangeReport) Print() {
+	logrus.Infof("balance changes from %s to %s:", report.StartTime.Format(time.RFC822), report.EndTime.Format(time.RFC822))
+	for _, change := range report.Changes {
+		if change.NetInflow != 0.0 {
+			logrus.Infof(" - %s: %f -> %f (%+f, net inflow %+f, performance %+f)", change.Currency, change.Before, change.After, change.Delta(), change.NetInflow, change.Performance())
+		} else {
+			logrus.Infof(" - %s: %f -> %f (%+f)", change.Currency, change.Before, change.After, change.Delta())
+		}
+	}
+}
+
+func (report BalanceChangeReport) SlackAttachment() slack.Attachment {
+	var fields []slack.AttachmentField
+	for _, change := range report.Changes {
+		value := strconv.FormatFloat(change.Before, 'f', 8, 64) + " → " + strconv.FormatFloat(change.After, 'f', 8, 64) +
+			" (" + strconv.FormatFloat(change.Delta(), 'f', 8, 64) + ")"
+
+		if change.NetInflow != 0.0 {
+			value += "\nnet inflow " + strconv.FormatFloat(change.NetInflow, 'f', 8, 64) +
+				", performance " + strconv.FormatFloat(change.Performance(), 'f', 8, 64)
+		}
+
+		fields = append(fields, slack.AttachmentField{
+			Title: change.Currency,
+			Value: value,
+			Short: true,
+		})
+	}
+
+	var text = "No balance changes"
+	if len(report.Changes) > 0 {
+		text = strconv.Itoa(len(report.Changes)) + " balance changes"
+	}
+
+	return slack.Attachment{
+		Title:  "Balance Changes",
+		Text:   text,
+		Fields: fields,
+		Footer: report.StartTime.Format(time.RFC822) + " - " + report.EndTime.Format(time.RFC822),
+	}
+}
@@ -0,0 +1,44 @@
+package accounting
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/types"
+)
+
+func TestNewValuationReport(t *testing.T) {
+	prices := NewPriceMap()
+	prices.SetPrice("BTCUSDT", 10000.0)
+	prices.SetPrice("USDTTWD", 30.0)
+
+	report := NewValuationReport(time.Now(), map[string]types.Balance{
+		"BTC":  {Currency: "BTC", Available: 0.5, Locked: 0.5},
+		"TWD":  {Currency: "TWD", Available: 3000.0},
+		"USDT": {Currency: "USDT", Available: 100.0},
+		"DOGE": {Currency: "DOGE", Available: 1000.0},
+		"ETH":  {Currency: "ETH"},
+	}, "USDT", prices)
+
+	assert.InDelta(t, 10000.0+100.0+100.0, report.Total, 1e-9)
+	assert.Len(t, report.Assets, 4)
+	assert.Equal(t, "BTC", report.Assets[0].Currency)
+	assert.False(t, report.Assets[3].Priced)
+}
+
+func TestNewBalanceChangeReport(t *testing.T) {
+	report := NewBalanceChangeReport(time.Now(), map[string]types.Balance{
+		"BTC":  {Currency: "BTC", Available: 1.0},
+		"USDT": {Currency: "USDT", Available: 100.0},
+	}, time.Now(), map[string]types.Balance{
+		"BTC": {Currency: "BTC", Available: 0.5, Locked: 0.5},
+		"ETH": {Currency: "ETH", Available: 2.0},
+	})
+
+	assert.Equal(t, []BalanceChange{
+		{Currency: "ETH", Before: 0.0, After: 2.0},
+		{Currency: "USDT", Before: 100.0, After: 0.0},
+	}, report.Changes)
+}
@@ -1,7 +1,6 @@
 package accounting
 
 import (
-	"strings"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -9,12 +8,32 @@ import (
 	"github.com/c9s/bbgo/types"
 )
 
+// DefaultFeeRate is the fee rate used when the fee rates of the account can not be queried
+const DefaultFeeRate = 0.0015
+
 type ProfitAndLossCalculator struct {
 	Symbol             string
 	StartTime          time.Time
 	CurrentPrice       float64
 	Trades             []types.Trade
 	TradingFeeCurrency string
+
+	// Market is the market of the symbol, it's looked up by the symbol if it's not set
+	Market types.Market
+
+	// PriceSource is used for converting the fees paid in the third currencies (e.g., BNB, MAX) to the quote currency,
+	// and converting the report to the reporting currency.
+	PriceSource PriceSource
+
+	// ReportingCurrency is the currency the report is expressed in, the quote currency is used if it's not set
+	ReportingCurrency string
+
+	// FeeRate is the taker fee rate of the symbol, it's used for estimating the fee of selling the stock,
+	// zero means there is no fee and a negative rate is the rebate.
+	FeeRate float64
+
+	// PaperTrade marks the report as a paper trading report
+	PaperTrade bool
 }
 
 func (c *ProfitAndLossCalculator) AddTrade(trade types.Trade) {
@@ -25,19 +44,47 @@ func (c *ProfitAndLossCalculator) SetCurrentPrice(price float64) {
 	c.CurrentPrice = price
 }
 
+func (c *ProfitAndLossCalculator) market() types.Market {
+	if len(c.Market.Symbol) > 0 {
+		return c.Market
+	}
+
+	if market, ok := types.FindMarket(c.Symbol); ok {
+		return market
+	}
+
+	return types.Market{Symbol: c.Symbol}
+}
+
+// TradeFee returns the fee of the trade in the quote currency of the market,
+// the fee paid in the third currency is converted by the price source.
+func TradeFee(market types.Market, source PriceSource, trade types.Trade) (float64, bool) {
+	switch trade.FeeCurrency {
+	case market.QuoteCurrency:
+		return trade.Fee, true
+	case market.BaseCurrency:
+		return trade.Price * trade.Fee, true
+	}
+
+	return ConvertCurrency(source, trade.Fee, trade.FeeCurrency, market.QuoteCurrency)
+}
+
 func (c *ProfitAndLossCalculator) Calculate() *ProfitAndLossReport {
 	// copy trades, so that we can truncate it.
 	var trades = c.Trades
+	var market = c.market()
 	var bidVolume = 0.0
 	var bidAmount = 0.0
 
 	var askVolume = 0.0
 
-	var feeUSD = 0.0
-	var bidFeeUSD = 0.0
-	var feeRate = 0.0015
+	// fees in the quote currency
+	var fee = 0.0
+	var bidFee = 0.0
+	var feeRate = c.FeeRate
 
 	var currencyFees = map[string]float64{}
+	var unconvertedFeeCurrencies = map[string]struct{}{}
 
 	for _, trade := range trades {
 		if trade.Symbol == c.Symbol {
@@ -46,18 +93,18 @@ func (c *ProfitAndLossCalculator) Calculate() *ProfitAndLossReport {
 				bidAmount += trade.Price * trade.Quantity
 			}
 
-			// since we use USDT as the quote currency, we simply check if it matches the currency symbol
-			if strings.HasPrefix(trade.Symbol, trade.FeeCurrency) {
+			// the fee paid by the base asset reduces the stock
+			if trade.FeeCurrency == market.BaseCurrency {
 				bidVolume -= trade.Fee
-				feeUSD += trade.Price * trade.Fee
-				if trade.IsBuyer {
-					bidFeeUSD += trade.Price * trade.Fee
-				}
-			} else if trade.FeeCurrency == "USDT" {
-				feeUSD += trade.Fee
+			}
+
+			if tradeFee, ok := TradeFee(market, c.PriceSource, trade); ok {
+				fee += tradeFee
 				if trade.IsBuyer {
-					bidFeeUSD += trade.Fee
+					bidFee += tradeFee
 				}
+			} else {
+				unconvertedFeeCurrencies[trade.FeeCurrency] = struct{}{}
 			}
 
 		} else {
@@ -72,9 +119,13 @@ func (c *ProfitAndLossCalculator) Calculate() *ProfitAndLossReport {
 		currencyFees[trade.FeeCurrency] += trade.Fee
 	}
 
-	logrus.Infof("average bid price = (total amount %f + total feeUSD %f) / volume %f", bidAmount, bidFeeUSD, bidVolume)
+	for currency := range unconvertedFeeCurrencies {
+		logrus.Warnf("%s price not found, the fee paid in %s is not converted to %s", currency, currency, market.QuoteCurrency)
+	}
+
+	logrus.Infof("average bid price = (total amount %f + total fee %f) / volume %f", bidAmount, bidFee, bidVolume)
 	profit := 0.0
-	averageCost := (bidAmount + bidFeeUSD) / bidVolume
+	averageCost := (bidAmount + bidFee) / bidVolume
 
 	for _, t := range trades {
 		if t.Symbol != c.Symbol {
@@ -89,7 +140,7 @@ func (c *ProfitAndLossCalculator) Calculate() *ProfitAndLossReport {
 		askVolume += t.Quantity
 	}
 
-	profit -= feeUSD
+	profit -= fee
 	unrealizedProfit := profit
 
 	stock := bidVolume - askVolume
@@ -98,12 +149,29 @@ func (c *ProfitAndLossCalculator) Calculate() *ProfitAndLossReport {
 		unrealizedProfit += (c.CurrentPrice-averageCost)*stock - stockFee
 	}
 
+	reportingCurrency := c.ReportingCurrency
+	if len(reportingCurrency) == 0 {
+		reportingCurrency = market.QuoteCurrency
+	}
+
+	reportingRate, ok := ConvertCurrency(c.PriceSource, 1.0, market.QuoteCurrency, reportingCurrency)
+	if !ok {
+		logrus.Warnf("can not convert %s to the reporting currency %s, reporting in %s", market.QuoteCurrency, reportingCurrency, market.QuoteCurrency)
+		reportingCurrency = market.QuoteCurrency
+		reportingRate = 1.0
+	}
+
 	return &ProfitAndLossReport{
 		Symbol:       c.Symbol,
+		PaperTrade:   c.PaperTrade,
 		StartTime:    c.StartTime,
 		CurrentPrice: c.CurrentPrice,
 		NumTrades:    len(trades),
 
+		QuoteCurrency:     market.QuoteCurrency,
+		ReportingCurrency: reportingCurrency,
+		ReportingRate:     reportingRate,
+
 		BidVolume: bidVolume,
 		AskVolume: askVolume,
 
@@ -111,7 +179,7 @@ func (c *ProfitAndLossCalculator) Calculate() *ProfitAndLossReport {
 		Profit:           profit,
 		UnrealizedProfit: unrealizedProfit,
 		AverageBidCost:   averageCost,
-		FeeUSD:           feeUSD,
+		Fee:              fee,
 		CurrencyFees:     currencyFees,
 	}
 }
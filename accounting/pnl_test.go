@@ -0,0 +1,39 @@
+package accounting
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/types"
+)
+
+func TestProfitAndLossCalculator_ThirdCurrencyFee(t *testing.T) {
+	prices := NewPriceMap()
+	prices.SetPrice("MAXTWD", 2.0)
+	prices.SetPrice("USDTTWD", 30.0)
+
+	calculator := &ProfitAndLossCalculator{
+		Symbol:            "BTCTWD",
+		CurrentPrice:      310000.0,
+		PriceSource:       prices,
+		ReportingCurrency: "USDT",
+		FeeRate:           0.001,
+		Trades: []types.Trade{
+			{Symbol: "BTCTWD", Price: 300000.0, Quantity: 1.0, IsBuyer: true, Fee: 150.0, FeeCurrency: "MAX"},
+			{Symbol: "BTCTWD", Price: 320000.0, Quantity: 0.5, IsBuyer: false, Fee: 160.0, FeeCurrency: "TWD"},
+		},
+	}
+
+	report := calculator.Calculate()
+	assert.Equal(t, "TWD", report.QuoteCurrency)
+	assert.Equal(t, "USDT", report.ReportingCurrency)
+	assert.InDelta(t, 1.0/30.0, report.ReportingRate, 1e-9)
+
+	// fee: 150 MAX * 2.0 + 160 TWD
+	assert.InDelta(t, 460.0, report.Fee, 1e-9)
+	assert.InDelta(t, 300300.0, report.AverageBidCost, 1e-9)
+	assert.InDelta(t, (320000.0-300300.0)*0.5-460.0, report.Profit, 1e-9)
+	assert.InDelta(t, 0.5, report.Stock, 1e-9)
+	assert.Equal(t, map[string]float64{"MAX": 150.0, "TWD": 160.0}, report.CurrencyFees)
+}
@@ -0,0 +1,58 @@
+package accounting
+
+import "sync"
+
+// PriceSource provides the last price of the market symbol, e.g., BNBUSDT
+type PriceSource interface {
+	LastPrice(symbol string) (float64, bool)
+}
+
+// PriceMap is a thread-safe symbol to last price map
+type PriceMap struct {
+	mu     sync.Mutex
+	prices map[string]float64
+}
+
+func NewPriceMap() *PriceMap {
+	return &PriceMap{prices: make(map[string]float64)}
+}
+
+func (m *PriceMap) SetPrice(symbol string, price float64) {
+	m.mu.Lock()
+	m.prices[symbol] = price
+	m.mu.Unlock()
+}
+
+func (m *PriceMap) LastPrice(symbol string) (float64, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	price, ok := m.prices[symbol]
+	return price, ok && price > 0
+}
+
+// ConversionSymbols returns the market symbols that can be used for converting the currency
+func ConversionSymbols(from, to string) []string {
+	return []string{from + to, to + from}
+}
+
+// ConvertCurrency converts the amount from one currency to another currency by the direct market price
+func ConvertCurrency(source PriceSource, amount float64, from, to string) (float64, bool) {
+	if from == to {
+		return amount, true
+	}
+
+	if source == nil {
+		return 0, false
+	}
+
+	if price, ok := source.LastPrice(from + to); ok {
+		return amount * price, true
+	}
+
+	if price, ok := source.LastPrice(to + from); ok {
+		return amount / price, true
+	}
+
+	return 0, false
+}
@@ -0,0 +1,98 @@
+package accounting
+
+import (
+	"sort"
+	"time"
+
+	"github.com/c9s/bbgo/types"
+)
+
+type ProfitPeriod string
+
+const (
+	ProfitPeriodDay   = ProfitPeriod("day")
+	ProfitPeriodWeek  = ProfitPeriod("week")
+	ProfitPeriodMonth = ProfitPeriod("month")
+)
+
+// Start returns the start time of the period that contains the given time, weeks start from Monday
+func (p ProfitPeriod) Start(t time.Time) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+
+	switch p {
+	case ProfitPeriodWeek:
+		offset := (int(day.Weekday()) + 6) % 7
+		return day.AddDate(0, 0, -offset)
+
+	case ProfitPeriodMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+	}
+
+	return day
+}
+
+// ProfitSummary is the realized profit of the sell trades in a period
+type ProfitSummary struct {
+	Period    ProfitPeriod
+	StartTime time.Time
+	NumTrades int
+	Profit    float64
+	Fee       float64
+}
+
+// SummarizeProfits groups the profits by the period in the given location, the summaries are ordered by the start time
+func SummarizeProfits(profits []types.Profit, period ProfitPeriod, loc *time.Location) (summaries []ProfitSummary) {
+	if loc == nil {
+		loc = time.Local
+	}
+
+	var index = map[time.Time]int{}
+	for _, profit := range profits {
+		start := period.Start(profit.Time.In(loc))
+
+		idx, ok := index[start]
+		if !ok {
+			idx = len(summaries)
+			index[start] = idx
+			summaries = append(summaries, ProfitSummary{Period: period, StartTime: start})
+		}
+
+		summaries[idx].NumTrades++
+		summaries[idx].Profit += profit.Profit
+		summaries[idx].Fee += profit.Fee
+	}
+
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].StartTime.Before(summaries[j].StartTime)
+	})
+
+	return summaries
+}
+
+// ProfitBreakdown is the realized profits summarized by the period
+type ProfitBreakdown struct {
+	Period    ProfitPeriod
+	Summaries []ProfitSummary
+}
+
+// Last returns the summary of the latest period
+func (b ProfitBreakdown) Last() (ProfitSummary, bool) {
+	if len(b.Summaries) == 0 {
+		return ProfitSummary{}, false
+	}
+
+	return b.Summaries[len(b.Summaries)-1], true
+}
+
+// BreakDownProfits summarizes the profits by day, week and month
+func BreakDownProfits(profits []types.Profit, loc *time.Location) []ProfitBreakdown {
+	var breakdowns []ProfitBreakdown
+	for _, period := range []ProfitPeriod{ProfitPeriodDay, ProfitPeriodWeek, ProfitPeriodMonth} {
+		breakdowns = append(breakdowns, ProfitBreakdown{
+			Period:    period,
+			Summaries: SummarizeProfits(profits, period, loc),
+		})
+	}
+
+	return breakdowns
+}
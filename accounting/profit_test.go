@@ -0,0 +1,45 @@
+package accounting
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/types"
+)
+
+func TestSummarizeProfits(t *testing.T) {
+	var profits = []types.Profit{
+		// Thursday
+		{Profit: 10.0, Fee: 1.0, Time: time.Date(2020, 10, 1, 10, 0, 0, 0, time.UTC)},
+		{Profit: -5.0, Fee: 1.0, Time: time.Date(2020, 10, 1, 23, 0, 0, 0, time.UTC)},
+		// Monday of the next week
+		{Profit: 20.0, Fee: 2.0, Time: time.Date(2020, 10, 5, 1, 0, 0, 0, time.UTC)},
+		{Profit: 30.0, Fee: 3.0, Time: time.Date(2020, 11, 2, 1, 0, 0, 0, time.UTC)},
+	}
+
+	days := SummarizeProfits(profits, ProfitPeriodDay, time.UTC)
+	if assert.Len(t, days, 3) {
+		assert.Equal(t, time.Date(2020, 10, 1, 0, 0, 0, 0, time.UTC), days[0].StartTime)
+		assert.Equal(t, 2, days[0].NumTrades)
+		assert.InDelta(t, 5.0, days[0].Profit, 1e-9)
+		assert.InDelta(t, 2.0, days[0].Fee, 1e-9)
+	}
+
+	weeks := SummarizeProfits(profits, ProfitPeriodWeek, time.UTC)
+	if assert.Len(t, weeks, 3) {
+		assert.Equal(t, time.Date(2020, 9, 28, 0, 0, 0, 0, time.UTC), weeks[0].StartTime)
+		assert.Equal(t, time.Date(2020, 10, 5, 0, 0, 0, 0, time.UTC), weeks[1].StartTime)
+	}
+
+	months := SummarizeProfits(profits, ProfitPeriodMonth, time.UTC)
+	if assert.Len(t, months, 2) {
+		assert.InDelta(t, 25.0, months[0].Profit, 1e-9)
+		assert.InDelta(t, 30.0, months[1].Profit, 1e-9)
+	}
+
+	// the day boundary follows the location
+	days = SummarizeProfits(profits, ProfitPeriodDay, time.FixedZone("UTC+8", 8*3600))
+	assert.Len(t, days, 4)
+}
@@ -15,6 +15,15 @@ type ProfitAndLossReport struct {
 	CurrentPrice float64
 	StartTime    time.Time
 	Symbol       string
+	PaperTrade   bool
+
+	// QuoteCurrency is the currency of the profit, the fee and the prices
+	QuoteCurrency string
+
+	// ReportingCurrency is the currency the profit and the fee are presented in,
+	// ReportingRate is the price of the quote currency in the reporting currency.
+	ReportingCurrency string
+	ReportingRate     float64
 
 	NumTrades        int
 	Profit           float64
@@ -22,25 +31,64 @@ type ProfitAndLossReport struct {
 	AverageBidCost   float64
 	BidVolume        float64
 	AskVolume        float64
-	FeeUSD           float64
+	Fee              float64
 	Stock            float64
 	CurrencyFees     map[string]float64
+
+	// Breakdowns are the realized profits of the profit ledger summarized by day, week and month
+	Breakdowns []ProfitBreakdown
+}
+
+func (s ProfitSummary) dateFormat() string {
+	if s.Period == ProfitPeriodMonth {
+		return "2006-01"
+	}
+
+	return "2006-01-02"
+}
+
+// formatAmount formats the amount in the quote currency as the amount in the reporting currency
+func (report ProfitAndLossReport) formatAmount(amount float64) string {
+	if len(report.ReportingCurrency) == 0 {
+		return types.FormatMoney(report.QuoteCurrency, amount)
+	}
+
+	return types.FormatMoney(report.ReportingCurrency, amount*report.ReportingRate)
+}
+
+func (report ProfitAndLossReport) formatPrice(price float64) string {
+	if market, ok := types.FindMarket(report.Symbol); ok {
+		return market.FormatPrice(price)
+	}
+
+	return types.FormatMoney(report.QuoteCurrency, price)
 }
 
 func (report ProfitAndLossReport) Print() {
 	logrus.Infof("trades since: %v", report.StartTime)
-	logrus.Infof("average bid cost: %s", types.USD.FormatMoneyFloat64(report.AverageBidCost))
+	logrus.Infof("average bid cost: %s", report.formatPrice(report.AverageBidCost))
 	logrus.Infof("total bid volume: %f", report.BidVolume)
 	logrus.Infof("total ask volume: %f", report.AskVolume)
 	logrus.Infof("stock: %f", report.Stock)
-	logrus.Infof("fee (USD): %f", report.FeeUSD)
-	logrus.Infof("current price: %s", types.USD.FormatMoneyFloat64(report.CurrentPrice))
-	logrus.Infof("profit: %s", types.USD.FormatMoneyFloat64(report.Profit))
-	logrus.Infof("unrealized profit: %s", types.USD.FormatMoneyFloat64(report.UnrealizedProfit))
+	logrus.Infof("fee: %s", report.formatAmount(report.Fee))
+	logrus.Infof("current price: %s", report.formatPrice(report.CurrentPrice))
+	logrus.Infof("profit: %s", report.formatAmount(report.Profit))
+	logrus.Infof("unrealized profit: %s", report.formatAmount(report.UnrealizedProfit))
 	logrus.Infof("currency fees:")
 	for currency, fee := range report.CurrencyFees {
 		logrus.Infof(" - %s: %f", currency, fee)
 	}
+
+	for _, breakdown := range report.Breakdowns {
+		logrus.Infof("realized profit by %s:", breakdown.Period)
+		for _, summary := range breakdown.Summaries {
+			logrus.Infof(" - %s: profit %s, fee %s, %d trades",
+				summary.StartTime.Format(summary.dateFormat()),
+				report.formatAmount(summary.Profit),
+				report.formatAmount(summary.Fee),
+				summary.NumTrades)
+		}
+	}
 }
 
 func (report ProfitAndLossReport) SlackAttachment() slack.Attachment {
@@ -56,21 +104,38 @@ func (report ProfitAndLossReport) SlackAttachment() slack.Attachment {
 		return slack.Attachment{}
 	}
 
+	var title = report.Symbol + " Profit and Loss report"
+	if report.PaperTrade {
+		title = "[paper] " + title
+	}
+
+	var fields = []slack.AttachmentField{
+		{Title: "Profit", Value: report.formatAmount(report.Profit)},
+		{Title: "Unrealized Profit", Value: report.formatAmount(report.UnrealizedProfit)},
+		{Title: "Current Price", Value: market.FormatPrice(report.CurrentPrice), Short: true},
+		{Title: "Average Cost", Value: market.FormatPrice(report.AverageBidCost), Short: true},
+		{Title: "Fee", Value: report.formatAmount(report.Fee), Short: true},
+		{Title: "Stock", Value: strconv.FormatFloat(report.Stock, 'f', 8, 64), Short: true},
+		{Title: "Number of Trades", Value: strconv.Itoa(report.NumTrades), Short: true},
+	}
+
+	for _, breakdown := range report.Breakdowns {
+		if summary, ok := breakdown.Last(); ok {
+			fields = append(fields, slack.AttachmentField{
+				Title: "Realized Profit (" + string(breakdown.Period) + " " + summary.StartTime.Format(summary.dateFormat()) + ")",
+				Value: report.formatAmount(summary.Profit),
+				Short: true,
+			})
+		}
+	}
+
 	return slack.Attachment{
-		Title: report.Symbol + " Profit and Loss report",
-		Text:  "Profit " + types.USD.FormatMoney(report.Profit),
+		Title: title,
+		Text:  "Profit " + report.formatAmount(report.Profit),
 		Color: color,
 		// Pretext:       "",
 		// Text:          "",
-		Fields: []slack.AttachmentField{
-			{Title: "Profit", Value: types.USD.FormatMoney(report.Profit)},
-			{Title: "Unrealized Profit", Value: types.USD.FormatMoney(report.UnrealizedProfit)},
-			{Title: "Current Price", Value: market.FormatPrice(report.CurrentPrice), Short: true},
-			{Title: "Average Cost", Value: market.FormatPrice(report.AverageBidCost), Short: true},
-			{Title: "Fee (USD)", Value: types.USD.FormatMoney(report.FeeUSD), Short: true},
-			{Title: "Stock", Value: strconv.FormatFloat(report.Stock, 'f', 8, 64), Short: true},
-			{Title: "Number of Trades", Value: strconv.Itoa(report.NumTrades), Short: true},
-		},
+		Fields:     fields,
 		Footer:     report.StartTime.Format(time.RFC822),
 		FooterIcon: "",
 	}
@@ -0,0 +1,36 @@
+package accounting
+
+import (
+	"github.com/c9s/bbgo/types"
+)
+
+// NetInflow sums up the transferred amount of each currency, the credited deposits are counted as inflow,
+// the debited withdraws and their transaction fees are counted as outflow.
+func NetInflow(deposits []types.Deposit, withdraws []types.Withdraw) map[string]float64 {
+	var inflows = map[string]float64{}
+
+	for _, deposit := range deposits {
+		if deposit.IsCredited() {
+			inflows[deposit.Asset] += deposit.Amount
+		}
+	}
+
+	for _, withdraw := range withdraws {
+		if withdraw.IsDebited() {
+			inflows[withdraw.Asset] -= withdraw.Amount + withdraw.TransactionFee
+		}
+	}
+
+	return inflows
+}
+
+// NetInflowValue converts the net inflows to the valuation currency, the currencies without price are skipped
+func NetInflowValue(inflows map[string]float64, currency string, source PriceSource) (total float64) {
+	for c, amount := range inflows {
+		if value, ok := ConvertCurrency(source, amount, c, currency); ok {
+			total += value
+		}
+	}
+
+	return total
+}
@@ -0,0 +1,46 @@
+package accounting
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/types"
+)
+
+func TestNetInflow(t *testing.T) {
+	deposits := []types.Deposit{
+		{Asset: "BTC", Amount: 1.0, Status: types.DepositSuccess},
+		{Asset: "BTC", Amount: 0.5, Status: types.DepositCredited},
+		{Asset: "BTC", Amount: 2.0, Status: types.DepositPending},
+		{Asset: "USDT", Amount: 1000.0, Status: types.DepositSuccess},
+	}
+
+	withdraws := []types.Withdraw{
+		{Asset: "BTC", Amount: 0.2, TransactionFee: 0.0005, Status: types.WithdrawCompleted},
+		{Asset: "BTC", Amount: 1.0, Status: types.WithdrawCancelled},
+		{Asset: "USDT", Amount: 300.0, TransactionFee: 1.0, Status: types.WithdrawProcessing},
+	}
+
+	inflows := NetInflow(deposits, withdraws)
+	assert.InDelta(t, 1.2995, inflows["BTC"], 1e-9)
+	assert.InDelta(t, 699.0, inflows["USDT"], 1e-9)
+
+	prices := NewPriceMap()
+	prices.SetPrice("BTCUSDT", 10000.0)
+	assert.InDelta(t, 13694.0, NetInflowValue(inflows, "USDT", prices), 1e-6)
+}
+
+func TestBalanceChangeReport_SetNetInflows(t *testing.T) {
+	now := time.Now()
+	before := map[string]types.Balance{"BTC": {Currency: "BTC", Available: 1.0}}
+	after := map[string]types.Balance{"BTC": {Currency: "BTC", Available: 2.1}}
+
+	report := NewBalanceChangeReport(now.Add(-time.Hour), before, now, after)
+	report.SetNetInflows(map[string]float64{"BTC": 1.0})
+
+	assert.Len(t, report.Changes, 1)
+	assert.InDelta(t, 1.1, report.Changes[0].Delta(), 1e-9)
+	assert.InDelta(t, 0.1, report.Changes[0].Performance(), 1e-9)
+}
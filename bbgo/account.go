@@ -4,7 +4,6 @@ import (
 	"context"
 	"sync"
 
-	"github.com/c9s/bbgo/exchange/binance"
 	"github.com/c9s/bbgo/types"
 	"github.com/c9s/bbgo/util"
 
@@ -17,7 +16,7 @@ type Account struct {
 	Balances map[string]types.Balance
 }
 
-func LoadAccount(ctx context.Context, exchange *binance.Exchange) (*Account, error) {
+func LoadAccount(ctx context.Context, exchange types.Exchange) (*Account, error) {
 	balances, err := exchange.QueryAccountBalances(ctx)
 	return &Account{
 		Balances: balances,
@@ -36,6 +35,19 @@ func (a *Account) BindPrivateStream(stream types.Stream) {
 
 }
 
+// Snapshot returns a copy of the balances
+func (a *Account) Snapshot() map[string]types.Balance {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var balances = make(map[string]types.Balance, len(a.Balances))
+	for currency, balance := range a.Balances {
+		balances[currency] = balance
+	}
+
+	return balances
+}
+
 func (a *Account) Print() {
 	for _, balance := range a.Balances {
 		if util.NotZero(balance.Available) {
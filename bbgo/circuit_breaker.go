@@ -0,0 +1,122 @@
+package bbgo
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultCircuitBreakerWindow is the rolling window used when the window is not configured
+const DefaultCircuitBreakerWindow = 24 * time.Hour
+
+type profitSample struct {
+	Time   time.Time
+	Profit float64
+}
+
+//go:generate callbackgen -type CircuitBreaker
+
+// CircuitBreaker halts the trading when the loss (the drop of realized + unrealized profit from its high) in the rolling window
+// exceeds MaxLoss. Once it's tripped, the order processor rejects all the new orders until Reset is called.
+type CircuitBreaker struct {
+	mu sync.Mutex
+
+	// MaxLoss is the max loss in the quote currency allowed in the rolling window
+	MaxLoss float64 `json:"maxLoss" yaml:"maxLoss"`
+
+	// Window is the rolling window of the loss calculation
+	Window time.Duration `json:"window" yaml:"window"`
+
+	// CancelOpenOrders cancels the open orders when the circuit breaker is tripped
+	CancelOpenOrders bool `json:"cancelOpenOrders" yaml:"cancelOpenOrders"`
+
+	samples  []profitSample
+	halted   bool
+	haltedAt time.Time
+
+	haltCallbacks  []func(loss float64)
+	resetCallbacks []func()
+}
+
+func (b *CircuitBreaker) window() time.Duration {
+	if b.Window > 0 {
+		return b.Window
+	}
+
+	return DefaultCircuitBreakerWindow
+}
+
+// RecordProfit records the total profit (realized + unrealized) at the given time,
+// and trips the circuit breaker if the loss in the window exceeds the max loss.
+func (b *CircuitBreaker) RecordProfit(t time.Time, profit float64) {
+	b.mu.Lock()
+
+	b.samples = append(b.samples, profitSample{Time: t, Profit: profit})
+
+	// remove the samples that are out of the window
+	since := t.Add(-b.window())
+	var idx = 0
+	for ; idx < len(b.samples)-1; idx++ {
+		if !b.samples[idx].Time.Before(since) {
+			break
+		}
+	}
+	b.samples = b.samples[idx:]
+
+	loss := b.loss()
+	if b.halted || b.MaxLoss <= 0 || loss < b.MaxLoss {
+		b.mu.Unlock()
+		return
+	}
+
+	b.halted = true
+	b.haltedAt = t
+	b.mu.Unlock()
+
+	b.EmitHalt(loss)
+}
+
+// loss is the drop from the highest profit in the window to the latest profit
+func (b *CircuitBreaker) loss() float64 {
+	if len(b.samples) == 0 {
+		return 0
+	}
+
+	var high = b.samples[0].Profit
+	for _, sample := range b.samples {
+		if sample.Profit > high {
+			high = sample.Profit
+		}
+	}
+
+	return high - b.samples[len(b.samples)-1].Profit
+}
+
+// Loss returns the loss in the current window
+func (b *CircuitBreaker) Loss() float64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.loss()
+}
+
+func (b *CircuitBreaker) IsHalted() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.halted
+}
+
+func (b *CircuitBreaker) HaltedAt() time.Time {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.haltedAt
+}
+
+// Reset re-enables the trading, the samples are cleared so that the loss is calculated from now on.
+func (b *CircuitBreaker) Reset() {
+	b.mu.Lock()
+	b.halted = false
+	b.haltedAt = time.Time{}
+	b.samples = nil
+	b.mu.Unlock()
+
+	b.EmitReset()
+}
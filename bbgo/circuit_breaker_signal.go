@@ -0,0 +1,37 @@
+//go:build !windows
+// +build !windows
+
+package bbgo
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// CircuitBreakerResetSignal is the signal that resets the circuit breakers of the running traders
+var CircuitBreakerResetSignal os.Signal = syscall.SIGUSR1
+
+// handleCircuitBreakerResetSignal resets the circuit breaker every time the reset signal is received until the context is done
+func (trader *Trader) handleCircuitBreakerResetSignal(ctx context.Context) {
+	var sigC = make(chan os.Signal, 1)
+	signal.Notify(sigC, CircuitBreakerResetSignal)
+
+	go func() {
+		defer signal.Stop(sigC)
+
+		for {
+			select {
+			case <-sigC:
+				log.Infof("%s circuit breaker reset signal received", trader.Symbol)
+				trader.ResetCircuitBreaker()
+
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
@@ -0,0 +1,32 @@
+//go:build !windows
+// +build !windows
+
+package bbgo
+
+import (
+	"context"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrader_handleCircuitBreakerResetSignal(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	breaker := &CircuitBreaker{MaxLoss: 10.0, Window: time.Hour}
+	trader := &Trader{Symbol: "BTCUSDT", CircuitBreaker: breaker}
+	trader.handleCircuitBreakerResetSignal(ctx)
+
+	now := time.Now()
+	breaker.RecordProfit(now, 0.0)
+	breaker.RecordProfit(now.Add(time.Minute), -20.0)
+	assert.True(t, breaker.IsHalted())
+
+	assert.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGUSR1))
+	assert.Eventually(t, func() bool {
+		return !breaker.IsHalted()
+	}, time.Second, 5*time.Millisecond)
+}
@@ -0,0 +1,7 @@
+package bbgo
+
+import "context"
+
+// handleCircuitBreakerResetSignal does nothing on windows since there is no user signal,
+// use Trader.ResetCircuitBreaker to reset the circuit breaker.
+func (trader *Trader) handleCircuitBreakerResetSignal(ctx context.Context) {}
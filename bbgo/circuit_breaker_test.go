@@ -0,0 +1,78 @@
+package bbgo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/exchange/paper"
+	"github.com/c9s/bbgo/types"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	var breaker = &CircuitBreaker{MaxLoss: 100.0, Window: time.Hour}
+
+	var haltedLoss float64
+	breaker.OnHalt(func(loss float64) {
+		haltedLoss = loss
+	})
+
+	var resetCalled bool
+	breaker.OnReset(func() {
+		resetCalled = true
+	})
+
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	breaker.RecordProfit(now, 50.0)
+	breaker.RecordProfit(now.Add(10*time.Minute), -40.0)
+	assert.False(t, breaker.IsHalted())
+	assert.InDelta(t, 90.0, breaker.Loss(), 1e-9)
+
+	// the first sample is out of the window, the loss is counted from the high -40.0
+	breaker.RecordProfit(now.Add(65*time.Minute), -60.0)
+	assert.False(t, breaker.IsHalted())
+	assert.InDelta(t, 20.0, breaker.Loss(), 1e-9)
+
+	breaker.RecordProfit(now.Add(68*time.Minute), -150.0)
+	assert.True(t, breaker.IsHalted())
+	assert.InDelta(t, 110.0, haltedLoss, 1e-9)
+	assert.Equal(t, now.Add(68*time.Minute), breaker.HaltedAt())
+
+	// orders are rejected until it's reset
+	breaker.RecordProfit(now.Add(80*time.Minute), 0.0)
+	assert.True(t, breaker.IsHalted())
+
+	breaker.Reset()
+	assert.False(t, breaker.IsHalted())
+	assert.True(t, resetCalled)
+	assert.Equal(t, 0.0, breaker.Loss())
+}
+
+func TestTrader_CircuitBreakerCancelsSessionOrders(t *testing.T) {
+	ctx := context.Background()
+
+	exchange := paper.New(nil, types.BalanceMap{"USDT": {Available: 10000.0}})
+	assert.NoError(t, exchange.SubmitOrder(ctx, &types.SubmitOrder{
+		Symbol:   "BTCUSDT",
+		Side:     types.SideTypeBuy,
+		Type:     types.OrderTypeLimit,
+		Quantity: 0.1,
+		Price:    9000.0,
+	}))
+
+	// the cross exchange trader has no exchange of its own
+	trader := &Trader{}
+	trader.AddExchange("paper", exchange).Symbols("BTCUSDT")
+	trader.SetCircuitBreaker(&CircuitBreaker{MaxLoss: 100.0, Window: time.Hour, CancelOpenOrders: true})
+
+	now := time.Now()
+	trader.CircuitBreaker.RecordProfit(now, 0.0)
+	trader.CircuitBreaker.RecordProfit(now.Add(time.Minute), -150.0)
+	assert.True(t, trader.CircuitBreaker.IsHalted())
+
+	orders, err := exchange.QueryOpenOrders(ctx, "BTCUSDT")
+	assert.NoError(t, err)
+	assert.Len(t, orders, 0)
+}
@@ -0,0 +1,35 @@
+package bbgo
+
+import (
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// maxClientOrderIDPrefix keeps the client order id within 36 characters, the limit of binance and max
+const maxClientOrderIDPrefix = 16
+
+var clientOrderIDSeq uint32
+
+// NewClientOrderID returns a unique client order id that starts with the prefix, e.g., the strategy id.
+// The strategies tag their orders with their own prefix, so that their orders can be found by HasClientOrderIDPrefix
+// after restart and told apart from the other orders of the symbol.
+func NewClientOrderID(prefix string) string {
+	if len(prefix) > maxClientOrderIDPrefix {
+		prefix = prefix[:maxClientOrderIDPrefix]
+	}
+
+	// the sequence is formatted in 2 base36 digits ("100" is 1296 in base36), so the ids of the same nanosecond are different
+	seq := atomic.AddUint32(&clientOrderIDSeq, 1)%1296 + 1296
+	return prefix + "-" + strconv.FormatInt(time.Now().UnixNano(), 36) + strconv.FormatUint(uint64(seq), 36)[1:]
+}
+
+// HasClientOrderIDPrefix returns true if the client order id is created by NewClientOrderID with the prefix
+func HasClientOrderIDPrefix(clientOrderID, prefix string) bool {
+	if len(prefix) > maxClientOrderIDPrefix {
+		prefix = prefix[:maxClientOrderIDPrefix]
+	}
+
+	return strings.HasPrefix(clientOrderID, prefix+"-")
+}
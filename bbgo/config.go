@@ -0,0 +1,292 @@
+package bbgo
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/c9s/bbgo/types"
+)
+
+// SubscriptionConfig is a market data subscription of the session stream
+type SubscriptionConfig struct {
+	Channel  types.Channel `yaml:"channel"`
+	Symbol   string        `yaml:"symbol"`
+	Interval string        `yaml:"interval"`
+	Depth    string        `yaml:"depth"`
+}
+
+// SessionConfig is the exchange session of the "sessions" section, the session name is the key of the section
+type SessionConfig struct {
+	// Exchange is the exchange name, e.g., binance or max
+	Exchange string `yaml:"exchange"`
+
+	// EnvVarPrefix is the prefix of the API key and secret environment variables, e.g., BINANCE for BINANCE_API_KEY,
+	// the upper case exchange name is used if it's empty
+	EnvVarPrefix string `yaml:"envVarPrefix"`
+
+	// PaperTrade submits the orders to the simulated matching engine with the paper balances
+	PaperTrade    bool               `yaml:"paperTrade"`
+	PaperBalances map[string]float64 `yaml:"paperBalances"`
+
+	Symbols       []string             `yaml:"symbols"`
+	Subscriptions []SubscriptionConfig `yaml:"subscriptions"`
+
+	// FeeRates are the symbol fee rates that override the fee rates queried from the exchange,
+	// e.g., the zero-fee promotion pairs that the exchange API doesn't report
+	FeeRates map[string]types.FeeRates `yaml:"feeRates"`
+}
+
+// StrategyMount is a strategy instance running on the symbol of the session
+type StrategyMount struct {
+	// ID is the registered strategy ID
+	ID string
+
+	// Session is the session name
+	Session string
+
+	Symbol string
+
+	// Exit is the exit rules of the long position of the strategy, see ExitManager
+	Exit *ExitConfig
+
+	Strategy MarketStrategy
+}
+
+// CrossExchangeStrategyMount is a strategy instance running over all the sessions
+type CrossExchangeStrategyMount struct {
+	// ID is the registered strategy ID
+	ID string
+
+	Strategy CrossExchangeStrategy
+}
+
+// Config is the config file of the bbgo run command
+//
+//	sessions:
+//	  binance:
+//	    exchange: binance
+//	    symbols: [BTCUSDT]
+//	    subscriptions:
+//	    - channel: kline
+//	      symbol: BTCUSDT
+//	      interval: 1m
+//
+//	strategies:
+//	- session: binance
+//	  symbol: BTCUSDT
+//	  exit:
+//	    trailingStop: 0.03
+//	  grid:
+//	    gridNumber: 10
+//
+//	crossExchangeStrategies:
+//	- arbitrage:
+//	    minSpread: 0.01
+//
+// Every strategy entry has the session name, the symbol, the optional exit rules and the strategy settings under the registered strategy ID,
+// the cross exchange strategy entry only has the strategy settings since it runs over all the sessions.
+type Config struct {
+	Sessions map[string]SessionConfig
+
+	RiskControls *RiskControlConfig
+
+	Reports *ReportConfig
+
+	// CircuitBreaker is the circuit breaker settings, every strategy has its own circuit breaker
+	CircuitBreaker *CircuitBreaker
+
+	// CostBasis is the cost basis method name, see NewCostBasisMethod
+	CostBasis string
+
+	ReportingCurrency string
+
+	Strategies []StrategyMount
+
+	CrossExchangeStrategies []CrossExchangeStrategyMount
+}
+
+type rawConfig struct {
+	Sessions          map[string]SessionConfig `yaml:"sessions"`
+	RiskControls      *RiskControlConfig       `yaml:"riskControls"`
+	Reports           *ReportConfig            `yaml:"reports"`
+	CircuitBreaker    *CircuitBreaker          `yaml:"circuitBreaker"`
+	CostBasis         string                   `yaml:"costBasis"`
+	ReportingCurrency string                   `yaml:"reportingCurrency"`
+	Strategies        []map[string]interface{} `yaml:"strategies"`
+
+	CrossExchangeStrategies []map[string]interface{} `yaml:"crossExchangeStrategies"`
+}
+
+func LoadConfig(filename string) (*Config, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseConfig(data)
+}
+
+// ParseConfig parses the YAML config, the strategy settings are loaded into the new instances of the registered strategies
+func ParseConfig(data []byte) (*Config, error) {
+	var raw rawConfig
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	config := &Config{
+		Sessions:          raw.Sessions,
+		RiskControls:      raw.RiskControls,
+		Reports:           raw.Reports,
+		CircuitBreaker:    raw.CircuitBreaker,
+		CostBasis:         raw.CostBasis,
+		ReportingCurrency: raw.ReportingCurrency,
+	}
+
+	for i, entry := range raw.Strategies {
+		mount, err := parseStrategyMount(entry)
+		if err != nil {
+			return nil, fmt.Errorf("strategies[%d]: %s", i, err.Error())
+		}
+
+		if _, ok := config.Sessions[mount.Session]; !ok {
+			return nil, fmt.Errorf("strategies[%d]: session %q is not defined", i, mount.Session)
+		}
+
+		config.Strategies = append(config.Strategies, *mount)
+	}
+
+	for i, entry := range raw.CrossExchangeStrategies {
+		if len(entry) != 1 {
+			return nil, fmt.Errorf("crossExchangeStrategies[%d]: one strategy per entry", i)
+		}
+
+		for id, settings := range entry {
+			strategy, err := newStrategyWithSettings(id, settings)
+			if err != nil {
+				return nil, fmt.Errorf("crossExchangeStrategies[%d]: %s", i, err.Error())
+			}
+
+			crossStrategy, ok := strategy.(CrossExchangeStrategy)
+			if !ok {
+				return nil, fmt.Errorf("crossExchangeStrategies[%d]: strategy %s is not a cross exchange strategy", i, id)
+			}
+
+			config.CrossExchangeStrategies = append(config.CrossExchangeStrategies, CrossExchangeStrategyMount{ID: id, Strategy: crossStrategy})
+		}
+	}
+
+	return config, nil
+}
+
+func parseStrategyMount(entry map[string]interface{}) (*StrategyMount, error) {
+	var mount StrategyMount
+	var settings, exitSettings interface{}
+
+	for key, value := range entry {
+		switch key {
+		case "session":
+			mount.Session, _ = value.(string)
+
+		case "symbol":
+			mount.Symbol, _ = value.(string)
+
+		case "exit":
+			exitSettings = value
+
+		default:
+			if len(mount.ID) > 0 {
+				return nil, fmt.Errorf("one strategy per entry, found %s and %s", mount.ID, key)
+			}
+
+			mount.ID = key
+			settings = value
+		}
+	}
+
+	if len(mount.ID) == 0 {
+		return nil, fmt.Errorf("strategy is not defined")
+	}
+
+	if len(mount.Session) == 0 || len(mount.Symbol) == 0 {
+		return nil, fmt.Errorf("strategy %s: session and symbol are required", mount.ID)
+	}
+
+	if exitSettings != nil {
+		out, err := yaml.Marshal(exitSettings)
+		if err != nil {
+			return nil, err
+		}
+
+		var exitConfig ExitConfig
+		if err := yaml.Unmarshal(out, &exitConfig); err != nil {
+			return nil, fmt.Errorf("strategy %s exit: %s", mount.ID, err.Error())
+		}
+
+		if err := exitConfig.Validate(); err != nil {
+			return nil, fmt.Errorf("strategy %s: %s", mount.ID, err.Error())
+		}
+
+		mount.Exit = &exitConfig
+	}
+
+	strategy, err := newStrategyWithSettings(mount.ID, settings)
+	if err != nil {
+		return nil, err
+	}
+
+	marketStrategy, ok := strategy.(MarketStrategy)
+	if !ok {
+		return nil, fmt.Errorf("strategy %s is not a market strategy", mount.ID)
+	}
+
+	mount.Strategy = marketStrategy
+	return &mount, nil
+}
+
+// newStrategyWithSettings creates the registered strategy, the settings are decoded into the strategy struct by its yaml tags
+func newStrategyWithSettings(id string, settings interface{}) (interface{}, error) {
+	strategy, err := NewStrategy(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if settings == nil {
+		return strategy, nil
+	}
+
+	out, err := yaml.Marshal(settings)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := yaml.Unmarshal(out, strategy); err != nil {
+		return nil, fmt.Errorf("strategy %s: %s", id, err.Error())
+	}
+
+	return strategy, nil
+}
+
+// StreamSubscriptions returns the stream subscriptions of the session
+func (c SessionConfig) StreamSubscriptions() (subscriptions []types.Subscription) {
+	for _, s := range c.Subscriptions {
+		subscriptions = append(subscriptions, types.Subscription{
+			Channel: s.Channel,
+			Symbol:  s.Symbol,
+			Options: types.SubscribeOptions{Interval: s.Interval, Depth: s.Depth},
+		})
+	}
+
+	return subscriptions
+}
+
+// InitialPaperBalances returns the paper balances as the available balances
+func (c SessionConfig) InitialPaperBalances() types.BalanceMap {
+	var balances = make(types.BalanceMap, len(c.PaperBalances))
+	for currency, amount := range c.PaperBalances {
+		balances[currency] = types.Balance{Currency: currency, Available: amount}
+	}
+
+	return balances
+}
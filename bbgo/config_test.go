@@ -0,0 +1,186 @@
+package bbgo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/types"
+)
+
+type testConfigStrategy struct {
+	GridNumber int                `yaml:"gridNumber"`
+	Spread     float64            `yaml:"spread"`
+	Levels     []float64          `yaml:"levels"`
+	Weights    map[string]float64 `yaml:"weights"`
+}
+
+func (s *testConfigStrategy) OnLoad(tradingContext *Context, trader types.Trader) error {
+	return nil
+}
+
+func (s *testConfigStrategy) OnNewStream(stream types.Stream) error {
+	return nil
+}
+
+func init() {
+	RegisterStrategy("test-config", &testConfigStrategy{
+		GridNumber: 10,
+		Spread:     0.01,
+		Levels:     []float64{0.01, 0.02},
+		Weights:    map[string]float64{"BTC": 0.5},
+	})
+	RegisterStrategy("test-cross", &testCrossExchangeStrategy{})
+}
+
+func TestParseConfig(t *testing.T) {
+	config, err := ParseConfig([]byte(`
+sessions:
+  binance:
+    exchange: binance
+    paperTrade: true
+    paperBalances:
+      USDT: 1000.0
+    symbols: [BTCUSDT]
+    feeRates:
+      BTCUSDT:
+        makerFeeRate: 0.0
+        takerFeeRate: 0.0002
+    subscriptions:
+    - channel: kline
+      symbol: BTCUSDT
+      interval: 1m
+
+riskControls:
+  sessions:
+    binance:
+      maxOrderNotional: 100.0
+
+circuitBreaker:
+  maxLoss: 50.0
+  window: 12h
+
+reports:
+  pnl: "0 9 * * *"
+
+costBasis: fifo
+
+strategies:
+- session: binance
+  symbol: BTCUSDT
+  exit:
+    trailingStop: 0.03
+    takeProfits:
+    - ratio: 0.05
+      quantityRatio: 0.5
+  test-config:
+    gridNumber: 20
+- session: binance
+  symbol: ETHUSDT
+  test-config:
+`))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	session := config.Sessions["binance"]
+	assert.True(t, session.PaperTrade)
+	assert.Equal(t, map[string]types.FeeRates{"BTCUSDT": {MakerFeeRate: 0.0, TakerFeeRate: 0.0002}}, session.FeeRates)
+	assert.Equal(t, types.BalanceMap{"USDT": {Currency: "USDT", Available: 1000.0}}, session.InitialPaperBalances())
+	assert.Equal(t, []types.Subscription{{Channel: types.KLineChannel, Symbol: "BTCUSDT", Options: types.SubscribeOptions{Interval: "1m"}}}, session.StreamSubscriptions())
+
+	assert.Equal(t, 100.0, config.RiskControls.For("binance", "test-config").MaxOrderNotional)
+	assert.Equal(t, 12*time.Hour, config.CircuitBreaker.Window)
+	assert.Equal(t, "0 9 * * *", config.Reports.PnL)
+	assert.Equal(t, "fifo", config.CostBasis)
+
+	if assert.Len(t, config.Strategies, 2) {
+		assert.Equal(t, "test-config", config.Strategies[0].ID)
+		assert.Equal(t, "binance", config.Strategies[0].Session)
+		assert.Equal(t, "BTCUSDT", config.Strategies[0].Symbol)
+		assert.Equal(t, &testConfigStrategy{
+			GridNumber: 20,
+			Spread:     0.01,
+			Levels:     []float64{0.01, 0.02},
+			Weights:    map[string]float64{"BTC": 0.5},
+		}, config.Strategies[0].Strategy)
+		assert.Equal(t, &ExitConfig{TrailingStop: 0.03, TakeProfits: []TakeProfitLevel{{Ratio: 0.05, QuantityRatio: 0.5}}}, config.Strategies[0].Exit)
+		assert.Nil(t, config.Strategies[1].Exit)
+
+		// the registered prototype is the default settings
+		assert.Equal(t, &testConfigStrategy{
+			GridNumber: 10,
+			Spread:     0.01,
+			Levels:     []float64{0.01, 0.02},
+			Weights:    map[string]float64{"BTC": 0.5},
+		}, config.Strategies[1].Strategy)
+	}
+}
+
+func TestParseConfig_Errors(t *testing.T) {
+	var tests = []string{
+		"strategies:\n- session: binance\n  symbol: BTCUSDT\n  test-config: {}\n",
+		"sessions: {binance: {exchange: binance}}\nstrategies:\n- session: binance\n  symbol: BTCUSDT\n  unknown: {}\n",
+		"sessions: {binance: {exchange: binance}}\nstrategies:\n- session: binance\n  test-config: {}\n",
+		"sessions: {binance: {exchange: binance}}\nstrategies:\n- session: binance\n  symbol: BTCUSDT\n",
+		"sessions: {binance: {exchange: binance}}\nstrategies:\n- session: binance\n  symbol: BTCUSDT\n  test-config: {gridNumber: abc}\n",
+		"sessions: {binance: {exchange: binance}}\nstrategies:\n- session: binance\n  symbol: BTCUSDT\n  exit: {stopLoss: 1.5}\n  test-config: {}\n",
+	}
+
+	for _, test := range tests {
+		_, err := ParseConfig([]byte(test))
+		assert.Error(t, err, test)
+	}
+}
+
+func TestRegisterStrategy(t *testing.T) {
+	assert.Contains(t, RegisteredStrategies(), "test-config")
+	assert.Panics(t, func() {
+		RegisterStrategy("test-config", &testConfigStrategy{})
+	})
+	assert.Panics(t, func() {
+		RegisterStrategy("test-not-a-strategy", &struct{}{})
+	})
+
+	a, err := NewStrategy("test-config")
+	assert.NoError(t, err)
+	b, err := NewStrategy("test-config")
+	assert.NoError(t, err)
+
+	// every instance is a new copy of the prototype
+	a.(*testConfigStrategy).GridNumber = 1
+	assert.Equal(t, 10, b.(*testConfigStrategy).GridNumber)
+
+	// the slices and the maps of the prototype are not shared
+	a.(*testConfigStrategy).Levels[0] = 0.5
+	a.(*testConfigStrategy).Weights["BTC"] = 1.0
+	assert.Equal(t, []float64{0.01, 0.02}, b.(*testConfigStrategy).Levels)
+	assert.Equal(t, map[string]float64{"BTC": 0.5}, b.(*testConfigStrategy).Weights)
+
+	c, err := NewStrategy("test-config")
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{0.01, 0.02}, c.(*testConfigStrategy).Levels)
+	assert.Equal(t, 0.5, c.(*testConfigStrategy).Weights["BTC"])
+}
+
+func TestParseConfig_CrossExchangeStrategies(t *testing.T) {
+	config, err := ParseConfig([]byte(`
+sessions:
+  a: {exchange: binance}
+  b: {exchange: max}
+crossExchangeStrategies:
+- test-cross: {}
+`))
+	if assert.NoError(t, err) && assert.Len(t, config.CrossExchangeStrategies, 1) {
+		assert.Equal(t, "test-cross", config.CrossExchangeStrategies[0].ID)
+		assert.IsType(t, &testCrossExchangeStrategy{}, config.CrossExchangeStrategies[0].Strategy)
+	}
+
+	// the strategy types are checked
+	_, err = ParseConfig([]byte("sessions: {a: {exchange: binance}}\nstrategies:\n- session: a\n  symbol: BTCUSDT\n  test-cross: {}\n"))
+	assert.Error(t, err)
+
+	_, err = ParseConfig([]byte("crossExchangeStrategies:\n- test-config: {}\n"))
+	assert.Error(t, err)
+}
@@ -21,6 +21,24 @@ type Context struct {
 	Balances                map[string]types.Balance
 	ProfitAndLossCalculator *accounting.ProfitAndLossCalculator
 	StockManager            *StockManager
+
+	// FeeSchedule is the fee rates of the exchange account
+	FeeSchedule *types.FeeSchedule
+
+	// TradedVolume tracks the traded volume of the current day for the daily volume cap
+	TradedVolume *TradedVolumeTracker
+
+	// MarketDataStore stores the closed klines of the strategy stream, the indicators subscribe to it
+	MarketDataStore *MarketDataStore
+}
+
+// FeeRates returns the fee rates of the symbol, the default fee rate is used when the fee schedule is not loaded
+func (c *Context) FeeRates() types.FeeRates {
+	if c.FeeSchedule == nil {
+		return types.FeeRates{MakerFeeRate: DefaultFeeRate, TakerFeeRate: DefaultFeeRate}
+	}
+
+	return c.FeeSchedule.FeeRates(c.Symbol)
 }
 
 func (c *Context) SetCurrentPrice(price float64) {
@@ -0,0 +1,158 @@
+package bbgo
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// CostBasisMethod matches the sell trade against the stock lots
+type CostBasisMethod interface {
+	// Consume consumes the stocks in place for the sell trade,
+	// returns the consumed lots and the sell trade with the quantity that is not matched.
+	Consume(stocks StockSlice, sell Stock) ([]ConsumedLot, Stock)
+}
+
+const (
+	CostBasisLowerPriceFirst = "lower-price-first"
+	CostBasisFIFO            = "fifo"
+	CostBasisLIFO            = "lifo"
+	CostBasisHIFO            = "hifo"
+	CostBasisAverage         = "average"
+)
+
+// NewCostBasisMethod returns the cost basis method of the given name, empty name means the lower price first method
+func NewCostBasisMethod(name string) (CostBasisMethod, error) {
+	switch strings.ToLower(name) {
+	case "", CostBasisLowerPriceFirst:
+		return LowerPriceFirst{}, nil
+	case CostBasisFIFO:
+		return FIFO{}, nil
+	case CostBasisLIFO:
+		return LIFO{}, nil
+	case CostBasisHIFO:
+		return HIFO{}, nil
+	case CostBasisAverage:
+		return AverageCost{}, nil
+	}
+
+	return nil, fmt.Errorf("unknown cost basis method: %s", name)
+}
+
+// CostBasisName returns the name of the cost basis method, it's stored with the stock checkpoint
+func CostBasisName(method CostBasisMethod) string {
+	switch method.(type) {
+	case nil, LowerPriceFirst:
+		return CostBasisLowerPriceFirst
+	case FIFO:
+		return CostBasisFIFO
+	case LIFO:
+		return CostBasisLIFO
+	case HIFO:
+		return CostBasisHIFO
+	case AverageCost:
+		return CostBasisAverage
+	}
+
+	return fmt.Sprintf("%T", method)
+}
+
+// consumeInOrder consumes the stocks by the order of the given indexes
+func consumeInOrder(stocks StockSlice, sell Stock, indexes []int) (lots []ConsumedLot, _ Stock) {
+	for _, idx := range indexes {
+		stock := stocks[idx]
+		if zero(stock.Quantity) {
+			continue
+		}
+
+		delta := stock.Consume(sell.Quantity)
+		sell.Consume(delta)
+		stocks[idx] = stock
+
+		lots = append(lots, ConsumedLot{
+			BuyTradeID: stock.ID,
+			BuyTime:    stock.Time,
+			Price:      stock.Price,
+			Quantity:   delta,
+		})
+
+		if zero(sell.Quantity) {
+			break
+		}
+	}
+
+	return lots, sell
+}
+
+func ascendingIndexes(stocks StockSlice) (indexes []int) {
+	for idx := range stocks {
+		indexes = append(indexes, idx)
+	}
+	return indexes
+}
+
+func descendingIndexes(stocks StockSlice) (indexes []int) {
+	for idx := len(stocks) - 1; idx >= 0; idx-- {
+		indexes = append(indexes, idx)
+	}
+	return indexes
+}
+
+// LowerPriceFirst consumes the stocks with the price lower than the sell price from the latest one,
+// then the rest of the stocks from the latest one.
+type LowerPriceFirst struct{}
+
+func (LowerPriceFirst) Consume(stocks StockSlice, sell Stock) ([]ConsumedLot, Stock) {
+	var indexes []int
+	for _, idx := range descendingIndexes(stocks) {
+		if stocks[idx].Price < sell.Price {
+			indexes = append(indexes, idx)
+		}
+	}
+
+	for _, idx := range descendingIndexes(stocks) {
+		if stocks[idx].Price >= sell.Price {
+			indexes = append(indexes, idx)
+		}
+	}
+
+	return consumeInOrder(stocks, sell, indexes)
+}
+
+// FIFO consumes the earliest stocks first
+type FIFO struct{}
+
+func (FIFO) Consume(stocks StockSlice, sell Stock) ([]ConsumedLot, Stock) {
+	return consumeInOrder(stocks, sell, ascendingIndexes(stocks))
+}
+
+// LIFO consumes the latest stocks first
+type LIFO struct{}
+
+func (LIFO) Consume(stocks StockSlice, sell Stock) ([]ConsumedLot, Stock) {
+	return consumeInOrder(stocks, sell, descendingIndexes(stocks))
+}
+
+// HIFO consumes the stocks with the highest price first, the earlier one goes first if the prices are the same
+type HIFO struct{}
+
+func (HIFO) Consume(stocks StockSlice, sell Stock) ([]ConsumedLot, Stock) {
+	indexes := ascendingIndexes(stocks)
+	sort.SliceStable(indexes, func(i, j int) bool {
+		return stocks[indexes[i]].Price > stocks[indexes[j]].Price
+	})
+	return consumeInOrder(stocks, sell, indexes)
+}
+
+// AverageCost uses the weighted average price of the stocks as the cost,
+// the stocks are re-priced to the average price before they are consumed from the earliest one.
+type AverageCost struct{}
+
+func (AverageCost) Consume(stocks StockSlice, sell Stock) ([]ConsumedLot, Stock) {
+	averagePrice := stocks.AveragePrice()
+	for idx := range stocks {
+		stocks[idx].Price = averagePrice
+	}
+
+	return consumeInOrder(stocks, sell, ascendingIndexes(stocks))
+}
@@ -0,0 +1,217 @@
+package bbgo
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/accounting"
+	"github.com/c9s/bbgo/types"
+)
+
+// CrossExchangeStrategy runs over all the exchange sessions, e.g., the arbitrage and the hedging strategies.
+// Every session has its own stream, account, markets and order executor.
+type CrossExchangeStrategy interface {
+	// CrossSubscribe subscribes the channels of the session streams, it's called before the streams are connected
+	CrossSubscribe(sessions map[string]*ExchangeSession) error
+
+	// CrossRun starts the strategy after the session streams are connected
+	CrossRun(ctx context.Context, sessions map[string]*ExchangeSession) error
+}
+
+// ExchangeOrderExecutor submits the orders to the exchange of the session, the orders are routed through the order processor,
+// so the circuit breaker of the trader and the risk controls are checked before the order is submitted.
+type ExchangeOrderExecutor struct {
+	Session *ExchangeSession
+
+	// Trader is the trader of the cross exchange strategies, the orders are rejected when its circuit breaker is tripped
+	Trader *Trader
+
+	RiskControls OrderRiskControls
+
+	Notifiers []Notifier
+}
+
+func (e *ExchangeOrderExecutor) notify(msg string, args ...interface{}) {
+	if e.Session.PaperTrade {
+		msg = "[paper] " + msg
+	}
+
+	for _, n := range e.Notifiers {
+		n.Notify(msg, args...)
+	}
+}
+
+// SubmitOrder formats the order by the market of the session and submits it through the order processor
+func (e *ExchangeOrderExecutor) SubmitOrder(ctx context.Context, order *types.SubmitOrder) error {
+	if len(order.Market.Symbol) == 0 {
+		market, ok := e.Session.Markets[order.Symbol]
+		if !ok {
+			return fmt.Errorf("%s market of session %s not found", order.Symbol, e.Session.Name)
+		}
+
+		order.Market = market
+	}
+
+	market := order.Market
+	order.QuantityString = market.FormatVolume(order.Quantity)
+	if order.Type == types.OrderTypeLimit && len(order.PriceString) == 0 {
+		order.PriceString = strconv.FormatFloat(order.Price, 'f', market.PricePrecision, 64)
+	}
+
+	processor := &OrderProcessor{
+		OrderRiskControls: e.RiskControls,
+		Exchange:          e.Session.Exchange,
+		Trader:            e.Trader,
+	}
+
+	e.notify(":memo: %s submitting %s %s %s order with quantity: %s", e.Session.Name, order.Symbol, order.Type, order.Side, order.QuantityString, order)
+	if err := processor.SubmitSessionOrder(ctx, e.Session, order); err != nil {
+		if IsRiskControlError(err) {
+			e.notify(":no_entry: %s %s %s order rejected by risk controls: %s", e.Session.Name, order.Symbol, order.Side, err.Error())
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// CancelOrders cancels the orders on the exchange of the session
+func (e *ExchangeOrderExecutor) CancelOrders(ctx context.Context, orders ...types.Order) error {
+	canceller, ok := e.Session.Exchange.(OrderCanceller)
+	if !ok {
+		return fmt.Errorf("exchange of session %s does not support order cancellation", e.Session.Name)
+	}
+
+	return canceller.CancelOrders(ctx, orders...)
+}
+
+// RunCrossExchangeStrategies loads all the exchange sessions and runs the strategies over them,
+// the session that has no order executor gets the one with the trader risk controls.
+// The returned channel is closed after the strategies are shut down and the session streams are closed.
+func (trader *Trader) RunCrossExchangeStrategies(ctx context.Context, strategies ...CrossExchangeStrategy) (chan struct{}, error) {
+	for _, session := range trader.ExchangeSessions {
+		if err := trader.loadSession(ctx, session); err != nil {
+			return nil, err
+		}
+
+		if session.OrderExecutor == nil {
+			session.OrderExecutor = &ExchangeOrderExecutor{
+				Session:      session,
+				Trader:       trader,
+				RiskControls: trader.RiskControls,
+				Notifiers:    trader.Notifiers,
+			}
+		}
+	}
+
+	if trader.CircuitBreaker != nil {
+		go trader.recordSessionValues(ctx, CircuitBreakerRecordInterval)
+		trader.handleCircuitBreakerResetSignal(ctx)
+	}
+
+	for _, strategy := range strategies {
+		trader.setStateStore(strategy)
+		if err := strategy.CrossSubscribe(trader.ExchangeSessions); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, session := range trader.ExchangeSessions {
+		if err := session.Stream.Connect(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, strategy := range strategies {
+		if err := strategy.CrossRun(ctx, trader.ExchangeSessions); err != nil {
+			return nil, err
+		}
+	}
+
+	var done = make(chan struct{})
+	go func() {
+		defer close(done)
+		<-ctx.Done()
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), StrategyShutdownTimeout)
+		defer cancel()
+
+		for _, strategy := range strategies {
+			if s, ok := strategy.(ShutdownStrategy); ok {
+				if err := s.OnShutdown(shutdownCtx); err != nil {
+					log.WithError(err).Error("cross exchange strategy shutdown error")
+				}
+			}
+		}
+
+		for _, session := range trader.ExchangeSessions {
+			if err := session.Stream.Close(); err != nil {
+				log.WithError(err).Errorf("session %s stream close error", session.Name)
+			}
+		}
+	}()
+
+	return done, nil
+}
+
+// CircuitBreakerRecordInterval is the interval of recording the total session value to the circuit breaker of the cross exchange strategies
+var CircuitBreakerRecordInterval = time.Minute
+
+// recordSessionValues records the total value of the session balances to the circuit breaker every interval until the context is done,
+// the cross exchange strategies have no profit and loss calculator, so the drop of the total value is the loss.
+func (trader *Trader) recordSessionValues(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		trader.CircuitBreaker.RecordProfit(time.Now(), trader.sessionValue(ctx))
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// sessionValue returns the total value of the session balances in the reporting currency (USDT by default),
+// the currencies that can not be converted are skipped.
+func (trader *Trader) sessionValue(ctx context.Context) (total float64) {
+	target := trader.ReportingCurrency
+	if len(target) == 0 {
+		target = "USDT"
+	}
+
+	prices := accounting.NewPriceMap()
+	for _, session := range trader.ExchangeSessions {
+		if session.Account == nil {
+			continue
+		}
+
+		balances := session.Account.Snapshot()
+
+		var currencies []string
+		for currency := range balances {
+			currencies = append(currencies, currency)
+		}
+
+		QueryConversionPrices(ctx, session.Exchange, prices, target, currencies...)
+
+		for currency, balance := range balances {
+			value, ok := accounting.ConvertCurrency(prices, balance.Available+balance.Locked, currency, target)
+			if !ok {
+				log.Warnf("session %s: %s price not found, the balance is not counted in the session value", session.Name, currency)
+				continue
+			}
+
+			total += value
+		}
+	}
+
+	return total
+}
@@ -0,0 +1,235 @@
+package bbgo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/fixedpoint"
+	"github.com/c9s/bbgo/types"
+)
+
+type testCrossExchange struct {
+	types.Exchange
+
+	name     string
+	balances types.BalanceMap
+	stream   *BackTestStream
+	orders   []types.SubmitOrder
+}
+
+func (e *testCrossExchange) Name() string {
+	return e.name
+}
+
+func (e *testCrossExchange) PlatformFeeCurrency() string {
+	return "BNB"
+}
+
+func (e *testCrossExchange) NewStream() types.Stream {
+	e.stream = &BackTestStream{}
+	return e.stream
+}
+
+func (e *testCrossExchange) QueryAccount(ctx context.Context) (*types.Account, error) {
+	return &types.Account{Balances: e.balances}, nil
+}
+
+func (e *testCrossExchange) QueryAccountBalances(ctx context.Context) (types.BalanceMap, error) {
+	return e.balances, nil
+}
+
+func (e *testCrossExchange) SubmitOrder(ctx context.Context, order *types.SubmitOrder) error {
+	e.orders = append(e.orders, *order)
+	return nil
+}
+
+type testCrossExchangeStrategy struct {
+	subscribed bool
+	shutdown   bool
+	runErr     error
+}
+
+func (s *testCrossExchangeStrategy) OnShutdown(ctx context.Context) error {
+	s.shutdown = true
+	return nil
+}
+
+func (s *testCrossExchangeStrategy) CrossSubscribe(sessions map[string]*ExchangeSession) error {
+	for _, session := range sessions {
+		session.Stream.Subscribe(types.BookChannel, "BTCUSDT", types.SubscribeOptions{})
+	}
+
+	s.subscribed = true
+	return nil
+}
+
+func (s *testCrossExchangeStrategy) CrossRun(ctx context.Context, sessions map[string]*ExchangeSession) error {
+	if err := sessions["a"].OrderExecutor.SubmitOrder(ctx, &types.SubmitOrder{
+		Symbol:   "BTCUSDT",
+		Side:     types.SideTypeBuy,
+		Type:     types.OrderTypeLimit,
+		Quantity: 0.01,
+		Price:    9000.0,
+	}); err != nil {
+		return err
+	}
+
+	// exceeds the max order notional of the session b
+	s.runErr = sessions["b"].OrderExecutor.SubmitOrder(ctx, &types.SubmitOrder{
+		Symbol:   "BTCUSDT",
+		Side:     types.SideTypeSell,
+		Type:     types.OrderTypeLimit,
+		Quantity: 1.0,
+		Price:    9100.0,
+	})
+
+	return nil
+}
+
+func TestTrader_RunCrossExchangeStrategies(t *testing.T) {
+	a := &testCrossExchange{name: "a", balances: types.BalanceMap{"USDT": {Currency: "USDT", Available: 1000.0}}}
+	b := &testCrossExchange{name: "b", balances: types.BalanceMap{"BTC": {Currency: "BTC", Available: 1.0}}}
+
+	trader := New(nil, nil, "")
+	for _, exchange := range []*testCrossExchange{a, b} {
+		session := trader.AddExchange(exchange.name, exchange).Symbols("BTCUSDT")
+
+		// nothing is synced or stored for the paper trading sessions
+		session.PaperTrade = true
+	}
+
+	trader.ExchangeSessions["b"].OrderExecutor = &ExchangeOrderExecutor{
+		Session:      trader.ExchangeSessions["b"],
+		RiskControls: OrderRiskControls{MaxOrderNotional: 1000.0},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	strategy := &testCrossExchangeStrategy{}
+	done, err := trader.RunCrossExchangeStrategies(ctx, strategy)
+	assert.NoError(t, err)
+	assert.True(t, strategy.subscribed)
+
+	for _, exchange := range []*testCrossExchange{a, b} {
+		session := trader.ExchangeSessions[exchange.name]
+		assert.Equal(t, exchange.balances["USDT"], session.Account.Snapshot()["USDT"])
+		assert.Len(t, exchange.stream.Subscriptions, 1)
+	}
+
+	if assert.Len(t, a.orders, 1) {
+		assert.Equal(t, "0.010000", a.orders[0].QuantityString)
+		assert.Equal(t, "9000.00", a.orders[0].PriceString)
+	}
+
+	assert.Len(t, b.orders, 0)
+	assert.Equal(t, ErrMaxOrderNotionalExceeded, errors.Cause(strategy.runErr))
+
+	cancel()
+	<-done
+	assert.True(t, strategy.shutdown)
+}
+
+func TestExchangeOrderExecutor(t *testing.T) {
+	ctx := context.Background()
+
+	exchange := &testCrossExchange{name: "a", balances: types.BalanceMap{"USDT": {Currency: "USDT", Available: 10000.0}}}
+	trader := New(nil, nil, "")
+	session := trader.AddExchange(exchange.name, exchange).Symbols("BTCUSDT")
+	session.PaperTrade = true
+	assert.NoError(t, trader.loadSession(ctx, session))
+
+	breaker := &CircuitBreaker{MaxLoss: 100.0}
+	trader.SetCircuitBreaker(breaker)
+
+	executor := &ExchangeOrderExecutor{
+		Session:      session,
+		Trader:       trader,
+		RiskControls: OrderRiskControls{MaxDailyVolume: 1000.0},
+	}
+
+	order := &types.SubmitOrder{
+		Symbol:   "BTCUSDT",
+		Side:     types.SideTypeBuy,
+		Type:     types.OrderTypeLimit,
+		Quantity: 0.05,
+		Price:    9000.0,
+	}
+	assert.NoError(t, executor.SubmitOrder(ctx, order))
+
+	// the traded volume of the session is counted in the daily volume cap
+	session.TradedVolume("BTCUSDT").AddTrade(types.Trade{Symbol: "BTCUSDT", Price: 9000.0, Quantity: 0.1, Time: time.Now()})
+	err := executor.SubmitOrder(ctx, order)
+	assert.Equal(t, ErrDailyVolumeCapExceeded, errors.Cause(err))
+
+	executor.RiskControls = OrderRiskControls{}
+	breaker.RecordProfit(time.Now(), 0.0)
+	breaker.RecordProfit(time.Now(), -200.0)
+	err = executor.SubmitOrder(ctx, order)
+	assert.Equal(t, ErrCircuitBreakerTripped, errors.Cause(err))
+
+	assert.Len(t, exchange.orders, 1)
+}
+
+// testTickerExchange is the test exchange that provides the last prices by the tickers
+type testTickerExchange struct {
+	*testCrossExchange
+
+	tickers map[string]types.Ticker
+}
+
+func (e *testTickerExchange) QueryTickers(ctx context.Context, symbols ...string) (map[string]types.Ticker, error) {
+	return e.tickers, nil
+}
+
+func TestExchangeOrderExecutor_MarketOrderNotional(t *testing.T) {
+	ctx := context.Background()
+
+	exchange := &testTickerExchange{
+		testCrossExchange: &testCrossExchange{name: "a", balances: types.BalanceMap{"USDT": {Currency: "USDT", Available: 10000.0}}},
+	}
+	trader := New(nil, nil, "")
+	session := trader.AddExchange(exchange.name, exchange).Symbols("BTCUSDT")
+	session.PaperTrade = true
+	assert.NoError(t, trader.loadSession(ctx, session))
+
+	executor := &ExchangeOrderExecutor{
+		Session:      session,
+		RiskControls: OrderRiskControls{MaxOrderNotional: 1000.0},
+	}
+
+	newMarketOrder := func(side types.SideType, quantity float64) *types.SubmitOrder {
+		return &types.SubmitOrder{Symbol: "BTCUSDT", Side: side, Type: types.OrderTypeMarket, Quantity: quantity}
+	}
+
+	// the order is rejected if the price can not be estimated
+	err := executor.SubmitOrder(ctx, newMarketOrder(types.SideTypeBuy, 0.05))
+	assert.Equal(t, ErrOrderPriceUnknown, errors.Cause(err))
+
+	// the last price of the ticker is used without the order book
+	exchange.tickers = map[string]types.Ticker{"BTCUSDT": {Last: 9000.0}}
+	err = executor.SubmitOrder(ctx, newMarketOrder(types.SideTypeBuy, 0.2))
+	assert.Equal(t, ErrMaxOrderNotionalExceeded, errors.Cause(err))
+	assert.NoError(t, executor.SubmitOrder(ctx, newMarketOrder(types.SideTypeBuy, 0.1)))
+
+	// the best price of the opposite side of the order book is used
+	exchange.stream.EmitBookSnapshot(types.OrderBook{
+		Symbol: "BTCUSDT",
+		Bids:   types.PriceVolumeSlice{{Price: fixedpoint.NewFromFloat(9000.0), Volume: fixedpoint.NewFromFloat(1.0)}},
+		Asks:   types.PriceVolumeSlice{{Price: fixedpoint.NewFromFloat(11000.0), Volume: fixedpoint.NewFromFloat(1.0)}},
+	})
+	err = executor.SubmitOrder(ctx, newMarketOrder(types.SideTypeBuy, 0.1))
+	assert.Equal(t, ErrMaxOrderNotionalExceeded, errors.Cause(err))
+	assert.NoError(t, executor.SubmitOrder(ctx, newMarketOrder(types.SideTypeSell, 0.1)))
+
+	// the price is not needed without the notional limits
+	executor.RiskControls = OrderRiskControls{}
+	exchange.tickers = nil
+	assert.NoError(t, executor.SubmitOrder(ctx, newMarketOrder(types.SideTypeBuy, 1.0)))
+
+	assert.Len(t, exchange.orders, 3)
+}
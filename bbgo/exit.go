@@ -0,0 +1,466 @@
+package bbgo
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/service"
+	"github.com/c9s/bbgo/types"
+)
+
+// TakeProfitLevel sells the quantity ratio of the initial position when the price reaches the ratio above the entry price
+type TakeProfitLevel struct {
+	// Ratio is the price ratio above the entry price, e.g., 0.05 for +5%
+	Ratio float64 `json:"ratio" yaml:"ratio"`
+
+	// QuantityRatio is the ratio of the initial position quantity to sell, e.g., 0.5 for half of the position
+	QuantityRatio float64 `json:"quantityRatio" yaml:"quantityRatio"`
+}
+
+// ExitConfig is the exit rules of the long position, the zero values disable the rules.
+//
+// The position is opened by the buy fills of the symbol and reduced by every sell fill of the symbol, not only by
+// the fills of the exit orders, so the sells of the strategy, the other strategies of the symbol on the same account
+// and the manual sells all reduce the watched position.
+type ExitConfig struct {
+	// TrailingStop closes the position when the price falls the ratio below the peak price since the entry
+	TrailingStop float64 `json:"trailingStop" yaml:"trailingStop"`
+
+	// StopLoss closes the position when the price falls the ratio below the entry price
+	StopLoss float64 `json:"stopLoss" yaml:"stopLoss"`
+
+	// TakeProfits is the take-profit ladder, the levels must be in the ascending order of the ratio
+	TakeProfits []TakeProfitLevel `json:"takeProfits" yaml:"takeProfits"`
+}
+
+func (c ExitConfig) Validate() error {
+	if c.TrailingStop < 0 || c.TrailingStop >= 1 {
+		return fmt.Errorf("exit: trailingStop must be between 0 and 1")
+	}
+
+	if c.StopLoss < 0 || c.StopLoss >= 1 {
+		return fmt.Errorf("exit: stopLoss must be between 0 and 1")
+	}
+
+	var lastRatio, quantityRatio float64
+	for i, level := range c.TakeProfits {
+		if level.Ratio <= lastRatio {
+			return fmt.Errorf("exit: take-profit level %d ratio %f must be greater than %f", i, level.Ratio, lastRatio)
+		}
+
+		if level.QuantityRatio <= 0 {
+			return fmt.Errorf("exit: take-profit level %d quantity ratio must be positive", i)
+		}
+
+		lastRatio = level.Ratio
+		quantityRatio += level.QuantityRatio
+	}
+
+	if quantityRatio > 1.0+1e-9 {
+		return fmt.Errorf("exit: the sum of the take-profit quantity ratios %f is greater than 1", quantityRatio)
+	}
+
+	return nil
+}
+
+// OrderSubmitter submits the order and returns the submission error, e.g., types.Exchange and ExchangeOrderExecutor
+type OrderSubmitter interface {
+	SubmitOrder(ctx context.Context, order *types.SubmitOrder) error
+}
+
+// ExitManager watches the long position of the symbol after the entry, and submits the market sell orders when
+// the stop loss, the trailing stop or the take-profit levels are triggered by the price updates.
+// The sell orders are submitted by the order submitter directly, the order processor profit check is not applied
+// since the stop loss sells below the cost.
+//
+// The position is reduced by the sell fills of the symbol, the quantity of the exit orders that are not filled yet
+// is not sold again. The exit orders are tagged by the client order id of the exitOrderPrefix.
+//
+// The position state is saved by the position exit service after every change, use Load to restore it after restarts.
+// Trader watches the position of the strategy when the exit rules are set by the "exit" section of the strategy config.
+type ExitManager struct {
+	ExitConfig
+
+	Exchange string
+	Market   types.Market
+
+	Submitter OrderSubmitter
+
+	// Service persists the position state, the state is kept in memory only when it's nil
+	Service *service.PositionExitService
+
+	Notifiers []Notifier
+
+	mu       sync.Mutex
+	position *service.PositionExit
+
+	// ownTrades tells the fills of the exit orders
+	ownTrades *OwnTradeFilter
+
+	// pending is the unfilled quantity of the exit orders by the client order id,
+	// clientOrderIDs maps the order ids of the exit orders to their client order ids
+	pending        map[string]float64
+	clientOrderIDs map[uint64]string
+}
+
+// exitOrderPrefix is the client order id prefix of the exit orders
+const exitOrderPrefix = "exit"
+
+func NewExitManager(config ExitConfig, exchange string, market types.Market, submitter OrderSubmitter, positionExitService *service.PositionExitService) *ExitManager {
+	return &ExitManager{
+		ExitConfig: config,
+		Exchange:   exchange,
+		Market:     market,
+		Submitter:  submitter,
+		Service:    positionExitService,
+
+		ownTrades:      NewOwnTradeFilter(exitOrderPrefix),
+		pending:        make(map[string]float64),
+		clientOrderIDs: make(map[uint64]string),
+	}
+}
+
+func (m *ExitManager) notify(msg string, args ...interface{}) {
+	for _, n := range m.Notifiers {
+		n.Notify(msg, args...)
+	}
+}
+
+// Load restores the persisted position of the exchange and the symbol
+func (m *ExitManager) Load() error {
+	if m.Service == nil {
+		return nil
+	}
+
+	position, err := m.Service.Query(m.Exchange, m.Market.Symbol)
+	if err != nil {
+		return err
+	}
+
+	if position != nil {
+		log.Infof("exit: restored %s position %f, entry price %f, peak price %f, %d take-profit levels triggered",
+			position.Symbol, position.Quantity, position.EntryPrice, position.PeakPrice, position.TakeProfits)
+	}
+
+	m.mu.Lock()
+	m.position = position
+	m.mu.Unlock()
+	return nil
+}
+
+// Position returns a copy of the watched position, false is returned if there is no open position
+func (m *ExitManager) Position() (service.PositionExit, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.position == nil {
+		return service.PositionExit{}, false
+	}
+
+	return *m.position, true
+}
+
+// Open adds the entry to the watched position, the entry price is averaged by the quantity.
+// The peak price is reset to the price of the new entry, so the trailing stop starts over from the entry,
+// and the take-profit levels are re-armed for the whole position after the entry.
+func (m *ExitManager) Open(price, quantity float64) error {
+	if price <= 0 || quantity <= 0 {
+		return fmt.Errorf("exit: invalid entry price %f or quantity %f", price, quantity)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// the position is changed on the copy, so the watched position is kept if the save fails
+	var position = service.PositionExit{
+		Exchange: m.Exchange,
+		Symbol:   m.Market.Symbol,
+		OpenedAt: time.Now(),
+	}
+
+	if m.position != nil {
+		position = *m.position
+	}
+
+	position.EntryPrice = (position.EntryPrice*position.Quantity + price*quantity) / (position.Quantity + quantity)
+	position.Quantity += quantity
+	position.InitialQuantity = position.Quantity
+	position.PeakPrice = price
+	position.TakeProfits = 0
+	return m.commit(position)
+}
+
+// pendingQuantity returns the unfilled quantity of the exit orders, it must be called with the lock held
+func (m *ExitManager) pendingQuantity() (quantity float64) {
+	for _, q := range m.pending {
+		quantity += math.Max(q, 0)
+	}
+
+	return quantity
+}
+
+// reduce reduces the position by the sell fill, the position is closed when the rest is less than the min quantity.
+// It must be called with the lock held.
+func (m *ExitManager) reduce(quantity float64) error {
+	if m.position == nil {
+		return nil
+	}
+
+	position := *m.position
+	position.Quantity -= quantity
+
+	if position.Quantity < m.Market.MinQuantity || position.Quantity <= 0 {
+		m.position = nil
+		m.pending = make(map[string]float64)
+		m.clientOrderIDs = make(map[uint64]string)
+
+		if m.Service == nil {
+			return nil
+		}
+
+		return m.Service.Delete(m.Exchange, m.Market.Symbol)
+	}
+
+	// the position is sold already, it's kept even if the save fails
+	m.position = &position
+	return m.save(m.position)
+}
+
+// handleOrderUpdate tracks the unfilled quantity of the exit order, the fills of the order that arrived before the
+// order update are taken from the pending quantity
+func (m *ExitManager) handleOrderUpdate(order types.Order) {
+	if order.Symbol != m.Market.Symbol {
+		return
+	}
+
+	own, trades := m.ownTrades.HandleOrderUpdate(order)
+	if !own {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, trade := range trades {
+		m.pending[order.ClientOrderID] -= trade.Quantity
+	}
+
+	switch order.Status {
+	case types.OrderStatusNew, types.OrderStatusPartiallyFilled:
+		m.clientOrderIDs[order.OrderID] = order.ClientOrderID
+
+	default:
+		delete(m.pending, order.ClientOrderID)
+		delete(m.clientOrderIDs, order.OrderID)
+	}
+}
+
+// handleTrade reduces the position by the sell fill of the symbol
+func (m *ExitManager) handleTrade(trade types.Trade) error {
+	if trade.Symbol != m.Market.Symbol || trade.IsBuyer {
+		return nil
+	}
+
+	own := m.ownTrades.HandleTrade(trade)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if clientOrderID, ok := m.clientOrderIDs[trade.OrderID]; own && ok {
+		m.pending[clientOrderID] -= trade.Quantity
+	}
+
+	return m.reduce(trade.Quantity)
+}
+
+func (m *ExitManager) save(position *service.PositionExit) error {
+	if m.Service == nil {
+		return nil
+	}
+
+	position.UpdatedAt = time.Now()
+	return m.Service.Save(*position)
+}
+
+// BindStream updates the position by the closed prices of the klines and reduces the position by the sell fills
+func (m *ExitManager) BindStream(stream types.Stream) {
+	stream.OnKLineClosed(func(kline types.KLine) {
+		if kline.Symbol != m.Market.Symbol {
+			return
+		}
+
+		if err := m.Update(context.Background(), kline.Close); err != nil {
+			log.WithError(err).Errorf("exit: %s position update error", m.Market.Symbol)
+		}
+	})
+
+	stream.OnOrderUpdate(m.handleOrderUpdate)
+	stream.OnTrade(func(trade *types.Trade) {
+		if err := m.handleTrade(*trade); err != nil {
+			log.WithError(err).Errorf("exit: %s position update error", m.Market.Symbol)
+		}
+	})
+}
+
+// BindOrderBook updates the position by the best bid price of the order book,
+// the fills are still bound by BindStream of the user data stream
+func (m *ExitManager) BindOrderBook(book *types.StreamOrderBook) {
+	handler := func(book *types.OrderBook) {
+		if len(book.Bids) == 0 {
+			return
+		}
+
+		if err := m.Update(context.Background(), book.Bids[0].Price.Float64()); err != nil {
+			log.WithError(err).Errorf("exit: %s position update error", m.Market.Symbol)
+		}
+	}
+
+	book.OnLoad(handler)
+	book.OnUpdate(handler)
+}
+
+// Update tracks the peak price and submits the sell order when an exit rule is triggered by the price,
+// the position is kept unchanged if the order submission fails, so the exit is retried at the next update.
+// The position is reduced when the sell order is filled.
+func (m *ExitManager) Update(ctx context.Context, price float64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.position == nil || price <= 0 {
+		return nil
+	}
+
+	// the position is changed on the copy, so the watched position is kept if the save fails
+	position := *m.position
+
+	peakChanged := price > position.PeakPrice
+	if peakChanged {
+		position.PeakPrice = price
+	}
+
+	// the quantity of the exit orders that are not filled yet can not be sold again
+	available := position
+	available.Quantity -= m.pendingQuantity()
+
+	var reason string
+	var quantity float64
+	var takeProfits = position.TakeProfits
+	if available.Quantity > 0 {
+		reason, quantity, takeProfits = m.exitQuantity(&available, price)
+	}
+
+	if quantity == 0 {
+		if peakChanged {
+			return m.commit(position)
+		}
+
+		return nil
+	}
+
+	order := &types.SubmitOrder{
+		Symbol:         m.Market.Symbol,
+		Side:           types.SideTypeSell,
+		Type:           types.OrderTypeMarket,
+		Market:         m.Market,
+		Quantity:       quantity,
+		QuantityString: m.Market.FormatVolume(quantity),
+		ClientOrderID:  NewClientOrderID(exitOrderPrefix),
+	}
+
+	if err := m.Submitter.SubmitOrder(ctx, order); err != nil {
+		if peakChanged {
+			if err := m.commit(position); err != nil {
+				log.WithError(err).Errorf("exit: %s position save error", m.Market.Symbol)
+			}
+		}
+
+		return err
+	}
+
+	log.Infof("exit: %s %s triggered at %f, selling %f", m.Market.Symbol, reason, price, quantity)
+	m.notify(":checkered_flag: %s %s triggered at %f, selling %f of the position entered at %f",
+		m.Market.Symbol, reason, price, quantity, position.EntryPrice)
+
+	m.pending[order.ClientOrderID] = quantity
+
+	// the order is submitted already, the triggered levels are kept even if the save fails
+	position.TakeProfits = takeProfits
+	m.position = &position
+	return m.save(m.position)
+}
+
+// commit saves the position and replaces the watched position with it, it must be called with the lock held
+func (m *ExitManager) commit(position service.PositionExit) error {
+	if err := m.save(&position); err != nil {
+		return err
+	}
+
+	m.position = &position
+	return nil
+}
+
+// exitQuantity returns the triggered exit rule, the quantity to sell and the number of the triggered take-profit levels,
+// the stop loss and the trailing stop close the whole position
+func (m *ExitManager) exitQuantity(position *service.PositionExit, price float64) (reason string, quantity float64, takeProfits int) {
+	takeProfits = position.TakeProfits
+
+	switch {
+	case m.StopLoss > 0 && price <= position.EntryPrice*(1.0-m.StopLoss):
+		return "stop loss", position.Quantity, takeProfits
+
+	case m.TrailingStop > 0 && price <= position.PeakPrice*(1.0-m.TrailingStop):
+		return "trailing stop", position.Quantity, takeProfits
+	}
+
+	for takeProfits < len(m.TakeProfits) && price >= position.EntryPrice*(1.0+m.TakeProfits[takeProfits].Ratio) {
+		quantity += position.InitialQuantity * m.TakeProfits[takeProfits].QuantityRatio
+		takeProfits++
+	}
+
+	if quantity == 0 {
+		return "", 0, takeProfits
+	}
+
+	quantity = math.Min(quantity, position.Quantity)
+
+	// the rest that is less than the min quantity can not be sold by itself
+	if position.Quantity-quantity < m.Market.MinQuantity {
+		quantity = position.Quantity
+	}
+
+	return fmt.Sprintf("take-profit level %d", takeProfits), quantity, takeProfits
+}
+
+// bindExitManager watches the long position of the trader symbol by the exit rules, the buy trades open the position.
+// The position is kept in memory only for the paper trading trader.
+func (trader *Trader) bindExitManager(stream types.Stream) error {
+	var positionExitService *service.PositionExitService
+	if !trader.IsPaperTrade() && trader.TradeService.DB != nil {
+		positionExitService = service.NewPositionExitService(trader.TradeService.DB)
+	}
+
+	manager := NewExitManager(*trader.Exit, trader.Exchange.Name(), trader.Context.Market, trader.Exchange, positionExitService)
+	manager.Notifiers = trader.Notifiers
+	if err := manager.Load(); err != nil {
+		return err
+	}
+
+	manager.BindStream(stream)
+	stream.OnTrade(func(trade *types.Trade) {
+		if trade.Symbol != trader.Symbol || !trade.IsBuyer {
+			return
+		}
+
+		if err := manager.Open(trade.Price, trade.Quantity); err != nil {
+			log.WithError(err).Errorf("exit: %s position open error", trader.Symbol)
+		}
+	})
+
+	trader.ExitManager = manager
+	return nil
+}
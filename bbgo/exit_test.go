@@ -0,0 +1,298 @@
+package bbgo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/fixedpoint"
+	"github.com/c9s/bbgo/migrations"
+	"github.com/c9s/bbgo/service"
+	"github.com/c9s/bbgo/types"
+)
+
+type testOrderSubmitter struct {
+	orders []types.SubmitOrder
+	err    error
+}
+
+func (s *testOrderSubmitter) SubmitOrder(ctx context.Context, order *types.SubmitOrder) error {
+	if s.err != nil {
+		return s.err
+	}
+
+	s.orders = append(s.orders, *order)
+	return nil
+}
+
+// fillExitOrder emits the order update and the sell trade of the submitted exit order
+func fillExitOrder(stream *BackTestStream, order types.SubmitOrder, orderID uint64, quantity float64, status types.OrderStatus) {
+	stream.EmitOrderUpdate(types.Order{SubmitOrder: order, OrderID: orderID, Status: status, ExecutedQuantity: quantity})
+	if quantity > 0 {
+		stream.EmitTrade(&types.Trade{ID: int64(orderID), OrderID: orderID, Symbol: order.Symbol, Price: order.Price, Quantity: quantity})
+	}
+}
+
+func newExitTestService(t *testing.T) (*sqlx.DB, *service.PositionExitService) {
+	db, err := sqlx.Connect("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db.SetMaxOpenConns(1)
+
+	if _, err := migrations.NewMigrator(db).Up(); err != nil {
+		t.Fatal(err)
+	}
+
+	return db, service.NewPositionExitService(db)
+}
+
+func TestExitConfig_Validate(t *testing.T) {
+	assert.NoError(t, ExitConfig{}.Validate())
+	assert.NoError(t, ExitConfig{TrailingStop: 0.03, StopLoss: 0.05, TakeProfits: []TakeProfitLevel{{0.05, 0.5}, {0.1, 0.5}}}.Validate())
+	assert.Error(t, ExitConfig{TrailingStop: 1.0}.Validate())
+	assert.Error(t, ExitConfig{StopLoss: -0.1}.Validate())
+	assert.Error(t, ExitConfig{TakeProfits: []TakeProfitLevel{{0.1, 0.5}, {0.05, 0.5}}}.Validate())
+	assert.Error(t, ExitConfig{TakeProfits: []TakeProfitLevel{{0.05, 0.6}, {0.1, 0.6}}}.Validate())
+	assert.Error(t, ExitConfig{TakeProfits: []TakeProfitLevel{{0.05, 0}}}.Validate())
+}
+
+func TestExitManager_TakeProfitsAndTrailingStop(t *testing.T) {
+	db, positionExitService := newExitTestService(t)
+	defer db.Close()
+
+	config := ExitConfig{
+		TrailingStop: 0.05,
+		StopLoss:     0.1,
+		TakeProfits:  []TakeProfitLevel{{Ratio: 0.05, QuantityRatio: 0.25}, {Ratio: 0.1, QuantityRatio: 0.25}},
+	}
+
+	submitter := &testOrderSubmitter{}
+	manager := NewExitManager(config, "binance", types.MarketBTCUSDT, submitter, positionExitService)
+	stream := &BackTestStream{}
+	manager.BindStream(stream)
+
+	assert.NoError(t, manager.Open(10000.0, 0.5))
+	assert.NoError(t, manager.Update(context.Background(), 10200.0))
+
+	// the peak is reset to the price of the new entry
+	assert.NoError(t, manager.Open(9000.0, 0.5))
+
+	position, ok := manager.Position()
+	if assert.True(t, ok) {
+		assert.Equal(t, 9500.0, position.EntryPrice)
+		assert.Equal(t, 1.0, position.Quantity)
+		assert.Equal(t, 9000.0, position.PeakPrice)
+	}
+
+	ctx := context.Background()
+
+	// +10.5%, both take-profit levels are triggered at once
+	assert.NoError(t, manager.Update(ctx, 10500.0))
+	assert.NoError(t, manager.Update(ctx, 10600.0))
+	if assert.Len(t, submitter.orders, 1) {
+		assert.Equal(t, types.SideTypeSell, submitter.orders[0].Side)
+		assert.Equal(t, types.OrderTypeMarket, submitter.orders[0].Type)
+		assert.Equal(t, 0.5, submitter.orders[0].Quantity)
+		assert.Equal(t, "0.500000", submitter.orders[0].QuantityString)
+		assert.True(t, HasClientOrderIDPrefix(submitter.orders[0].ClientOrderID, exitOrderPrefix))
+	}
+
+	// the position is reduced by the fill
+	position, _ = manager.Position()
+	assert.Equal(t, 1.0, position.Quantity)
+	fillExitOrder(stream, submitter.orders[0], 1, 0.5, types.OrderStatusFilled)
+
+	// the state survives the restart
+	restarted := NewExitManager(config, "binance", types.MarketBTCUSDT, submitter, positionExitService)
+	assert.NoError(t, restarted.Load())
+	restartedStream := &BackTestStream{}
+	restarted.BindStream(restartedStream)
+
+	position, ok = restarted.Position()
+	if assert.True(t, ok) {
+		assert.Equal(t, 0.5, position.Quantity)
+		assert.Equal(t, 10600.0, position.PeakPrice)
+		assert.Equal(t, 2, position.TakeProfits)
+	}
+
+	// 5% below the peak 10600
+	assert.NoError(t, restarted.Update(ctx, 10100.0))
+	assert.Len(t, submitter.orders, 1)
+	assert.NoError(t, restarted.Update(ctx, 10070.0))
+	if assert.Len(t, submitter.orders, 2) {
+		assert.Equal(t, 0.5, submitter.orders[1].Quantity)
+	}
+
+	// the trade that arrives before the order update closes the position
+	restartedStream.EmitTrade(&types.Trade{ID: 2, OrderID: 2, Symbol: "BTCUSDT", Price: 10070.0, Quantity: 0.5})
+	restartedStream.EmitOrderUpdate(types.Order{SubmitOrder: submitter.orders[1], OrderID: 2, Status: types.OrderStatusFilled, ExecutedQuantity: 0.5})
+
+	_, ok = restarted.Position()
+	assert.False(t, ok)
+
+	stored, err := positionExitService.Query("binance", "BTCUSDT")
+	assert.NoError(t, err)
+	assert.Nil(t, stored)
+}
+
+func TestExitManager_OpenRearmsTakeProfits(t *testing.T) {
+	submitter := &testOrderSubmitter{}
+	manager := NewExitManager(ExitConfig{TakeProfits: []TakeProfitLevel{{Ratio: 0.05, QuantityRatio: 0.5}}}, "binance", types.MarketBTCUSDT, submitter, nil)
+	stream := &BackTestStream{}
+	manager.BindStream(stream)
+
+	ctx := context.Background()
+	assert.NoError(t, manager.Open(10000.0, 0.2))
+	assert.NoError(t, manager.Update(ctx, 10500.0))
+	if assert.Len(t, submitter.orders, 1) {
+		assert.Equal(t, 0.1, submitter.orders[0].Quantity)
+	}
+
+	fillExitOrder(stream, submitter.orders[0], 1, 0.1, types.OrderStatusFilled)
+
+	// the new entry re-arms the take-profit level for the rest 0.1 and the new 0.3
+	assert.NoError(t, manager.Open(10000.0, 0.3))
+	position, ok := manager.Position()
+	if assert.True(t, ok) {
+		assert.Equal(t, 0, position.TakeProfits)
+		assert.InDelta(t, 0.4, position.InitialQuantity, 1e-9)
+		assert.InDelta(t, 10000.0, position.EntryPrice, 1e-9)
+	}
+
+	assert.NoError(t, manager.Update(ctx, 10500.0))
+	if assert.Len(t, submitter.orders, 2) {
+		assert.InDelta(t, 0.2, submitter.orders[1].Quantity, 1e-9)
+	}
+}
+
+func TestExitManager_StopLoss(t *testing.T) {
+	submitter := &testOrderSubmitter{err: errors.New("network error")}
+	manager := NewExitManager(ExitConfig{StopLoss: 0.05}, "binance", types.MarketBTCUSDT, submitter, nil)
+	assert.NoError(t, manager.Load())
+	assert.NoError(t, manager.Open(10000.0, 0.1))
+
+	stream := &BackTestStream{}
+	manager.BindStream(stream)
+
+	// the position is kept when the order submission fails
+	stream.EmitKLineClosed(types.KLine{Symbol: "BTCUSDT", Close: 9400.0})
+	position, ok := manager.Position()
+	assert.True(t, ok)
+	assert.Equal(t, 0.1, position.Quantity)
+
+	submitter.err = nil
+	stream.EmitKLineClosed(types.KLine{Symbol: "ETHUSDT", Close: 100.0})
+	assert.Len(t, submitter.orders, 0)
+
+	stream.EmitKLineClosed(types.KLine{Symbol: "BTCUSDT", Close: 9400.0})
+	if assert.Len(t, submitter.orders, 1) {
+		assert.Equal(t, 0.1, submitter.orders[0].Quantity)
+	}
+
+	// the unfilled quantity of the exit order is not sold again
+	stream.EmitKLineClosed(types.KLine{Symbol: "BTCUSDT", Close: 9300.0})
+	assert.Len(t, submitter.orders, 1)
+
+	// the rest of the partially filled order that is canceled is sold again
+	fillExitOrder(stream, submitter.orders[0], 1, 0.04, types.OrderStatusPartiallyFilled)
+	stream.EmitOrderUpdate(types.Order{SubmitOrder: submitter.orders[0], OrderID: 1, Status: types.OrderStatusCanceled, ExecutedQuantity: 0.04})
+
+	position, ok = manager.Position()
+	if assert.True(t, ok) {
+		assert.InDelta(t, 0.06, position.Quantity, 1e-9)
+	}
+
+	stream.EmitKLineClosed(types.KLine{Symbol: "BTCUSDT", Close: 9300.0})
+	if assert.Len(t, submitter.orders, 2) {
+		assert.InDelta(t, 0.06, submitter.orders[1].Quantity, 1e-9)
+	}
+
+	fillExitOrder(stream, submitter.orders[1], 2, submitter.orders[1].Quantity, types.OrderStatusFilled)
+	_, ok = manager.Position()
+	assert.False(t, ok)
+}
+
+func TestExitManager_OpenSaveError(t *testing.T) {
+	db, positionExitService := newExitTestService(t)
+
+	manager := NewExitManager(ExitConfig{StopLoss: 0.05}, "binance", types.MarketBTCUSDT, &testOrderSubmitter{}, positionExitService)
+	assert.NoError(t, manager.Open(10000.0, 0.1))
+
+	// the watched position is kept when the save fails
+	assert.NoError(t, db.Close())
+	assert.Error(t, manager.Open(9000.0, 0.1))
+
+	position, ok := manager.Position()
+	if assert.True(t, ok) {
+		assert.Equal(t, 10000.0, position.EntryPrice)
+		assert.Equal(t, 0.1, position.Quantity)
+	}
+}
+
+func TestTrader_BindExitManager(t *testing.T) {
+	exchange := &testCrossExchange{name: "binance"}
+	trader := &Trader{
+		Symbol:       "BTCUSDT",
+		Exchange:     exchange,
+		Context:      &Context{Market: types.MarketBTCUSDT},
+		TradeService: &service.TradeService{},
+		Exit:         &ExitConfig{StopLoss: 0.05},
+	}
+
+	stream := &BackTestStream{}
+	assert.NoError(t, trader.bindExitManager(stream))
+
+	// the buy trades open the position, the sell trades of the strategy reduce it
+	stream.EmitTrade(&types.Trade{ID: 1, OrderID: 1, Symbol: "BTCUSDT", Price: 10000.0, Quantity: 0.2, IsBuyer: true})
+	stream.EmitTrade(&types.Trade{ID: 2, OrderID: 2, Symbol: "BTCUSDT", Price: 10100.0, Quantity: 0.1})
+	stream.EmitTrade(&types.Trade{ID: 3, OrderID: 3, Symbol: "ETHUSDT", Price: 200.0, Quantity: 1.0, IsBuyer: true})
+
+	position, ok := trader.ExitManager.Position()
+	if assert.True(t, ok) {
+		assert.Equal(t, 10000.0, position.EntryPrice)
+		assert.InDelta(t, 0.1, position.Quantity, 1e-9)
+	}
+
+	stream.EmitKLineClosed(types.KLine{Symbol: "BTCUSDT", Close: 9400.0})
+	if assert.Len(t, exchange.orders, 1) {
+		assert.Equal(t, types.SideTypeSell, exchange.orders[0].Side)
+		assert.InDelta(t, 0.1, exchange.orders[0].Quantity, 1e-9)
+	}
+}
+
+func TestExitManager_BindOrderBook(t *testing.T) {
+	submitter := &testOrderSubmitter{}
+	manager := NewExitManager(ExitConfig{TakeProfits: []TakeProfitLevel{{Ratio: 0.05, QuantityRatio: 0.5}}}, "binance", types.MarketBTCUSDT, submitter, nil)
+	assert.NoError(t, manager.Open(10000.0, 0.1))
+
+	stream := &BackTestStream{}
+	book := types.NewStreamBook("BTCUSDT")
+	book.BindStream(stream)
+	manager.BindOrderBook(book)
+
+	// the fills are bound by the user data stream
+	manager.BindStream(stream)
+
+	stream.EmitBookSnapshot(types.OrderBook{
+		Symbol: "BTCUSDT",
+		Bids:   types.PriceVolumeSlice{{Price: fixedpoint.NewFromFloat(10600.0), Volume: fixedpoint.NewFromFloat(1.0)}},
+	})
+
+	if assert.Len(t, submitter.orders, 1) {
+		assert.Equal(t, 0.05, submitter.orders[0].Quantity)
+	}
+
+	fillExitOrder(stream, submitter.orders[0], 1, 0.05, types.OrderStatusFilled)
+
+	position, ok := manager.Position()
+	if assert.True(t, ok) {
+		assert.InDelta(t, 0.05, position.Quantity, 1e-9)
+		assert.Equal(t, 10600.0, position.PeakPrice)
+	}
+}
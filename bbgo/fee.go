@@ -0,0 +1,44 @@
+package bbgo
+
+import (
+	"context"
+
+	"github.com/c9s/bbgo/accounting"
+	"github.com/c9s/bbgo/types"
+)
+
+// DefaultFeeRate is used when the fee rates can not be queried from the exchange
+const DefaultFeeRate = accounting.DefaultFeeRate
+
+// FeeScheduleQuerier is implemented by the exchanges that can query the fee rates of the account
+type FeeScheduleQuerier interface {
+	QueryFeeSchedule(ctx context.Context) (*types.FeeSchedule, error)
+}
+
+// QueryFeeSchedule queries the fee schedule if the exchange supports it,
+// otherwise the commission rates of the account are used. Zero rates are valid rates of the zero-fee accounts.
+//
+// The symbol fee rates override the queried rates of the symbols, e.g., the zero-fee promotion pairs.
+func QueryFeeSchedule(ctx context.Context, exchange types.Exchange, symbolFeeRates map[string]types.FeeRates) (*types.FeeSchedule, error) {
+	var schedule *types.FeeSchedule
+	if querier, ok := exchange.(FeeScheduleQuerier); ok {
+		var err error
+		schedule, err = querier.QueryFeeSchedule(ctx)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		account, err := exchange.QueryAccount(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		schedule = account.FeeSchedule()
+	}
+
+	for symbol, rates := range symbolFeeRates {
+		schedule.SetSymbolFeeRates(symbol, rates)
+	}
+
+	return schedule, nil
+}
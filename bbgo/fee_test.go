@@ -0,0 +1,21 @@
+package bbgo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/types"
+)
+
+func TestQueryFeeSchedule(t *testing.T) {
+	// the zero commissions of the zero-fee account are not replaced by the default fee rate
+	schedule, err := QueryFeeSchedule(context.Background(), &testCrossExchange{}, map[string]types.FeeRates{
+		"BTCUSDT": {MakerFeeRate: -0.0001, TakerFeeRate: 0.0004},
+	})
+	if assert.NoError(t, err) {
+		assert.Equal(t, types.FeeRates{}, schedule.FeeRates("ETHUSDT"))
+		assert.Equal(t, types.FeeRates{MakerFeeRate: -0.0001, TakerFeeRate: 0.0004}, schedule.FeeRates("BTCUSDT"))
+	}
+}
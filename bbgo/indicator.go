@@ -1,25 +1,30 @@
 package bbgo
 
 import (
-	"math"
+	"sync"
 	"time"
 
 	"github.com/c9s/bbgo/types"
 )
 
+// MovingAverageIndicator calculates the simple moving average of the closed prices of every interval in the store
 type MovingAverageIndicator struct {
-	store *MarketDataStore
+	store  *MarketDataStore
 	Period int
+
+	mu     sync.Mutex
+	values map[Interval]IndicatorValue
 }
 
 func NewMovingAverageIndicator(period int) *MovingAverageIndicator {
 	return &MovingAverageIndicator{
 		Period: period,
+		values: make(map[Interval]IndicatorValue),
 	}
 }
 
 func (i *MovingAverageIndicator) handleUpdate(kline types.KLine) {
-	klines, ok := i.store.KLineWindows[ Interval(kline.Interval) ]
+	klines, ok := i.store.KLineWindows[Interval(kline.Interval)]
 	if !ok {
 		return
 	}
@@ -28,36 +33,46 @@ func (i *MovingAverageIndicator) handleUpdate(kline types.KLine) {
 		return
 	}
 
-	// calculate ma
+	values := calculateMovingAverage(klines.Tail(i.Period), i.Period)
+	if len(values) == 0 {
+		return
+	}
+
+	i.mu.Lock()
+	i.values[Interval(kline.Interval)] = values[len(values)-1]
+	i.mu.Unlock()
+}
+
+// Last returns the moving average of the latest kline of the interval,
+// false is returned if the store doesn't have enough klines yet.
+func (i *MovingAverageIndicator) Last(interval Interval) (IndicatorValue, bool) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	value, ok := i.values[interval]
+	return value, ok
 }
 
 type IndicatorValue struct {
 	Value float64
-	Time time.Time
+	Time  time.Time
 }
 
+// calculateMovingAverage returns the moving averages of the windows ending at each kline since the period-th kline
 func calculateMovingAverage(klines types.KLineWindow, period int) (values []IndicatorValue) {
-	for idx := range klines[period:] {
-		offset := idx + period
-		sum := klines[offset - period:offset].ReduceClose()
+	for offset := period; offset <= len(klines); offset++ {
+		sum := klines[offset-period : offset].ReduceClose()
 		values = append(values, IndicatorValue{
-			Time:  klines[offset].GetEndTime(),
-			Value: math.Round(sum / float64(period)),
+			Time:  klines[offset-1].GetEndTime(),
+			Value: sum / float64(period),
 		})
 	}
 	return values
 }
 
-
-
-
 func (i *MovingAverageIndicator) SubscribeStore(store *MarketDataStore) {
 	i.store = store
 
 	// register kline update callback
 	store.OnUpdate(i.handleUpdate)
 }
-
-
-
-
@@ -1,17 +1,53 @@
 package bbgo
 
-
 import (
 	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
 
 	"github.com/c9s/bbgo/types"
 )
 
 func TestCalculateMovingAverage(t *testing.T) {
+	now := time.Now()
 	klines := types.KLineWindow{
-		{
+		{Close: 1.0, EndTime: now},
+		{Close: 2.0, EndTime: now.Add(time.Minute)},
+		{Close: 3.5, EndTime: now.Add(2 * time.Minute)},
+	}
 
-		},
+	values := calculateMovingAverage(klines, 2)
+	if assert.Len(t, values, 2) {
+		assert.Equal(t, 1.5, values[0].Value)
+		assert.Equal(t, now.Add(time.Minute), values[0].Time)
+		assert.Equal(t, 2.75, values[1].Value)
 	}
-	_ = klines
+
+	assert.Len(t, calculateMovingAverage(klines, 4), 0)
+}
+
+func TestMovingAverageIndicator(t *testing.T) {
+	store := NewMarketDataStore()
+	indicator := NewMovingAverageIndicator(3)
+	indicator.SubscribeStore(store)
+
+	for _, price := range []float64{9000.0, 9100.0} {
+		store.AddKLine(types.KLine{Symbol: "BTCUSDT", Interval: "1h", Close: price})
+	}
+
+	_, ok := indicator.Last(Interval1h)
+	assert.False(t, ok)
+
+	for _, price := range []float64{9200.0, 9600.0} {
+		store.AddKLine(types.KLine{Symbol: "BTCUSDT", Interval: "1h", Close: price})
+	}
+
+	value, ok := indicator.Last(Interval1h)
+	assert.True(t, ok)
+	assert.InDelta(t, 9300.0, value.Value, 1e-9)
+	assert.Len(t, store.KLineWindows[Interval1h], 4)
+
+	_, ok = indicator.Last(Interval1m)
+	assert.False(t, ok)
 }
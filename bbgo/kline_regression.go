@@ -24,12 +24,18 @@ func (s *BackTestStream) Close() error {
 	return nil
 }
 
+// DefaultBackTestFeeRate is the fee rate used by the back test when the fee schedule is not set
+const DefaultBackTestFeeRate = 0.001
+
 type BackTestTrader struct {
 	// Context is trading Context
 	Context                 *Context
 	SourceKLines            []types.KLine
 	ProfitAndLossCalculator *accounting.ProfitAndLossCalculator
 
+	// FeeSchedule is the simulated fee rates, DefaultBackTestFeeRate is used when it's not set
+	FeeSchedule *types.FeeSchedule
+
 	doneOrders    []*types.SubmitOrder
 	pendingOrders []*types.SubmitOrder
 }
@@ -44,15 +50,28 @@ func (trader *BackTestTrader) RunStrategy(ctx context.Context, strategy MarketSt
 	done := make(chan struct{})
 	defer close(done)
 
+	if trader.Context.MarketDataStore == nil {
+		trader.Context.MarketDataStore = NewMarketDataStore()
+	}
+
 	if err := strategy.OnLoad(trader.Context, trader); err != nil {
 		return nil, err
 	}
 
 	stream := &BackTestStream{}
+	trader.Context.MarketDataStore.BindPrivateStream(stream)
 	if err := strategy.OnNewStream(stream); err != nil {
 		return nil, err
 	}
 
+	feeSchedule := trader.FeeSchedule
+	if feeSchedule == nil {
+		feeSchedule = types.NewFeeSchedule(DefaultBackTestFeeRate, DefaultBackTestFeeRate)
+	}
+	feeRates := feeSchedule.FeeRates(trader.Context.Symbol)
+	trader.Context.FeeSchedule = feeSchedule
+	trader.ProfitAndLossCalculator.FeeRate = feeRates.TakerFeeRate
+
 	var tradeID int64 = 0
 	for _, kline := range trader.SourceKLines {
 		logrus.Debugf("kline %+v", kline)
@@ -77,12 +96,13 @@ func (trader *BackTestTrader) RunStrategy(ctx context.Context, strategy MarketSt
 			}
 
 			volume := util.MustParseFloat(order.QuantityString)
+			feeRate := feeRates.FeeRate(order.Type)
 			fee := 0.0
 			feeCurrency := ""
 
 			trader.Context.Lock()
 			if order.Side == types.SideTypeBuy {
-				fee = price * volume * 0.001
+				fee = price * volume * feeRate
 				feeCurrency = "USDT"
 
 				quote := trader.Context.Balances[trader.Context.Market.QuoteCurrency]
@@ -98,7 +118,7 @@ func (trader *BackTestTrader) RunStrategy(ctx context.Context, strategy MarketSt
 				trader.Context.Balances[trader.Context.Market.BaseCurrency] = base
 
 			} else {
-				fee = volume * 0.001
+				fee = volume * feeRate
 				feeCurrency = "BTC"
 
 				base := trader.Context.Balances[trader.Context.Market.BaseCurrency]
@@ -121,7 +141,7 @@ func (trader *BackTestTrader) RunStrategy(ctx context.Context, strategy MarketSt
 				Quantity:    volume,
 				Side:        string(order.Side),
 				IsBuyer:     order.Side == types.SideTypeBuy,
-				IsMaker:     false,
+				IsMaker:     order.Type != types.OrderTypeMarket,
 				Time:        kline.EndTime,
 				Symbol:      trader.Context.Symbol,
 				Fee:         fee,
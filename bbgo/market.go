@@ -1,2 +1,95 @@
 package bbgo
 
+import (
+	"context"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/accounting"
+	"github.com/c9s/bbgo/types"
+)
+
+// AveragePriceQuerier is implemented by the exchanges that provide the average price API, e.g., binance
+type AveragePriceQuerier interface {
+	QueryAveragePrice(ctx context.Context, symbol string) (float64, error)
+}
+
+// QueryCurrentPrice queries the average price if the exchange supports it,
+// otherwise the close price of the last 1m kline is used.
+func QueryCurrentPrice(ctx context.Context, exchange types.Exchange, symbol string) (float64, error) {
+	if querier, ok := exchange.(AveragePriceQuerier); ok {
+		return querier.QueryAveragePrice(ctx, symbol)
+	}
+
+	klines, err := exchange.QueryKLines(ctx, symbol, "1m", types.KLineQueryOptions{Limit: 1})
+	if err != nil {
+		return 0, err
+	}
+
+	if len(klines) == 0 {
+		return 0, fmt.Errorf("%s kline not found", symbol)
+	}
+
+	return klines[len(klines)-1].Close, nil
+}
+
+// QueryConversionPrices queries the prices of the markets that can convert the given currencies to the target currency,
+// the currencies that can not be converted are skipped.
+func QueryConversionPrices(ctx context.Context, exchange types.Exchange, prices *accounting.PriceMap, target string, currencies ...string) {
+	for _, currency := range currencies {
+		if len(currency) == 0 || currency == target {
+			continue
+		}
+
+		for _, symbol := range accounting.ConversionSymbols(currency, target) {
+			if _, ok := types.FindMarket(symbol); !ok {
+				continue
+			}
+
+			price, err := QueryCurrentPrice(ctx, exchange, symbol)
+			if err != nil {
+				log.WithError(err).Warnf("can not query %s price for converting %s to %s", symbol, currency, target)
+				continue
+			}
+
+			prices.SetPrice(symbol, price)
+			break
+		}
+	}
+}
+
+// QueryLastPrices queries the last prices of the symbols from the tickers if the exchange supports the ticker query,
+// otherwise the current price of every symbol is queried.
+func QueryLastPrices(ctx context.Context, exchange types.Exchange, symbols ...string) (map[string]float64, error) {
+	var prices = make(map[string]float64)
+
+	if querier, ok := exchange.(types.TickerQuerier); ok {
+		tickers, err := querier.QueryTickers(ctx, symbols...)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, symbol := range symbols {
+			ticker, ok := tickers[symbol]
+			if !ok || ticker.Last <= 0 {
+				return nil, fmt.Errorf("%s ticker not found", symbol)
+			}
+
+			prices[symbol] = ticker.Last
+		}
+
+		return prices, nil
+	}
+
+	for _, symbol := range symbols {
+		price, err := QueryCurrentPrice(ctx, exchange, symbol)
+		if err != nil {
+			return nil, err
+		}
+
+		prices[symbol] = price
+	}
+
+	return prices, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"math"
+	"time"
 
 	"github.com/pkg/errors"
 
@@ -25,16 +26,9 @@ var (
 //  - Check and control the order amount
 //  - Adjust order amount due to the minAmount configuration and maxAmount configuration
 //  - Canonicalize the volume precision base on the given exchange
+//  - Check the position size, order notional, open orders and daily volume limits
 type OrderProcessor struct {
-	// balance control
-	MinQuoteBalance float64 `json:"minQuoteBalance"`
-	MaxAssetBalance float64 `json:"maxBaseAssetBalance"`
-	MinAssetBalance float64 `json:"minBaseAssetBalance"`
-
-	// MinProfitSpread is used when submitting sell orders, it check if there the selling can make the profit.
-	MinProfitSpread float64 `json:"minProfitSpread"`
-
-	MaxOrderAmount float64 `json:"maxOrderAmount"`
+	OrderRiskControls
 
 	Exchange types.Exchange `json:"-"`
 	Trader   *Trader        `json:"-"`
@@ -46,6 +40,10 @@ func (p *OrderProcessor) Submit(ctx context.Context, order *types.SubmitOrder) e
 	market := order.Market
 	quantity := order.Quantity
 
+	if err := p.checkCircuitBreaker(); err != nil {
+		return err
+	}
+
 	tradingCtx.Lock()
 	defer tradingCtx.Unlock()
 
@@ -73,6 +71,9 @@ func (p *OrderProcessor) Submit(ctx context.Context, order *types.SubmitOrder) e
 
 			quantity = adjustQuantityByMinAmount(quantity, currentPrice, market.MinAmount*1.01)
 			quantity = adjustQuantityByMaxAmount(quantity, currentPrice, available)
+			if util.NotZero(p.MaxOrderAmount) {
+				quantity = adjustQuantityByMaxAmount(quantity, currentPrice, p.MaxOrderAmount)
+			}
 			amount := quantity * currentPrice
 			if amount < market.MinAmount {
 				return fmt.Errorf("amount too small: %f < min amount %f", amount, market.MinAmount)
@@ -104,8 +105,16 @@ func (p *OrderProcessor) Submit(ctx context.Context, order *types.SubmitOrder) e
 			// 4 -> 0.0001 -> 0.001
 			tick10 := math.Pow10(-market.PricePrecision + 1)
 			minProfitSpread := math.Max(p.MinProfitSpread, tick10)
-			estimatedFee := currentPrice * 0.0015 * 2 // double the fee
-			targetPrice := currentPrice - estimatedFee - minProfitSpread
+			// the limit sell order is filled at its price, so the profit is checked by the order price
+			sellPrice := currentPrice
+			if order.Type == types.OrderTypeLimit && order.Price > 0.0 {
+				sellPrice = order.Price
+			}
+
+			// the fee of the buy order (unknown order type, so the taker fee rate is used) and this sell order
+			feeRates := tradingCtx.FeeRates()
+			estimatedFee := sellPrice * (feeRates.TakerFeeRate + feeRates.FeeRate(order.Type))
+			targetPrice := sellPrice - estimatedFee - minProfitSpread
 
 			stockQuantity := tradingCtx.StockManager.Stocks.QuantityBelowPrice(targetPrice)
 			if math.Round(stockQuantity*1e8) == 0.0 {
@@ -124,11 +133,140 @@ func (p *OrderProcessor) Submit(ctx context.Context, order *types.SubmitOrder) e
 		}
 	}
 
+	price := order.Price
+	if order.Type == types.OrderTypeMarket || price == 0.0 {
+		price = currentPrice
+	}
+
+	if err := p.checkRiskControls(ctx, order, quantity, price, tradingCtx.Balances, tradingCtx.TradedVolume); err != nil {
+		return err
+	}
+
 	order.Quantity = quantity
 	order.QuantityString = market.FormatVolume(quantity)
 	return p.Exchange.SubmitOrder(ctx, order)
 }
 
+// SubmitSessionOrder submits the order of a cross exchange strategy to the exchange of the session,
+// the circuit breaker and the risk controls are checked by the session balances and the session traded volume.
+// The quantity is not adjusted by the balances since the cross exchange strategies size their orders by themselves.
+// The notional of the market order is estimated by the session order book or the last price of the ticker,
+// the order is rejected if the price can not be estimated and a notional limit is set.
+func (p *OrderProcessor) SubmitSessionOrder(ctx context.Context, session *ExchangeSession, order *types.SubmitOrder) error {
+	if err := p.checkCircuitBreaker(); err != nil {
+		return err
+	}
+
+	var balances map[string]types.Balance
+	if session.Account != nil {
+		balances = session.Account.Snapshot()
+	}
+
+	price := order.Price
+	if (order.Type == types.OrderTypeMarket || price == 0.0) && (util.NotZero(p.MaxOrderNotional) || util.NotZero(p.MaxDailyVolume)) {
+		estimatedPrice, err := estimateSessionOrderPrice(ctx, session, order)
+		if err != nil {
+			return errors.Wrapf(ErrOrderPriceUnknown, "%s %s order: %s", order.Symbol, order.Type, err.Error())
+		}
+
+		price = estimatedPrice
+	}
+
+	if err := p.checkRiskControls(ctx, order, order.Quantity, price, balances, session.TradedVolume(order.Symbol)); err != nil {
+		return err
+	}
+
+	return p.Exchange.SubmitOrder(ctx, order)
+}
+
+// estimateSessionOrderPrice estimates the price of the order by the best price of the opposite side of the session order book,
+// the last price of the ticker is used if the order book is empty.
+func estimateSessionOrderPrice(ctx context.Context, session *ExchangeSession, order *types.SubmitOrder) (float64, error) {
+	if book := session.OrderBook(order.Symbol); book != nil {
+		book.Lock()
+		pvs := book.Bids
+		if order.Side == types.SideTypeBuy {
+			pvs = book.Asks
+		}
+
+		var price float64
+		if len(pvs) > 0 {
+			price = pvs[0].Price.Float64()
+		}
+		book.Unlock()
+
+		if price > 0 {
+			return price, nil
+		}
+	}
+
+	prices, err := QueryLastPrices(ctx, session.Exchange, order.Symbol)
+	if err != nil {
+		return 0, err
+	}
+
+	return prices[order.Symbol], nil
+}
+
+func (p *OrderProcessor) checkCircuitBreaker() error {
+	if p.Trader == nil {
+		return nil
+	}
+
+	if breaker := p.Trader.CircuitBreaker; breaker != nil && breaker.IsHalted() {
+		return errors.Wrapf(ErrCircuitBreakerTripped, "halted since %s", breaker.HaltedAt())
+	}
+
+	return nil
+}
+
+// checkRiskControls checks the order against the risk controls, the position size is calculated from the given balances
+// and the daily volume cap is skipped if the traded volume tracker is nil.
+func (p *OrderProcessor) checkRiskControls(ctx context.Context, order *types.SubmitOrder, quantity, price float64, balances map[string]types.Balance, tradedVolume *TradedVolumeTracker) error {
+	market := order.Market
+	notional := quantity * price
+
+	if util.NotZero(p.MaxOrderNotional) && notional > p.MaxOrderNotional {
+		return errors.Wrapf(ErrMaxOrderNotionalExceeded, "order notional %f > max order notional %f", notional, p.MaxOrderNotional)
+	}
+
+	if util.NotZero(p.MaxPositionSize) && order.Side == types.SideTypeBuy {
+		position := quantity
+		if balance, ok := balances[market.BaseCurrency]; ok {
+			position += balance.Available + balance.Locked
+		}
+
+		if position > p.MaxPositionSize {
+			return errors.Wrapf(ErrMaxPositionSizeExceeded, "position size %f > max position size %f", position, p.MaxPositionSize)
+		}
+	}
+
+	if util.NotZero(p.MaxDailyVolume) && tradedVolume != nil {
+		volume := tradedVolume.Volume(time.Now())
+		if volume+notional > p.MaxDailyVolume {
+			return errors.Wrapf(ErrDailyVolumeCapExceeded, "daily traded volume %f + order notional %f > daily volume cap %f", volume, notional, p.MaxDailyVolume)
+		}
+	}
+
+	if p.MaxOpenOrders > 0 {
+		querier, ok := p.Exchange.(OpenOrderQuerier)
+		if !ok {
+			return ErrOpenOrdersNotQueryable
+		}
+
+		openOrders, err := querier.QueryOpenOrders(ctx, order.Symbol)
+		if err != nil {
+			return err
+		}
+
+		if len(openOrders) >= p.MaxOpenOrders {
+			return errors.Wrapf(ErrMaxOpenOrdersExceeded, "%d open orders >= max open orders %d", len(openOrders), p.MaxOpenOrders)
+		}
+	}
+
+	return nil
+}
+
 func adjustQuantityByMinAmount(quantity float64, currentPrice float64, minAmount float64) float64 {
 	// modify quantity for the min amount
 	amount := currentPrice * quantity
@@ -1,13 +1,136 @@
 package bbgo
 
-
 import (
+	"context"
 	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/types"
 )
 
+type noOpenOrderExchange struct {
+	types.Exchange
+}
+
 func TestOrderProcessor(t *testing.T) {
-	processor := &OrderProcessor{}
-	_ = processor
+	ctx := context.Background()
+
+	newProcessor := func(controls OrderRiskControls) *OrderProcessor {
+		tradedVolume := &TradedVolumeTracker{}
+		tradedVolume.AddTrade(types.Trade{Symbol: "BTCUSDT", Price: 9000.0, Quantity: 0.1, Time: time.Now()})
+
+		return &OrderProcessor{
+			OrderRiskControls: controls,
+			Trader: &Trader{
+				Context: &Context{
+					Symbol:       "BTCUSDT",
+					Market:       types.MarketBTCUSDT,
+					CurrentPrice: 9000.0,
+					Balances: map[string]types.Balance{
+						"BTC":  {Currency: "BTC", Available: 0.5, Locked: 0.1},
+						"USDT": {Currency: "USDT", Available: 10000.0},
+					},
+					TradedVolume: tradedVolume,
+				},
+			},
+		}
+	}
+
+	order := &types.SubmitOrder{
+		Symbol: "BTCUSDT",
+		Side:   types.SideTypeBuy,
+		Type:   types.OrderTypeMarket,
+		Market: types.MarketBTCUSDT,
+	}
+
+	check := func(processor *OrderProcessor, quantity float64) error {
+		tradingCtx := processor.Trader.Context
+		return processor.checkRiskControls(ctx, order, quantity, 9000.0, tradingCtx.Balances, tradingCtx.TradedVolume)
+	}
+
+	t.Run("max order notional", func(t *testing.T) {
+		err := check(newProcessor(OrderRiskControls{MaxOrderNotional: 1000.0}), 0.2)
+		assert.Equal(t, ErrMaxOrderNotionalExceeded, errors.Cause(err))
+		assert.True(t, IsRiskControlError(err))
+	})
+
+	t.Run("max position size", func(t *testing.T) {
+		err := check(newProcessor(OrderRiskControls{MaxPositionSize: 0.7}), 0.2)
+		assert.Equal(t, ErrMaxPositionSizeExceeded, errors.Cause(err))
+
+		err = check(newProcessor(OrderRiskControls{MaxPositionSize: 1.0}), 0.2)
+		assert.NoError(t, err)
+	})
+
+	t.Run("daily volume cap", func(t *testing.T) {
+		err := check(newProcessor(OrderRiskControls{MaxDailyVolume: 1500.0}), 0.1)
+		assert.Equal(t, ErrDailyVolumeCapExceeded, errors.Cause(err))
+
+		err = check(newProcessor(OrderRiskControls{MaxDailyVolume: 1500.0}), 0.01)
+		assert.NoError(t, err)
+	})
+
+	t.Run("max open orders without open order query", func(t *testing.T) {
+		processor := newProcessor(OrderRiskControls{MaxOpenOrders: 5})
+		processor.Exchange = &noOpenOrderExchange{}
+
+		err := check(processor, 0.01)
+		assert.Equal(t, ErrOpenOrdersNotQueryable, errors.Cause(err))
+		assert.True(t, IsRiskControlError(err))
+	})
+
+	t.Run("limit sell profit check by the order price", func(t *testing.T) {
+		processor := newProcessor(OrderRiskControls{})
+		processor.Exchange = &testCrossExchange{}
+		processor.Trader.Context.StockManager = &StockManager{
+			Symbol: "BTCUSDT",
+			Stocks: StockSlice{{Symbol: "BTCUSDT", Price: 9050.0, Quantity: 0.5}},
+		}
+
+		sell := &types.SubmitOrder{
+			Symbol:   "BTCUSDT",
+			Side:     types.SideTypeSell,
+			Type:     types.OrderTypeLimit,
+			Market:   types.MarketBTCUSDT,
+			Quantity: 0.1,
+			Price:    9200.0,
+		}
+		assert.NoError(t, processor.Submit(ctx, sell))
+
+		// the stock bought at 9050 is not profitable when it's sold at 9060
+		sell.Price = 9060.0
+		assert.Error(t, processor.Submit(ctx, sell))
+	})
+}
+
+func TestRiskControlConfig_For(t *testing.T) {
+	config := &RiskControlConfig{
+		Sessions: map[string]OrderRiskControls{
+			"binance": {MaxOrderNotional: 1000.0, MaxOpenOrders: 10},
+		},
+		Strategies: map[string]OrderRiskControls{
+			"grid": {MaxOpenOrders: 20},
+		},
+	}
+
+	controls := config.For("binance", "grid")
+	assert.Equal(t, 1000.0, controls.MaxOrderNotional)
+	assert.Equal(t, 20, controls.MaxOpenOrders)
+
+	controls = config.For("max", "grid")
+	assert.Equal(t, 0.0, controls.MaxOrderNotional)
+	assert.Equal(t, 20, controls.MaxOpenOrders)
+}
 
+func TestOrderRiskControls_Merge(t *testing.T) {
+	controls := OrderRiskControls{MaxOrderNotional: 1000.0, MaxOpenOrders: 10, MaxDailyVolume: 5000.0}
+	controls = controls.Merge(OrderRiskControls{MaxOrderNotional: -1, MaxOpenOrders: -1, MaxPositionSize: 2.0})
 
+	assert.Equal(t, 0.0, controls.MaxOrderNotional)
+	assert.Equal(t, 0, controls.MaxOpenOrders)
+	assert.Equal(t, 2.0, controls.MaxPositionSize)
+	assert.Equal(t, 5000.0, controls.MaxDailyVolume)
 }
@@ -0,0 +1,76 @@
+package bbgo
+
+import (
+	"sync"
+
+	"github.com/c9s/bbgo/types"
+)
+
+// maxRecentOrders is the number of the recent order ids kept for matching the trades that arrive after the order update
+const maxRecentOrders = 1000
+
+// OwnTradeFilter tells the trades of the orders that are tagged by the client order id prefix of a strategy.
+// The trades only carry the order id, so the order updates tell which orders are the own orders,
+// the trades that arrive before the order update of their order are kept until the order update arrives.
+type OwnTradeFilter struct {
+	Prefix string
+
+	mu sync.Mutex
+
+	// orders tells whether the recent orders are the own orders by the order id,
+	// the oldest id is evicted from orderIDs first
+	orders   map[uint64]bool
+	orderIDs []uint64
+
+	// pending are the trades of the unknown orders by the order id
+	pending map[uint64][]types.Trade
+}
+
+func NewOwnTradeFilter(prefix string) *OwnTradeFilter {
+	return &OwnTradeFilter{
+		Prefix:  prefix,
+		orders:  make(map[uint64]bool),
+		pending: make(map[uint64][]types.Trade),
+	}
+}
+
+// HandleOrderUpdate returns whether the order is an own order, and the pending trades of the order if it's an own order
+func (f *OwnTradeFilter) HandleOrderUpdate(order types.Order) (own bool, trades []types.Trade) {
+	own = HasClientOrderIDPrefix(order.ClientOrderID, f.Prefix)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	trades = f.pending[order.OrderID]
+	delete(f.pending, order.OrderID)
+
+	if _, ok := f.orders[order.OrderID]; !ok {
+		f.orders[order.OrderID] = own
+		f.orderIDs = append(f.orderIDs, order.OrderID)
+
+		if len(f.orderIDs) > maxRecentOrders {
+			delete(f.orders, f.orderIDs[0])
+			f.orderIDs = f.orderIDs[1:]
+		}
+	}
+
+	if !own {
+		return false, nil
+	}
+
+	return true, trades
+}
+
+// HandleTrade returns whether the trade is a trade of an own order,
+// the trade of an unknown order is kept and returned by HandleOrderUpdate of the order
+func (f *OwnTradeFilter) HandleTrade(trade types.Trade) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	own, ok := f.orders[trade.OrderID]
+	if !ok {
+		f.pending[trade.OrderID] = append(f.pending[trade.OrderID], trade)
+	}
+
+	return own
+}
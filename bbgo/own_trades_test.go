@@ -0,0 +1,41 @@
+package bbgo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/types"
+)
+
+func TestOwnTradeFilter(t *testing.T) {
+	filter := NewOwnTradeFilter("dca")
+
+	own := types.Order{SubmitOrder: types.SubmitOrder{ClientOrderID: NewClientOrderID("dca")}, OrderID: 1}
+	foreign := types.Order{SubmitOrder: types.SubmitOrder{ClientOrderID: NewClientOrderID("grid")}, OrderID: 2}
+
+	// the trades of the unknown orders are kept until the order updates
+	assert.False(t, filter.HandleTrade(types.Trade{ID: 1, OrderID: 1}))
+	assert.False(t, filter.HandleTrade(types.Trade{ID: 2, OrderID: 2}))
+
+	isOwn, trades := filter.HandleOrderUpdate(own)
+	assert.True(t, isOwn)
+	assert.Equal(t, []types.Trade{{ID: 1, OrderID: 1}}, trades)
+
+	isOwn, trades = filter.HandleOrderUpdate(foreign)
+	assert.False(t, isOwn)
+	assert.Len(t, trades, 0)
+
+	// the trades of the known orders are told at once
+	assert.True(t, filter.HandleTrade(types.Trade{ID: 3, OrderID: 1}))
+	assert.False(t, filter.HandleTrade(types.Trade{ID: 4, OrderID: 2}))
+
+	_, trades = filter.HandleOrderUpdate(own)
+	assert.Len(t, trades, 0)
+
+	// the oldest orders are evicted
+	for i := 0; i < maxRecentOrders; i++ {
+		filter.HandleOrderUpdate(types.Order{OrderID: uint64(100 + i)})
+	}
+	assert.False(t, filter.HandleTrade(types.Trade{ID: 5, OrderID: 1}))
+}
@@ -0,0 +1,116 @@
+package bbgo
+
+import (
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/accounting"
+	"github.com/c9s/bbgo/service"
+	"github.com/c9s/bbgo/types"
+)
+
+// ProfitLedger records the realized profit, the fee and the holding time of each sell trade
+// by the stock lots consumed in the stock manager.
+type ProfitLedger struct {
+	// Exchange is the exchange name of the trades
+	Exchange string
+
+	Market       types.Market
+	StockManager *StockManager
+
+	// PriceSource is used for converting the fee paid in the third currency to the quote currency
+	PriceSource accounting.PriceSource
+
+	// ProfitService stores the profits, the profits are only kept in memory if it's nil
+	ProfitService *service.ProfitService
+
+	mu      sync.Mutex
+	profits []types.Profit
+}
+
+// NewProfit calculates the realized profit of the sell trade from the consumed lots,
+// the fee of the trade is charged pro rata to the matched quantity.
+func NewProfit(trade types.Trade, record *SellRecord, fee float64) types.Profit {
+	quantity := record.Quantity()
+	cost := record.Cost()
+
+	if trade.Quantity > 0 && quantity < trade.Quantity {
+		fee *= quantity / trade.Quantity
+	}
+
+	var holdingSeconds float64
+	for _, lot := range record.Lots {
+		holdingSeconds += trade.Time.Sub(lot.BuyTime).Seconds() * lot.Quantity
+	}
+
+	if quantity > 0 {
+		holdingSeconds /= quantity
+	}
+
+	return types.Profit{
+		Exchange:       trade.Exchange,
+		Symbol:         trade.Symbol,
+		TradeID:        trade.ID,
+		Price:          trade.Price,
+		Quantity:       quantity,
+		Cost:           cost,
+		Fee:            fee,
+		Profit:         trade.Price*quantity - cost - fee,
+		HoldingSeconds: int64(holdingSeconds),
+		Time:           trade.Time,
+	}
+}
+
+// AddTrade records the profit of the sell trade, the trade must be added to the stock manager first.
+// Only the quantity that is matched when the trade arrives is counted.
+func (l *ProfitLedger) AddTrade(trade types.Trade) (*types.Profit, error) {
+	if trade.IsBuyer || trade.Symbol != l.Market.Symbol {
+		return nil, nil
+	}
+
+	record, ok := l.StockManager.SellRecord(trade.ID)
+	if !ok {
+		log.Warnf("%s sell trade %d does not match any stock, profit is not recorded", trade.Symbol, trade.ID)
+		return nil, nil
+	}
+
+	fee, ok := accounting.TradeFee(l.Market, l.PriceSource, trade)
+	if !ok {
+		log.Warnf("%s price not found, the fee of trade %d is not converted to %s", trade.FeeCurrency, trade.ID, l.Market.QuoteCurrency)
+	}
+
+	profit := NewProfit(trade, record, fee)
+
+	l.mu.Lock()
+	l.profits = append(l.profits, profit)
+	l.mu.Unlock()
+
+	if l.ProfitService != nil {
+		if err := l.ProfitService.Insert(profit); err != nil {
+			return &profit, err
+		}
+	}
+
+	return &profit, nil
+}
+
+// QueryProfits returns the profits traded since the given time
+func (l *ProfitLedger) QueryProfits(since time.Time) ([]types.Profit, error) {
+	if l.ProfitService != nil {
+		return l.ProfitService.Query(l.Exchange, l.Market.Symbol, since)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var profits []types.Profit
+	for _, profit := range l.profits {
+		if !profit.Time.Before(since) {
+			profits = append(profits, profit)
+		}
+	}
+
+	return profits, nil
+}
@@ -0,0 +1,78 @@
+package bbgo
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+
+	"gopkg.in/yaml.v2"
+)
+
+var registryMu sync.Mutex
+
+// registeredStrategies is the strategy ID to strategy prototype map
+var registeredStrategies = map[string]interface{}{}
+
+// RegisterStrategy registers the strategy prototype by the ID, it's usually called in the init function of the strategy package.
+// The prototype must be a struct pointer that implements MarketStrategy or CrossExchangeStrategy,
+// its yaml field values are the default settings of the new strategy instances.
+func RegisterStrategy(id string, strategy interface{}) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	switch strategy.(type) {
+	case MarketStrategy, CrossExchangeStrategy:
+	default:
+		panic(fmt.Errorf("strategy %s must implement MarketStrategy or CrossExchangeStrategy, got %T", id, strategy))
+	}
+
+	if t := reflect.TypeOf(strategy); t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Struct {
+		panic(fmt.Errorf("strategy %s must be a struct pointer, got %T", id, strategy))
+	}
+
+	if _, ok := registeredStrategies[id]; ok {
+		panic(fmt.Errorf("strategy %s is already registered", id))
+	}
+
+	registeredStrategies[id] = strategy
+}
+
+// RegisteredStrategies returns the sorted IDs of the registered strategies
+func RegisteredStrategies() (ids []string) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	for id := range registeredStrategies {
+		ids = append(ids, id)
+	}
+
+	sort.Strings(ids)
+	return ids
+}
+
+// NewStrategy creates a new strategy instance of the registered ID. The instance is a new zero value of the prototype type,
+// the default settings of the prototype are decoded into it by the yaml tags, so that the instances never share
+// the slices, maps and pointers of the prototype.
+func NewStrategy(id string) (interface{}, error) {
+	registryMu.Lock()
+	prototype, ok := registeredStrategies[id]
+	registryMu.Unlock()
+
+	if !ok {
+		return nil, fmt.Errorf("strategy %s is not registered", id)
+	}
+
+	instance := reflect.New(reflect.TypeOf(prototype).Elem()).Interface()
+
+	out, err := yaml.Marshal(prototype)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := yaml.Unmarshal(out, instance); err != nil {
+		return nil, fmt.Errorf("strategy %s: %s", id, err.Error())
+	}
+
+	return instance, nil
+}
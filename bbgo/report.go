@@ -0,0 +1,109 @@
+package bbgo
+
+import (
+	"context"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/accounting"
+)
+
+// ReportConfig is the "reports" section of the config file, the schedules are cron expressions,
+// empty schedule disables the report.
+//
+//	reports:
+//	  timezone: Asia/Taipei
+//	  pnl: "0 9 * * *"
+//	  valuation: "0 9 * * *"
+//	  balanceChanges: "0 9 * * 1"
+type ReportConfig struct {
+	// Timezone is the IANA time zone name of the schedules, the local time zone is used if it's empty
+	Timezone string `json:"timezone" yaml:"timezone"`
+
+	// PnL sends the profit and loss report of the symbol
+	PnL string `json:"pnl" yaml:"pnl"`
+
+	// Valuation sends the total value of the account balances in the reporting currency
+	Valuation string `json:"valuation" yaml:"valuation"`
+
+	// BalanceChanges sends the balance changes since the last balance change report, the deposits and the withdraws
+	// are reported as the net inflow
+	BalanceChanges string `json:"balanceChanges" yaml:"balanceChanges"`
+}
+
+// ScheduleReports starts the report jobs in the background until the context is done
+func (trader *Trader) ScheduleReports(ctx context.Context, config ReportConfig) (*Scheduler, error) {
+	location := time.Local
+	if len(config.Timezone) > 0 {
+		var err error
+		location, err = time.LoadLocation(config.Timezone)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	scheduler := NewScheduler(location)
+
+	if len(config.PnL) > 0 {
+		if err := scheduler.AddJob("pnl", config.PnL, func(ctx context.Context) {
+			trader.reportPnL()
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(config.Valuation) > 0 {
+		if err := scheduler.AddJob("valuation", config.Valuation, func(ctx context.Context) {
+			trader.NotifyReport(trader.valuationReport(ctx))
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(config.BalanceChanges) > 0 {
+		var lastTime = time.Now()
+		var lastBalances = trader.Account.Snapshot()
+
+		if err := scheduler.AddJob("balanceChanges", config.BalanceChanges, func(ctx context.Context) {
+			now := time.Now()
+			balances := trader.Account.Snapshot()
+			report := accounting.NewBalanceChangeReport(lastTime.In(location), lastBalances, now.In(location), balances)
+
+			// exclude the transfers from the balance changes, the paper balances have no transfers
+			if !trader.IsPaperTrade() {
+				if inflows, err := QueryNetInflow(ctx, trader.TransferSync, trader.Exchange, lastTime, now); err != nil {
+					log.WithError(err).Error("net inflow query error")
+				} else {
+					report.SetNetInflows(inflows)
+				}
+			}
+
+			lastTime, lastBalances = now, balances
+			trader.NotifyReport(report)
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	scheduler.Run(ctx)
+	return scheduler, nil
+}
+
+// valuationReport values the account balances in the reporting currency, or the quote currency if it's not set
+func (trader *Trader) valuationReport(ctx context.Context) *accounting.ValuationReport {
+	currency := trader.ReportingCurrency
+	if len(currency) == 0 {
+		currency = trader.Context.Market.QuoteCurrency
+	}
+
+	balances := trader.Account.Snapshot()
+
+	var currencies []string
+	for c := range balances {
+		currencies = append(currencies, c)
+	}
+
+	QueryConversionPrices(ctx, trader.Exchange, trader.Prices, currency, currencies...)
+	return accounting.NewValuationReport(time.Now(), balances, currency, trader.Prices)
+}
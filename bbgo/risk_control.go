@@ -0,0 +1,210 @@
+package bbgo
+
+import (
+	"context"
+	"io/ioutil"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+
+	"github.com/c9s/bbgo/types"
+)
+
+var (
+	ErrMaxPositionSizeExceeded  = errors.New("max position size exceeded")
+	ErrMaxOrderNotionalExceeded = errors.New("max order notional exceeded")
+	ErrMaxOpenOrdersExceeded    = errors.New("max open orders exceeded")
+	ErrDailyVolumeCapExceeded   = errors.New("daily traded volume cap exceeded")
+	ErrOpenOrdersNotQueryable   = errors.New("exchange does not support open order query, max open orders can not be checked")
+	ErrCircuitBreakerTripped    = errors.New("circuit breaker tripped, trading is halted")
+	ErrOrderPriceUnknown        = errors.New("order price is unknown, the order notional can not be checked")
+)
+
+// IsRiskControlError checks if the error is returned by the risk controls of the order processor
+func IsRiskControlError(err error) bool {
+	switch errors.Cause(err) {
+	case ErrQuoteBalanceLevelTooLow, ErrAssetBalanceLevelTooLow, ErrAssetBalanceLevelTooHigh,
+		ErrMaxPositionSizeExceeded, ErrMaxOrderNotionalExceeded, ErrMaxOpenOrdersExceeded, ErrDailyVolumeCapExceeded,
+		ErrOpenOrdersNotQueryable, ErrCircuitBreakerTripped, ErrOrderPriceUnknown:
+		return true
+	}
+
+	return false
+}
+
+// OrderRiskControls are the limits checked by the OrderProcessor before the order is submitted.
+// Zero value means the limit is disabled.
+type OrderRiskControls struct {
+	// balance control
+	MinQuoteBalance float64 `json:"minQuoteBalance" yaml:"minQuoteBalance"`
+	MaxAssetBalance float64 `json:"maxBaseAssetBalance" yaml:"maxBaseAssetBalance"`
+	MinAssetBalance float64 `json:"minBaseAssetBalance" yaml:"minBaseAssetBalance"`
+
+	// MinProfitSpread is used when submitting sell orders, it check if there the selling can make the profit.
+	MinProfitSpread float64 `json:"minProfitSpread" yaml:"minProfitSpread"`
+
+	// MaxOrderAmount is used for adjusting the buy order quantity, the order amount will be truncated to this amount
+	MaxOrderAmount float64 `json:"maxOrderAmount" yaml:"maxOrderAmount"`
+
+	// MaxPositionSize is the max base asset quantity (available + locked) we can hold after the buy order is filled
+	MaxPositionSize float64 `json:"maxPositionSize" yaml:"maxPositionSize"`
+
+	// MaxOrderNotional rejects the orders that have the notional (price * quantity) larger than this value
+	MaxOrderNotional float64 `json:"maxOrderNotional" yaml:"maxOrderNotional"`
+
+	// MaxOpenOrders rejects the new orders when the number of the open orders of the symbol reaches this value
+	MaxOpenOrders int `json:"maxOpenOrders" yaml:"maxOpenOrders"`
+
+	// MaxDailyVolume is the cap of the traded quote volume per day
+	MaxDailyVolume float64 `json:"maxDailyVolume" yaml:"maxDailyVolume"`
+}
+
+// Merge overrides the limits with the non-zero limits of the given risk controls,
+// a negative limit resets the limit back to zero, which disables it.
+func (c OrderRiskControls) Merge(o OrderRiskControls) OrderRiskControls {
+	c.MinQuoteBalance = mergeLimit(c.MinQuoteBalance, o.MinQuoteBalance)
+	c.MaxAssetBalance = mergeLimit(c.MaxAssetBalance, o.MaxAssetBalance)
+	c.MinAssetBalance = mergeLimit(c.MinAssetBalance, o.MinAssetBalance)
+	c.MinProfitSpread = mergeLimit(c.MinProfitSpread, o.MinProfitSpread)
+	c.MaxOrderAmount = mergeLimit(c.MaxOrderAmount, o.MaxOrderAmount)
+	c.MaxPositionSize = mergeLimit(c.MaxPositionSize, o.MaxPositionSize)
+	c.MaxOrderNotional = mergeLimit(c.MaxOrderNotional, o.MaxOrderNotional)
+	c.MaxOpenOrders = mergeCount(c.MaxOpenOrders, o.MaxOpenOrders)
+	c.MaxDailyVolume = mergeLimit(c.MaxDailyVolume, o.MaxDailyVolume)
+	return c
+}
+
+func mergeLimit(limit, override float64) float64 {
+	switch {
+	case override < 0:
+		return 0
+	case override > 0:
+		return override
+	}
+	return limit
+}
+
+func mergeCount(limit, override int) int {
+	switch {
+	case override < 0:
+		return 0
+	case override > 0:
+		return override
+	}
+	return limit
+}
+
+// RiskControlConfig is the "riskControls" section of the config file
+//
+//	riskControls:
+//	  sessions:
+//	    binance:
+//	      maxOrderNotional: 1000.0
+//	  strategies:
+//	    grid:
+//	      maxOpenOrders: 20
+//	      # disable the session limit for this strategy
+//	      maxOrderNotional: -1
+type RiskControlConfig struct {
+	// Sessions is the session name to risk controls map
+	Sessions map[string]OrderRiskControls `json:"sessions" yaml:"sessions"`
+
+	// Strategies is the strategy ID to risk controls map, the strategy limits override the session limits
+	Strategies map[string]OrderRiskControls `json:"strategies" yaml:"strategies"`
+}
+
+// For returns the risk controls of the given session and strategy
+func (c *RiskControlConfig) For(session, strategyID string) (controls OrderRiskControls) {
+	if c == nil {
+		return controls
+	}
+
+	if sessionControls, ok := c.Sessions[session]; ok {
+		controls = controls.Merge(sessionControls)
+	}
+
+	if strategyControls, ok := c.Strategies[strategyID]; ok {
+		controls = controls.Merge(strategyControls)
+	}
+
+	return controls
+}
+
+// LoadRiskControlConfig loads the "riskControls" section from the given config file
+func LoadRiskControlConfig(filename string) (*RiskControlConfig, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var config struct {
+		RiskControls *RiskControlConfig `yaml:"riskControls"`
+	}
+
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, err
+	}
+
+	return config.RiskControls, nil
+}
+
+// OpenOrderQuerier is implemented by the exchanges that can query the open orders
+type OpenOrderQuerier interface {
+	QueryOpenOrders(ctx context.Context, symbol string) ([]types.Order, error)
+}
+
+// OrderCanceller is implemented by the exchanges that can cancel orders
+type OrderCanceller interface {
+	CancelOrders(ctx context.Context, orders ...types.Order) error
+}
+
+// TradedVolumeTracker sums up the traded quote volume of the current day
+type TradedVolumeTracker struct {
+	mu sync.Mutex
+
+	Location *time.Location
+
+	day    time.Time
+	volume float64
+}
+
+func (t *TradedVolumeTracker) startOfDay(tt time.Time) time.Time {
+	loc := t.Location
+	if loc == nil {
+		loc = time.Local
+	}
+
+	tt = tt.In(loc)
+	return time.Date(tt.Year(), tt.Month(), tt.Day(), 0, 0, 0, 0, loc)
+}
+
+func (t *TradedVolumeTracker) AddTrade(trade types.Trade) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	day := t.startOfDay(trade.Time)
+	if day.Before(t.day) {
+		return
+	}
+
+	if day.After(t.day) {
+		t.day = day
+		t.volume = 0
+	}
+
+	t.volume += trade.Price * trade.Quantity
+}
+
+// Volume returns the traded quote volume of the day of the given time
+func (t *TradedVolumeTracker) Volume(now time.Time) float64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !t.startOfDay(now).Equal(t.day) {
+		return 0
+	}
+
+	return t.volume
+}
@@ -0,0 +1,39 @@
+package bbgo
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/exchange/binance"
+	"github.com/c9s/bbgo/types"
+)
+
+func TestTradedVolumeTracker_BinanceTrade(t *testing.T) {
+	now := time.Now()
+
+	var tracker = &TradedVolumeTracker{}
+	tracker.AddTrade(types.Trade{Symbol: "BTCUSDT", Price: 9000.0, Quantity: 0.1, Time: now})
+
+	report := &binance.ExecutionReportEvent{
+		Symbol:                           "BTCUSDT",
+		Side:                             "BUY",
+		CurrentExecutionType:             "TRADE",
+		TradeID:                          200,
+		OrderID:                          100,
+		TransactionTime:                  now.UnixNano() / int64(time.Millisecond),
+		LastExecutedPrice:                "9000.0",
+		LastExecutedQuantity:             "0.2",
+		LastQuoteAssetTransactedQuantity: strconv.FormatFloat(9000.0*0.2, 'f', -1, 64),
+		CommissionAmount:                 "0",
+	}
+
+	trade, err := report.Trade()
+	if assert.NoError(t, err) {
+		tracker.AddTrade(*trade)
+	}
+
+	assert.InDelta(t, 900.0+1800.0, tracker.Volume(now), 1e-8)
+}
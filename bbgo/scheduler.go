@@ -0,0 +1,255 @@
+package bbgo
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Schedule is a cron schedule with the standard 5 fields: minute, hour, day of month, month and day of week.
+// Each field supports "*", lists "1,2", ranges "1-5" and steps "*/15" or "0-30/10".
+// The descriptors @hourly, @daily (@midnight), @weekly, @monthly and @yearly are also supported.
+type Schedule struct {
+	minute, hour, dom, month, dow uint64
+
+	// anyDom and anyDow are used for the cron day matching rule:
+	// if both the day of month and the day of week are restricted, either of them matches.
+	anyDom, anyDow bool
+}
+
+var scheduleDescriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+type scheduleField struct {
+	name     string
+	min, max int
+}
+
+var scheduleFields = []scheduleField{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 6},
+}
+
+func ParseSchedule(spec string) (*Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if expanded, ok := scheduleDescriptors[spec]; ok {
+		spec = expanded
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != len(scheduleFields) {
+		return nil, fmt.Errorf("invalid schedule %q: expecting %d fields, got %d", spec, len(scheduleFields), len(fields))
+	}
+
+	var bits [5]uint64
+	for i, field := range fields {
+		b, err := parseScheduleField(field, scheduleFields[i])
+		if err != nil {
+			return nil, fmt.Errorf("invalid schedule %q: %s", spec, err.Error())
+		}
+		bits[i] = b
+	}
+
+	// 7 is also sunday
+	if bits[4]&(1<<7) > 0 {
+		bits[4] |= 1
+	}
+
+	return &Schedule{
+		minute: bits[0],
+		hour:   bits[1],
+		dom:    bits[2],
+		month:  bits[3],
+		dow:    bits[4],
+		anyDom: fields[2] == "*",
+		anyDow: fields[4] == "*",
+	}, nil
+}
+
+func parseScheduleField(expr string, field scheduleField) (bits uint64, err error) {
+	max := field.max
+	if field.name == "day of week" {
+		max = 7
+	}
+
+	for _, part := range strings.Split(expr, ",") {
+		var step = 1
+		var hasStep = false
+		if idx := strings.Index(part, "/"); idx >= 0 {
+			hasStep = true
+			step, err = strconv.Atoi(part[idx+1:])
+			if err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid %s step %q", field.name, part)
+			}
+			part = part[:idx]
+		}
+
+		var from, to int
+		switch {
+		case part == "*":
+			from, to = field.min, field.max
+
+		case strings.Contains(part, "-"):
+			bounds := strings.SplitN(part, "-", 2)
+			if from, err = strconv.Atoi(bounds[0]); err != nil {
+				return 0, fmt.Errorf("invalid %s range %q", field.name, part)
+			}
+			if to, err = strconv.Atoi(bounds[1]); err != nil {
+				return 0, fmt.Errorf("invalid %s range %q", field.name, part)
+			}
+
+		default:
+			if from, err = strconv.Atoi(part); err != nil {
+				return 0, fmt.Errorf("invalid %s value %q", field.name, part)
+			}
+
+			// N/step is expanded like */step but starts at N
+			to = from
+			if hasStep {
+				to = field.max
+			}
+		}
+
+		if from < field.min || to > max || from > to {
+			return 0, fmt.Errorf("%s %q is out of range %d-%d", field.name, part, field.min, field.max)
+		}
+
+		for v := from; v <= to; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+
+	return bits, nil
+}
+
+func (s *Schedule) matchDay(t time.Time) bool {
+	domMatch := s.dom&(1<<uint(t.Day())) > 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) > 0
+
+	if s.anyDom || s.anyDow {
+		return domMatch && dowMatch
+	}
+
+	return domMatch || dowMatch
+}
+
+// Next returns the next activation time after the given time, in the location of the given time
+func (s *Schedule) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+
+	// the schedule always matches within 5 years (e.g., Feb 29)
+	end := t.AddDate(5, 0, 0)
+	for t.Before(end) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+
+		if !s.matchDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+
+		return t
+	}
+
+	return time.Time{}
+}
+
+// Job is the function run by the scheduler
+type Job func(ctx context.Context)
+
+type scheduledJob struct {
+	name     string
+	schedule *Schedule
+	job      Job
+}
+
+// Scheduler runs the jobs at the times of their cron schedules in the location
+type Scheduler struct {
+	// Location is the timezone of the schedules, time.Local is used if it's nil
+	Location *time.Location
+
+	mu   sync.Mutex
+	jobs []scheduledJob
+}
+
+func NewScheduler(location *time.Location) *Scheduler {
+	return &Scheduler{Location: location}
+}
+
+// AddJob adds the job with the cron schedule spec, e.g., "0 9 * * *" runs the job at 09:00 every day
+func (s *Scheduler) AddJob(name, spec string, job Job) error {
+	schedule, err := ParseSchedule(spec)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.jobs = append(s.jobs, scheduledJob{name: name, schedule: schedule, job: job})
+	s.mu.Unlock()
+	return nil
+}
+
+// Run starts the jobs in the background until the context is done
+func (s *Scheduler) Run(ctx context.Context) {
+	location := s.Location
+	if location == nil {
+		location = time.Local
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, job := range s.jobs {
+		go s.runJob(ctx, job, location)
+	}
+}
+
+func (s *Scheduler) runJob(ctx context.Context, job scheduledJob, location *time.Location) {
+	for {
+		next := job.schedule.Next(time.Now().In(location))
+		if next.IsZero() {
+			log.Errorf("[scheduler] job %s will never run", job.name)
+			return
+		}
+
+		log.Infof("[scheduler] job %s will run at %s", job.name, next)
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+
+		case <-timer.C:
+			log.Infof("[scheduler] running job %s", job.name)
+			job.job(ctx)
+		}
+	}
+}
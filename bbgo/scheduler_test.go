@@ -0,0 +1,44 @@
+package bbgo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchedule_Next(t *testing.T) {
+	taipei := time.FixedZone("Asia/Taipei", 8*3600)
+
+	var tests = []struct {
+		spec     string
+		from     time.Time
+		expected time.Time
+	}{
+		{"0 9 * * *", time.Date(2020, 10, 1, 8, 30, 0, 0, taipei), time.Date(2020, 10, 1, 9, 0, 0, 0, taipei)},
+		{"0 9 * * *", time.Date(2020, 10, 1, 9, 0, 0, 0, taipei), time.Date(2020, 10, 2, 9, 0, 0, 0, taipei)},
+		{"*/15 * * * *", time.Date(2020, 10, 1, 9, 16, 30, 0, time.UTC), time.Date(2020, 10, 1, 9, 30, 0, 0, time.UTC)},
+		// N/step starts at N
+		{"5/15 * * * *", time.Date(2020, 10, 1, 9, 21, 0, 0, time.UTC), time.Date(2020, 10, 1, 9, 35, 0, 0, time.UTC)},
+		{"5/15 * * * *", time.Date(2020, 10, 1, 9, 50, 30, 0, time.UTC), time.Date(2020, 10, 1, 10, 5, 0, 0, time.UTC)},
+		{"30 8-10 * * 1-5", time.Date(2020, 10, 2, 11, 0, 0, 0, time.UTC), time.Date(2020, 10, 5, 8, 30, 0, 0, time.UTC)},
+		{"@weekly", time.Date(2020, 10, 1, 0, 0, 0, 0, time.UTC), time.Date(2020, 10, 4, 0, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2020, 12, 15, 0, 0, 0, 0, time.UTC), time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		// either the day of month or the day of week matches
+		{"0 0 1 * 0", time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC), time.Date(2020, 10, 4, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC), time.Date(2020, 10, 4, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, test := range tests {
+		schedule, err := ParseSchedule(test.spec)
+		if assert.NoError(t, err, test.spec) {
+			assert.Equal(t, test.expected, schedule.Next(test.from), test.spec)
+		}
+	}
+
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "5-1 * * * *", "*/0 * * * *", "a * * * *"} {
+		_, err := ParseSchedule(spec)
+		assert.Error(t, err, spec)
+	}
+}
@@ -9,6 +9,9 @@ type Notifier interface {
 	Notify(format string, args ...interface{})
 	NotifyTrade(trade *types.Trade)
 	NotifyPnL(report *accounting.ProfitAndLossReport)
+
+	// NotifyReport sends the scheduled reports, e.g., the account valuation and the balance changes
+	NotifyReport(report accounting.Report)
 }
 
 type NullNotifier struct{}
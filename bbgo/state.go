@@ -0,0 +1,91 @@
+package bbgo
+
+import (
+	"encoding/json"
+	"sync"
+)
+
+// StateStore saves and loads the strategy states by the state key, e.g., the positions that must survive restarts.
+// service.StrategyStateService stores the states in the database.
+type StateStore interface {
+	Load(key string, v interface{}) (bool, error)
+	Save(key string, v interface{}) error
+}
+
+// StatefulStrategy is implemented by the strategies that persist their states,
+// the state store is set before the strategy is loaded by OnLoad or CrossSubscribe.
+// The state keys of the single exchange strategies are prefixed by the exchange name.
+type StatefulStrategy interface {
+	SetStateStore(store StateStore)
+}
+
+// MemoryStateStore keeps the JSON encoded states in memory, it's used when there is no database,
+// e.g., the paper trading sessions always start from the empty states.
+type MemoryStateStore struct {
+	mu     sync.Mutex
+	states map[string][]byte
+}
+
+func NewMemoryStateStore() *MemoryStateStore {
+	return &MemoryStateStore{states: make(map[string][]byte)}
+}
+
+func (s *MemoryStateStore) Load(key string, v interface{}) (bool, error) {
+	s.mu.Lock()
+	state, ok := s.states[key]
+	s.mu.Unlock()
+
+	if !ok {
+		return false, nil
+	}
+
+	return true, json.Unmarshal(state, v)
+}
+
+func (s *MemoryStateStore) Save(key string, v interface{}) error {
+	state, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.states[key] = state
+	s.mu.Unlock()
+	return nil
+}
+
+// prefixStateStore prefixes the state keys of the underlying state store
+type prefixStateStore struct {
+	StateStore
+	prefix string
+}
+
+func (s *prefixStateStore) Load(key string, v interface{}) (bool, error) {
+	return s.StateStore.Load(s.prefix+key, v)
+}
+
+func (s *prefixStateStore) Save(key string, v interface{}) error {
+	return s.StateStore.Save(s.prefix+key, v)
+}
+
+// stateStore returns the database state store, the states are kept in memory for the paper trading trader
+// and the trader without the database
+func (trader *Trader) stateStore() StateStore {
+	var store StateStore = trader.StateService
+	if trader.StateService == nil || trader.StateService.DB == nil || trader.IsPaperTrade() {
+		store = NewMemoryStateStore()
+	}
+
+	if trader.Exchange != nil {
+		return &prefixStateStore{StateStore: store, prefix: trader.Exchange.Name() + ":"}
+	}
+
+	return store
+}
+
+// setStateStore sets the state store of the stateful strategy
+func (trader *Trader) setStateStore(strategy interface{}) {
+	if s, ok := strategy.(StatefulStrategy); ok {
+		s.SetStateStore(trader.stateStore())
+	}
+}
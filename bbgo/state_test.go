@@ -0,0 +1,39 @@
+package bbgo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/exchange/paper"
+)
+
+func TestTrader_StateStore(t *testing.T) {
+	type state struct {
+		Spent float64 `json:"spent"`
+	}
+
+	// the paper trading trader keeps the states in memory, the keys are prefixed by the exchange name
+	trader := &Trader{Exchange: &paper.Exchange{}}
+	store := trader.stateStore()
+
+	prefixed, ok := store.(*prefixStateStore)
+	if assert.True(t, ok) {
+		assert.Equal(t, trader.Exchange.Name()+":", prefixed.prefix)
+		assert.IsType(t, &MemoryStateStore{}, prefixed.StateStore)
+	}
+
+	var loaded state
+	found, err := store.Load("dca:BTCUSDT", &loaded)
+	assert.NoError(t, err)
+	assert.False(t, found)
+
+	assert.NoError(t, store.Save("dca:BTCUSDT", state{Spent: 100.0}))
+	found, err = store.Load("dca:BTCUSDT", &loaded)
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, state{Spent: 100.0}, loaded)
+
+	// the cross exchange trader doesn't prefix the keys
+	assert.IsType(t, &MemoryStateStore{}, (&Trader{}).stateStore())
+}
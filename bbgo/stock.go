@@ -7,6 +7,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/c9s/bbgo/types"
 )
@@ -43,6 +44,21 @@ func (slice StockSlice) QuantityBelowPrice(price float64) (quantity float64) {
 	return round(quantity)
 }
 
+// AveragePrice returns the quantity weighted average price of the stocks
+func (slice StockSlice) AveragePrice() float64 {
+	var amount, quantity float64
+	for _, stock := range slice {
+		amount += stock.Price * stock.Quantity
+		quantity += stock.Quantity
+	}
+
+	if zero(quantity) {
+		return 0.0
+	}
+
+	return amount / quantity
+}
+
 func (slice StockSlice) Quantity() (total float64) {
 	for _, stock := range slice {
 		total += stock.Quantity
@@ -51,6 +67,44 @@ func (slice StockSlice) Quantity() (total float64) {
 	return round(total)
 }
 
+// ConsumedLot is the part of the buy lot that is consumed by a sell trade
+type ConsumedLot struct {
+	BuyTradeID int64     `json:"buyTradeID"`
+	BuyTime    time.Time `json:"buyTime"`
+
+	// Price is the cost price of the lot
+	Price    float64 `json:"price"`
+	Quantity float64 `json:"quantity"`
+}
+
+// SellRecord records the buy lots that are consumed by the sell trade,
+// the quantity of Sell is the unmatched quantity when it's matched at the first time.
+type SellRecord struct {
+	Sell types.Trade   `json:"sell"`
+	Lots []ConsumedLot `json:"lots"`
+}
+
+// Quantity returns the matched quantity of the sell trade
+func (r *SellRecord) Quantity() (quantity float64) {
+	for _, lot := range r.Lots {
+		quantity += lot.Quantity
+	}
+	return round(quantity)
+}
+
+// Cost returns the cost of the matched quantity
+func (r *SellRecord) Cost() (cost float64) {
+	for _, lot := range r.Lots {
+		cost += lot.Price * lot.Quantity
+	}
+	return cost
+}
+
+// RealizedProfit returns the profit of the matched quantity, the fee is not included
+func (r *SellRecord) RealizedProfit() float64 {
+	return r.Sell.Price*r.Quantity() - r.Cost()
+}
+
 type StockManager struct {
 	mu sync.Mutex
 
@@ -58,6 +112,45 @@ type StockManager struct {
 	TradingFeeCurrency string
 	Stocks             StockSlice
 	PendingSells       StockSlice
+
+	// CostBasis is the method of matching the sell trades against the stocks, LowerPriceFirst is used if it's not set
+	CostBasis CostBasisMethod
+
+	// LastTradeGID is the largest gid of the processed trades
+	LastTradeGID int64
+
+	// SellRecords are the consumed lots of the sell trades, ordered by the first match time
+	SellRecords []*SellRecord
+
+	sellRecordIndex map[int64]*SellRecord
+}
+
+// SellRecord returns the consumed lots of the sell trade
+func (m *StockManager) SellRecord(tradeID int64) (*SellRecord, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	record, ok := m.sellRecordIndex[tradeID]
+	return record, ok
+}
+
+func (m *StockManager) recordLots(sell Stock, lots []ConsumedLot) {
+	if len(lots) == 0 {
+		return
+	}
+
+	if m.sellRecordIndex == nil {
+		m.sellRecordIndex = make(map[int64]*SellRecord)
+	}
+
+	record, ok := m.sellRecordIndex[sell.ID]
+	if !ok {
+		record = &SellRecord{Sell: types.Trade(sell)}
+		m.sellRecordIndex[sell.ID] = record
+		m.SellRecords = append(m.SellRecords, record)
+	}
+
+	record.Lots = append(record.Lots, lots...)
 }
 
 type Distribution struct {
@@ -148,43 +241,17 @@ func (m *StockManager) consume(sell Stock) error {
 		return nil
 	}
 
-	idx := len(m.Stocks) - 1
-	for ; idx >= 0; idx-- {
-		stock := m.Stocks[idx]
-
-		// find any stock price is lower than the sell trade
-		if stock.Price >= sell.Price {
-			continue
-		}
-
-		if zero(stock.Quantity) {
-			continue
-		}
-
-		delta := stock.Consume(sell.Quantity)
-		sell.Consume(delta)
-		m.Stocks[idx] = stock
-
-		if zero(sell.Quantity) {
-			return nil
-		}
+	costBasis := m.CostBasis
+	if costBasis == nil {
+		costBasis = LowerPriceFirst{}
 	}
 
-	idx = len(m.Stocks) - 1
-	for ; idx >= 0; idx-- {
-		stock := m.Stocks[idx]
-
-		if zero(stock.Quantity) {
-			continue
-		}
-
-		delta := stock.Consume(sell.Quantity)
-		sell.Consume(delta)
-		m.Stocks[idx] = stock
+	lots, rest := costBasis.Consume(m.Stocks, sell)
+	m.recordLots(sell, lots)
+	sell = rest
 
-		if zero(sell.Quantity) {
-			return nil
-		}
+	if zero(sell.Quantity) {
+		return nil
 	}
 
 	if sell.Quantity > 0.0 {
@@ -194,13 +261,22 @@ func (m *StockManager) consume(sell Stock) error {
 	return nil
 }
 
+// IsFeeTrade returns true if the trade of the other symbol pays the fee in the trading fee currency of the symbol, e.g., BNBUSDT
+func (m *StockManager) IsFeeTrade(trade types.Trade) bool {
+	return len(m.TradingFeeCurrency) > 0 && trade.Symbol != m.Symbol && trade.FeeCurrency == m.TradingFeeCurrency &&
+		strings.HasPrefix(m.Symbol, m.TradingFeeCurrency)
+}
+
 func (m *StockManager) AddTrades(trades []types.Trade) (checkpoints []int, err error) {
-	feeSymbol := strings.HasPrefix(m.Symbol, m.TradingFeeCurrency)
 	for idx, trade := range trades {
+		if trade.GID > m.LastTradeGID {
+			m.LastTradeGID = trade.GID
+		}
+
 		// for other market trades
 		// convert trading fee trades to sell trade
 		if trade.Symbol != m.Symbol {
-			if feeSymbol && trade.FeeCurrency == m.TradingFeeCurrency {
+			if m.IsFeeTrade(trade) {
 				trade.Symbol = m.Symbol
 				trade.IsBuyer = false
 				trade.Quantity = trade.Fee
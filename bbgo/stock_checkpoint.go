@@ -0,0 +1,120 @@
+package bbgo
+
+import (
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/service"
+	"github.com/c9s/bbgo/types"
+)
+
+// StockLoader restores the stock manager from the stock checkpoint and replays the trades after the checkpoint
+type StockLoader struct {
+	// Exchange is the exchange name of the trades
+	Exchange string
+
+	TradeService *service.TradeService
+	StockService *service.StockService
+}
+
+// Checkpoint returns the snapshot of the current stock lots
+func (m *StockManager) Checkpoint() service.StockCheckpoint {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	checkpoint := service.StockCheckpoint{
+		Symbol:       m.Symbol,
+		CostBasis:    CostBasisName(m.CostBasis),
+		LastTradeGID: m.LastTradeGID,
+	}
+
+	for _, stock := range m.Stocks {
+		checkpoint.Stocks = append(checkpoint.Stocks, types.Trade(stock))
+	}
+
+	for _, sell := range m.PendingSells {
+		checkpoint.PendingSells = append(checkpoint.PendingSells, types.Trade(sell))
+	}
+
+	return checkpoint
+}
+
+// Restore replaces the stock lots with the lots of the checkpoint
+func (m *StockManager) Restore(checkpoint service.StockCheckpoint) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.Stocks = nil
+	for _, trade := range checkpoint.Stocks {
+		m.Stocks = append(m.Stocks, Stock(trade))
+	}
+
+	m.PendingSells = nil
+	for _, trade := range checkpoint.PendingSells {
+		m.PendingSells = append(m.PendingSells, Stock(trade))
+	}
+
+	m.LastTradeGID = checkpoint.LastTradeGID
+}
+
+func (l *StockLoader) queryTrades(symbol, tradingFeeCurrency string, gid int64) ([]types.Trade, error) {
+	if strings.HasPrefix(symbol, tradingFeeCurrency) {
+		return l.TradeService.QueryForTradingFeeCurrencyAfterGID(l.Exchange, symbol, tradingFeeCurrency, gid)
+	}
+
+	return l.TradeService.QueryAfterGID(l.Exchange, symbol, gid)
+}
+
+// Load restores the stock manager from the checkpoint, replays the newer trades and saves the new checkpoint.
+// The checkpoint is ignored if it was built with a different cost basis method.
+// The replayed trades are returned, e.g., the trades synced when bbgo is not running.
+func (l *StockLoader) Load(stockManager *StockManager) ([]types.Trade, error) {
+	checkpoint, err := l.StockService.QueryCheckpoint(l.Exchange, stockManager.Symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	if checkpoint != nil {
+		if checkpoint.CostBasis == CostBasisName(stockManager.CostBasis) {
+			log.Infof("%s stock checkpoint found, last trade gid = %d", stockManager.Symbol, checkpoint.LastTradeGID)
+			stockManager.Restore(*checkpoint)
+		} else {
+			log.Warnf("%s stock checkpoint cost basis %s does not match %s, rebuilding the stocks",
+				stockManager.Symbol, checkpoint.CostBasis, CostBasisName(stockManager.CostBasis))
+		}
+	}
+
+	trades, err := l.queryTrades(stockManager.Symbol, stockManager.TradingFeeCurrency, stockManager.LastTradeGID)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Infof("%s replaying %d trades after gid %d", stockManager.Symbol, len(trades), stockManager.LastTradeGID)
+
+	checkpoints, err := stockManager.AddTrades(trades)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Infof("%s found stock checkpoints: %+v", stockManager.Symbol, checkpoints)
+
+	return trades, l.Save(stockManager)
+}
+
+// Save saves the checkpoint of the current stock lots
+func (l *StockLoader) Save(stockManager *StockManager) error {
+	checkpoint := stockManager.Checkpoint()
+	checkpoint.Exchange = l.Exchange
+	return l.StockService.SaveCheckpoint(checkpoint)
+}
+
+// Rebuild drops the checkpoint and rebuilds the stock lots from all the trades, all the trades are returned
+func (l *StockLoader) Rebuild(stockManager *StockManager) ([]types.Trade, error) {
+	if err := l.StockService.DeleteCheckpoint(l.Exchange, stockManager.Symbol); err != nil {
+		return nil, err
+	}
+
+	stockManager.Restore(service.StockCheckpoint{})
+	return l.Load(stockManager)
+}
@@ -0,0 +1,64 @@
+package bbgo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/service"
+	"github.com/c9s/bbgo/types"
+)
+
+func TestTrader_FeeTradeCheckpoint(t *testing.T) {
+	db, _ := newExitTestService(t)
+	defer db.Close()
+
+	newLoader := func() *StockLoader {
+		return &StockLoader{
+			Exchange:     "binance",
+			TradeService: service.NewTradeService(db),
+			StockService: service.NewStockService(db),
+		}
+	}
+
+	newStockManager := func() *StockManager {
+		return &StockManager{Symbol: "BNBUSDT", TradingFeeCurrency: "BNB", CostBasis: FIFO{}}
+	}
+
+	stockManager := newStockManager()
+	loader := newLoader()
+	_, err := loader.Load(stockManager)
+	assert.NoError(t, err)
+
+	trader := &Trader{
+		Symbol:       "BNBUSDT",
+		Exchange:     &testCrossExchange{name: "binance"},
+		TradeService: loader.TradeService,
+		Context:      &Context{StockManager: stockManager},
+		stockLoader:  loader,
+	}
+
+	// the BTCUSDT trade pays the fee in BNB, it's stored before the next BNBUSDT trade
+	for _, trade := range []types.Trade{
+		{ID: 1, Exchange: "binance", Symbol: "BNBUSDT", Price: 30.0, Quantity: 1.0, IsBuyer: true, FeeCurrency: "BNB", Fee: 0.001},
+		{ID: 1, Exchange: "binance", Symbol: "BTCUSDT", Price: 9000.0, Quantity: 0.1, IsBuyer: true, FeeCurrency: "BNB", Fee: 0.01},
+		{ID: 2, Exchange: "binance", Symbol: "BNBUSDT", Price: 31.0, Quantity: 0.5, IsBuyer: true, FeeCurrency: "BNB", Fee: 0.0005},
+	} {
+		trade := trade
+		assert.True(t, trade.Symbol == trader.Symbol || stockManager.IsFeeTrade(trade))
+		trader.storeTrade(&trade)
+		assert.True(t, trade.GID > 0)
+		trader.addStockTrade(trade)
+	}
+
+	assert.Equal(t, int64(3), stockManager.LastTradeGID)
+
+	// the fee of the BTCUSDT trade is covered by the checkpoint
+	restored := newStockManager()
+	replayed, err := newLoader().Load(restored)
+	if assert.NoError(t, err) {
+		assert.Len(t, replayed, 0)
+		assert.Equal(t, stockManager.Stocks, restored.Stocks)
+		assert.InDelta(t, 1.5-0.001-0.0005-0.01, restored.Stocks.Quantity(), 1e-9)
+	}
+}
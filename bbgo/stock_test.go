@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"io/ioutil"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
@@ -181,3 +182,166 @@ func TestStockManager(t *testing.T) {
 	})
 
 }
+
+func TestStockManager_CostBasis(t *testing.T) {
+	var trades = []types.Trade{
+		{ID: 1, Symbol: "BTCUSDT", Price: 9000.0, Quantity: 0.1, IsBuyer: true},
+		{ID: 2, Symbol: "BTCUSDT", Price: 9400.0, Quantity: 0.1, IsBuyer: true},
+		{ID: 3, Symbol: "BTCUSDT", Price: 9200.0, Quantity: 0.1, IsBuyer: true},
+		{ID: 4, Symbol: "BTCUSDT", Price: 9500.0, Quantity: 0.15, IsBuyer: false},
+	}
+
+	var tests = []struct {
+		method string
+		lots   []ConsumedLot
+	}{
+		{
+			method: CostBasisFIFO,
+			lots: []ConsumedLot{
+				{BuyTradeID: 1, Price: 9000.0, Quantity: 0.1},
+				{BuyTradeID: 2, Price: 9400.0, Quantity: 0.05},
+			},
+		},
+		{
+			method: CostBasisLIFO,
+			lots: []ConsumedLot{
+				{BuyTradeID: 3, Price: 9200.0, Quantity: 0.1},
+				{BuyTradeID: 2, Price: 9400.0, Quantity: 0.05},
+			},
+		},
+		{
+			method: CostBasisHIFO,
+			lots: []ConsumedLot{
+				{BuyTradeID: 2, Price: 9400.0, Quantity: 0.1},
+				{BuyTradeID: 3, Price: 9200.0, Quantity: 0.05},
+			},
+		},
+		{
+			method: CostBasisAverage,
+			lots: []ConsumedLot{
+				{BuyTradeID: 1, Price: 9200.0, Quantity: 0.1},
+				{BuyTradeID: 2, Price: 9200.0, Quantity: 0.05},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.method, func(t *testing.T) {
+			costBasis, err := NewCostBasisMethod(test.method)
+			assert.NoError(t, err)
+
+			var stockManager = &StockManager{
+				TradingFeeCurrency: "BNB",
+				Symbol:             "BTCUSDT",
+				CostBasis:          costBasis,
+			}
+
+			_, err = stockManager.AddTrades(trades)
+			assert.NoError(t, err)
+			assert.Equal(t, 0.15, stockManager.Stocks.Quantity())
+
+			record, ok := stockManager.SellRecord(4)
+			assert.True(t, ok)
+			if assert.Len(t, record.Lots, len(test.lots)) {
+				for i, lot := range test.lots {
+					assert.Equal(t, lot.BuyTradeID, record.Lots[i].BuyTradeID)
+					assert.InDelta(t, lot.Price, record.Lots[i].Price, 1e-8)
+					assert.InDelta(t, lot.Quantity, record.Lots[i].Quantity, 1e-8)
+				}
+			}
+
+			var cost float64
+			for _, lot := range test.lots {
+				cost += lot.Price * lot.Quantity
+			}
+			assert.InDelta(t, 9500.0*0.15-cost, record.RealizedProfit(), 1e-6)
+		})
+	}
+
+	t.Run("unknown", func(t *testing.T) {
+		_, err := NewCostBasisMethod("foo")
+		assert.Error(t, err)
+	})
+}
+
+func TestStockManager_Checkpoint(t *testing.T) {
+	var trades = []types.Trade{
+		{GID: 1, ID: 1, Symbol: "BTCUSDT", Price: 9000.0, Quantity: 0.1, IsBuyer: true},
+		{GID: 2, ID: 2, Symbol: "BTCUSDT", Price: 9400.0, Quantity: 0.1, IsBuyer: true},
+		{GID: 3, ID: 3, Symbol: "BTCUSDT", Price: 9500.0, Quantity: 0.15, IsBuyer: false},
+		{GID: 4, ID: 4, Symbol: "BTCUSDT", Price: 9200.0, Quantity: 0.1, IsBuyer: true},
+		{GID: 5, ID: 5, Symbol: "BTCUSDT", Price: 9300.0, Quantity: 0.05, IsBuyer: false},
+	}
+
+	var fullManager = &StockManager{Symbol: "BTCUSDT", TradingFeeCurrency: "BNB", CostBasis: FIFO{}}
+	_, err := fullManager.AddTrades(trades)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), fullManager.LastTradeGID)
+
+	// build the checkpoint with the first 3 trades, then replay the rest trades on the restored stock manager
+	var manager = &StockManager{Symbol: "BTCUSDT", TradingFeeCurrency: "BNB", CostBasis: FIFO{}}
+	_, err = manager.AddTrades(trades[:3])
+	assert.NoError(t, err)
+
+	checkpoint := manager.Checkpoint()
+	assert.Equal(t, CostBasisFIFO, checkpoint.CostBasis)
+	assert.Equal(t, int64(3), checkpoint.LastTradeGID)
+
+	var restored = &StockManager{Symbol: "BTCUSDT", TradingFeeCurrency: "BNB", CostBasis: FIFO{}}
+	restored.Restore(checkpoint)
+	_, err = restored.AddTrades(trades[3:])
+	assert.NoError(t, err)
+
+	assert.Equal(t, fullManager.Stocks, restored.Stocks)
+	assert.Equal(t, fullManager.LastTradeGID, restored.LastTradeGID)
+}
+
+func TestProfitLedger(t *testing.T) {
+	buyTime := time.Date(2020, 10, 1, 0, 0, 0, 0, time.UTC)
+	var trades = []types.Trade{
+		{ID: 1, Symbol: "BTCUSDT", Price: 9000.0, Quantity: 0.1, IsBuyer: true, Time: buyTime},
+		{ID: 2, Symbol: "BTCUSDT", Price: 9400.0, Quantity: 0.1, IsBuyer: true, Time: buyTime.Add(2 * time.Hour)},
+		{ID: 3, Symbol: "BTCUSDT", Price: 9500.0, Quantity: 0.15, IsBuyer: false, Time: buyTime.Add(4 * time.Hour), Fee: 1.5, FeeCurrency: "USDT"},
+	}
+
+	var stockManager = &StockManager{Symbol: "BTCUSDT", TradingFeeCurrency: "BNB", CostBasis: FIFO{}}
+	var ledger = &ProfitLedger{Market: types.MarketBTCUSDT, StockManager: stockManager}
+
+	for _, trade := range trades {
+		_, err := stockManager.AddTrades([]types.Trade{trade})
+		assert.NoError(t, err)
+
+		profit, err := ledger.AddTrade(trade)
+		assert.NoError(t, err)
+
+		if trade.IsBuyer {
+			assert.Nil(t, profit)
+		}
+	}
+
+	profits, err := ledger.QueryProfits(buyTime)
+	assert.NoError(t, err)
+	if assert.Len(t, profits, 1) {
+		profit := profits[0]
+		assert.Equal(t, int64(3), profit.TradeID)
+		assert.InDelta(t, 0.15, profit.Quantity, 1e-8)
+		assert.InDelta(t, 9000.0*0.1+9400.0*0.05, profit.Cost, 1e-8)
+		assert.InDelta(t, 1.5, profit.Fee, 1e-8)
+		assert.InDelta(t, 9500.0*0.15-(9000.0*0.1+9400.0*0.05)-1.5, profit.Profit, 1e-8)
+
+		// (4h * 0.1 + 2h * 0.05) / 0.15
+		assert.Equal(t, int64((4*3600*0.1+2*3600*0.05)/0.15), profit.HoldingSeconds)
+	}
+
+	// only 0.05 of the sell is matched, the fee is charged pro rata
+	sell := types.Trade{ID: 4, Symbol: "BTCUSDT", Price: 9600.0, Quantity: 0.1, IsBuyer: false, Time: buyTime.Add(5 * time.Hour), Fee: 2.0, FeeCurrency: "USDT"}
+	_, err = stockManager.AddTrades([]types.Trade{sell})
+	assert.NoError(t, err)
+
+	profit, err := ledger.AddTrade(sell)
+	if assert.NoError(t, err) && assert.NotNil(t, profit) {
+		assert.InDelta(t, 0.05, profit.Quantity, 1e-8)
+		assert.InDelta(t, 1.0, profit.Fee, 1e-8)
+		assert.InDelta(t, 9600.0*0.05-9400.0*0.05-1.0, profit.Profit, 1e-8)
+	}
+}
@@ -11,6 +11,9 @@ var Interval5m = Interval("5m")
 var Interval1h = Interval("1h")
 var Interval1d = Interval("1d")
 
+// MaxNumOfKLines is the max number of the klines kept in the window of each interval
+const MaxNumOfKLines = 1000
+
 type KLineCallback func(kline types.KLine)
 
 //go:generate callbackgen -type MarketDataStore
@@ -40,6 +43,8 @@ func (store *MarketDataStore) AddKLine(kline types.KLine) {
 	var interval = Interval(kline.Interval)
 	var window = store.KLineWindows[interval]
 	window.Add(kline)
+	window.Truncate(MaxNumOfKLines)
+	store.KLineWindows[interval] = window
 
 	store.EmitUpdate(kline)
 }
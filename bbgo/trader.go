@@ -12,7 +12,8 @@ import (
 
 	"github.com/c9s/bbgo/accounting"
 	"github.com/c9s/bbgo/bbgo/config"
-	"github.com/c9s/bbgo/exchange/binance"
+	"github.com/c9s/bbgo/exchange/paper"
+	"github.com/c9s/bbgo/migrations"
 	"github.com/c9s/bbgo/service"
 	"github.com/c9s/bbgo/types"
 )
@@ -23,16 +24,33 @@ type MarketStrategy interface {
 	OnNewStream(stream types.Stream) error
 }
 
+// ShutdownStrategy is implemented by the strategies that clean up when the strategy context is done, e.g., cancel the open orders
+type ShutdownStrategy interface {
+	OnShutdown(ctx context.Context) error
+}
+
+// StrategyShutdownTimeout is the time limit of the strategy clean up
+const StrategyShutdownTimeout = 30 * time.Second
+
 type ExchangeSession struct {
 	Name string
 
 	Account *Account
 
+	// FeeSchedule is the fee rates of the session account
+	FeeSchedule *types.FeeSchedule
+
+	// SymbolFeeRates override the queried fee rates of the symbols
+	SymbolFeeRates map[string]types.FeeRates
+
 	Stream types.Stream
 
 	Subscriptions []types.Subscription
 
-	Exchange *binance.Exchange
+	Exchange types.Exchange
+
+	// PaperTrade is true when the session submits orders to the simulated matching engine
+	PaperTrade bool
 
 	Strategies []MarketStrategy
 
@@ -41,6 +59,35 @@ type ExchangeSession struct {
 	Markets map[string]types.Market
 
 	Trades map[string][]types.Trade
+
+	// tradedVolumes are the traded volume trackers of the loaded symbols for the daily volume cap
+	tradedVolumes map[string]*TradedVolumeTracker
+
+	// orderBooks are the order books of the loaded symbols, they are updated by the book events of the session stream
+	orderBooks map[string]*types.MutexOrderBook
+
+	// OrderExecutor submits the orders of the cross exchange strategies to the session exchange
+	OrderExecutor *ExchangeOrderExecutor
+}
+
+// NewExchangeSession creates the session of the exchange, the session of the paper exchange is a paper trading session
+func NewExchangeSession(name string, exchange types.Exchange) *ExchangeSession {
+	_, isPaper := exchange.(*paper.Exchange)
+	return &ExchangeSession{
+		Name:       name,
+		Exchange:   exchange,
+		PaperTrade: isPaper,
+	}
+}
+
+// TradedVolume returns the traded volume tracker of the symbol, nil is returned if the symbol is not loaded
+func (session *ExchangeSession) TradedVolume(symbol string) *TradedVolumeTracker {
+	return session.tradedVolumes[symbol]
+}
+
+// OrderBook returns the order book of the symbol, nil is returned if the symbol is not loaded
+func (session *ExchangeSession) OrderBook(symbol string) *types.MutexOrderBook {
+	return session.orderBooks[symbol]
 }
 
 func (session *ExchangeSession) Subscribe(channel types.Channel, symbol string, options types.SubscribeOptions) *ExchangeSession {
@@ -87,31 +134,93 @@ type Trader struct {
 	TradeService *service.TradeService
 	TradeSync    *service.TradeSync
 
+	// OrderService stores the orders, OrderSync backfills the historical orders on connect
+	OrderService *service.OrderService
+	OrderSync    *service.OrderSync
+
+	// StockService stores the stock checkpoints, so that only the newer trades are replayed on start
+	StockService *service.StockService
+
+	// ProfitService stores the realized profit of each sell trade
+	ProfitService *service.ProfitService
+
+	// TransferSync syncs the deposits and the withdraws, they are excluded from the balance change reports
+	TransferSync *service.TransferSync
+
 	// Context is trading Context
 	Context *Context
 
-	Exchange *binance.Exchange
+	Exchange types.Exchange
 
 	reportTimer *time.Timer
 
+	// stockLoader saves the stock checkpoint when the new trades are added to the stock manager
+	stockLoader *StockLoader
+
 	ProfitAndLossCalculator *accounting.ProfitAndLossCalculator
 
+	// ProfitLedger records the realized profit of the sell trades
+	ProfitLedger *ProfitLedger
+
+	// StateService stores the states of the stateful strategies, see StatefulStrategy
+	StateService *service.StrategyStateService
+
 	Account *Account
 
+	// CostBasis is the method of matching the sell trades against the bought stocks, see NewCostBasisMethod
+	CostBasis CostBasisMethod
+
+	// ReportingCurrency is the currency of the PnL reports, the quote currency of the symbol is used if it's not set
+	ReportingCurrency string
+
+	// SymbolFeeRates override the queried fee rates of the symbols, e.g., the zero-fee promotion pairs
+	SymbolFeeRates map[string]types.FeeRates
+
+	// Prices are the last prices used for converting the fees and the reports to the other currencies
+	Prices *accounting.PriceMap
+
 	Notifiers []Notifier
 
+	// RiskControls are the order limits applied when submitting orders,
+	// use RiskControlConfig.For to resolve the session and strategy based limits from the config file.
+	RiskControls OrderRiskControls
+
+	// CircuitBreaker halts the trading when the loss exceeds the threshold, use SetCircuitBreaker to set it up.
+	CircuitBreaker *CircuitBreaker
+
+	// Exit is the exit rules of the long position, the buy trades of the symbol are watched by the ExitManager
+	Exit *ExitConfig
+
+	// ExitManager is set up by RunStrategy when the exit rules are set
+	ExitManager *ExitManager
+
 	ExchangeSessions map[string]*ExchangeSession
+
+	// Subscriptions are subscribed on the strategy stream before the strategy subscribes its channels
+	Subscriptions []types.Subscription
 }
 
-func New(db *sqlx.DB, exchange *binance.Exchange, symbol string) *Trader {
+func New(db *sqlx.DB, exchange types.Exchange, symbol string) *Trader {
 	tradeService := &service.TradeService{DB: db}
+	orderService := &service.OrderService{DB: db}
 	return &Trader{
-		Symbol:       symbol,
-		Exchange:     exchange,
-		TradeService: tradeService,
+		Symbol:        symbol,
+		Exchange:      exchange,
+		TradeService:  tradeService,
+		StockService:  &service.StockService{DB: db},
+		ProfitService: &service.ProfitService{DB: db},
 		TradeSync: &service.TradeSync{
 			Service: tradeService,
 		},
+		OrderService: orderService,
+		OrderSync: &service.OrderSync{
+			Service: orderService,
+		},
+		TransferSync: &service.TransferSync{
+			DepositService:  service.NewDepositService(db),
+			WithdrawService: service.NewWithdrawService(db),
+		},
+		StateService: service.NewStrategyStateService(db),
 	}
 }
 
@@ -119,11 +228,14 @@ func (trader *Trader) AddNotifier(notifier Notifier) {
 	trader.Notifiers = append(trader.Notifiers, notifier)
 }
 
-func (trader *Trader) AddExchange(name string, exchange *binance.Exchange) (session *ExchangeSession) {
-	session = &ExchangeSession{
-		Name:     name,
-		Exchange: exchange,
-	}
+// IsPaperTrade returns true if the trader submits orders to the paper exchange
+func (trader *Trader) IsPaperTrade() bool {
+	_, ok := trader.Exchange.(*paper.Exchange)
+	return ok
+}
+
+func (trader *Trader) AddExchange(name string, exchange types.Exchange) (session *ExchangeSession) {
+	session = NewExchangeSession(name, exchange)
 
 	if trader.ExchangeSessions == nil {
 		trader.ExchangeSessions = make(map[string]*ExchangeSession)
@@ -133,76 +245,220 @@ func (trader *Trader) AddExchange(name string, exchange *binance.Exchange) (sess
 	return session
 }
 
-func (trader *Trader) Connect(ctx context.Context) (err error) {
-	log.Info("syncing trades from exchange...")
-	startTime := time.Now().AddDate(0, 0, -7) // sync from 7 days ago
+// AddPaperExchange adds a paper trading session, the market data is streamed from the source exchange,
+// and the orders are filled by the simulated matching engine with the given virtual balances.
+func (trader *Trader) AddPaperExchange(name string, source types.Exchange, balances types.BalanceMap) (session *ExchangeSession) {
+	return trader.AddExchange(name, paper.New(source, balances))
+}
+
+// checkSchema refuses to run the trader on a database that the migrations are not applied to
+func (trader *Trader) checkSchema() error {
+	if trader.TradeService == nil || trader.TradeService.DB == nil {
+		return nil
+	}
+
+	return migrations.NewMigrator(trader.TradeService.DB).Check()
+}
 
+func (trader *Trader) Connect(ctx context.Context) (err error) {
 	for _, session := range trader.ExchangeSessions {
+		if err := trader.loadSession(ctx, session); err != nil {
+			return err
+		}
 
-		for symbol := range session.loadedSymbols {
-			if err := trader.TradeSync.Sync(ctx, session.Exchange, symbol, startTime); err != nil {
-				return err
-			}
+		if err := session.Stream.Connect(ctx); err != nil {
+			return err
+		}
+	}
 
-			var trades []types.Trade
+	return nil
+}
 
-			tradingFeeCurrency := session.Exchange.PlatformFeeCurrency()
-			if strings.HasPrefix(symbol, tradingFeeCurrency) {
-				trades, err = trader.TradeService.QueryForTradingFeeCurrency(symbol, tradingFeeCurrency)
-			} else {
-				trades, err = trader.TradeService.Query(symbol)
-			}
+// loadSession syncs the trades and the orders of the session symbols, loads the account and the fee schedule,
+// and creates the session stream with the session subscriptions. The stream is not connected yet.
+func (trader *Trader) loadSession(ctx context.Context, session *ExchangeSession) (err error) {
+	if !session.PaperTrade {
+		if err := trader.checkSchema(); err != nil {
+			return err
+		}
+	}
 
-			if err != nil {
-				return err
-			}
+	log.Infof("syncing %s trades from exchange...", session.Name)
+	startTime := time.Now().AddDate(0, 0, -7) // sync from 7 days ago
 
-			log.Infof("symbol %s: %d trades loaded", symbol, len(trades))
-			if session.Trades == nil {
-				session.Trades = make(map[string][]types.Trade)
-			}
-			session.Trades[symbol] = trades
+	for symbol := range session.loadedSymbols {
+		// paper trades are not persisted, the simulated session always starts from an empty history
+		if session.PaperTrade {
+			continue
+		}
 
-			stockManager := &StockManager{
-				Symbol:             symbol,
-				TradingFeeCurrency: tradingFeeCurrency,
-			}
+		if err := trader.repairTradeTimes(ctx, session.Exchange, symbol); err != nil {
+			return err
+		}
 
-			checkpoints, err := stockManager.AddTrades(trades)
-			if err != nil {
-				return err
-			}
+		if err := trader.TradeSync.Sync(ctx, session.Exchange, symbol, startTime); err != nil {
+			return err
+		}
+
+		if err := trader.OrderSync.Sync(ctx, session.Exchange, symbol, startTime); err != nil {
+			return err
+		}
+
+		var trades []types.Trade
 
-			log.Infof("symbol %s: found stock checkpoints: %+v", symbol, checkpoints)
+		tradingFeeCurrency := session.Exchange.PlatformFeeCurrency()
+		if strings.HasPrefix(symbol, tradingFeeCurrency) {
+			trades, err = trader.TradeService.QueryForTradingFeeCurrency(session.Exchange.Name(), symbol, tradingFeeCurrency)
+		} else {
+			trades, err = trader.TradeService.Query(session.Exchange.Name(), symbol)
 		}
 
-		session.Account, err = LoadAccount(ctx, session.Exchange)
 		if err != nil {
 			return err
 		}
 
-		session.Stream = session.Exchange.NewStream()
+		log.Infof("symbol %s: %d trades loaded", symbol, len(trades))
+		if session.Trades == nil {
+			session.Trades = make(map[string][]types.Trade)
+		}
+		session.Trades[symbol] = trades
+
+		stockManager := &StockManager{
+			Symbol:             symbol,
+			TradingFeeCurrency: tradingFeeCurrency,
+			CostBasis:          trader.CostBasis,
+		}
+
+		checkpoints, err := stockManager.AddTrades(trades)
 		if err != nil {
 			return err
 		}
 
-		if err := session.Stream.Connect(ctx); err != nil {
-			return err
+		log.Infof("symbol %s: found stock checkpoints: %+v", symbol, checkpoints)
+	}
+
+	session.tradedVolumes = make(map[string]*TradedVolumeTracker)
+	for symbol := range session.loadedSymbols {
+		tradedVolume := &TradedVolumeTracker{}
+		for _, trade := range session.Trades[symbol] {
+			if trade.Symbol == symbol {
+				tradedVolume.AddTrade(trade)
+			}
+		}
+
+		session.tradedVolumes[symbol] = tradedVolume
+	}
+
+	session.Account, err = LoadAccount(ctx, session.Exchange)
+	if err != nil {
+		return err
+	}
+
+	session.FeeSchedule, err = QueryFeeSchedule(ctx, session.Exchange, session.SymbolFeeRates)
+	if err != nil {
+		return err
+	}
+
+	session.Stream = session.Exchange.NewStream()
+	for _, s := range session.Subscriptions {
+		session.Stream.Subscribe(s.Channel, s.Symbol, s.Options)
+	}
+
+	session.Account.BindPrivateStream(session.Stream)
+
+	session.Stream.OnTrade(func(trade *types.Trade) {
+		if tradedVolume, ok := session.tradedVolumes[trade.Symbol]; ok {
+			tradedVolume.AddTrade(*trade)
+		}
+	})
+
+	session.orderBooks = make(map[string]*types.MutexOrderBook)
+	for symbol := range session.loadedSymbols {
+		session.orderBooks[symbol] = types.NewMutexOrderBook(symbol)
+	}
+
+	session.Stream.OnBookSnapshot(func(book types.OrderBook) {
+		if orderBook, ok := session.orderBooks[book.Symbol]; ok {
+			orderBook.Load(book)
+		}
+	})
+
+	session.Stream.OnBookUpdate(func(book types.OrderBook) {
+		if orderBook, ok := session.orderBooks[book.Symbol]; ok {
+			orderBook.Update(book)
 		}
+	})
+
+	// the trades and the order events of the real trading sessions are stored
+	if !session.PaperTrade {
+		session.Stream.OnTrade(func(trade *types.Trade) {
+			if err := trader.TradeService.Insert(*trade); err != nil {
+				log.WithError(err).Error("trade insert error")
+			}
+		})
+
+		session.Stream.OnOrderUpdate(func(order types.Order) {
+			if err := trader.OrderService.Insert(order); err != nil {
+				log.WithError(err).Error("order insert error")
+			}
+		})
 	}
 
 	return nil
 }
 
+// Sync syncs the trades and the orders of the trader symbol from the exchange into the database,
+// nothing is synced in the paper trading mode.
+func (trader *Trader) Sync(ctx context.Context, startTime time.Time) error {
+	if trader.IsPaperTrade() {
+		return nil
+	}
+
+	if err := trader.checkSchema(); err != nil {
+		return err
+	}
+
+	if err := trader.repairTradeTimes(ctx, trader.Exchange, trader.Symbol); err != nil {
+		return err
+	}
+
+	if err := trader.TradeSync.Sync(ctx, trader.Exchange, trader.Symbol, startTime); err != nil {
+		return err
+	}
+
+	return trader.OrderSync.Sync(ctx, trader.Exchange, trader.Symbol, startTime)
+}
+
+// repairTradeTimes repairs the stored trades with the invalid trade time before syncing the new trades,
+// the stock checkpoint and the profits of the symbol are rebuilt if any trade is repaired.
+func (trader *Trader) repairTradeTimes(ctx context.Context, exchange types.Exchange, symbol string) error {
+	reconciler := &service.TradeReconciler{
+		Service:       trader.TradeService,
+		StockService:  trader.StockService,
+		ProfitService: trader.ProfitService,
+	}
+
+	_, err := reconciler.RepairTradeTimes(ctx, exchange, symbol)
+	return err
+}
+
 func (trader *Trader) Initialize(ctx context.Context, startTime time.Time) error {
+	if !trader.IsPaperTrade() {
+		if err := trader.checkSchema(); err != nil {
+			return err
+		}
+	}
+
 	// query all trades from database so that we can get the correct pnl
 	var err error
 	var trades []types.Trade
 	tradingFeeCurrency := trader.Exchange.PlatformFeeCurrency()
-	if strings.HasPrefix(trader.Symbol, tradingFeeCurrency) {
-		trades, err = trader.TradeService.QueryForTradingFeeCurrency(trader.Symbol, tradingFeeCurrency)
+	if trader.IsPaperTrade() {
+		log.Info("paper trading mode, skip loading trades from database")
+	} else if strings.HasPrefix(trader.Symbol, tradingFeeCurrency) {
+		trades, err = trader.TradeService.QueryForTradingFeeCurrency(trader.Exchange.Name(), trader.Symbol, tradingFeeCurrency)
 	} else {
-		trades, err = trader.TradeService.Query(trader.Symbol)
+		trades, err = trader.TradeService.Query(trader.Exchange.Name(), trader.Symbol)
 	}
 
 	if err != nil {
@@ -214,30 +470,61 @@ func (trader *Trader) Initialize(ctx context.Context, startTime time.Time) error
 	stockManager := &StockManager{
 		Symbol:             trader.Symbol,
 		TradingFeeCurrency: tradingFeeCurrency,
+		CostBasis:          trader.CostBasis,
 	}
 
-	checkpoints, err := stockManager.AddTrades(trades)
-	if err != nil {
-		return err
-	}
+	var replayedTrades []types.Trade
+	if trader.IsPaperTrade() {
+		if _, err := stockManager.AddTrades(trades); err != nil {
+			return err
+		}
+	} else {
+		stockLoader := &StockLoader{
+			Exchange:     trader.Exchange.Name(),
+			TradeService: trader.TradeService,
+			StockService: trader.StockService,
+		}
 
-	log.Infof("found checkpoints: %+v", checkpoints)
+		replayedTrades, err = stockLoader.Load(stockManager)
+		if err != nil {
+			return err
+		}
+
+		trader.stockLoader = stockLoader
+	}
 
 	market, ok := types.FindMarket(trader.Symbol)
 	if !ok {
 		return fmt.Errorf("%s market not found", trader.Symbol)
 	}
 
-	currentPrice, err := trader.Exchange.QueryAveragePrice(ctx, trader.Symbol)
+	currentPrice, err := QueryCurrentPrice(ctx, trader.Exchange, trader.Symbol)
+	if err != nil {
+		return err
+	}
+
+	feeSchedule, err := QueryFeeSchedule(ctx, trader.Exchange, trader.SymbolFeeRates)
 	if err != nil {
 		return err
 	}
 
+	feeRates := feeSchedule.FeeRates(trader.Symbol)
+	log.Infof("%s fee rates: maker %f, taker %f", trader.Symbol, feeRates.MakerFeeRate, feeRates.TakerFeeRate)
+
+	tradedVolume := &TradedVolumeTracker{}
+	for _, trade := range trades {
+		if trade.Symbol == trader.Symbol {
+			tradedVolume.AddTrade(trade)
+		}
+	}
+
 	trader.Context = &Context{
 		CurrentPrice: currentPrice,
 		Symbol:       trader.Symbol,
 		Market:       market,
 		StockManager: stockManager,
+		FeeSchedule:  feeSchedule,
+		TradedVolume: tradedVolume,
 	}
 
 	/*
@@ -253,14 +540,42 @@ func (trader *Trader) Initialize(ctx context.Context, startTime time.Time) error
 		}
 	*/
 
+	trader.Prices = accounting.NewPriceMap()
+	trader.Prices.SetPrice(trader.Symbol, currentPrice)
+	trader.updateConversionPrices(ctx)
+
 	trader.ProfitAndLossCalculator = &accounting.ProfitAndLossCalculator{
 		TradingFeeCurrency: tradingFeeCurrency,
+		Market:             market,
+		PriceSource:        trader.Prices,
+		ReportingCurrency:  trader.ReportingCurrency,
+		FeeRate:            feeRates.TakerFeeRate,
+		PaperTrade:         trader.IsPaperTrade(),
 		Symbol:             trader.Symbol,
 		StartTime:          startTime,
 		CurrentPrice:       currentPrice,
 		Trades:             trades,
 	}
 
+	trader.ProfitLedger = &ProfitLedger{
+		Exchange:     trader.Exchange.Name(),
+		Market:       market,
+		StockManager: stockManager,
+		PriceSource:  trader.Prices,
+	}
+
+	// simulated profits are only kept in memory
+	if !trader.IsPaperTrade() {
+		trader.ProfitLedger.ProfitService = trader.ProfitService
+	}
+
+	// the profits of the trades synced when the trader is not running are recorded from the replayed trades
+	for _, trade := range replayedTrades {
+		if _, err := trader.ProfitLedger.AddTrade(trade); err != nil {
+			return err
+		}
+	}
+
 	account, err := LoadAccount(ctx, trader.Exchange)
 	if err != nil {
 		return err
@@ -350,43 +665,60 @@ func (trader *Trader) RunStrategyWithHotReload(ctx context.Context, strategy Mar
 }
 
 func (trader *Trader) RunStrategy(ctx context.Context, strategy MarketStrategy) (chan struct{}, error) {
+	// the kline store is created before the strategy is loaded, so that the strategy can subscribe the indicators
+	klineStore := NewMarketDataStore()
+	trader.Context.MarketDataStore = klineStore
+
+	trader.setStateStore(strategy)
 	if err := strategy.OnLoad(trader.Context, trader); err != nil {
 		return nil, err
 	}
 
 	stream := trader.Exchange.NewStream()
-	if err != nil {
-		return nil, err
-	}
 
 	// bind kline store to the stream
-	klineStore := NewMarketDataStore()
 	klineStore.BindPrivateStream(stream)
 
 	trader.Account.BindPrivateStream(stream)
 
+	for _, s := range trader.Subscriptions {
+		stream.Subscribe(s.Channel, s.Symbol, s.Options)
+	}
+
 	if err := strategy.OnNewStream(stream); err != nil {
 		return nil, err
 	}
 
+	if trader.Exit != nil {
+		if err := trader.bindExitManager(stream); err != nil {
+			return nil, err
+		}
+	}
+
 	trader.reportTimer = time.AfterFunc(1*time.Second, func() {
 		trader.reportPnL()
 	})
 
 	stream.OnTrade(func(trade *types.Trade) {
 		if trade.Symbol != trader.Symbol {
+			// the fee trades of the other symbols consume the stocks of the fee currency symbol, e.g., BNBUSDT,
+			// they are added live since the checkpoint cursor moves past them
+			if trader.Context.StockManager.IsFeeTrade(*trade) {
+				trader.storeTrade(trade)
+				trader.addStockTrade(*trade)
+			}
 			return
 		}
 
-		if err := trader.TradeService.Insert(*trade); err != nil {
-			log.WithError(err).Error("trade insert error")
-		}
+		trader.storeTrade(trade)
 
 		trader.NotifyTrade(trade)
+		trader.Context.TradedVolume.AddTrade(*trade)
 		trader.ProfitAndLossCalculator.AddTrade(*trade)
-		_, err := trader.Context.StockManager.AddTrades([]types.Trade{*trade})
-		if err != nil {
-			log.WithError(err).Error("stock manager load trades error")
+		trader.addStockTrade(*trade)
+
+		if _, err := trader.ProfitLedger.AddTrade(*trade); err != nil {
+			log.WithError(err).Error("profit insert error")
 		}
 
 		if trader.reportTimer != nil {
@@ -398,11 +730,37 @@ func (trader *Trader) RunStrategy(ctx context.Context, strategy MarketStrategy)
 		})
 	})
 
+	// the order events insert the new orders and update the status of the stored orders
+	if !trader.IsPaperTrade() {
+		stream.OnOrderUpdate(func(order types.Order) {
+			if order.Symbol != trader.Symbol {
+				return
+			}
+
+			if err := trader.OrderService.Insert(order); err != nil {
+				log.WithError(err).Error("order insert error")
+			}
+		})
+	}
+
 	stream.OnKLineClosed(func(kline types.KLine) {
+		trader.Prices.SetPrice(kline.Symbol, kline.Close)
 		trader.ProfitAndLossCalculator.SetCurrentPrice(kline.Close)
 		trader.Context.SetCurrentPrice(kline.Close)
 	})
 
+	if trader.CircuitBreaker != nil {
+		stream.OnKLineClosed(func(kline types.KLine) {
+			trader.recordProfit()
+		})
+
+		stream.OnBalanceSnapshot(func(balances map[string]types.Balance) {
+			trader.recordProfit()
+		})
+
+		trader.handleCircuitBreakerResetSignal(ctx)
+	}
+
 	if err := stream.Connect(ctx); err != nil {
 		return nil, err
 	}
@@ -416,6 +774,14 @@ func (trader *Trader) RunStrategy(ctx context.Context, strategy MarketStrategy)
 		select {
 
 		case <-ctx.Done():
+			if s, ok := strategy.(ShutdownStrategy); ok {
+				shutdownCtx, cancel := context.WithTimeout(context.Background(), StrategyShutdownTimeout)
+				defer cancel()
+
+				if err := s.OnShutdown(shutdownCtx); err != nil {
+					log.WithError(err).Error("strategy shutdown error")
+				}
+			}
 			return
 
 		}
@@ -424,8 +790,176 @@ func (trader *Trader) RunStrategy(ctx context.Context, strategy MarketStrategy)
 	return done, nil
 }
 
+// storeTrade stores the trade and sets the gid of the stored trade, the simulated trades are not stored
+func (trader *Trader) storeTrade(trade *types.Trade) {
+	// simulated trades must not pollute the real trade history
+	if trader.IsPaperTrade() {
+		return
+	}
+
+	if err := trader.TradeService.Insert(*trade); err != nil {
+		log.WithError(err).Error("trade insert error")
+	} else if stored, err := trader.TradeService.QueryByID(trade.Exchange, trade.Symbol, trade.ID); err != nil {
+		log.WithError(err).Error("trade query error")
+	} else if stored != nil {
+		// the gid is the cursor of the stock checkpoint
+		trade.GID = stored.GID
+	}
+}
+
+// addStockTrade adds the trade to the stock manager and saves the stock checkpoint
+func (trader *Trader) addStockTrade(trade types.Trade) {
+	if _, err := trader.Context.StockManager.AddTrades([]types.Trade{trade}); err != nil {
+		log.WithError(err).Error("stock manager load trades error")
+		return
+	}
+
+	if trader.stockLoader == nil {
+		return
+	}
+
+	if trade.GID == 0 {
+		// the checkpoint can not cover the trade that is not stored, stop saving the checkpoints
+		// so that the trade is replayed from the database after it's synced on the next start
+		log.Warnf("%s trade %d is not stored, stock checkpoint saving is stopped", trade.Symbol, trade.ID)
+		trader.stockLoader = nil
+		return
+	}
+
+	if err := trader.stockLoader.Save(trader.Context.StockManager); err != nil {
+		log.WithError(err).Error("stock checkpoint save error")
+	}
+}
+
+// SetCircuitBreaker sets up the circuit breaker, the notifiers are alerted when the circuit breaker is tripped or reset.
+func (trader *Trader) SetCircuitBreaker(breaker *CircuitBreaker) {
+	trader.CircuitBreaker = breaker
+
+	breaker.OnHalt(func(loss float64) {
+		trader.Notify(":rotating_light: %s circuit breaker tripped, loss %f >= max loss %f in %s, trading is halted",
+			trader.Symbol, loss, breaker.MaxLoss, breaker.window())
+
+		if breaker.CancelOpenOrders {
+			if err := trader.CancelOpenOrders(context.Background()); err != nil {
+				log.WithError(err).Error("can not cancel open orders")
+				trader.Notify(":x: %s can not cancel open orders: %s", trader.Symbol, err.Error())
+			}
+		}
+	})
+
+	breaker.OnReset(func() {
+		trader.Notify(":white_check_mark: %s circuit breaker reset, trading is re-enabled", trader.Symbol)
+	})
+}
+
+// ResetCircuitBreaker re-enables the trading after the circuit breaker is tripped
+func (trader *Trader) ResetCircuitBreaker() {
+	if trader.CircuitBreaker != nil {
+		trader.CircuitBreaker.Reset()
+	}
+}
+
+func (trader *Trader) recordProfit() {
+	report := trader.ProfitAndLossCalculator.Calculate()
+	trader.CircuitBreaker.RecordProfit(time.Now(), report.UnrealizedProfit)
+}
+
+// QueryOpenOrders queries the open orders of the trader symbol
+func (trader *Trader) QueryOpenOrders(ctx context.Context) ([]types.Order, error) {
+	querier, ok := trader.Exchange.(OpenOrderQuerier)
+	if !ok {
+		return nil, fmt.Errorf("exchange does not support open order query")
+	}
+
+	return querier.QueryOpenOrders(ctx, trader.Symbol)
+}
+
+// CancelOrders cancels the given orders on the trader exchange
+func (trader *Trader) CancelOrders(ctx context.Context, orders ...types.Order) error {
+	canceller, ok := trader.Exchange.(OrderCanceller)
+	if !ok {
+		return fmt.Errorf("exchange does not support order cancellation")
+	}
+
+	if len(orders) == 0 {
+		return nil
+	}
+
+	log.Infof("canceling %d orders of %s", len(orders), trader.Symbol)
+	return canceller.CancelOrders(ctx, orders...)
+}
+
+// CancelOpenOrders cancels all the open orders of the trader symbol,
+// the cross exchange trader cancels the open orders of the loaded symbols of all the sessions.
+func (trader *Trader) CancelOpenOrders(ctx context.Context) error {
+	if trader.Exchange == nil {
+		return trader.cancelSessionOpenOrders(ctx)
+	}
+
+	orders, err := trader.QueryOpenOrders(ctx)
+	if err != nil {
+		return err
+	}
+
+	return trader.CancelOrders(ctx, orders...)
+}
+
+// cancelSessionOpenOrders cancels the open orders of the loaded symbols of all the sessions
+func (trader *Trader) cancelSessionOpenOrders(ctx context.Context) error {
+	for _, session := range trader.ExchangeSessions {
+		querier, ok := session.Exchange.(OpenOrderQuerier)
+		if !ok {
+			return fmt.Errorf("exchange of session %s does not support open order query", session.Name)
+		}
+
+		canceller, ok := session.Exchange.(OrderCanceller)
+		if !ok {
+			return fmt.Errorf("exchange of session %s does not support order cancellation", session.Name)
+		}
+
+		for symbol := range session.loadedSymbols {
+			orders, err := querier.QueryOpenOrders(ctx, symbol)
+			if err != nil {
+				return err
+			}
+
+			if len(orders) == 0 {
+				continue
+			}
+
+			log.Infof("canceling %d open orders of %s on session %s", len(orders), symbol, session.Name)
+			if err := canceller.CancelOrders(ctx, orders...); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// updateConversionPrices updates the prices for converting the platform fee currency to the quote currency,
+// and converting the quote currency to the reporting currency.
+func (trader *Trader) updateConversionPrices(ctx context.Context) {
+	market := trader.Context.Market
+	QueryConversionPrices(ctx, trader.Exchange, trader.Prices, market.QuoteCurrency, trader.Exchange.PlatformFeeCurrency())
+
+	if len(trader.ReportingCurrency) > 0 {
+		QueryConversionPrices(ctx, trader.Exchange, trader.Prices, trader.ReportingCurrency, market.QuoteCurrency)
+	}
+}
+
 func (trader *Trader) reportPnL() {
+	trader.updateConversionPrices(context.Background())
+
 	report := trader.ProfitAndLossCalculator.Calculate()
+
+	profits, err := trader.ProfitLedger.QueryProfits(trader.ProfitAndLossCalculator.StartTime)
+	if err != nil {
+		log.WithError(err).Error("profit query error")
+	} else {
+		report.Breakdowns = accounting.BreakDownProfits(profits, time.Local)
+	}
+
 	report.Print()
 	trader.NotifyPnL(report)
 }
@@ -436,6 +970,14 @@ func (trader *Trader) NotifyPnL(report *accounting.ProfitAndLossReport) {
 	}
 }
 
+func (trader *Trader) NotifyReport(report accounting.Report) {
+	report.Print()
+
+	for _, n := range trader.Notifiers {
+		n.NotifyReport(report)
+	}
+}
+
 func (trader *Trader) NotifyTrade(trade *types.Trade) {
 	for _, n := range trader.Notifiers {
 		n.NotifyTrade(trade)
@@ -443,6 +985,10 @@ func (trader *Trader) NotifyTrade(trade *types.Trade) {
 }
 
 func (trader *Trader) Notify(msg string, args ...interface{}) {
+	if trader.IsPaperTrade() {
+		msg = "[paper] " + msg
+	}
+
 	for _, n := range trader.Notifiers {
 		n.Notify(msg, args...)
 	}
@@ -452,19 +998,19 @@ func (trader *Trader) SubmitOrder(ctx context.Context, order *types.SubmitOrder)
 	trader.Notify(":memo: Submitting %s %s %s order with quantity: %s", order.Symbol, order.Type, order.Side, order.QuantityString, order)
 
 	orderProcessor := &OrderProcessor{
-		MinQuoteBalance: 0,
-		MaxAssetBalance: 0,
-		MinAssetBalance: 0,
-		MinProfitSpread: 0,
-		MaxOrderAmount:  0,
-		Exchange:        trader.Exchange,
-		Trader:          trader,
+		OrderRiskControls: trader.RiskControls,
+		Exchange:          trader.Exchange,
+		Trader:            trader,
 	}
 
 	err := orderProcessor.Submit(ctx, order)
 
 	if err != nil {
 		log.WithError(err).Errorf("order create error: side %s quantity: %s", order.Side, order.QuantityString)
+
+		if IsRiskControlError(err) {
+			trader.Notify(":no_entry: %s %s order rejected by risk controls: %s", order.Symbol, order.Side, err.Error())
+		}
 		return
 	}
 }
@@ -0,0 +1,30 @@
+package bbgo
+
+import (
+	"context"
+	"time"
+
+	"github.com/c9s/bbgo/accounting"
+	"github.com/c9s/bbgo/service"
+	"github.com/c9s/bbgo/types"
+)
+
+// QueryNetInflow syncs the new deposits and withdraws of the exchange into the database, then sums up the net inflow
+// of each currency in the time range from the stored records. The exchanges that can not query the transfer history have no inflow.
+func QueryNetInflow(ctx context.Context, transferSync *service.TransferSync, exchange types.Exchange, since, until time.Time) (map[string]float64, error) {
+	if err := transferSync.Sync(ctx, exchange.Name(), exchange, since); err != nil {
+		return nil, err
+	}
+
+	deposits, err := transferSync.DepositService.Query(exchange.Name(), since, until)
+	if err != nil {
+		return nil, err
+	}
+
+	withdraws, err := transferSync.WithdrawService.Query(exchange.Name(), since, until)
+	if err != nil {
+		return nil, err
+	}
+
+	return accounting.NetInflow(deposits, withdraws), nil
+}
@@ -0,0 +1,63 @@
+package bbgo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/service"
+	"github.com/c9s/bbgo/types"
+)
+
+type testTransferExchange struct {
+	testCrossExchange
+
+	deposits  []types.Deposit
+	withdraws []types.Withdraw
+}
+
+func (e *testTransferExchange) QueryDepositHistory(ctx context.Context, asset string, since, until time.Time) ([]types.Deposit, error) {
+	return e.deposits, nil
+}
+
+func (e *testTransferExchange) QueryWithdrawHistory(ctx context.Context, asset string, since, until time.Time) ([]types.Withdraw, error) {
+	return e.withdraws, nil
+}
+
+func TestQueryNetInflow(t *testing.T) {
+	db, _ := newExitTestService(t)
+	defer db.Close()
+
+	transferSync := &service.TransferSync{
+		DepositService:  service.NewDepositService(db),
+		WithdrawService: service.NewWithdrawService(db),
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	exchange := &testTransferExchange{
+		testCrossExchange: testCrossExchange{name: "binance"},
+		deposits: []types.Deposit{
+			{Exchange: "binance", Asset: "USDT", Amount: 1000.0, TransactionID: "tx1", Status: types.DepositSuccess, Time: now.Add(-time.Hour)},
+		},
+		withdraws: []types.Withdraw{
+			// the pending withdraws have no transaction id yet
+			{Exchange: "binance", ID: "w1", Asset: "BTC", Amount: 0.1, Status: types.WithdrawProcessing, ApplyTime: now.Add(-time.Hour)},
+			{Exchange: "binance", ID: "w2", Asset: "BTC", Amount: 0.2, Status: types.WithdrawProcessing, ApplyTime: now.Add(-time.Hour)},
+		},
+	}
+
+	inflows, err := QueryNetInflow(context.Background(), transferSync, exchange, now.Add(-2*time.Hour), now)
+	if assert.NoError(t, err) {
+		assert.InDelta(t, 1000.0, inflows["USDT"], 1e-9)
+		assert.InDelta(t, -0.3, inflows["BTC"], 1e-9)
+	}
+
+	// the stored transfers are still counted when the exchange only returns the new transfers
+	exchange.deposits = nil
+	inflows, err = QueryNetInflow(context.Background(), transferSync, exchange, now.Add(-2*time.Hour), now)
+	if assert.NoError(t, err) {
+		assert.InDelta(t, 1000.0, inflows["USDT"], 1e-9)
+	}
+}
@@ -0,0 +1,9 @@
+package cmd
+
+// the built-in strategies are registered for the run command
+import (
+	_ "github.com/c9s/bbgo/strategies/dca"
+	_ "github.com/c9s/bbgo/strategies/grid"
+	_ "github.com/c9s/bbgo/strategies/rebalance"
+	_ "github.com/c9s/bbgo/strategies/xmaker"
+)
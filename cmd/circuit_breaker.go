@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	CircuitBreakerResetCmd.Flags().Int("pid", 0, "the pid of the running bbgo process")
+	CircuitBreakerCmd.AddCommand(CircuitBreakerResetCmd)
+	RootCmd.AddCommand(CircuitBreakerCmd)
+}
+
+var CircuitBreakerCmd = &cobra.Command{
+	Use:   "circuit-breaker",
+	Short: "manage the circuit breaker of the running bbgo process",
+}
+
+var CircuitBreakerResetCmd = &cobra.Command{
+	Use:   "reset",
+	Short: "reset the tripped circuit breaker to re-enable the trading",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pid, err := cmd.Flags().GetInt("pid")
+		if err != nil {
+			return err
+		}
+
+		if pid <= 0 {
+			return fmt.Errorf("--pid is required")
+		}
+
+		if err := sendCircuitBreakerReset(pid); err != nil {
+			return err
+		}
+
+		log.Infof("circuit breaker reset signal sent to process %d", pid)
+		return nil
+	},
+}
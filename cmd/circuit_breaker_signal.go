@@ -0,0 +1,19 @@
+//go:build !windows
+// +build !windows
+
+package cmd
+
+import (
+	"os"
+
+	"github.com/c9s/bbgo/bbgo"
+)
+
+func sendCircuitBreakerReset(pid int) error {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+
+	return process.Signal(bbgo.CircuitBreakerResetSignal)
+}
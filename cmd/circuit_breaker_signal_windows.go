@@ -0,0 +1,7 @@
+package cmd
+
+import "fmt"
+
+func sendCircuitBreakerReset(pid int) error {
+	return fmt.Errorf("circuit breaker reset by signal is not supported on windows")
+}
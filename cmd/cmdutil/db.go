@@ -0,0 +1,49 @@
+package cmdutil
+
+import (
+	"strings"
+
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// ConnectDB connects to the database by the DSN prefix:
+//
+//	sqlite3://bbgo.sqlite3 or sqlite3://:memory:
+//	mysql://root@tcp(127.0.0.1:3306)/bbgo?parseTime=true
+//	postgres://postgres@127.0.0.1:5432/bbgo?sslmode=disable
+//
+// The DSN without a prefix is a mysql DSN, e.g., root@tcp(127.0.0.1:3306)/bbgo?parseTime=true
+func ConnectDB(dsn string) (*sqlx.DB, error) {
+	driver, dsn := ParseDSN(dsn)
+
+	db, err := sqlx.Connect(driver, dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	// every connection of the in-memory sqlite database is a new database
+	if driver == "sqlite3" && strings.Contains(dsn, ":memory:") {
+		db.SetMaxOpenConns(1)
+	}
+
+	return db, nil
+}
+
+// ParseDSN returns the driver name and the DSN without the prefix
+func ParseDSN(dsn string) (driver string, driverDSN string) {
+	// lib/pq accepts the postgres URL as it is
+	if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+		return "postgres", dsn
+	}
+
+	for _, driver := range []string{"sqlite3", "mysql"} {
+		if strings.HasPrefix(dsn, driver+"://") {
+			return driver, strings.TrimPrefix(dsn, driver+"://")
+		}
+	}
+
+	return "mysql", dsn
+}
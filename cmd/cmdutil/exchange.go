@@ -0,0 +1,34 @@
+package cmdutil
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/c9s/bbgo/exchange/binance"
+	"github.com/c9s/bbgo/exchange/max"
+	"github.com/c9s/bbgo/types"
+)
+
+// NewExchange creates the exchange of the given name with the API key and secret from the environment variables,
+// e.g., BINANCE_API_KEY and BINANCE_API_SECRET
+func NewExchange(name string) (types.Exchange, error) {
+	return NewExchangeWithEnvVarPrefix(name, name)
+}
+
+// NewExchangeWithEnvVarPrefix creates the exchange with the API key and secret from the environment variables of the prefix,
+// e.g., the prefix BINANCE2 reads BINANCE2_API_KEY and BINANCE2_API_SECRET
+func NewExchangeWithEnvVarPrefix(name, prefix string) (types.Exchange, error) {
+	key := os.Getenv(strings.ToUpper(prefix) + "_API_KEY")
+	secret := os.Getenv(strings.ToUpper(prefix) + "_API_SECRET")
+
+	switch strings.ToLower(name) {
+	case "binance":
+		return binance.New(key, secret), nil
+
+	case "max":
+		return max.New(key, secret), nil
+	}
+
+	return nil, fmt.Errorf("unsupported exchange: %s", name)
+}
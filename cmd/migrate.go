@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/c9s/bbgo/migrations"
+)
+
+func init() {
+	MigrateCmd.AddCommand(MigrateUpCmd)
+	MigrateCmd.AddCommand(MigrateDownCmd)
+	MigrateCmd.AddCommand(MigrateStatusCmd)
+	RootCmd.AddCommand(MigrateCmd)
+}
+
+var MigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "apply the embedded database migrations",
+}
+
+var MigrateUpCmd = &cobra.Command{
+	Use:   "up",
+	Short: "apply all the pending migrations",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		db, err := connectDB()
+		if err != nil {
+			return err
+		}
+
+		defer db.Close()
+
+		applied, err := migrations.NewMigrator(db).Up()
+		if err != nil {
+			return err
+		}
+
+		log.Infof("%d migrations applied", len(applied))
+		return nil
+	},
+}
+
+var MigrateDownCmd = &cobra.Command{
+	Use:   "down",
+	Short: "roll back the latest applied migration",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		db, err := connectDB()
+		if err != nil {
+			return err
+		}
+
+		defer db.Close()
+
+		migration, err := migrations.NewMigrator(db).Down()
+		if err != nil {
+			return err
+		}
+
+		if migration == nil {
+			log.Info("no migration is applied")
+			return nil
+		}
+
+		log.Infof("migration %d_%s rolled back", migration.Version, migration.Name)
+		return nil
+	},
+}
+
+var MigrateStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "show the applied and the pending migrations",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		db, err := connectDB()
+		if err != nil {
+			return err
+		}
+
+		defer db.Close()
+
+		status, err := migrations.NewMigrator(db).Status()
+		if err != nil {
+			return err
+		}
+
+		for _, s := range status {
+			state := "pending"
+			if s.Applied {
+				state = "applied"
+			}
+
+			fmt.Printf("%-8s %d_%s\n", state, s.Version, s.Name)
+		}
+
+		return nil
+	},
+}
@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/c9s/bbgo/bbgo"
+	"github.com/c9s/bbgo/cmd/cmdutil"
+	"github.com/c9s/bbgo/service"
+)
+
+func init() {
+	ReconcileCmd.Flags().String("exchange", "binance", "the exchange of the trades")
+	ReconcileCmd.Flags().String("symbol", "", "the symbol of the trades")
+	ReconcileCmd.Flags().Duration("since", 7*24*time.Hour, "reconcile the trades since the duration ago")
+	ReconcileCmd.Flags().Bool("repair", false, "insert the missing trades and update the mismatched trades, then rebuild the stocks and the profits")
+	ReconcileCmd.Flags().Bool("delete-extra", false, "delete the extra trades that are not returned by the exchange when repairing")
+	ReconcileCmd.Flags().String("cost-basis", bbgo.CostBasisLowerPriceFirst, "the cost basis method of the rebuilt stocks: lower-price-first, fifo, lifo, hifo or average")
+	RootCmd.AddCommand(ReconcileCmd)
+}
+
+// ReconcileCmd reports the differences between the stored trades and the exchange trades
+var ReconcileCmd = &cobra.Command{
+	Use:   "reconcile",
+	Short: "reconcile the stored trades with the exchange trades",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		symbol, err := cmd.Flags().GetString("symbol")
+		if err != nil {
+			return err
+		}
+
+		if len(symbol) == 0 {
+			return fmt.Errorf("--symbol is required")
+		}
+
+		exchangeName, err := cmd.Flags().GetString("exchange")
+		if err != nil {
+			return err
+		}
+
+		since, err := cmd.Flags().GetDuration("since")
+		if err != nil {
+			return err
+		}
+
+		repair, err := cmd.Flags().GetBool("repair")
+		if err != nil {
+			return err
+		}
+
+		deleteExtra, err := cmd.Flags().GetBool("delete-extra")
+		if err != nil {
+			return err
+		}
+
+		costBasisName, err := cmd.Flags().GetString("cost-basis")
+		if err != nil {
+			return err
+		}
+
+		costBasis, err := bbgo.NewCostBasisMethod(costBasisName)
+		if err != nil {
+			return err
+		}
+
+		exchange, err := cmdutil.NewExchange(exchangeName)
+		if err != nil {
+			return err
+		}
+
+		db, err := connectDB()
+		if err != nil {
+			return err
+		}
+
+		defer db.Close()
+
+		reconciler := &service.TradeReconciler{
+			Service:       service.NewTradeService(db),
+			StockService:  service.NewStockService(db),
+			ProfitService: service.NewProfitService(db),
+		}
+
+		ctx := context.Background()
+		now := time.Now()
+		report, err := reconciler.Reconcile(ctx, exchange, symbol, now.Add(-since), now)
+		if err != nil {
+			return err
+		}
+
+		report.Print()
+
+		if !repair || report.OK() {
+			return nil
+		}
+
+		if err := reconciler.Repair(report, deleteExtra); err != nil {
+			return err
+		}
+
+		stockManager, err := rebuildStocks(ctx, db, exchange, symbol, costBasis)
+		if err != nil {
+			return err
+		}
+
+		log.Infof("%s stocks and profits rebuilt: %d lots, quantity %f", symbol, len(stockManager.Stocks), stockManager.Stocks.Quantity())
+		return nil
+	},
+}
@@ -6,12 +6,15 @@ import (
 	"strings"
 	"time"
 
+	"github.com/jmoiron/sqlx"
 	rotatelogs "github.com/lestrrat-go/file-rotatelogs"
 	"github.com/rifflock/lfshook"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	prefixed "github.com/x-cray/logrus-prefixed-formatter"
+
+	"github.com/c9s/bbgo/cmd/cmdutil"
 )
 
 var RootCmd = &cobra.Command{
@@ -36,6 +39,19 @@ func init() {
 	RootCmd.PersistentFlags().String("slack-token", "", "slack token")
 	RootCmd.PersistentFlags().String("slack-trading-channel", "dev-bbgo", "slack trading channel")
 	RootCmd.PersistentFlags().String("slack-error-channel", "bbgo-error", "slack error channel")
+
+	RootCmd.PersistentFlags().String("mysql-url", "root@tcp(127.0.0.1:3306)/bbgo?parseTime=true", "mysql data source name, used when --db-url is not set")
+	RootCmd.PersistentFlags().String("db-url", "", "database data source name, the prefix selects the database, e.g., sqlite3://bbgo.sqlite3, postgres://postgres@127.0.0.1:5432/bbgo or mysql://root@tcp(127.0.0.1:3306)/bbgo?parseTime=true")
+}
+
+// connectDB connects to the database of --db-url, or the mysql database of --mysql-url
+func connectDB() (*sqlx.DB, error) {
+	dsn := viper.GetString("db-url")
+	if len(dsn) == 0 {
+		dsn = viper.GetString("mysql-url")
+	}
+
+	return cmdutil.ConnectDB(dsn)
 }
 
 func Run() {
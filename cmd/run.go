@@ -0,0 +1,237 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"syscall"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/c9s/bbgo/bbgo"
+	"github.com/c9s/bbgo/cmd/cmdutil"
+	"github.com/c9s/bbgo/exchange/paper"
+	"github.com/c9s/bbgo/notifier/slacknotifier"
+)
+
+func init() {
+	RunCmd.Flags().String("config", "", "the strategy config file, the bbgo.yaml loaded by the root command is used if it's empty")
+	RunCmd.Flags().String("risk-controls", "", "load the riskControls section from this file instead of the strategy config file")
+	RunCmd.Flags().Duration("since", 7*24*time.Hour, "sync the trades since the duration ago when nothing is synced")
+	RootCmd.AddCommand(RunCmd)
+}
+
+// RunCmd runs the registered strategies of the config file, the strategies are registered by bbgo.RegisterStrategy
+// in the init functions of the strategy packages imported by the main package.
+var RunCmd = &cobra.Command{
+	Use:   "run",
+	Short: "run the strategies of the config file",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configFile, err := cmd.Flags().GetString("config")
+		if err != nil {
+			return err
+		}
+
+		if len(configFile) == 0 {
+			configFile = viper.ConfigFileUsed()
+		}
+
+		since, err := cmd.Flags().GetDuration("since")
+		if err != nil {
+			return err
+		}
+
+		config, err := bbgo.LoadConfig(configFile)
+		if err != nil {
+			return err
+		}
+
+		riskControlsFile, err := cmd.Flags().GetString("risk-controls")
+		if err != nil {
+			return err
+		}
+
+		if len(riskControlsFile) > 0 {
+			config.RiskControls, err = bbgo.LoadRiskControlConfig(riskControlsFile)
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(config.Strategies) == 0 && len(config.CrossExchangeStrategies) == 0 {
+			return fmt.Errorf("no strategy is defined in %s, the registered strategies are %v", configFile, bbgo.RegisteredStrategies())
+		}
+
+		costBasis, err := bbgo.NewCostBasisMethod(config.CostBasis)
+		if err != nil {
+			return err
+		}
+
+		sessions, err := newExchangeSessions(config)
+		if err != nil {
+			return err
+		}
+
+		// the database is only required by the real trading sessions
+		var db *sqlx.DB
+		for _, session := range sessions {
+			if !session.PaperTrade {
+				db, err = connectDB()
+				if err != nil {
+					return err
+				}
+
+				defer db.Close()
+				break
+			}
+		}
+
+		var notifiers []bbgo.Notifier
+		if token := viper.GetString("slack-token"); len(token) > 0 {
+			notifiers = append(notifiers, slacknotifier.New(token, viper.GetString("slack-trading-channel")))
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		startTime := time.Now().Add(-since)
+
+		var doneChannels []chan struct{}
+		for _, mount := range config.Strategies {
+			session := sessions[mount.Session]
+			session.Symbols(mount.Symbol)
+
+			trader := bbgo.New(db, session.Exchange, mount.Symbol)
+			trader.ExchangeSessions = map[string]*bbgo.ExchangeSession{session.Name: session}
+			trader.CostBasis = costBasis
+			trader.ReportingCurrency = config.ReportingCurrency
+			trader.SymbolFeeRates = session.SymbolFeeRates
+			trader.RiskControls = config.RiskControls.For(mount.Session, mount.ID)
+			trader.Notifiers = notifiers
+			trader.Exit = mount.Exit
+
+			for _, s := range session.Subscriptions {
+				if s.Symbol == mount.Symbol {
+					trader.Subscriptions = append(trader.Subscriptions, s)
+				}
+			}
+
+			if config.CircuitBreaker != nil {
+				trader.SetCircuitBreaker(&bbgo.CircuitBreaker{
+					MaxLoss:          config.CircuitBreaker.MaxLoss,
+					Window:           config.CircuitBreaker.Window,
+					CancelOpenOrders: config.CircuitBreaker.CancelOpenOrders,
+				})
+			}
+
+			if err := trader.Sync(ctx, startTime); err != nil {
+				return err
+			}
+
+			if err := trader.Initialize(ctx, startTime); err != nil {
+				return err
+			}
+
+			log.Infof("running strategy %s on %s %s", mount.ID, mount.Session, mount.Symbol)
+
+			done, err := trader.RunStrategy(ctx, mount.Strategy)
+			if err != nil {
+				return err
+			}
+
+			doneChannels = append(doneChannels, done)
+
+			if config.Reports != nil {
+				if _, err := trader.ScheduleReports(ctx, *config.Reports); err != nil {
+					return err
+				}
+			}
+		}
+
+		// the cross exchange strategies share the sessions, so only the session risk controls and the circuit breaker of the
+		// cross exchange trader are applied to their orders
+		if len(config.CrossExchangeStrategies) > 0 {
+			trader := bbgo.New(db, nil, "")
+			trader.ExchangeSessions = sessions
+			trader.CostBasis = costBasis
+			trader.ReportingCurrency = config.ReportingCurrency
+			trader.Notifiers = notifiers
+
+			// the open orders of the loaded symbols of all the sessions are canceled when the circuit breaker is tripped
+			if config.CircuitBreaker != nil {
+				trader.SetCircuitBreaker(&bbgo.CircuitBreaker{
+					MaxLoss:          config.CircuitBreaker.MaxLoss,
+					Window:           config.CircuitBreaker.Window,
+					CancelOpenOrders: config.CircuitBreaker.CancelOpenOrders,
+				})
+			}
+
+			var strategies []bbgo.CrossExchangeStrategy
+			for _, mount := range config.CrossExchangeStrategies {
+				log.Infof("running cross exchange strategy %s", mount.ID)
+				strategies = append(strategies, mount.Strategy)
+			}
+
+			for name, session := range sessions {
+				session.OrderExecutor = &bbgo.ExchangeOrderExecutor{
+					Session:      session,
+					Trader:       trader,
+					RiskControls: config.RiskControls.For(name, ""),
+					Notifiers:    notifiers,
+				}
+			}
+
+			done, err := trader.RunCrossExchangeStrategies(ctx, strategies...)
+			if err != nil {
+				return err
+			}
+
+			doneChannels = append(doneChannels, done)
+		}
+
+		cmdutil.WaitForSignal(ctx, syscall.SIGINT, syscall.SIGTERM)
+
+		// wait for the strategies to clean up, e.g., cancel the open orders
+		cancel()
+		for _, done := range doneChannels {
+			<-done
+		}
+
+		return nil
+	},
+}
+
+// newExchangeSessions creates the exchange sessions of the config, the paper trading session streams the market data
+// from the real exchange and fills the orders with the paper balances.
+func newExchangeSessions(config *bbgo.Config) (map[string]*bbgo.ExchangeSession, error) {
+	var sessions = make(map[string]*bbgo.ExchangeSession, len(config.Sessions))
+	for name, sessionConfig := range config.Sessions {
+		prefix := sessionConfig.EnvVarPrefix
+		if len(prefix) == 0 {
+			prefix = sessionConfig.Exchange
+		}
+
+		exchange, err := cmdutil.NewExchangeWithEnvVarPrefix(sessionConfig.Exchange, prefix)
+		if err != nil {
+			return nil, fmt.Errorf("session %s: %s", name, err.Error())
+		}
+
+		if sessionConfig.PaperTrade {
+			exchange = paper.New(exchange, sessionConfig.InitialPaperBalances())
+		}
+
+		session := bbgo.NewExchangeSession(name, exchange)
+		session.SymbolFeeRates = sessionConfig.FeeRates
+		session.Symbols(sessionConfig.Symbols...)
+		for _, s := range sessionConfig.StreamSubscriptions() {
+			session.Subscribe(s.Channel, s.Symbol, s.Options)
+		}
+
+		sessions[name] = session
+	}
+
+	return sessions, nil
+}
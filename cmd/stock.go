@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/c9s/bbgo/accounting"
+	"github.com/c9s/bbgo/bbgo"
+	"github.com/c9s/bbgo/cmd/cmdutil"
+	"github.com/c9s/bbgo/service"
+	"github.com/c9s/bbgo/types"
+)
+
+func init() {
+	RebuildStocksCmd.Flags().String("exchange", "binance", "the exchange of the trades")
+	RebuildStocksCmd.Flags().String("symbol", "", "the symbol of the stocks")
+	RebuildStocksCmd.Flags().String("cost-basis", bbgo.CostBasisLowerPriceFirst, "the cost basis method: lower-price-first, fifo, lifo, hifo or average")
+	RootCmd.AddCommand(RebuildStocksCmd)
+}
+
+// RebuildStocksCmd drops the stock checkpoint and rebuilds the stock lots and the profits from all the trades in the database
+var RebuildStocksCmd = &cobra.Command{
+	Use:   "rebuild-stocks",
+	Short: "rebuild the stock lots, the checkpoint and the profits from all the trades",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		symbol, err := cmd.Flags().GetString("symbol")
+		if err != nil {
+			return err
+		}
+
+		if len(symbol) == 0 {
+			return fmt.Errorf("--symbol is required")
+		}
+
+		exchangeName, err := cmd.Flags().GetString("exchange")
+		if err != nil {
+			return err
+		}
+
+		costBasisName, err := cmd.Flags().GetString("cost-basis")
+		if err != nil {
+			return err
+		}
+
+		costBasis, err := bbgo.NewCostBasisMethod(costBasisName)
+		if err != nil {
+			return err
+		}
+
+		exchange, err := cmdutil.NewExchange(exchangeName)
+		if err != nil {
+			return err
+		}
+
+		db, err := connectDB()
+		if err != nil {
+			return err
+		}
+
+		defer db.Close()
+
+		stockManager, err := rebuildStocks(context.Background(), db, exchange, symbol, costBasis)
+		if err != nil {
+			return err
+		}
+
+		log.Infof("%s stocks rebuilt: %d lots, quantity %f, last trade gid %d",
+			symbol, len(stockManager.Stocks), stockManager.Stocks.Quantity(), stockManager.LastTradeGID)
+
+		return nil
+	},
+}
+
+// rebuildStocks rebuilds the stock lots and the checkpoint from all the trades of the symbol,
+// the profits of the symbol are deleted and recorded again from the replayed sell trades.
+func rebuildStocks(ctx context.Context, db *sqlx.DB, exchange types.Exchange, symbol string, costBasis bbgo.CostBasisMethod) (*bbgo.StockManager, error) {
+	market, ok := types.FindMarket(symbol)
+	if !ok {
+		return nil, fmt.Errorf("%s market not found", symbol)
+	}
+
+	stockManager := &bbgo.StockManager{
+		Symbol:             symbol,
+		TradingFeeCurrency: exchange.PlatformFeeCurrency(),
+		CostBasis:          costBasis,
+	}
+
+	stockLoader := &bbgo.StockLoader{
+		Exchange:     exchange.Name(),
+		TradeService: service.NewTradeService(db),
+		StockService: service.NewStockService(db),
+	}
+
+	trades, err := stockLoader.Rebuild(stockManager)
+	if err != nil {
+		return nil, err
+	}
+
+	profitService := service.NewProfitService(db)
+	if err := profitService.Delete(exchange.Name(), symbol); err != nil {
+		return nil, err
+	}
+
+	prices := accounting.NewPriceMap()
+	bbgo.QueryConversionPrices(ctx, exchange, prices, market.QuoteCurrency, exchange.PlatformFeeCurrency())
+
+	ledger := &bbgo.ProfitLedger{
+		Exchange:      exchange.Name(),
+		Market:        market,
+		StockManager:  stockManager,
+		PriceSource:   prices,
+		ProfitService: profitService,
+	}
+
+	for _, trade := range trades {
+		if _, err := ledger.AddTrade(trade); err != nil {
+			return nil, err
+		}
+	}
+
+	return stockManager, nil
+}
@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/c9s/bbgo/cmd/cmdutil"
+	"github.com/c9s/bbgo/service"
+)
+
+func init() {
+	SyncCmd.Flags().String("exchange", "binance", "the exchange of the trades")
+	SyncCmd.Flags().String("symbol", "", "the symbol of the trades")
+	SyncCmd.Flags().Duration("since", 7*24*time.Hour, "sync the trades since the duration ago when nothing is synced, it's also the range of --verify")
+	SyncCmd.Flags().Bool("verify", false, "verify the stored trades day by day against the exchange and backfill the missing trades")
+	RootCmd.AddCommand(SyncCmd)
+}
+
+// SyncCmd syncs the trades of the symbol from the exchange into the database
+var SyncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "sync the trades from the exchange",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		symbol, err := cmd.Flags().GetString("symbol")
+		if err != nil {
+			return err
+		}
+
+		if len(symbol) == 0 {
+			return fmt.Errorf("--symbol is required")
+		}
+
+		exchangeName, err := cmd.Flags().GetString("exchange")
+		if err != nil {
+			return err
+		}
+
+		since, err := cmd.Flags().GetDuration("since")
+		if err != nil {
+			return err
+		}
+
+		verify, err := cmd.Flags().GetBool("verify")
+		if err != nil {
+			return err
+		}
+
+		exchange, err := cmdutil.NewExchange(exchangeName)
+		if err != nil {
+			return err
+		}
+
+		db, err := connectDB()
+		if err != nil {
+			return err
+		}
+
+		defer db.Close()
+
+		ctx := context.Background()
+		startTime := time.Now().Add(-since)
+		tradeSync := &service.TradeSync{Service: service.NewTradeService(db)}
+
+		// the trades stored with the invalid trade time are repaired before the new trades are synced
+		reconciler := &service.TradeReconciler{
+			Service:       tradeSync.Service,
+			StockService:  service.NewStockService(db),
+			ProfitService: service.NewProfitService(db),
+		}
+
+		if _, err := reconciler.RepairTradeTimes(ctx, exchange, symbol); err != nil {
+			return err
+		}
+
+		if err := tradeSync.Sync(ctx, exchange, symbol, startTime); err != nil {
+			return err
+		}
+
+		if !verify {
+			return nil
+		}
+
+		verifications, err := tradeSync.Verify(ctx, exchange, symbol, startTime, time.Now(), true)
+		if err != nil {
+			return err
+		}
+
+		var numMissing, numExtra = 0, 0
+		for _, v := range verifications {
+			if v.OK() {
+				continue
+			}
+
+			numMissing += len(v.Missing)
+			numExtra += len(v.Extra)
+			log.Warnf("%s %s trades: %d stored, %d on the exchange, %d missing, %d not on the exchange",
+				v.Day.Format("2006-01-02"), symbol, v.Stored, v.Remote, len(v.Missing), len(v.Extra))
+
+			for _, trade := range v.Extra {
+				log.Warnf(" - trade %d at %s is not on the exchange", trade.ID, trade.Time)
+			}
+		}
+
+		log.Infof("verified %d days of %s trades: %d missing trades backfilled, %d trades not on the exchange", len(verifications), symbol, numMissing, numExtra)
+		return nil
+	},
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"context"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/c9s/bbgo/cmd/cmdutil"
+	"github.com/c9s/bbgo/service"
+)
+
+func init() {
+	SyncTransfersCmd.Flags().String("exchange", "binance", "the exchange of the deposits and the withdraws")
+	SyncTransfersCmd.Flags().Duration("since", 90*24*time.Hour, "sync the transfers since the duration ago when nothing is synced")
+	RootCmd.AddCommand(SyncTransfersCmd)
+}
+
+// SyncTransfersCmd syncs the deposit and the withdraw history of the exchange into the database
+var SyncTransfersCmd = &cobra.Command{
+	Use:   "sync-transfers",
+	Short: "sync the deposits and the withdraws from the exchange",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		exchangeName, err := cmd.Flags().GetString("exchange")
+		if err != nil {
+			return err
+		}
+
+		since, err := cmd.Flags().GetDuration("since")
+		if err != nil {
+			return err
+		}
+
+		exchange, err := cmdutil.NewExchange(exchangeName)
+		if err != nil {
+			return err
+		}
+
+		db, err := connectDB()
+		if err != nil {
+			return err
+		}
+
+		defer db.Close()
+
+		transferSync := &service.TransferSync{
+			DepositService:  service.NewDepositService(db),
+			WithdrawService: service.NewWithdrawService(db),
+		}
+
+		return transferSync.Sync(context.Background(), exchangeName, exchange, time.Now().Add(-since))
+	},
+}
@@ -14,10 +14,16 @@ import (
 	"github.com/c9s/bbgo/util"
 )
 
+// ExchangeName is the exchange name of the trades and the transfers
+const ExchangeName = "binance"
+
 var log = logrus.WithFields(logrus.Fields{
-	"exchange": "binance",
+	"exchange": ExchangeName,
 })
 
+// BNBFeeDiscountRate is the discount of the trading fee when it's paid by BNB
+const BNBFeeDiscountRate = 0.25
+
 func init() {
 	_ = types.Exchange(&Exchange{})
 }
@@ -42,29 +48,50 @@ func (e *Exchange) QueryAveragePrice(ctx context.Context, symbol string) (float6
 	return util.MustParseFloat(resp.Price), nil
 }
 
-func (e *Exchange) NewStream() types.Stream {
-	return NewStream(e.Client)
+func (e *Exchange) QueryTickers(ctx context.Context, symbols ...string) (map[string]types.Ticker, error) {
+	service := e.Client.NewListPriceChangeStatsService()
+	if len(symbols) == 1 {
+		service.Symbol(symbols[0])
+	}
+
+	stats, err := service.Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var wanted = make(map[string]struct{})
+	for _, symbol := range symbols {
+		wanted[symbol] = struct{}{}
+	}
+
+	var tickers = make(map[string]types.Ticker)
+	for _, stat := range stats {
+		if _, ok := wanted[stat.Symbol]; len(wanted) > 0 && !ok {
+			continue
+		}
+
+		tickers[stat.Symbol] = types.Ticker{
+			Symbol: stat.Symbol,
+			Time:   time.Unix(0, stat.CloseTime*int64(time.Millisecond)),
+			Buy:    util.MustParseFloat(stat.BidPrice),
+			Sell:   util.MustParseFloat(stat.AskPrice),
+			Last:   util.MustParseFloat(stat.LastPrice),
+		}
+	}
+
+	return tickers, nil
 }
 
-type Withdraw struct {
-	ID         string  `json:"id"`
-	Asset      string  `json:"asset"`
-	Amount     float64 `json:"amount"`
-	Address    string  `json:"address"`
-	AddressTag string  `json:"addressTag"`
-	Status     string  `json:"status"`
-
-	TransactionID   string    `json:"txId"`
-	TransactionFee  float64   `json:"transactionFee"`
-	WithdrawOrderID string    `json:"withdrawOrderId"`
-	ApplyTime       time.Time `json:"applyTime"`
-	Network         string    `json:"network"`
+func (e *Exchange) NewStream() types.Stream {
+	return NewStream(e.Client)
 }
 
-func (e *Exchange) QueryWithdrawHistory(ctx context.Context, asset string, since, until time.Time) (allWithdraws []Withdraw, err error) {
+func (e *Exchange) QueryWithdrawHistory(ctx context.Context, asset string, since, until time.Time) (allWithdraws []types.Withdraw, err error) {
 
 	startTime := since
-	txIDs := map[string]struct{}{}
+
+	// the pending withdraws have no transaction id yet, so the withdraws are deduplicated by the withdraw id
+	withdrawIDs := map[string]struct{}{}
 
 	for startTime.Before(until) {
 		// startTime ~ endTime must be in 90 days
@@ -73,42 +100,49 @@ func (e *Exchange) QueryWithdrawHistory(ctx context.Context, asset string, since
 			endTime = until
 		}
 
-		withdraws, err := e.Client.NewListWithdrawsService().
-			Asset(asset).
+		req := e.Client.NewListWithdrawsService().
 			StartTime(startTime.UnixNano() / int64(time.Millisecond)).
-			EndTime(endTime.UnixNano() / int64(time.Millisecond)).
-			Do(ctx)
+			EndTime(endTime.UnixNano() / int64(time.Millisecond))
+
+		// empty asset queries all the assets
+		if len(asset) > 0 {
+			req.Asset(asset)
+		}
+
+		withdraws, err := req.Do(ctx)
 
 		if err != nil {
 			return nil, err
 		}
 
 		for _, d := range withdraws {
-			if _, ok := txIDs[d.TxID]; ok {
+			if _, ok := withdrawIDs[d.ID]; ok {
 				continue
 			}
 
-			// 0(0:pending,6: credited but cannot withdraw, 1:success)
-			status := ""
+			// 0:email sent, 1:cancelled, 2:awaiting approval, 3:rejected, 4:processing, 5:failure, 6:completed
+			var status types.WithdrawStatus
 			switch d.Status {
 			case 0:
-				status = "email_sent"
+				status = types.WithdrawEmailSent
 			case 1:
-				status = "cancelled"
+				status = types.WithdrawCancelled
 			case 2:
-				status = "awaiting_approval"
+				status = types.WithdrawAwaitingApproval
 			case 3:
-				status = "rejected"
+				status = types.WithdrawRejected
 			case 4:
-				status = "processing"
+				status = types.WithdrawProcessing
 			case 5:
-				status = "failure"
+				status = types.WithdrawFailure
 			case 6:
-				status = "completed"
+				status = types.WithdrawCompleted
 			}
 
-			txIDs[d.TxID] = struct{}{}
-			allWithdraws = append(allWithdraws, Withdraw{
+			withdrawIDs[d.ID] = struct{}{}
+			allWithdraws = append(allWithdraws, types.Withdraw{
+				Exchange:        ExchangeName,
+				ID:              d.ID,
 				ApplyTime:       time.Unix(0, d.ApplyTime*int64(time.Millisecond)),
 				Asset:           d.Asset,
 				Amount:          d.Amount,
@@ -128,17 +162,7 @@ func (e *Exchange) QueryWithdrawHistory(ctx context.Context, asset string, since
 	return allWithdraws, nil
 }
 
-type Deposit struct {
-	Time          time.Time `json:"time"`
-	Amount        float64   `json:"amount"`
-	Asset         string    `json:"asset"`
-	Address       string    `json:"address"`
-	AddressTag    string    `json:"addressTag"`
-	TransactionID string    `json:"txId"`
-	Status        string    `json:"status"`
-}
-
-func (e *Exchange) QueryDepositHistory(ctx context.Context, asset string, since, until time.Time) (allDeposits []Deposit, err error) {
+func (e *Exchange) QueryDepositHistory(ctx context.Context, asset string, since, until time.Time) (allDeposits []types.Deposit, err error) {
 	startTime := since
 	txIDs := map[string]struct{}{}
 	for startTime.Before(until) {
@@ -149,11 +173,16 @@ func (e *Exchange) QueryDepositHistory(ctx context.Context, asset string, since,
 			endTime = until
 		}
 
-		deposits, err := e.Client.NewListDepositsService().
-			Asset(asset).
+		req := e.Client.NewListDepositsService().
 			StartTime(startTime.UnixNano() / int64(time.Millisecond)).
-			EndTime(endTime.UnixNano() / int64(time.Millisecond)).
-			Do(ctx)
+			EndTime(endTime.UnixNano() / int64(time.Millisecond))
+
+		// empty asset queries all the assets
+		if len(asset) > 0 {
+			req.Asset(asset)
+		}
+
+		deposits, err := req.Do(ctx)
 
 		if err != nil {
 			return nil, err
@@ -165,18 +194,19 @@ func (e *Exchange) QueryDepositHistory(ctx context.Context, asset string, since,
 			}
 
 			// 0(0:pending,6: credited but cannot withdraw, 1:success)
-			status := ""
+			var status types.DepositStatus
 			switch d.Status {
 			case 0:
-				status = "pending"
+				status = types.DepositPending
 			case 6:
-				status = "credited"
+				status = types.DepositCredited
 			case 1:
-				status = "success"
+				status = types.DepositSuccess
 			}
 
 			txIDs[d.TxID] = struct{}{}
-			allDeposits = append(allDeposits, Deposit{
+			allDeposits = append(allDeposits, types.Deposit{
+				Exchange:      ExchangeName,
 				Time:          time.Unix(0, d.InsertTime*int64(time.Millisecond)),
 				Asset:         d.Asset,
 				Amount:        d.Amount,
@@ -202,7 +232,12 @@ func (e *Exchange) QueryAccountBalances(ctx context.Context) (types.BalanceMap,
 	return account.Balances, nil
 }
 
-// PlatformFeeCurrency
+// Name returns the exchange name of the trades, the orders and the transfers
+func (e *Exchange) Name() string {
+	return ExchangeName
+}
+
+// PlatformFeeCurrency returns the currency that pays the trading fee with the discount
 func (e *Exchange) PlatformFeeCurrency() string {
 	return "BNB"
 }
@@ -229,6 +264,24 @@ func (e *Exchange) QueryAccount(ctx context.Context) (*types.Account, error) {
 	}, nil
 }
 
+// QueryFeeSchedule returns the commission rates of the account, the BNB discount is applied
+// when there is available BNB balance since the fee is deducted from BNB first.
+func (e *Exchange) QueryFeeSchedule(ctx context.Context) (*types.FeeSchedule, error) {
+	account, err := e.QueryAccount(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	schedule := account.FeeSchedule()
+	schedule.DiscountCurrency = "BNB"
+	schedule.DiscountRate = BNBFeeDiscountRate
+	if balance, ok := account.Balances["BNB"]; ok && balance.Available > 0 {
+		schedule.DiscountEnabled = true
+	}
+
+	return schedule, nil
+}
+
 func (e *Exchange) SubmitOrder(ctx context.Context, order *types.SubmitOrder) error {
 	/*
 		limit order example
@@ -260,12 +313,126 @@ func (e *Exchange) SubmitOrder(ctx context.Context, order *types.SubmitOrder) er
 	if len(order.TimeInForce) > 0 {
 		req.TimeInForce(order.TimeInForce)
 	}
+	if len(order.ClientOrderID) > 0 {
+		req.NewClientOrderID(order.ClientOrderID)
+	}
 
 	retOrder, err := req.Do(ctx)
 	log.Infof("order created: %+v", retOrder)
 	return err
 }
 
+func (e *Exchange) QueryOpenOrders(ctx context.Context, symbol string) (orders []types.Order, err error) {
+	remoteOrders, err := e.Client.NewListOpenOrdersService().Symbol(symbol).Do(ctx)
+	if err != nil {
+		return orders, err
+	}
+
+	for _, o := range remoteOrders {
+		order, err := convertRemoteOrder(o)
+		if err != nil {
+			return orders, err
+		}
+
+		orders = append(orders, *order)
+	}
+
+	return orders, err
+}
+
+// QueryOrderHistory queries the orders (open and closed) of the symbol from the allOrders endpoint,
+// the orders with the ID larger than lastOrderID are returned, or the orders created since the given time if lastOrderID is zero.
+func (e *Exchange) QueryOrderHistory(ctx context.Context, symbol string, since time.Time, lastOrderID uint64) (orders []types.Order, err error) {
+	for {
+		req := e.Client.NewListOrdersService().Symbol(symbol).Limit(500)
+		if lastOrderID > 0 {
+			req.OrderID(int64(lastOrderID + 1))
+		} else {
+			req.StartTime(since.UnixNano() / int64(time.Millisecond))
+		}
+
+		remoteOrders, err := req.Do(ctx)
+		if err != nil {
+			return orders, err
+		}
+
+		var numNewOrders = 0
+		for _, o := range remoteOrders {
+			if uint64(o.OrderID) <= lastOrderID {
+				continue
+			}
+
+			order, err := convertRemoteOrder(o)
+			if err != nil {
+				return orders, err
+			}
+
+			orders = append(orders, *order)
+			lastOrderID = order.OrderID
+			numNewOrders++
+		}
+
+		if numNewOrders == 0 {
+			break
+		}
+	}
+
+	return orders, nil
+}
+
+func (e *Exchange) CancelOrders(ctx context.Context, orders ...types.Order) error {
+	for _, o := range orders {
+		_, err := e.Client.NewCancelOrderService().
+			Symbol(o.Symbol).
+			OrderID(int64(o.OrderID)).
+			Do(ctx)
+		if err != nil {
+			return err
+		}
+
+		log.Infof("order canceled: %s %d", o.Symbol, o.OrderID)
+	}
+
+	return nil
+}
+
+func convertRemoteOrder(o *binance.Order) (*types.Order, error) {
+	price, err := util.ParseFloat(o.Price)
+	if err != nil {
+		return nil, err
+	}
+
+	quantity, err := util.ParseFloat(o.OrigQuantity)
+	if err != nil {
+		return nil, err
+	}
+
+	executedQuantity, err := util.ParseFloat(o.ExecutedQuantity)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.Order{
+		SubmitOrder: types.SubmitOrder{
+			Symbol:         o.Symbol,
+			Side:           types.SideType(o.Side),
+			Type:           types.OrderType(o.Type),
+			Quantity:       quantity,
+			Price:          price,
+			PriceString:    o.Price,
+			QuantityString: o.OrigQuantity,
+			TimeInForce:    o.TimeInForce,
+			ClientOrderID:  o.ClientOrderID,
+		},
+		OrderID:          uint64(o.OrderID),
+		Exchange:         ExchangeName,
+		Status:           types.OrderStatus(o.Status),
+		ExecutedQuantity: executedQuantity,
+		CreationTime:     time.Unix(0, o.Time*int64(time.Millisecond)),
+		UpdateTime:       time.Unix(0, o.UpdateTime*int64(time.Millisecond)),
+	}, nil
+}
+
 func toLocalOrderType(orderType types.OrderType) (binance.OrderType, error) {
 	switch orderType {
 	case types.OrderTypeLimit:
@@ -383,7 +550,7 @@ func (e *Exchange) BatchQueryTrades(ctx context.Context, symbol string, options
 			return allTrades, err
 		}
 
-		if len(trades) == 1 && trades[0].ID == lastTradeID {
+		if len(trades) == 0 || (len(trades) == 1 && trades[0].ID == lastTradeID) {
 			break
 		}
 
@@ -393,6 +560,10 @@ func (e *Exchange) BatchQueryTrades(ctx context.Context, symbol string, options
 				continue
 			}
 
+			if options.EndTime != nil && t.Time.After(*options.EndTime) {
+				return allTrades, nil
+			}
+
 			allTrades = append(allTrades, t)
 			lastTradeID = t.ID
 		}
@@ -435,13 +606,15 @@ func convertRemoteTrade(t binance.TradeV3) (*types.Trade, error) {
 
 	return &types.Trade{
 		ID:            t.ID,
+		OrderID:       uint64(t.OrderID),
 		Price:         price,
 		Symbol:        t.Symbol,
-		Exchange:      "binance",
+		Exchange:      ExchangeName,
 		Quantity:      quantity,
 		Side:          side,
 		IsBuyer:       t.IsBuyer,
 		IsMaker:       t.IsMaker,
+		IsMargin:      false, // spot account trades
 		Fee:           fee,
 		FeeCurrency:   t.CommissionAsset,
 		QuoteQuantity: quoteQuantity,
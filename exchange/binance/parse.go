@@ -62,13 +62,24 @@ type ExecutionReportEvent struct {
 	OrderType     string `json:"o"`
 	TimeInForce   string `json:"f"`
 
-	OrderQuantity string `json:"q"`
-	OrderPrice    string `json:"p"`
-	StopPrice     string `json:"P"`
+	// OriginalClientOrderID is the client order id of the canceled order, the ClientOrderID of the cancel report
+	// is the id of the cancel request
+	OriginalClientOrderID string `json:"C"`
+
+	OrderQuantity      string `json:"q"`
+	OrderPrice         string `json:"p"`
+	StopPrice          string `json:"P"`
+	IcebergQuantity    string `json:"F"`
+	QuoteOrderQuantity string `json:"Q"`
 
 	IsOnBook bool `json:"w"`
 	IsMaker  bool `json:"m"`
 
+	// the ignored fields are declared, otherwise they are decoded into the fields of the lower case keys,
+	// since encoding/json matches the keys case-insensitively
+	IgnoreI int64 `json:"I"`
+	IgnoreM bool  `json:"M"`
+
 	CommissionAmount string `json:"n"`
 	CommissionAsset  string `json:"N"`
 
@@ -80,22 +91,68 @@ type ExecutionReportEvent struct {
 	TradeID         int64 `json:"t"`
 	TransactionTime int64 `json:"T"`
 
-	LastExecutedQuantity             string `json:"l"`
-	CumulativeFilledQuantity         string `json:"z"`
-	LastExecutedPrice                string `json:"L"`
-	LastQuoteAssetTransactedQuantity string `json:"Y"`
+	LastExecutedQuantity                   string `json:"l"`
+	CumulativeFilledQuantity               string `json:"z"`
+	LastExecutedPrice                      string `json:"L"`
+	LastQuoteAssetTransactedQuantity       string `json:"Y"`
+	CumulativeQuoteAssetTransactedQuantity string `json:"Z"`
 
 	OrderCreationTime int `json:"O"`
 }
 
+// Order converts the execution report to the order with the current status
+func (e *ExecutionReportEvent) Order() (*types.Order, error) {
+	price, err := util.ParseFloat(e.OrderPrice)
+	if err != nil {
+		return nil, err
+	}
+
+	quantity, err := util.ParseFloat(e.OrderQuantity)
+	if err != nil {
+		return nil, err
+	}
+
+	executedQuantity, err := util.ParseFloat(e.CumulativeFilledQuantity)
+	if err != nil {
+		return nil, err
+	}
+
+	clientOrderID := e.ClientOrderID
+	if len(e.OriginalClientOrderID) > 0 {
+		clientOrderID = e.OriginalClientOrderID
+	}
+
+	return &types.Order{
+		SubmitOrder: types.SubmitOrder{
+			Symbol:         e.Symbol,
+			Side:           types.SideType(e.Side),
+			Type:           types.OrderType(e.OrderType),
+			Quantity:       quantity,
+			Price:          price,
+			PriceString:    e.OrderPrice,
+			QuantityString: e.OrderQuantity,
+			TimeInForce:    binance.TimeInForceType(e.TimeInForce),
+			ClientOrderID:  clientOrderID,
+		},
+		OrderID:          uint64(e.OrderID),
+		Exchange:         ExchangeName,
+		Status:           types.OrderStatus(e.CurrentOrderStatus),
+		ExecutedQuantity: executedQuantity,
+		CreationTime:     time.Unix(0, int64(e.OrderCreationTime)*int64(time.Millisecond)),
+		UpdateTime:       time.Unix(0, e.TransactionTime*int64(time.Millisecond)),
+	}, nil
+}
+
 func (e *ExecutionReportEvent) Trade() (*types.Trade, error) {
 	if e.CurrentExecutionType != "TRADE" {
 		return nil, errors.New("execution report is not a trade")
 	}
 
-	tt := time.Unix(0, e.TransactionTime/1000000)
+	tt := time.Unix(0, e.TransactionTime*int64(time.Millisecond))
 	return &types.Trade{
 		ID:            e.TradeID,
+		OrderID:       uint64(e.OrderID),
+		Exchange:      ExchangeName,
 		Symbol:        e.Symbol,
 		Price:         util.MustParseFloat(e.LastExecutedPrice),
 		Quantity:      util.MustParseFloat(e.LastExecutedQuantity),
@@ -103,6 +160,7 @@ func (e *ExecutionReportEvent) Trade() (*types.Trade, error) {
 		Side:          e.Side,
 		IsBuyer:       e.Side == "BUY",
 		IsMaker:       e.IsMaker,
+		IsMargin:      false, // spot account trades
 		Time:          tt,
 		Fee:           util.MustParseFloat(e.CommissionAmount),
 		FeeCurrency:   e.CommissionAsset,
@@ -0,0 +1,116 @@
+package binance
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/types"
+)
+
+const tradeExecutionReport = `{
+  "e": "executionReport",
+  "E": 1603018800123,
+  "s": "BTCUSDT",
+  "c": "bbgo-1",
+  "S": "BUY",
+  "o": "LIMIT",
+  "f": "GTC",
+  "q": "0.10000000",
+  "p": "9000.00000000",
+  "P": "0.00000000",
+  "F": "0.00000000",
+  "g": -1,
+  "C": "",
+  "x": "TRADE",
+  "X": "FILLED",
+  "r": "NONE",
+  "i": 100,
+  "l": "0.10000000",
+  "z": "0.10000000",
+  "L": "9000.00000000",
+  "n": "0.00010000",
+  "N": "BTC",
+  "T": 1603018800100,
+  "t": 200,
+  "I": 8641984,
+  "w": false,
+  "m": false,
+  "M": true,
+  "O": 1603018800000,
+  "Z": "900.00000000",
+  "Y": "900.00000000",
+  "Q": "0.00000000"
+}`
+
+func TestExecutionReportEvent_Trade(t *testing.T) {
+	event, err := ParseEvent(tradeExecutionReport)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	report, ok := event.(*ExecutionReportEvent)
+	if !assert.True(t, ok) {
+		return
+	}
+
+	trade, err := report.Trade()
+	if assert.NoError(t, err) {
+		assert.Equal(t, int64(200), trade.ID)
+		assert.Equal(t, uint64(100), trade.OrderID)
+		assert.Equal(t, 9000.0, trade.Price)
+		assert.Equal(t, 0.1, trade.Quantity)
+		assert.True(t, trade.IsBuyer)
+		assert.False(t, trade.IsMaker)
+		assert.Equal(t, time.Date(2020, time.October, 18, 11, 0, 0, int(100*time.Millisecond), time.UTC), trade.Time.UTC())
+	}
+
+	order, err := report.Order()
+	if assert.NoError(t, err) {
+		assert.Equal(t, uint64(100), order.OrderID)
+		assert.Equal(t, 0.1, order.Quantity)
+		assert.Equal(t, 0.1, order.ExecutedQuantity)
+		assert.Equal(t, "GTC", string(order.TimeInForce))
+	}
+}
+
+func TestExecutionReportEvent_CanceledOrder(t *testing.T) {
+	event, err := ParseEvent(`{
+  "e": "executionReport",
+  "E": 1603018800123,
+  "s": "BTCUSDT",
+  "c": "web_cancel_request",
+  "S": "BUY",
+  "o": "LIMIT",
+  "f": "GTC",
+  "q": "0.10000000",
+  "p": "9000.00000000",
+  "C": "bbgo-1",
+  "x": "CANCELED",
+  "X": "CANCELED",
+  "i": 100,
+  "z": "0.00000000",
+  "T": 1603018800100,
+  "t": -1,
+  "O": 1603018800000
+}`)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	order, err := event.(*ExecutionReportEvent).Order()
+	if assert.NoError(t, err) {
+		assert.Equal(t, "bbgo-1", order.ClientOrderID)
+		assert.Equal(t, types.OrderStatusCanceled, order.Status)
+	}
+
+	// the client order id of the new order report
+	event, err = ParseEvent(tradeExecutionReport)
+	if assert.NoError(t, err) {
+		order, err = event.(*ExecutionReportEvent).Order()
+		if assert.NoError(t, err) {
+			assert.Equal(t, "bbgo-1", order.ClientOrderID)
+		}
+	}
+}
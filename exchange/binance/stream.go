@@ -126,6 +126,13 @@ func NewStream(client *binance.Client) *Stream {
 	})
 
 	stream.OnExecutionReportEvent(func(e *ExecutionReportEvent) {
+		order, err := e.Order()
+		if err != nil {
+			log.WithError(err).Error("order convert error")
+		} else {
+			stream.EmitOrderUpdate(*order)
+		}
+
 		switch e.CurrentExecutionType {
 		case "TRADE":
 			trade, err := e.Trade()
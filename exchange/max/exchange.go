@@ -3,15 +3,35 @@ package max
 import (
 	"context"
 	"fmt"
+	"math"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
+	log "github.com/sirupsen/logrus"
+
 	maxapi "github.com/c9s/bbgo/exchange/max/maxapi"
 	"github.com/c9s/bbgo/types"
 	"github.com/c9s/bbgo/util"
 )
 
+// ExchangeName is the exchange name of the trades, the orders and the transfers
+const ExchangeName = "max"
+
+// DefaultMakerFeeRate and DefaultTakerFeeRate are the fee rates of the base VIP level
+const (
+	DefaultMakerFeeRate = 0.0005
+	DefaultTakerFeeRate = 0.0015
+)
+
+// transferPageLimit is the page size of the deposit and the withdraw history queries
+const transferPageLimit = 100
+
+func init() {
+	_ = types.Exchange(&Exchange{})
+}
+
 type Exchange struct {
 	client      *maxapi.RestClient
 	key, secret string
@@ -38,12 +58,16 @@ func (e *Exchange) SubmitOrder(ctx context.Context, order *types.SubmitOrder) er
 	}
 
 	req := e.client.OrderService.NewCreateOrderRequest().
-		Market(order.Symbol).
+		Market(toLocalSymbol(order.Symbol)).
 		OrderType(string(orderType)).
 		Side(toLocalSideType(order.Side)).
 		Volume(order.QuantityString).
 		Price(order.PriceString)
 
+	if len(order.ClientOrderID) > 0 {
+		req.ClientOrderID(order.ClientOrderID)
+	}
+
 	retOrder, err := req.Do(ctx)
 	if err != nil {
 		return err
@@ -53,7 +77,97 @@ func (e *Exchange) SubmitOrder(ctx context.Context, order *types.SubmitOrder) er
 	return err
 }
 
-// PlatformFeeCurrency
+// QueryOpenOrders queries the active orders of the symbol page by page until a short page is returned,
+// the orders that are shifted to the next page by the new orders are returned once.
+func (e *Exchange) QueryOpenOrders(ctx context.Context, symbol string) (orders []types.Order, err error) {
+	const limit = 100
+
+	var seen = make(map[uint64]struct{})
+	for page := 1; ; page++ {
+		remoteOrders, err := e.client.OrderService.All(toLocalSymbol(symbol), limit, page, maxapi.Active)
+		if err != nil {
+			return orders, err
+		}
+
+		for _, o := range remoteOrders {
+			order, err := convertRemoteOrder(o)
+			if err != nil {
+				return orders, err
+			}
+
+			if _, ok := seen[order.OrderID]; ok {
+				continue
+			}
+
+			seen[order.OrderID] = struct{}{}
+			orders = append(orders, *order)
+		}
+
+		if len(remoteOrders) < limit {
+			break
+		}
+	}
+
+	return orders, nil
+}
+
+// QueryOrderHistory queries the orders (open and closed) of the symbol page by page from the newest order,
+// the orders with the ID larger than lastOrderID are returned, or the orders created since the given time if lastOrderID is zero.
+// The returned orders are sorted by the order ID.
+func (e *Exchange) QueryOrderHistory(ctx context.Context, symbol string, since time.Time, lastOrderID uint64) (orders []types.Order, err error) {
+	const limit = 100
+
+	for page := 1; ; page++ {
+		remoteOrders, err := e.client.OrderService.All(toLocalSymbol(symbol), limit, page, maxapi.All)
+		if err != nil {
+			return orders, err
+		}
+
+		var done = len(remoteOrders) < limit
+		for _, o := range remoteOrders {
+			order, err := convertRemoteOrder(o)
+			if err != nil {
+				return orders, err
+			}
+
+			if order.OrderID <= lastOrderID || (lastOrderID == 0 && order.CreationTime.Before(since)) {
+				done = true
+				break
+			}
+
+			orders = append(orders, *order)
+		}
+
+		if done {
+			break
+		}
+	}
+
+	sort.Slice(orders, func(i, j int) bool {
+		return orders[i].OrderID < orders[j].OrderID
+	})
+
+	return orders, nil
+}
+
+func (e *Exchange) CancelOrders(ctx context.Context, orders ...types.Order) error {
+	for _, o := range orders {
+		if err := e.client.OrderService.NewOrderCancelRequest().ID(o.OrderID).Do(ctx); err != nil {
+			return err
+		}
+
+		logger.Infof("order canceled: %s %d", o.Symbol, o.OrderID)
+	}
+
+	return nil
+}
+
+// Name returns the exchange name of the trades, the orders and the transfers
+func (e *Exchange) Name() string {
+	return ExchangeName
+}
+
+// PlatformFeeCurrency returns the currency that pays the trading fee with the discount
 func (e *Exchange) PlatformFeeCurrency() string {
 	return toGlobalCurrency("MAX")
 }
@@ -73,13 +187,32 @@ func (e *Exchange) QueryAccount(ctx context.Context) (*types.Account, error) {
 		}
 	}
 
+	makerFeeRate, takerFeeRate := e.queryFeeRates()
 	return &types.Account{
-		MakerCommission: 15, // 0.15%
-		TakerCommission: 15, // 0.15%
+		// convert the fee rates to the commissions in basis points
+		MakerCommission: int64(math.Round(makerFeeRate * 10000)),
+		TakerCommission: int64(math.Round(takerFeeRate * 10000)),
 		Balances:        balances,
 	}, nil
 }
 
+// QueryFeeSchedule returns the fee rates of the current VIP level
+func (e *Exchange) QueryFeeSchedule(ctx context.Context) (*types.FeeSchedule, error) {
+	return types.NewFeeSchedule(e.queryFeeRates()), nil
+}
+
+// queryFeeRates returns the fee rates of the current VIP level,
+// the default rates of the base VIP level are returned when the VIP level can not be queried.
+func (e *Exchange) queryFeeRates() (makerFeeRate, takerFeeRate float64) {
+	vipLevel, err := e.client.AccountService.VipLevel()
+	if err != nil {
+		log.WithError(err).Warnf("can not query the vip level, using the default fee rates: maker %f, taker %f", DefaultMakerFeeRate, DefaultTakerFeeRate)
+		return DefaultMakerFeeRate, DefaultTakerFeeRate
+	}
+
+	return vipLevel.Current.MakerFee, vipLevel.Current.TakerFee
+}
+
 func (e *Exchange) QueryAccountBalances(ctx context.Context) (types.BalanceMap, error) {
 	accounts, err := e.client.AccountService.Accounts()
 	if err != nil {
@@ -128,6 +261,234 @@ func (e *Exchange) QueryTrades(ctx context.Context, symbol string, options *type
 	return trades, nil
 }
 
+func (e *Exchange) BatchQueryTrades(ctx context.Context, symbol string, options *types.TradeQueryOptions) (allTrades []types.Trade, err error) {
+	var lastTradeID = options.LastTradeID
+	for {
+		trades, err := e.QueryTrades(ctx, symbol, &types.TradeQueryOptions{
+			Limit:       options.Limit,
+			LastTradeID: lastTradeID,
+		})
+		if err != nil {
+			return allTrades, err
+		}
+
+		var numNewTrades = 0
+		for _, t := range trades {
+			if t.ID <= lastTradeID {
+				continue
+			}
+
+			if options.StartTime != nil && t.Time.Before(*options.StartTime) {
+				continue
+			}
+
+			if options.EndTime != nil && t.Time.After(*options.EndTime) {
+				return allTrades, nil
+			}
+
+			allTrades = append(allTrades, t)
+			lastTradeID = t.ID
+			numNewTrades++
+		}
+
+		if numNewTrades == 0 {
+			break
+		}
+	}
+
+	return allTrades, nil
+}
+
+func (e *Exchange) QueryWithdrawHistory(ctx context.Context, asset string, since, until time.Time) (allWithdraws []types.Withdraw, err error) {
+	txIDs := map[string]struct{}{}
+
+	// the records are paged by the offset, since the order of the records is not guaranteed for a time cursor
+	for offset := 0; ; {
+		withdraws, err := e.client.AccountService.Withdraws(toLocalCurrency(asset), since.Unix(), until.Unix(), offset, transferPageLimit)
+		if err != nil {
+			return allWithdraws, err
+		}
+
+		for _, d := range withdraws {
+			if _, ok := txIDs[d.UUID]; ok {
+				continue
+			}
+
+			withdraw, err := convertRemoteWithdraw(d)
+			if err != nil {
+				return allWithdraws, err
+			}
+
+			txIDs[d.UUID] = struct{}{}
+			allWithdraws = append(allWithdraws, *withdraw)
+		}
+
+		if len(withdraws) < transferPageLimit {
+			break
+		}
+
+		offset += len(withdraws)
+	}
+
+	return allWithdraws, nil
+}
+
+func (e *Exchange) QueryDepositHistory(ctx context.Context, asset string, since, until time.Time) (allDeposits []types.Deposit, err error) {
+	txIDs := map[string]struct{}{}
+
+	for offset := 0; ; {
+		deposits, err := e.client.AccountService.Deposits(toLocalCurrency(asset), since.Unix(), until.Unix(), offset, transferPageLimit)
+		if err != nil {
+			return allDeposits, err
+		}
+
+		for _, d := range deposits {
+			if _, ok := txIDs[d.UUID]; ok {
+				continue
+			}
+
+			deposit, err := convertRemoteDeposit(d)
+			if err != nil {
+				return allDeposits, err
+			}
+
+			txIDs[d.UUID] = struct{}{}
+			allDeposits = append(allDeposits, *deposit)
+		}
+
+		if len(deposits) < transferPageLimit {
+			break
+		}
+
+		offset += len(deposits)
+	}
+
+	return allDeposits, nil
+}
+
+func (e *Exchange) QueryTickers(ctx context.Context, symbols ...string) (map[string]types.Ticker, error) {
+	var tickers = make(map[string]types.Ticker)
+	if len(symbols) == 1 {
+		ticker, err := e.client.PublicService.Ticker(toLocalSymbol(symbols[0]))
+		if err != nil {
+			return nil, err
+		}
+
+		tickers[symbols[0]] = toGlobalTicker(symbols[0], *ticker)
+		return tickers, nil
+	}
+
+	localTickers, err := e.client.PublicService.Tickers()
+	if err != nil {
+		return nil, err
+	}
+
+	var wanted = make(map[string]struct{})
+	for _, symbol := range symbols {
+		wanted[symbol] = struct{}{}
+	}
+
+	for market, ticker := range localTickers {
+		symbol := toGlobalSymbol(market)
+		if _, ok := wanted[symbol]; len(wanted) > 0 && !ok {
+			continue
+		}
+
+		tickers[symbol] = toGlobalTicker(symbol, ticker)
+	}
+
+	return tickers, nil
+}
+
+func toGlobalTicker(symbol string, ticker maxapi.Ticker) types.Ticker {
+	return types.Ticker{
+		Symbol: symbol,
+		Time:   ticker.Time,
+		Buy:    util.MustParseFloat(ticker.Buy),
+		Sell:   util.MustParseFloat(ticker.Sell),
+		Last:   util.MustParseFloat(ticker.Last),
+	}
+}
+
+func (e *Exchange) QueryKLines(ctx context.Context, symbol string, interval string, options types.KLineQueryOptions) ([]types.KLine, error) {
+	period, err := toLocalPeriod(interval)
+	if err != nil {
+		return nil, err
+	}
+
+	var startTime int64 = 0
+	if options.StartTime != nil {
+		startTime = options.StartTime.Unix()
+	}
+
+	localKLines, err := e.client.PublicService.KLines(toLocalSymbol(symbol), period, startTime, options.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	var kLines []types.KLine
+	for _, k := range localKLines {
+		if options.EndTime != nil && k.EndTime.After(*options.EndTime) {
+			break
+		}
+
+		kLines = append(kLines, types.KLine{
+			Symbol:    symbol,
+			Interval:  interval,
+			StartTime: k.StartTime,
+			EndTime:   k.EndTime,
+			Open:      k.Open,
+			Close:     k.Close,
+			High:      k.High,
+			Low:       k.Low,
+			Volume:    k.Volume,
+			Closed:    true,
+		})
+	}
+
+	return kLines, nil
+}
+
+func toLocalSymbol(symbol string) string {
+	return strings.ToLower(symbol)
+}
+
+func toGlobalSymbol(symbol string) string {
+	return strings.ToUpper(symbol)
+}
+
+// toLocalPeriod converts the kline interval to the period in minutes used by max
+func toLocalPeriod(interval string) (int, error) {
+	switch interval {
+	case "1m":
+		return 1, nil
+	case "5m":
+		return 5, nil
+	case "15m":
+		return 15, nil
+	case "30m":
+		return 30, nil
+	case "1h":
+		return 60, nil
+	case "2h":
+		return 120, nil
+	case "4h":
+		return 240, nil
+	case "6h":
+		return 360, nil
+	case "12h":
+		return 720, nil
+	case "1d":
+		return 1440, nil
+	case "3d":
+		return 4320, nil
+	case "1w":
+		return 10080, nil
+	}
+
+	return 0, fmt.Errorf("interval %s is not supported", interval)
+}
+
 func toGlobalCurrency(currency string) string {
 	return strings.ToUpper(currency)
 }
@@ -165,6 +526,60 @@ func toLocalOrderType(orderType types.OrderType) (maxapi.OrderType, error) {
 	return "", fmt.Errorf("order type %s not supported", orderType)
 }
 
+func toGlobalOrderStatus(state string, executedVolume float64) types.OrderStatus {
+	switch state {
+	case "cancel":
+		return types.OrderStatusCanceled
+
+	case "done":
+		return types.OrderStatusFilled
+
+	case "wait", "convert":
+		if executedVolume > 0 {
+			return types.OrderStatusPartiallyFilled
+		}
+
+		return types.OrderStatusNew
+	}
+
+	return types.OrderStatus(strings.ToUpper(state))
+}
+
+func convertRemoteOrder(o maxapi.Order) (*types.Order, error) {
+	price, err := util.ParseFloat(o.Price)
+	if err != nil {
+		return nil, err
+	}
+
+	volume, err := util.ParseFloat(o.Volume)
+	if err != nil {
+		return nil, err
+	}
+
+	executedVolume, err := util.ParseFloat(o.ExecutedVolume)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.Order{
+		SubmitOrder: types.SubmitOrder{
+			Symbol:         toGlobalSymbol(o.Market),
+			Side:           types.SideType(toGlobalSideType(o.Side)),
+			Type:           types.OrderType(strings.ToUpper(o.OrderType)),
+			Quantity:       volume,
+			Price:          price,
+			PriceString:    o.Price,
+			QuantityString: o.Volume,
+			ClientOrderID:  o.ClientOID,
+		},
+		OrderID:          o.ID,
+		Exchange:         ExchangeName,
+		Status:           toGlobalOrderStatus(o.State, executedVolume),
+		ExecutedQuantity: executedVolume,
+		CreationTime:     time.Unix(0, o.CreatedAtMs*int64(time.Millisecond)),
+	}, nil
+}
+
 func convertRemoteTrade(t maxapi.Trade) (*types.Trade, error) {
 	// skip trade ID that is the same. however this should not happen
 	var side = toGlobalSideType(t.Side)
@@ -194,16 +609,137 @@ func convertRemoteTrade(t maxapi.Trade) (*types.Trade, error) {
 
 	return &types.Trade{
 		ID:            int64(t.ID),
+		OrderID:       t.OrderID,
 		Price:         price,
-		Symbol:        t.Market,
-		Exchange:      "max",
+		Symbol:        toGlobalSymbol(t.Market),
+		Exchange:      ExchangeName,
 		Quantity:      quantity,
 		Side:          side,
 		IsBuyer:       t.IsBuyer(),
 		IsMaker:       t.IsMaker(),
+		IsMargin:      false, // spot account trades
 		Fee:           fee,
-		FeeCurrency:   t.FeeCurrency,
+		FeeCurrency:   toGlobalCurrency(t.FeeCurrency),
 		QuoteQuantity: quoteQuantity,
 		Time:          mts,
 	}, nil
 }
+
+func toGlobalDepositStatus(state string) types.DepositStatus {
+	switch state {
+	case "accepted":
+		return types.DepositSuccess
+
+	case "rejected", "refunded", "cancelled":
+		return types.DepositRejected
+
+	}
+
+	// submitting, submitted, suspended, suspect...
+	return types.DepositPending
+}
+
+func toGlobalWithdrawStatus(state string) types.WithdrawStatus {
+	switch state {
+	case "submitting", "submitted", "pending":
+		return types.WithdrawAwaitingApproval
+
+	case "confirmed":
+		return types.WithdrawCompleted
+
+	case "canceled", "cancelled":
+		return types.WithdrawCancelled
+
+	case "rejected":
+		return types.WithdrawRejected
+
+	case "failed":
+		return types.WithdrawFailure
+
+	}
+
+	// accepted, approved, processing, sent, retryable...
+	return types.WithdrawProcessing
+}
+
+func convertRemoteDeposit(d maxapi.Deposit) (*types.Deposit, error) {
+	amount, err := util.ParseFloat(d.Amount)
+	if err != nil {
+		return nil, err
+	}
+
+	// the internal transfers do not have the transaction id
+	txID := d.TxID
+	if len(txID) == 0 {
+		txID = d.UUID
+	}
+
+	return &types.Deposit{
+		Exchange:      ExchangeName,
+		Time:          time.Unix(d.CreatedAt, 0),
+		Amount:        amount,
+		Asset:         toGlobalCurrency(d.Currency),
+		TransactionID: txID,
+		Status:        toGlobalDepositStatus(d.State),
+	}, nil
+}
+
+func convertRemoteWithdraw(w maxapi.Withdraw) (*types.Withdraw, error) {
+	amount, err := util.ParseFloat(w.Amount)
+	if err != nil {
+		return nil, err
+	}
+
+	fee, err := util.ParseFloat(w.Fee)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.Withdraw{
+		Exchange:       ExchangeName,
+		ID:             w.UUID,
+		ApplyTime:      time.Unix(w.CreatedAt, 0),
+		Asset:          toGlobalCurrency(w.Currency),
+		Amount:         amount,
+		TransactionID:  w.TxID,
+		TransactionFee: fee,
+		Network:        w.CurrencyVersion,
+		Status:         toGlobalWithdrawStatus(w.State),
+	}, nil
+}
+
+func convertWebSocketOrderUpdate(u maxapi.OrderUpdate) (*types.Order, error) {
+	price, err := util.ParseFloat(u.Price)
+	if err != nil {
+		return nil, err
+	}
+
+	volume, err := util.ParseFloat(u.Volume)
+	if err != nil {
+		return nil, err
+	}
+
+	executedVolume, err := util.ParseFloat(u.ExecutedVolume)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.Order{
+		SubmitOrder: types.SubmitOrder{
+			Symbol:         toGlobalSymbol(u.Market),
+			Side:           types.SideType(toGlobalSideType(u.Side)),
+			Type:           types.OrderType(strings.ToUpper(u.OrderType)),
+			Quantity:       volume,
+			Price:          price,
+			PriceString:    u.Price,
+			QuantityString: u.Volume,
+			ClientOrderID:  u.ClientOID,
+		},
+		OrderID:          u.ID,
+		Exchange:         ExchangeName,
+		Status:           toGlobalOrderStatus(u.State, executedVolume),
+		ExecutedQuantity: executedVolume,
+		CreationTime:     time.Unix(0, u.CreatedAtMs*int64(time.Millisecond)),
+		UpdateTime:       time.Now(),
+	}, nil
+}
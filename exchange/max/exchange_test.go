@@ -0,0 +1,168 @@
+package max
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	maxapi "github.com/c9s/bbgo/exchange/max/maxapi"
+)
+
+// newTestExchange creates the exchange that sends the requests to the test server,
+// the timestamp request of the client is answered by the test server
+func newTestExchange(handler http.HandlerFunc) (*Exchange, func()) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v2/timestamp" {
+			_ = json.NewEncoder(w).Encode(time.Now().Unix())
+			return
+		}
+
+		handler(w, r)
+	}))
+	client := maxapi.NewRestClient(server.URL + "/api/v2")
+	client.Auth("key", "secret")
+	return &Exchange{client: client}, server.Close
+}
+
+// decodePayload decodes the request parameters from the payload header of the authenticated request
+func decodePayload(r *http.Request) map[string]interface{} {
+	var payload map[string]interface{}
+	data, _ := base64.StdEncoding.DecodeString(r.Header.Get("X-MAX-PAYLOAD"))
+	_ = json.Unmarshal(data, &payload)
+	return payload
+}
+
+// pageOf returns the records of the page by the offset and the limit parameters
+func pageOf(payload map[string]interface{}, n int) (from, to int) {
+	offset, _ := payload["offset"].(float64)
+	limit, _ := payload["limit"].(float64)
+
+	from = int(offset)
+	to = from + int(limit)
+	if from > n {
+		from = n
+	}
+	if to > n {
+		to = n
+	}
+	return from, to
+}
+
+func TestExchange_QueryWithdrawHistory(t *testing.T) {
+	since := time.Date(2020, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	// the records are returned from the newest one
+	var withdraws []maxapi.Withdraw
+	for i := 250; i > 0; i-- {
+		withdraws = append(withdraws, maxapi.Withdraw{
+			UUID:      strconv.Itoa(i),
+			Currency:  "btc",
+			Amount:    "0.1",
+			Fee:       "0.0005",
+			State:     "confirmed",
+			CreatedAt: since.Add(time.Duration(i) * time.Minute).Unix(),
+		})
+	}
+
+	exchange, closeServer := newTestExchange(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v2/withdrawals", r.URL.Path)
+		from, to := pageOf(decodePayload(r), len(withdraws))
+		_ = json.NewEncoder(w).Encode(withdraws[from:to])
+	})
+	defer closeServer()
+
+	records, err := exchange.QueryWithdrawHistory(context.Background(), "BTC", since, since.Add(24*time.Hour))
+	assert.NoError(t, err)
+	assert.Len(t, records, 250)
+}
+
+func TestExchange_QueryDepositHistory(t *testing.T) {
+	since := time.Date(2020, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	var deposits []maxapi.Deposit
+	for i := 200; i > 0; i-- {
+		deposits = append(deposits, maxapi.Deposit{
+			UUID:      strconv.Itoa(i),
+			Currency:  "btc",
+			Amount:    "0.1",
+			Fee:       "0",
+			State:     "accepted",
+			CreatedAt: since.Add(time.Duration(i) * time.Minute).Unix(),
+		})
+	}
+
+	var requests int
+	exchange, closeServer := newTestExchange(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v2/deposits", r.URL.Path)
+		requests++
+		from, to := pageOf(decodePayload(r), len(deposits))
+		_ = json.NewEncoder(w).Encode(deposits[from:to])
+	})
+	defer closeServer()
+
+	records, err := exchange.QueryDepositHistory(context.Background(), "BTC", since, since.Add(24*time.Hour))
+	assert.NoError(t, err)
+	assert.Len(t, records, 200)
+
+	// the last empty page tells there are no more records
+	assert.Equal(t, 3, requests)
+}
+
+func TestExchange_QueryOpenOrders(t *testing.T) {
+	newOrder := func(id int) maxapi.Order {
+		return maxapi.Order{
+			ID:             uint64(id),
+			Side:           "sell",
+			OrderType:      "limit",
+			Price:          "9000.0",
+			State:          "wait",
+			Market:         "btcusdt",
+			Volume:         "0.1",
+			ExecutedVolume: "0",
+		}
+	}
+
+	// the orders are returned from the newest one
+	var orders []maxapi.Order
+	for i := 250; i > 0; i-- {
+		orders = append(orders, newOrder(i))
+	}
+
+	var requests int
+	exchange, closeServer := newTestExchange(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v2/orders", r.URL.Path)
+		requests++
+
+		payload := decodePayload(r)
+		page, _ := payload["page"].(float64)
+		limit, _ := payload["limit"].(float64)
+
+		from, to := int(page-1)*int(limit), int(page)*int(limit)
+		if to > len(orders) {
+			to = len(orders)
+		}
+		_ = json.NewEncoder(w).Encode(orders[from:to])
+
+		// the new order shifts the last order of the page to the next page
+		if requests == 1 {
+			orders = append([]maxapi.Order{newOrder(251)}, orders...)
+		}
+	})
+	defer closeServer()
+
+	openOrders, err := exchange.QueryOpenOrders(context.Background(), "BTCUSDT")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, requests)
+	if assert.Len(t, openOrders, 250) {
+		assert.Equal(t, "BTCUSDT", openOrders[0].Symbol)
+		assert.Equal(t, uint64(250), openOrders[0].OrderID)
+		assert.Equal(t, uint64(1), openOrders[249].OrderID)
+	}
+}
@@ -108,3 +108,144 @@ func (s *AccountService) Me() (*UserInfo, error) {
 
 	return &m, nil
 }
+
+type VipLevelSettings struct {
+	Level                int     `json:"level"`
+	MinimumTradingVolume float64 `json:"minimum_trading_volume"`
+	MinimumStakingVolume float64 `json:"minimum_staking_volume"`
+	MakerFee             float64 `json:"maker_fee"`
+	TakerFee             float64 `json:"taker_fee"`
+}
+
+type VipLevel struct {
+	Current VipLevelSettings `json:"current_vip_level"`
+	Next    VipLevelSettings `json:"next_vip_level"`
+}
+
+// VipLevel returns the current and the next VIP level settings of the member, the fee rates depend on the VIP level
+func (s *AccountService) VipLevel() (*VipLevel, error) {
+	req, err := s.client.newAuthenticatedRequest("GET", "v2/members/vip_level", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := s.client.sendRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var vipLevel VipLevel
+	err = response.DecodeJSON(&vipLevel)
+	if err != nil {
+		return nil, err
+	}
+
+	return &vipLevel, nil
+}
+
+type Deposit struct {
+	UUID            string `json:"uuid"`
+	Currency        string `json:"currency"`
+	CurrencyVersion string `json:"currency_version"` // "eth"
+	Amount          string `json:"amount"`
+	Fee             string `json:"fee"`
+	TxID            string `json:"txid"`
+	State           string `json:"state"`
+	CreatedAt       int64  `json:"created_at"`
+	UpdatedAt       int64  `json:"updated_at"`
+}
+
+// Deposits returns the deposits created between the given unix timestamps (in seconds), 0 means no limit.
+// The records are paged by the offset.
+func (s *AccountService) Deposits(currency string, from, to int64, offset, limit int) ([]Deposit, error) {
+	payload := map[string]interface{}{
+		"limit": limit,
+	}
+
+	if len(currency) > 0 {
+		payload["currency"] = currency
+	}
+
+	if from > 0 {
+		payload["from"] = from
+	}
+
+	if to > 0 {
+		payload["to"] = to
+	}
+
+	if offset > 0 {
+		payload["offset"] = offset
+	}
+
+	req, err := s.client.newAuthenticatedRequest("GET", "v2/deposits", payload)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := s.client.sendRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var deposits []Deposit
+	if err := response.DecodeJSON(&deposits); err != nil {
+		return nil, err
+	}
+
+	return deposits, nil
+}
+
+type Withdraw struct {
+	UUID            string `json:"uuid"`
+	Currency        string `json:"currency"`
+	CurrencyVersion string `json:"currency_version"` // "eth"
+	Amount          string `json:"amount"`
+	Fee             string `json:"fee"`
+	FeeCurrency     string `json:"fee_currency"`
+	TxID            string `json:"txid"`
+	State           string `json:"state"`
+	CreatedAt       int64  `json:"created_at"`
+	UpdatedAt       int64  `json:"updated_at"`
+}
+
+// Withdraws returns the withdraws created between the given unix timestamps (in seconds), 0 means no limit.
+// The records are paged by the offset.
+func (s *AccountService) Withdraws(currency string, from, to int64, offset, limit int) ([]Withdraw, error) {
+	payload := map[string]interface{}{
+		"limit": limit,
+	}
+
+	if len(currency) > 0 {
+		payload["currency"] = currency
+	}
+
+	if from > 0 {
+		payload["from"] = from
+	}
+
+	if to > 0 {
+		payload["to"] = to
+	}
+
+	if offset > 0 {
+		payload["offset"] = offset
+	}
+
+	req, err := s.client.newAuthenticatedRequest("GET", "v2/withdrawals", payload)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := s.client.sendRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var withdraws []Withdraw
+	if err := response.DecodeJSON(&withdraws); err != nil {
+		return nil, err
+	}
+
+	return withdraws, nil
+}
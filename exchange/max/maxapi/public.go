@@ -1,7 +1,9 @@
 package max
 
 import (
+	"fmt"
 	"net/url"
+	"strconv"
 	"time"
 
 	"github.com/valyala/fastjson"
@@ -125,6 +127,79 @@ func (s *PublicService) Ticker(market string) (*Ticker, error) {
 	return &ticker, nil
 }
 
+type KLine struct {
+	Market    string
+	Period    int
+	StartTime time.Time
+	EndTime   time.Time
+	Open      float64
+	High      float64
+	Low       float64
+	Close     float64
+	Volume    float64
+}
+
+// KLines returns the k-lines of the market. period is in minutes and startTime is the unix timestamp in seconds.
+// The response is an array of [timestamp, open, high, low, close, volume].
+func (s *PublicService) KLines(market string, period int, startTime int64, limit int) ([]KLine, error) {
+	var params = url.Values{}
+	params.Set("market", market)
+	params.Set("period", strconv.Itoa(period))
+	if startTime > 0 {
+		params.Set("timestamp", strconv.FormatInt(startTime, 10))
+	}
+	if limit > 0 {
+		params.Set("limit", strconv.Itoa(limit))
+	}
+
+	req, err := s.client.newRequest("GET", "v2/k", params, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := s.client.sendRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := fastjson.ParseBytes(response.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := v.Array()
+	if err != nil {
+		return nil, err
+	}
+
+	var klines []KLine
+	for _, row := range rows {
+		cols, err := row.Array()
+		if err != nil {
+			return nil, err
+		}
+
+		if len(cols) < 6 {
+			return nil, fmt.Errorf("unexpected kline column length: %d", len(cols))
+		}
+
+		t := time.Unix(cols[0].GetInt64(), 0)
+		klines = append(klines, KLine{
+			Market:    market,
+			Period:    period,
+			StartTime: t,
+			EndTime:   t.Add(time.Duration(period)*time.Minute - time.Millisecond),
+			Open:      cols[1].GetFloat64(),
+			High:      cols[2].GetFloat64(),
+			Low:       cols[3].GetFloat64(),
+			Close:     cols[4].GetFloat64(),
+			Volume:    cols[5].GetFloat64(),
+		})
+	}
+
+	return klines, nil
+}
+
 func mustParseTicker(v *fastjson.Value) Ticker {
 	var at = v.GetInt64("at")
 	return Ticker{
@@ -43,6 +43,30 @@ func NewStream(key, secret string) *Stream {
 		}
 	})
 
+	wss.OnOrderSnapshotEvent(func(e max.OrderSnapshotEvent) {
+		for _, u := range e.Orders {
+			order, err := convertWebSocketOrderUpdate(u)
+			if err != nil {
+				logger.WithError(err).Error("order convert error")
+				continue
+			}
+
+			stream.EmitOrderUpdate(*order)
+		}
+	})
+
+	wss.OnOrderUpdateEvent(func(e max.OrderUpdateEvent) {
+		for _, u := range e.Orders {
+			order, err := convertWebSocketOrderUpdate(u)
+			if err != nil {
+				logger.WithError(err).Error("order convert error")
+				continue
+			}
+
+			stream.EmitOrderUpdate(*order)
+		}
+	})
+
 	wss.OnAccountSnapshotEvent(func(e max.AccountSnapshotEvent) {
 		snapshot := map[string]types.Balance{}
 		for _, bm := range e.Balances {
@@ -0,0 +1,683 @@
+package paper
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/types"
+)
+
+// ExchangeName is used as the exchange name of the simulated trades
+const ExchangeName = "paper"
+
+// DefaultFeeRate is the fee rate used when the fee rate is not set
+const DefaultFeeRate = 0.001
+
+var log = logrus.WithField("exchange", ExchangeName)
+
+var ErrInsufficientBalance = errors.New("insufficient balance")
+var ErrPriceNotFound = errors.New("market price not found")
+
+func init() {
+	_ = types.Exchange(&Exchange{})
+}
+
+type openOrder struct {
+	types.SubmitOrder
+
+	OrderID      uint64
+	CreationTime time.Time
+
+	Remaining float64
+
+	// Locked is the balance amount that is locked for this order (quote for buy orders, base for sell orders)
+	Locked float64
+}
+
+// order returns the current state of the order
+func (o *openOrder) order(status types.OrderStatus) types.Order {
+	return types.Order{
+		SubmitOrder:      o.SubmitOrder,
+		OrderID:          o.OrderID,
+		Exchange:         ExchangeName,
+		Status:           status,
+		ExecutedQuantity: round(o.Quantity - o.Remaining),
+		CreationTime:     o.CreationTime,
+		UpdateTime:       time.Now(),
+	}
+}
+
+// status returns the order status by the filled quantity
+func (o *openOrder) status() types.OrderStatus {
+	if o.Remaining <= 0 {
+		return types.OrderStatusFilled
+	} else if o.Remaining < o.Quantity {
+		return types.OrderStatusPartiallyFilled
+	}
+
+	return types.OrderStatusNew
+}
+
+// bookLevel is the order book level that is consumed by the orders of the side, buy orders consume the asks
+type bookLevel struct {
+	Side  types.SideType
+	Price float64
+}
+
+// Exchange is a simulated exchange that uses the public market data from the source exchange,
+// and fills the submitted orders against the live order book with a virtual account.
+type Exchange struct {
+	mu sync.Mutex
+
+	// source exchange provides the public market data (kline and order book)
+	source types.Exchange
+
+	FeeRate float64
+
+	balances types.BalanceMap
+	books    map[string]*types.MutexOrderBook
+	// consumed is the quantity of the book levels that has been filled since the level was last updated,
+	// so that the same liquidity is not filled twice
+	consumed   map[string]map[bookLevel]float64
+	lastPrices map[string]float64
+	openOrders []*openOrder
+	trades     []types.Trade
+	tradeID    int64
+	orderID    uint64
+
+	streams []*Stream
+}
+
+func New(source types.Exchange, balances types.BalanceMap) *Exchange {
+	var initBalances = make(types.BalanceMap)
+	for currency, balance := range balances {
+		balance.Currency = currency
+		initBalances[currency] = balance
+	}
+
+	return &Exchange{
+		source:     source,
+		FeeRate:    DefaultFeeRate,
+		balances:   initBalances,
+		books:      make(map[string]*types.MutexOrderBook),
+		consumed:   make(map[string]map[bookLevel]float64),
+		lastPrices: make(map[string]float64),
+	}
+}
+
+func (e *Exchange) NewStream() types.Stream {
+	stream := &Stream{
+		exchange: e,
+		source:   e.source.NewStream(),
+	}
+
+	stream.source.OnKLineClosed(func(kline types.KLine) {
+		e.handleKLineClosed(kline)
+		stream.EmitKLineClosed(kline)
+	})
+
+	stream.source.OnBookSnapshot(func(book types.OrderBook) {
+		e.handleBook(book, true)
+		stream.EmitBookSnapshot(book)
+	})
+
+	stream.source.OnBookUpdate(func(book types.OrderBook) {
+		e.handleBook(book, false)
+		stream.EmitBookUpdate(book)
+	})
+
+	e.mu.Lock()
+	e.streams = append(e.streams, stream)
+	e.mu.Unlock()
+
+	return stream
+}
+
+func (e *Exchange) Name() string {
+	return ExchangeName
+}
+
+func (e *Exchange) PlatformFeeCurrency() string {
+	return e.source.PlatformFeeCurrency()
+}
+
+func (e *Exchange) QueryAccount(ctx context.Context) (*types.Account, error) {
+	balances, err := e.QueryAccountBalances(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	commission := int64(math.Round(e.FeeRate * 1e4))
+	return &types.Account{
+		MakerCommission: commission,
+		TakerCommission: commission,
+		AccountType:     ExchangeName,
+		Balances:        balances,
+	}, nil
+}
+
+// QueryFeeSchedule returns the simulated fee rate for both maker and taker
+func (e *Exchange) QueryFeeSchedule(ctx context.Context) (*types.FeeSchedule, error) {
+	return types.NewFeeSchedule(e.FeeRate, e.FeeRate), nil
+}
+
+func (e *Exchange) QueryAccountBalances(ctx context.Context) (types.BalanceMap, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.copyBalances(), nil
+}
+
+func (e *Exchange) QueryKLines(ctx context.Context, symbol string, interval string, options types.KLineQueryOptions) ([]types.KLine, error) {
+	return e.source.QueryKLines(ctx, symbol, interval, options)
+}
+
+// QueryTrades returns the simulated trades, the trades are kept in memory only.
+func (e *Exchange) QueryTrades(ctx context.Context, symbol string, options *types.TradeQueryOptions) (trades []types.Trade, err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, trade := range e.trades {
+		if trade.Symbol != symbol {
+			continue
+		}
+
+		if options.LastTradeID > 0 && trade.ID <= options.LastTradeID {
+			continue
+		}
+
+		if options.StartTime != nil && trade.Time.Before(*options.StartTime) {
+			continue
+		}
+
+		if options.EndTime != nil && trade.Time.After(*options.EndTime) {
+			continue
+		}
+
+		trades = append(trades, trade)
+		if options.Limit > 0 && int64(len(trades)) >= options.Limit {
+			break
+		}
+	}
+
+	return trades, nil
+}
+
+func (e *Exchange) BatchQueryTrades(ctx context.Context, symbol string, options *types.TradeQueryOptions) ([]types.Trade, error) {
+	return e.QueryTrades(ctx, symbol, &types.TradeQueryOptions{
+		StartTime:   options.StartTime,
+		EndTime:     options.EndTime,
+		LastTradeID: options.LastTradeID,
+	})
+}
+
+func (e *Exchange) QueryOpenOrders(ctx context.Context, symbol string) (orders []types.Order, err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, o := range e.openOrders {
+		if o.Symbol != symbol {
+			continue
+		}
+
+		orders = append(orders, o.order(o.status()))
+	}
+
+	return orders, nil
+}
+
+func (e *Exchange) CancelOrders(ctx context.Context, orders ...types.Order) error {
+	e.mu.Lock()
+
+	var canceled = make(map[uint64]struct{})
+	for _, o := range orders {
+		canceled[o.OrderID] = struct{}{}
+	}
+
+	var openOrders []*openOrder
+	var canceledOrders []types.Order
+	for _, o := range e.openOrders {
+		if _, ok := canceled[o.OrderID]; !ok {
+			openOrders = append(openOrders, o)
+			continue
+		}
+
+		canceledOrders = append(canceledOrders, o.order(types.OrderStatusCanceled))
+
+		// release the locked balance of the remaining quantity
+		o.Remaining = 0
+		e.unlockFilled(o)
+	}
+	e.openOrders = openOrders
+
+	balances := e.copyBalances()
+	streams := e.streams
+	e.mu.Unlock()
+
+	for _, stream := range streams {
+		for _, order := range canceledOrders {
+			stream.EmitOrderUpdate(order)
+		}
+
+		stream.EmitBalanceSnapshot(balances)
+	}
+
+	return nil
+}
+
+func (e *Exchange) SubmitOrder(ctx context.Context, order *types.SubmitOrder) error {
+	market, ok := findMarket(order)
+	if !ok {
+		return fmt.Errorf("market %s not found", order.Symbol)
+	}
+
+	if order.Quantity <= 0 {
+		return fmt.Errorf("invalid order quantity: %f", order.Quantity)
+	}
+
+	o := &openOrder{
+		SubmitOrder:  *order,
+		Remaining:    order.Quantity,
+		CreationTime: time.Now(),
+	}
+	o.Market = market
+
+	e.mu.Lock()
+	e.orderID++
+	o.OrderID = e.orderID
+	trades, err := e.submit(o)
+	submitted := o.order(o.status())
+	e.mu.Unlock()
+
+	if err != nil {
+		return err
+	}
+
+	log.Infof("order submitted: %s %s %s quantity %f price %f", o.Symbol, o.Type, o.Side, o.Quantity, o.Price)
+	e.emitEvents(trades, []types.Order{submitted})
+	return nil
+}
+
+func (e *Exchange) submit(o *openOrder) (trades []types.Trade, err error) {
+	switch o.Type {
+
+	case types.OrderTypeMarket:
+		price, ok := e.bestPrice(o.Symbol, o.Side)
+		if !ok {
+			return nil, errors.Wrapf(ErrPriceNotFound, "symbol %s", o.Symbol)
+		}
+
+		// lock the cost of walking the book for the market buy, so that the fills never spend more than the locked amount
+		if o.Side == types.SideTypeBuy {
+			err = e.lockAmount(o, o.Market.QuoteCurrency, e.marketCost(o, price))
+		} else {
+			err = e.lock(o, price)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		trades = e.match(o, false)
+
+		// the book is not deep enough or we have no book, fill the rest with the last price
+		if o.Remaining > 0 {
+			trades = append(trades, e.fill(o, price, o.Remaining, false))
+		}
+
+	case types.OrderTypeLimit:
+		if o.Price <= 0 {
+			return nil, fmt.Errorf("invalid limit order price: %f", o.Price)
+		}
+
+		if err := e.lock(o, o.Price); err != nil {
+			return nil, err
+		}
+
+		trades = e.match(o, false)
+		if o.Remaining > 0 {
+			e.openOrders = append(e.openOrders, o)
+		}
+
+	default:
+		return nil, fmt.Errorf("order type %s not supported", o.Type)
+	}
+
+	e.unlockFilled(o)
+	return trades, nil
+}
+
+// lock moves the required amount of the order from the available balance to the locked balance
+func (e *Exchange) lock(o *openOrder, price float64) error {
+	var currency string
+	var amount float64
+
+	switch o.Side {
+	case types.SideTypeBuy:
+		currency = o.Market.QuoteCurrency
+		amount = o.Quantity * price
+
+	case types.SideTypeSell:
+		currency = o.Market.BaseCurrency
+		amount = o.Quantity
+
+	default:
+		return fmt.Errorf("unknown order side: %s", o.Side)
+	}
+
+	return e.lockAmount(o, currency, amount)
+}
+
+// lockAmount moves the amount of the currency from the available balance to the locked balance of the order
+func (e *Exchange) lockAmount(o *openOrder, currency string, amount float64) error {
+	balance := e.balances[currency]
+	if balance.Available < amount {
+		return errors.Wrapf(ErrInsufficientBalance, "%s available %f < required %f", currency, balance.Available, amount)
+	}
+
+	balance.Currency = currency
+	balance.Available -= amount
+	balance.Locked += amount
+	e.balances[currency] = balance
+	o.Locked = amount
+	return nil
+}
+
+// unlockFilled releases the locked balance of the order once it's completely filled
+func (e *Exchange) unlockFilled(o *openOrder) {
+	if o.Remaining > 0 || o.Locked == 0 {
+		return
+	}
+
+	currency := o.Market.BaseCurrency
+	if o.Side == types.SideTypeBuy {
+		currency = o.Market.QuoteCurrency
+	}
+
+	balance := e.balances[currency]
+	balance.Locked -= o.Locked
+	balance.Available += o.Locked
+	e.balances[currency] = balance
+	o.Locked = 0
+}
+
+// levels returns the opposite order book levels of the order
+func (e *Exchange) levels(o *openOrder) types.PriceVolumeSlice {
+	book, ok := e.books[o.Symbol]
+	if !ok {
+		return nil
+	}
+
+	if o.Side == types.SideTypeBuy {
+		return book.Asks
+	}
+
+	return book.Bids
+}
+
+// available returns the quantity of the book level that is not consumed yet
+func (e *Exchange) available(o *openOrder, pv types.PriceVolume) float64 {
+	level := bookLevel{Side: o.Side, Price: pv.Price.Float64()}
+	return round(pv.Volume.Float64() - e.consumed[o.Symbol][level])
+}
+
+// consume records the filled quantity of the book level
+func (e *Exchange) consume(o *openOrder, pv types.PriceVolume, quantity float64) {
+	levels, ok := e.consumed[o.Symbol]
+	if !ok {
+		levels = make(map[bookLevel]float64)
+		e.consumed[o.Symbol] = levels
+	}
+
+	levels[bookLevel{Side: o.Side, Price: pv.Price.Float64()}] += quantity
+}
+
+// marketCost returns the quote amount of filling the market order by walking the book,
+// the quantity that the book can not fill is priced at the given price.
+func (e *Exchange) marketCost(o *openOrder, price float64) float64 {
+	var cost float64
+	var remaining = o.Remaining
+	for _, pv := range e.levels(o) {
+		if remaining <= 0 {
+			break
+		}
+
+		quantity := math.Min(remaining, e.available(o, pv))
+		if quantity <= 0 {
+			continue
+		}
+
+		cost += pv.Price.Float64() * quantity
+		remaining = round(remaining - quantity)
+	}
+
+	if remaining > 0 {
+		cost += price * remaining
+	}
+
+	return cost
+}
+
+// match fills the order against the order book levels that cross the order price,
+// the filled quantity is consumed from the levels until the levels are updated.
+// isMaker is true when the order was resting on the book, then the order price is used as the fill price.
+func (e *Exchange) match(o *openOrder, isMaker bool) (trades []types.Trade) {
+	for _, pv := range e.levels(o) {
+		if o.Remaining <= 0 {
+			break
+		}
+
+		price := pv.Price.Float64()
+		if o.Type == types.OrderTypeLimit {
+			if o.Side == types.SideTypeBuy && price > o.Price {
+				break
+			}
+
+			if o.Side == types.SideTypeSell && price < o.Price {
+				break
+			}
+		}
+
+		quantity := math.Min(o.Remaining, e.available(o, pv))
+		if quantity <= 0 {
+			continue
+		}
+
+		e.consume(o, pv, quantity)
+
+		if isMaker {
+			price = o.Price
+		}
+
+		trades = append(trades, e.fill(o, price, quantity, isMaker))
+	}
+
+	return trades
+}
+
+// fill executes the given quantity of the order and settles the balances
+func (e *Exchange) fill(o *openOrder, price, quantity float64, isMaker bool) types.Trade {
+	market := o.Market
+	quoteQuantity := price * quantity
+
+	base := e.balances[market.BaseCurrency]
+	quote := e.balances[market.QuoteCurrency]
+	base.Currency = market.BaseCurrency
+	quote.Currency = market.QuoteCurrency
+
+	var fee float64
+	var feeCurrency string
+
+	switch o.Side {
+	case types.SideTypeBuy:
+		// the fee is deducted from the received asset
+		fee = quantity * e.FeeRate
+		feeCurrency = market.BaseCurrency
+
+		spent := math.Min(quoteQuantity, o.Locked)
+		quote.Locked -= spent
+		quote.Available -= quoteQuantity - spent
+		o.Locked -= spent
+		base.Available += quantity - fee
+
+	case types.SideTypeSell:
+		fee = quoteQuantity * e.FeeRate
+		feeCurrency = market.QuoteCurrency
+
+		base.Locked -= quantity
+		o.Locked -= quantity
+		quote.Available += quoteQuantity - fee
+	}
+
+	e.balances[market.BaseCurrency] = base
+	e.balances[market.QuoteCurrency] = quote
+
+	o.Remaining = round(o.Remaining - quantity)
+	e.lastPrices[o.Symbol] = price
+	e.tradeID++
+
+	trade := types.Trade{
+		ID:            e.tradeID,
+		OrderID:       o.OrderID,
+		Exchange:      ExchangeName,
+		Price:         price,
+		Quantity:      quantity,
+		QuoteQuantity: quoteQuantity,
+		Symbol:        o.Symbol,
+		Side:          string(o.Side),
+		IsBuyer:       o.Side == types.SideTypeBuy,
+		IsMaker:       isMaker,
+		Time:          time.Now(),
+		Fee:           fee,
+		FeeCurrency:   feeCurrency,
+	}
+	e.trades = append(e.trades, trade)
+	return trade
+}
+
+// bestPrice returns the best opposite price from the book, or the last price if there is no book
+func (e *Exchange) bestPrice(symbol string, side types.SideType) (float64, bool) {
+	if book, ok := e.books[symbol]; ok {
+		if side == types.SideTypeBuy && len(book.Asks) > 0 {
+			return book.Asks[0].Price.Float64(), true
+		}
+
+		if side == types.SideTypeSell && len(book.Bids) > 0 {
+			return book.Bids[0].Price.Float64(), true
+		}
+	}
+
+	price, ok := e.lastPrices[symbol]
+	return price, ok
+}
+
+func (e *Exchange) handleKLineClosed(kline types.KLine) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	// the book price is more accurate than the kline close price
+	if _, ok := e.books[kline.Symbol]; ok {
+		return
+	}
+
+	e.lastPrices[kline.Symbol] = kline.Close
+}
+
+func (e *Exchange) handleBook(book types.OrderBook, snapshot bool) {
+	e.mu.Lock()
+
+	localBook, ok := e.books[book.Symbol]
+	if !ok {
+		localBook = types.NewMutexOrderBook(book.Symbol)
+		e.books[book.Symbol] = localBook
+	}
+
+	if snapshot {
+		localBook.Load(book)
+		delete(e.consumed, book.Symbol)
+	} else {
+		localBook.Update(book)
+
+		// the updated levels carry the fresh volumes
+		if levels, ok := e.consumed[book.Symbol]; ok {
+			for _, pv := range book.Asks {
+				delete(levels, bookLevel{Side: types.SideTypeBuy, Price: pv.Price.Float64()})
+			}
+
+			for _, pv := range book.Bids {
+				delete(levels, bookLevel{Side: types.SideTypeSell, Price: pv.Price.Float64()})
+			}
+		}
+	}
+
+	var trades []types.Trade
+	var updatedOrders []types.Order
+	var openOrders []*openOrder
+	for _, o := range e.openOrders {
+		if o.Symbol == book.Symbol {
+			if orderTrades := e.match(o, true); len(orderTrades) > 0 {
+				trades = append(trades, orderTrades...)
+				e.unlockFilled(o)
+				updatedOrders = append(updatedOrders, o.order(o.status()))
+			}
+		}
+
+		if o.Remaining > 0 {
+			openOrders = append(openOrders, o)
+		}
+	}
+	e.openOrders = openOrders
+	e.mu.Unlock()
+
+	e.emitEvents(trades, updatedOrders)
+}
+
+// emitEvents emits the trades, the order updates and the balance snapshot to the streams
+func (e *Exchange) emitEvents(trades []types.Trade, orders []types.Order) {
+	if len(trades) == 0 && len(orders) == 0 {
+		return
+	}
+
+	e.mu.Lock()
+	balances := e.copyBalances()
+	streams := e.streams
+	e.mu.Unlock()
+
+	for _, stream := range streams {
+		for i := range trades {
+			trade := trades[i]
+			stream.EmitTrade(&trade)
+		}
+
+		for _, order := range orders {
+			stream.EmitOrderUpdate(order)
+		}
+
+		stream.EmitBalanceSnapshot(balances)
+	}
+}
+
+func (e *Exchange) copyBalances() types.BalanceMap {
+	var balances = make(types.BalanceMap)
+	for currency, balance := range e.balances {
+		balances[currency] = balance
+	}
+	return balances
+}
+
+func findMarket(order *types.SubmitOrder) (types.Market, bool) {
+	if len(order.Market.Symbol) > 0 {
+		return order.Market, true
+	}
+
+	return types.FindMarket(order.Symbol)
+}
+
+func round(a float64) float64 {
+	return math.Round(a*1e8) / 1e8
+}
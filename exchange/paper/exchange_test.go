@@ -0,0 +1,216 @@
+package paper
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/fixedpoint"
+	"github.com/c9s/bbgo/types"
+)
+
+func newTestBook(bids, asks [][2]float64) types.OrderBook {
+	var book = types.OrderBook{Symbol: "BTCUSDT"}
+	for _, pv := range bids {
+		book.Bids = append(book.Bids, types.PriceVolume{Price: fixedpoint.NewFromFloat(pv[0]), Volume: fixedpoint.NewFromFloat(pv[1])})
+	}
+	for _, pv := range asks {
+		book.Asks = append(book.Asks, types.PriceVolume{Price: fixedpoint.NewFromFloat(pv[0]), Volume: fixedpoint.NewFromFloat(pv[1])})
+	}
+	return book
+}
+
+func TestExchange_SubmitOrder(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("market buy walks the book", func(t *testing.T) {
+		exchange := New(nil, types.BalanceMap{"USDT": {Available: 10000.0}})
+		exchange.handleBook(newTestBook(
+			[][2]float64{{8999.0, 1.0}},
+			[][2]float64{{9000.0, 0.1}, {9001.0, 1.0}}), true)
+
+		err := exchange.SubmitOrder(ctx, &types.SubmitOrder{
+			Symbol:   "BTCUSDT",
+			Side:     types.SideTypeBuy,
+			Type:     types.OrderTypeMarket,
+			Quantity: 0.2,
+		})
+		assert.NoError(t, err)
+
+		trades, err := exchange.QueryTrades(ctx, "BTCUSDT", &types.TradeQueryOptions{})
+		assert.NoError(t, err)
+		if assert.Len(t, trades, 2) {
+			assert.Equal(t, 9000.0, trades[0].Price)
+			assert.Equal(t, 9001.0, trades[1].Price)
+			assert.Equal(t, ExchangeName, trades[0].Exchange)
+		}
+
+		balances, _ := exchange.QueryAccountBalances(ctx)
+		assert.InDelta(t, 10000.0-900.0-900.1, balances["USDT"].Available, 1e-8)
+		assert.InDelta(t, 0.0, balances["USDT"].Locked, 1e-8)
+		assert.InDelta(t, 0.2*(1-DefaultFeeRate), balances["BTC"].Available, 1e-8)
+	})
+
+	t.Run("fills consume the book liquidity until the level is updated", func(t *testing.T) {
+		exchange := New(nil, types.BalanceMap{"USDT": {Available: 10000.0}})
+		exchange.handleBook(newTestBook(
+			[][2]float64{{8999.0, 1.0}},
+			[][2]float64{{9000.0, 0.1}, {9001.0, 1.0}}), true)
+
+		buy := &types.SubmitOrder{
+			Symbol:   "BTCUSDT",
+			Side:     types.SideTypeBuy,
+			Type:     types.OrderTypeMarket,
+			Quantity: 0.1,
+		}
+		assert.NoError(t, exchange.SubmitOrder(ctx, buy))
+		assert.NoError(t, exchange.SubmitOrder(ctx, buy))
+
+		exchange.handleBook(newTestBook(nil, [][2]float64{{9000.0, 0.1}}), false)
+		assert.NoError(t, exchange.SubmitOrder(ctx, buy))
+
+		trades, err := exchange.QueryTrades(ctx, "BTCUSDT", &types.TradeQueryOptions{})
+		assert.NoError(t, err)
+		if assert.Len(t, trades, 3) {
+			assert.Equal(t, 9000.0, trades[0].Price)
+			assert.Equal(t, 9001.0, trades[1].Price)
+			assert.Equal(t, 9000.0, trades[2].Price)
+		}
+	})
+
+	t.Run("market buy locks the walked cost", func(t *testing.T) {
+		book := newTestBook(
+			[][2]float64{{8999.0, 1.0}},
+			[][2]float64{{9000.0, 0.01}, {10000.0, 1.0}})
+
+		// the walked cost is 90 + 900 = 990
+		exchange := New(nil, types.BalanceMap{"USDT": {Available: 950.0}})
+		exchange.handleBook(book, true)
+		err := exchange.SubmitOrder(ctx, &types.SubmitOrder{
+			Symbol:   "BTCUSDT",
+			Side:     types.SideTypeBuy,
+			Type:     types.OrderTypeMarket,
+			Quantity: 0.1,
+		})
+		assert.Error(t, err)
+
+		balances, _ := exchange.QueryAccountBalances(ctx)
+		assert.Equal(t, 950.0, balances["USDT"].Available)
+
+		exchange = New(nil, types.BalanceMap{"USDT": {Available: 1000.0}})
+		exchange.handleBook(book, true)
+		err = exchange.SubmitOrder(ctx, &types.SubmitOrder{
+			Symbol:   "BTCUSDT",
+			Side:     types.SideTypeBuy,
+			Type:     types.OrderTypeMarket,
+			Quantity: 0.1,
+		})
+		assert.NoError(t, err)
+
+		balances, _ = exchange.QueryAccountBalances(ctx)
+		assert.InDelta(t, 10.0, balances["USDT"].Available, 1e-8)
+		assert.InDelta(t, 0.0, balances["USDT"].Locked, 1e-8)
+	})
+
+	t.Run("resting limit sell fills on book update", func(t *testing.T) {
+		exchange := New(nil, types.BalanceMap{"BTC": {Available: 1.0}})
+		exchange.handleBook(newTestBook(
+			[][2]float64{{8999.0, 1.0}},
+			[][2]float64{{9000.0, 1.0}}), true)
+
+		err := exchange.SubmitOrder(ctx, &types.SubmitOrder{
+			Symbol:   "BTCUSDT",
+			Side:     types.SideTypeSell,
+			Type:     types.OrderTypeLimit,
+			Quantity: 0.5,
+			Price:    9100.0,
+		})
+		assert.NoError(t, err)
+		assert.Len(t, exchange.openOrders, 1)
+
+		balances, _ := exchange.QueryAccountBalances(ctx)
+		assert.Equal(t, 0.5, balances["BTC"].Locked)
+
+		exchange.handleBook(newTestBook([][2]float64{{9150.0, 1.0}}, nil), false)
+		assert.Len(t, exchange.openOrders, 0)
+
+		balances, _ = exchange.QueryAccountBalances(ctx)
+		assert.InDelta(t, 0.0, balances["BTC"].Locked, 1e-8)
+		assert.InDelta(t, 0.5*9100.0*(1-DefaultFeeRate), balances["USDT"].Available, 1e-8)
+	})
+
+	t.Run("cancel releases the locked balance", func(t *testing.T) {
+		exchange := New(nil, types.BalanceMap{"USDT": {Available: 1000.0}})
+		err := exchange.SubmitOrder(ctx, &types.SubmitOrder{
+			Symbol:   "BTCUSDT",
+			Side:     types.SideTypeBuy,
+			Type:     types.OrderTypeLimit,
+			Quantity: 0.1,
+			Price:    9000.0,
+		})
+		assert.NoError(t, err)
+
+		orders, err := exchange.QueryOpenOrders(ctx, "BTCUSDT")
+		assert.NoError(t, err)
+		assert.Len(t, orders, 1)
+
+		assert.NoError(t, exchange.CancelOrders(ctx, orders...))
+
+		orders, _ = exchange.QueryOpenOrders(ctx, "BTCUSDT")
+		assert.Len(t, orders, 0)
+
+		balances, _ := exchange.QueryAccountBalances(ctx)
+		assert.InDelta(t, 1000.0, balances["USDT"].Available, 1e-8)
+		assert.InDelta(t, 0.0, balances["USDT"].Locked, 1e-8)
+	})
+
+	t.Run("order updates are emitted", func(t *testing.T) {
+		exchange := New(nil, types.BalanceMap{"BTC": {Available: 1.0}})
+		stream := &Stream{exchange: exchange}
+		exchange.streams = append(exchange.streams, stream)
+
+		var updates []types.Order
+		stream.OnOrderUpdate(func(order types.Order) {
+			updates = append(updates, order)
+		})
+
+		exchange.handleBook(newTestBook(
+			[][2]float64{{8999.0, 1.0}},
+			[][2]float64{{9000.0, 1.0}}), true)
+
+		err := exchange.SubmitOrder(ctx, &types.SubmitOrder{
+			Symbol:   "BTCUSDT",
+			Side:     types.SideTypeSell,
+			Type:     types.OrderTypeLimit,
+			Quantity: 0.5,
+			Price:    9100.0,
+		})
+		assert.NoError(t, err)
+
+		exchange.handleBook(newTestBook([][2]float64{{9150.0, 0.2}}, nil), false)
+
+		orders, _ := exchange.QueryOpenOrders(ctx, "BTCUSDT")
+		assert.NoError(t, exchange.CancelOrders(ctx, orders...))
+
+		if assert.Len(t, updates, 3) {
+			assert.Equal(t, types.OrderStatusNew, updates[0].Status)
+			assert.Equal(t, types.OrderStatusPartiallyFilled, updates[1].Status)
+			assert.InDelta(t, 0.2, updates[1].ExecutedQuantity, 1e-8)
+			assert.Equal(t, types.OrderStatusCanceled, updates[2].Status)
+			assert.Equal(t, updates[0].OrderID, updates[2].OrderID)
+		}
+	})
+
+	t.Run("insufficient balance", func(t *testing.T) {
+		exchange := New(nil, types.BalanceMap{"USDT": {Available: 100.0}})
+		err := exchange.SubmitOrder(ctx, &types.SubmitOrder{
+			Symbol:   "BTCUSDT",
+			Side:     types.SideTypeBuy,
+			Type:     types.OrderTypeLimit,
+			Quantity: 0.1,
+			Price:    9000.0,
+		})
+		assert.Error(t, err)
+	})
+}
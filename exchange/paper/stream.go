@@ -0,0 +1,39 @@
+package paper
+
+import (
+	"context"
+
+	"github.com/c9s/bbgo/types"
+)
+
+// Stream forwards the public market data from the source stream,
+// and emits the simulated trades and balances of the paper exchange.
+type Stream struct {
+	types.StandardStream
+
+	exchange *Exchange
+	source   types.Stream
+}
+
+func (s *Stream) Subscribe(channel types.Channel, symbol string, options types.SubscribeOptions) {
+	s.StandardStream.Subscribe(channel, symbol, options)
+	s.source.Subscribe(channel, symbol, options)
+}
+
+func (s *Stream) Connect(ctx context.Context) error {
+	if err := s.source.Connect(ctx); err != nil {
+		return err
+	}
+
+	balances, err := s.exchange.QueryAccountBalances(ctx)
+	if err != nil {
+		return err
+	}
+
+	s.EmitBalanceSnapshot(balances)
+	return nil
+}
+
+func (s *Stream) Close() error {
+	return s.source.Close()
+}
@@ -0,0 +1,42 @@
+// Code generated by "go run gen.go"; DO NOT EDIT.
+
+package migrations
+
+var assets = map[string]string{
+	"mysql/20200721225616_trades.sql":                        "-- +goose Up\nCREATE TABLE `trades` (\n  `gid` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,\n\n  `id` BIGINT UNSIGNED,\n  `exchange` VARCHAR(24) NOT NULL DEFAULT '',\n  `symbol` VARCHAR(7) NOT NULL,\n  `price` DECIMAL(16, 8) UNSIGNED NOT NULL,\n  `quantity` DECIMAL(16, 8) UNSIGNED NOT NULL,\n  `quote_quantity` DECIMAL(16, 8) UNSIGNED NOT NULL,\n  `fee` DECIMAL(16, 8) UNSIGNED NOT NULL,\n  `fee_currency` VARCHAR(4) NOT NULL,\n  `is_buyer` BOOLEAN NOT NULL DEFAULT FALSE,\n  `is_maker` BOOLEAN NOT NULL DEFAULT FALSE,\n  `side` VARCHAR(4) NOT NULL DEFAULT '',\n  `traded_at` DATETIME(6) NOT NULL,\n\n  PRIMARY KEY (`gid`),\n  UNIQUE KEY `id` (`id`)\n\n) ENGINE=InnoDB;\n-- +goose Down\nDROP TABLE `trades`;\n",
+	"mysql/20200819054742_trade_index.sql":                   "-- +goose Up\nCREATE INDEX trades_symbol ON trades(symbol);\nCREATE INDEX trades_symbol_fee_currency ON trades(symbol, fee_currency, traded_at);\nCREATE INDEX trades_traded_at_symbol ON trades(traded_at, symbol);\n\n-- +goose Down\nDROP INDEX trades_symbol ON trades;\nDROP INDEX trades_symbol_fee_currency ON trades;\nDROP INDEX trades_traded_at_symbol ON trades;\n",
+	"mysql/20261018220000_stock_checkpoints.sql":             "-- +goose Up\nCREATE TABLE `stock_checkpoints` (\n  `gid` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,\n\n  `symbol` VARCHAR(12) NOT NULL,\n  `cost_basis` VARCHAR(24) NOT NULL DEFAULT '',\n\n  -- the gid of the last trade that is processed by the stock manager\n  `last_trade_gid` BIGINT UNSIGNED NOT NULL DEFAULT 0,\n\n  -- the JSON encoded stock lots and the pending sells\n  `stocks` MEDIUMTEXT NOT NULL,\n  `pending_sells` MEDIUMTEXT NOT NULL,\n\n  `updated_at` DATETIME(6) NOT NULL,\n\n  PRIMARY KEY (`gid`),\n  UNIQUE KEY `symbol` (`symbol`)\n\n) ENGINE=InnoDB;\n-- +goose Down\nDROP TABLE `stock_checkpoints`;\n",
+	"mysql/20261018230000_profits.sql":                       "-- +goose Up\nCREATE TABLE `profits` (\n  `gid` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,\n\n  `symbol` VARCHAR(12) NOT NULL,\n\n  -- the id of the sell trade\n  `trade_id` BIGINT UNSIGNED NOT NULL,\n\n  `price` DECIMAL(16, 8) UNSIGNED NOT NULL,\n\n  -- the matched quantity of the sell trade\n  `quantity` DECIMAL(16, 8) UNSIGNED NOT NULL,\n\n  -- the cost of the consumed stock lots in the quote currency\n  `cost` DECIMAL(16, 8) UNSIGNED NOT NULL,\n\n  -- the fee of the sell trade in the quote currency\n  `fee` DECIMAL(16, 8) UNSIGNED NOT NULL,\n\n  -- the realized profit in the quote currency, the fee is deducted\n  `profit` DECIMAL(16, 8) NOT NULL,\n\n  -- the quantity weighted average holding time of the consumed stock lots\n  `holding_seconds` BIGINT UNSIGNED NOT NULL DEFAULT 0,\n\n  `traded_at` DATETIME(6) NOT NULL,\n\n  PRIMARY KEY (`gid`),\n  UNIQUE KEY `symbol_trade_id` (`symbol`, `trade_id`),\n  INDEX `profits_traded_at_symbol` (`traded_at`, `symbol`)\n\n) ENGINE=InnoDB;\n-- +goose Down\nDROP TABLE `profits`;\n",
+	"mysql/20261019000000_deposits_withdraws.sql":            "-- +goose Up\nCREATE TABLE `deposits` (\n  `gid` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,\n\n  `exchange` VARCHAR(24) NOT NULL,\n\n  -- asset is the asset name (currency)\n  `asset` VARCHAR(10) NOT NULL,\n\n  `address` VARCHAR(128) NOT NULL DEFAULT '',\n  `address_tag` VARCHAR(128) NOT NULL DEFAULT '',\n  `amount` DECIMAL(16, 8) NOT NULL,\n\n  -- the transaction id of the deposit, it's the internal id on some exchanges\n  `txn_id` VARCHAR(256) NOT NULL,\n\n  `status` VARCHAR(20) NOT NULL,\n\n  `time` DATETIME(6) NOT NULL,\n\n  PRIMARY KEY (`gid`),\n  UNIQUE KEY `deposits_exchange_txn_id` (`exchange`, `txn_id`(128)),\n  INDEX `deposits_asset_time` (`asset`, `time`)\n\n) ENGINE=InnoDB;\n\nCREATE TABLE `withdraws` (\n  `gid` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,\n\n  `exchange` VARCHAR(24) NOT NULL,\n\n  -- the id of the withdraw on the exchange\n  `id` VARCHAR(64) NOT NULL,\n\n  -- asset is the asset name (currency)\n  `asset` VARCHAR(10) NOT NULL,\n\n  `address` VARCHAR(128) NOT NULL DEFAULT '',\n  `address_tag` VARCHAR(128) NOT NULL DEFAULT '',\n  `network` VARCHAR(32) NOT NULL DEFAULT '',\n  `amount` DECIMAL(16, 8) NOT NULL,\n\n  `txn_id` VARCHAR(256) NOT NULL DEFAULT '',\n  `txn_fee` DECIMAL(16, 8) NOT NULL DEFAULT 0,\n\n  `withdraw_order_id` VARCHAR(64) NOT NULL DEFAULT '',\n\n  `status` VARCHAR(20) NOT NULL,\n\n  `time` DATETIME(6) NOT NULL,\n\n  PRIMARY KEY (`gid`),\n  UNIQUE KEY `withdraws_exchange_id` (`exchange`, `id`),\n  INDEX `withdraws_asset_time` (`asset`, `time`)\n\n) ENGINE=InnoDB;\n-- +goose Down\nDROP TABLE `deposits`;\nDROP TABLE `withdraws`;\n",
+	"mysql/20261019010000_trades_exchange_unique_key.sql":    "-- +goose Up\nALTER TABLE `trades`\n  DROP INDEX `id`,\n  MODIFY COLUMN `symbol` VARCHAR(20) NOT NULL,\n  MODIFY COLUMN `fee_currency` VARCHAR(10) NOT NULL,\n  ADD COLUMN `order_id` BIGINT UNSIGNED NOT NULL DEFAULT 0 AFTER `exchange`,\n  ADD COLUMN `is_margin` BOOLEAN NOT NULL DEFAULT FALSE AFTER `is_maker`,\n  ADD UNIQUE KEY `trades_exchange_symbol_id` (`exchange`, `symbol`, `id`),\n  ADD INDEX `trades_exchange_order_id` (`exchange`, `order_id`);\n\n-- +goose Down\nALTER TABLE `trades`\n  DROP INDEX `trades_exchange_symbol_id`,\n  DROP INDEX `trades_exchange_order_id`,\n  DROP COLUMN `order_id`,\n  DROP COLUMN `is_margin`,\n  MODIFY COLUMN `symbol` VARCHAR(7) NOT NULL,\n  MODIFY COLUMN `fee_currency` VARCHAR(4) NOT NULL,\n  ADD UNIQUE KEY `id` (`id`);\n",
+	"mysql/20261019020000_orders.sql":                        "-- +goose Up\nCREATE TABLE `orders` (\n  `gid` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,\n\n  `exchange` VARCHAR(24) NOT NULL DEFAULT '',\n\n  -- order_id is the order id returned from the exchange\n  `order_id` BIGINT UNSIGNED NOT NULL,\n  `client_order_id` VARCHAR(64) NOT NULL DEFAULT '',\n  `order_type` VARCHAR(16) NOT NULL,\n\n  `symbol` VARCHAR(20) NOT NULL,\n  `status` VARCHAR(20) NOT NULL,\n  `time_in_force` VARCHAR(4) NOT NULL DEFAULT '',\n  `side` VARCHAR(4) NOT NULL,\n\n  `price` DECIMAL(16, 8) UNSIGNED NOT NULL,\n  `quantity` DECIMAL(16, 8) UNSIGNED NOT NULL,\n  `executed_quantity` DECIMAL(16, 8) UNSIGNED NOT NULL DEFAULT 0.0,\n\n  `created_at` DATETIME(6) NOT NULL,\n  `updated_at` DATETIME(6) NOT NULL,\n\n  PRIMARY KEY (`gid`),\n  UNIQUE KEY `orders_exchange_order_id` (`exchange`, `order_id`),\n  INDEX `orders_exchange_symbol_created_at` (`exchange`, `symbol`, `created_at`)\n\n) ENGINE=InnoDB;\n-- +goose Down\nDROP TABLE `orders`;\n",
+	"mysql/20261019030000_position_exits.sql":                "-- +goose Up\nCREATE TABLE `position_exits` (\n  `gid` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,\n\n  `exchange` VARCHAR(24) NOT NULL DEFAULT '',\n  `symbol` VARCHAR(20) NOT NULL,\n\n  `entry_price` DECIMAL(16, 8) UNSIGNED NOT NULL,\n  `initial_quantity` DECIMAL(16, 8) UNSIGNED NOT NULL,\n\n  -- quantity is the remaining quantity of the position\n  `quantity` DECIMAL(16, 8) UNSIGNED NOT NULL,\n\n  -- peak_price is the highest price since the entry, it's used by the trailing stop\n  `peak_price` DECIMAL(16, 8) UNSIGNED NOT NULL,\n\n  -- take_profits is the number of the triggered take-profit levels\n  `take_profits` INT UNSIGNED NOT NULL DEFAULT 0,\n\n  `opened_at` DATETIME(6) NOT NULL,\n  `updated_at` DATETIME(6) NOT NULL,\n\n  PRIMARY KEY (`gid`),\n  UNIQUE KEY `position_exits_exchange_symbol` (`exchange`, `symbol`)\n\n) ENGINE=InnoDB;\n-- +goose Down\nDROP TABLE `position_exits`;\n",
+	"mysql/20261019040000_profits_exchange.sql":              "-- +goose Up\nALTER TABLE `profits`\n  DROP INDEX `symbol_trade_id`,\n  ADD COLUMN `exchange` VARCHAR(24) NOT NULL DEFAULT '' AFTER `gid`,\n  ADD UNIQUE KEY `profits_exchange_symbol_trade_id` (`exchange`, `symbol`, `trade_id`);\n\n-- the exchange of the stored profits is filled by the sell trades\nUPDATE `profits` SET `exchange` = COALESCE((\n  SELECT `trades`.`exchange` FROM `trades`\n  WHERE `trades`.`symbol` = `profits`.`symbol` AND `trades`.`id` = `profits`.`trade_id` AND `trades`.`is_buyer` = FALSE\n  LIMIT 1\n), '');\n\n-- +goose Down\nALTER TABLE `profits`\n  DROP INDEX `profits_exchange_symbol_trade_id`,\n  DROP COLUMN `exchange`,\n  ADD UNIQUE KEY `symbol_trade_id` (`symbol`, `trade_id`);\n",
+	"mysql/20261019050000_trades_backfill.sql":               "-- +goose Up\n-- the binance trades synced before the exchange was filled have the empty exchange,\n-- the rows that are synced again with the exchange are kept\nDELETE `t1` FROM `trades` AS `t1` JOIN `trades` AS `t2`\n  ON `t2`.`exchange` = 'binance' AND `t2`.`symbol` = `t1`.`symbol` AND `t2`.`id` = `t1`.`id`\nWHERE `t1`.`exchange` = '';\nUPDATE `trades` SET `exchange` = 'binance' WHERE `exchange` = '';\n\n-- the max trades were stored with the lower case local symbols and fee currencies,\n-- the symbols are compared in binary since the default collation is case insensitive\nDELETE `t1` FROM `trades` AS `t1` JOIN `trades` AS `t2`\n  ON `t2`.`exchange` = 'max' AND BINARY `t2`.`symbol` = BINARY UPPER(`t1`.`symbol`) AND `t2`.`id` = `t1`.`id` AND `t2`.`gid` <> `t1`.`gid`\nWHERE `t1`.`exchange` = 'max' AND BINARY `t1`.`symbol` <> BINARY UPPER(`t1`.`symbol`);\nUPDATE `trades` SET `symbol` = UPPER(`symbol`), `fee_currency` = UPPER(`fee_currency`) WHERE `exchange` = 'max';\n\nDELETE `p1` FROM `profits` AS `p1` JOIN `profits` AS `p2`\n  ON `p2`.`exchange` = 'binance' AND `p2`.`symbol` = `p1`.`symbol` AND `p2`.`trade_id` = `p1`.`trade_id`\nWHERE `p1`.`exchange` = '';\nUPDATE `profits` SET `exchange` = 'binance' WHERE `exchange` = '';\n\n-- the stock checkpoints are rebuilt with the backfilled trades on the next start\nDELETE FROM `stock_checkpoints`;\n\n-- +goose Down\n-- the backfilled values can not be told apart from the synced values, nothing is reverted\nSELECT 1;\n",
+	"mysql/20261019060000_strategy_states.sql":               "-- +goose Up\nCREATE TABLE `strategy_states` (\n  `gid` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,\n\n  -- state_key identifies the strategy instance, e.g., xmaker:max:binance:BTCUSDT\n  `state_key` VARCHAR(128) NOT NULL,\n\n  -- state is the JSON encoded state of the strategy\n  `state` TEXT NOT NULL,\n\n  `updated_at` DATETIME(6) NOT NULL,\n\n  PRIMARY KEY (`gid`),\n  UNIQUE KEY `strategy_states_state_key` (`state_key`)\n\n) ENGINE=InnoDB;\n-- +goose Down\nDROP TABLE `strategy_states`;\n",
+	"mysql/20261019070000_stock_checkpoints_exchange.sql":    "-- +goose Up\n-- the stored checkpoints can not be told apart by the exchange, they are rebuilt from the trades on the next start\nDELETE FROM `stock_checkpoints`;\n\nALTER TABLE `stock_checkpoints`\n  DROP INDEX `symbol`,\n  ADD COLUMN `exchange` VARCHAR(24) NOT NULL DEFAULT '' AFTER `gid`,\n  ADD UNIQUE KEY `stock_checkpoints_exchange_symbol` (`exchange`, `symbol`);\n\n-- +goose Down\nDELETE FROM `stock_checkpoints`;\n\nALTER TABLE `stock_checkpoints`\n  DROP INDEX `stock_checkpoints_exchange_symbol`,\n  DROP COLUMN `exchange`,\n  ADD UNIQUE KEY `symbol` (`symbol`);\n",
+	"postgres/20200721225616_trades.sql":                     "-- +goose Up\nCREATE TABLE trades (\n  gid BIGSERIAL PRIMARY KEY,\n\n  id BIGINT,\n  exchange VARCHAR(24) NOT NULL DEFAULT '',\n  symbol VARCHAR(7) NOT NULL,\n  price NUMERIC(16, 8) NOT NULL,\n  quantity NUMERIC(16, 8) NOT NULL,\n  quote_quantity NUMERIC(16, 8) NOT NULL,\n  fee NUMERIC(16, 8) NOT NULL,\n  fee_currency VARCHAR(4) NOT NULL,\n  is_buyer BOOLEAN NOT NULL DEFAULT FALSE,\n  is_maker BOOLEAN NOT NULL DEFAULT FALSE,\n  side VARCHAR(4) NOT NULL DEFAULT '',\n  traded_at TIMESTAMP(6) NOT NULL,\n\n  CONSTRAINT trades_id UNIQUE (id)\n);\n-- +goose Down\nDROP TABLE trades;\n",
+	"postgres/20200819054742_trade_index.sql":                "-- +goose Up\nCREATE INDEX trades_symbol ON trades(symbol);\nCREATE INDEX trades_symbol_fee_currency ON trades(symbol, fee_currency, traded_at);\nCREATE INDEX trades_traded_at_symbol ON trades(traded_at, symbol);\n\n-- +goose Down\nDROP INDEX trades_symbol;\nDROP INDEX trades_symbol_fee_currency;\nDROP INDEX trades_traded_at_symbol;\n",
+	"postgres/20261018220000_stock_checkpoints.sql":          "-- +goose Up\nCREATE TABLE stock_checkpoints (\n  gid BIGSERIAL PRIMARY KEY,\n\n  symbol VARCHAR(12) NOT NULL,\n  cost_basis VARCHAR(24) NOT NULL DEFAULT '',\n\n  -- the gid of the last trade that is processed by the stock manager\n  last_trade_gid BIGINT NOT NULL DEFAULT 0,\n\n  -- the JSON encoded stock lots and the pending sells\n  stocks TEXT NOT NULL,\n  pending_sells TEXT NOT NULL,\n\n  updated_at TIMESTAMP(6) NOT NULL,\n\n  CONSTRAINT stock_checkpoints_symbol UNIQUE (symbol)\n);\n-- +goose Down\nDROP TABLE stock_checkpoints;\n",
+	"postgres/20261018230000_profits.sql":                    "-- +goose Up\nCREATE TABLE profits (\n  gid BIGSERIAL PRIMARY KEY,\n\n  symbol VARCHAR(12) NOT NULL,\n\n  -- the id of the sell trade\n  trade_id BIGINT NOT NULL,\n\n  price NUMERIC(16, 8) NOT NULL,\n\n  -- the matched quantity of the sell trade\n  quantity NUMERIC(16, 8) NOT NULL,\n\n  -- the cost of the consumed stock lots in the quote currency\n  cost NUMERIC(16, 8) NOT NULL,\n\n  -- the fee of the sell trade in the quote currency\n  fee NUMERIC(16, 8) NOT NULL,\n\n  -- the realized profit in the quote currency, the fee is deducted\n  profit NUMERIC(16, 8) NOT NULL,\n\n  -- the quantity weighted average holding time of the consumed stock lots\n  holding_seconds BIGINT NOT NULL DEFAULT 0,\n\n  traded_at TIMESTAMP(6) NOT NULL,\n\n  CONSTRAINT profits_symbol_trade_id UNIQUE (symbol, trade_id)\n);\n\nCREATE INDEX profits_traded_at_symbol ON profits (traded_at, symbol);\n-- +goose Down\nDROP TABLE profits;\n",
+	"postgres/20261019000000_deposits_withdraws.sql":         "-- +goose Up\nCREATE TABLE deposits (\n  gid BIGSERIAL PRIMARY KEY,\n\n  exchange VARCHAR(24) NOT NULL,\n\n  -- asset is the asset name (currency)\n  asset VARCHAR(10) NOT NULL,\n\n  address VARCHAR(128) NOT NULL DEFAULT '',\n  address_tag VARCHAR(128) NOT NULL DEFAULT '',\n  amount NUMERIC(16, 8) NOT NULL,\n\n  -- the transaction id of the deposit, it's the internal id on some exchanges\n  txn_id VARCHAR(256) NOT NULL,\n\n  status VARCHAR(20) NOT NULL,\n\n  time TIMESTAMP(6) NOT NULL,\n\n  CONSTRAINT deposits_exchange_txn_id UNIQUE (exchange, txn_id)\n);\n\nCREATE INDEX deposits_asset_time ON deposits (asset, time);\n\nCREATE TABLE withdraws (\n  gid BIGSERIAL PRIMARY KEY,\n\n  exchange VARCHAR(24) NOT NULL,\n\n  -- the id of the withdraw on the exchange\n  id VARCHAR(64) NOT NULL,\n\n  -- asset is the asset name (currency)\n  asset VARCHAR(10) NOT NULL,\n\n  address VARCHAR(128) NOT NULL DEFAULT '',\n  address_tag VARCHAR(128) NOT NULL DEFAULT '',\n  network VARCHAR(32) NOT NULL DEFAULT '',\n  amount NUMERIC(16, 8) NOT NULL,\n\n  txn_id VARCHAR(256) NOT NULL DEFAULT '',\n  txn_fee NUMERIC(16, 8) NOT NULL DEFAULT 0,\n\n  withdraw_order_id VARCHAR(64) NOT NULL DEFAULT '',\n\n  status VARCHAR(20) NOT NULL,\n\n  time TIMESTAMP(6) NOT NULL,\n\n  CONSTRAINT withdraws_exchange_id UNIQUE (exchange, id)\n);\n\nCREATE INDEX withdraws_asset_time ON withdraws (asset, time);\n-- +goose Down\nDROP TABLE deposits;\nDROP TABLE withdraws;\n",
+	"postgres/20261019010000_trades_exchange_unique_key.sql": "-- +goose Up\nALTER TABLE trades\n  DROP CONSTRAINT trades_id,\n  ALTER COLUMN symbol TYPE VARCHAR(20),\n  ALTER COLUMN fee_currency TYPE VARCHAR(10),\n  ADD COLUMN order_id BIGINT NOT NULL DEFAULT 0,\n  ADD COLUMN is_margin BOOLEAN NOT NULL DEFAULT FALSE,\n  ADD CONSTRAINT trades_exchange_symbol_id UNIQUE (exchange, symbol, id);\n\nCREATE INDEX trades_exchange_order_id ON trades (exchange, order_id);\n\n-- +goose Down\nDROP INDEX trades_exchange_order_id;\n\nALTER TABLE trades\n  DROP CONSTRAINT trades_exchange_symbol_id,\n  DROP COLUMN order_id,\n  DROP COLUMN is_margin,\n  ALTER COLUMN symbol TYPE VARCHAR(7),\n  ALTER COLUMN fee_currency TYPE VARCHAR(4),\n  ADD CONSTRAINT trades_id UNIQUE (id);\n",
+	"postgres/20261019020000_orders.sql":                     "-- +goose Up\nCREATE TABLE orders (\n  gid BIGSERIAL PRIMARY KEY,\n\n  exchange VARCHAR(24) NOT NULL DEFAULT '',\n\n  -- order_id is the order id returned from the exchange\n  order_id BIGINT NOT NULL,\n  client_order_id VARCHAR(64) NOT NULL DEFAULT '',\n  order_type VARCHAR(16) NOT NULL,\n\n  symbol VARCHAR(20) NOT NULL,\n  status VARCHAR(20) NOT NULL,\n  time_in_force VARCHAR(4) NOT NULL DEFAULT '',\n  side VARCHAR(4) NOT NULL,\n\n  price NUMERIC(16, 8) NOT NULL,\n  quantity NUMERIC(16, 8) NOT NULL,\n  executed_quantity NUMERIC(16, 8) NOT NULL DEFAULT 0.0,\n\n  created_at TIMESTAMP(6) NOT NULL,\n  updated_at TIMESTAMP(6) NOT NULL,\n\n  CONSTRAINT orders_exchange_order_id UNIQUE (exchange, order_id)\n);\n\nCREATE INDEX orders_exchange_symbol_created_at ON orders (exchange, symbol, created_at);\n-- +goose Down\nDROP TABLE orders;\n",
+	"postgres/20261019030000_position_exits.sql":             "-- +goose Up\nCREATE TABLE position_exits (\n  gid BIGSERIAL PRIMARY KEY,\n\n  exchange VARCHAR(24) NOT NULL DEFAULT '',\n  symbol VARCHAR(20) NOT NULL,\n\n  entry_price NUMERIC(16, 8) NOT NULL,\n  initial_quantity NUMERIC(16, 8) NOT NULL,\n\n  -- quantity is the remaining quantity of the position\n  quantity NUMERIC(16, 8) NOT NULL,\n\n  -- peak_price is the highest price since the entry, it's used by the trailing stop\n  peak_price NUMERIC(16, 8) NOT NULL,\n\n  -- take_profits is the number of the triggered take-profit levels\n  take_profits INTEGER NOT NULL DEFAULT 0,\n\n  opened_at TIMESTAMP(6) NOT NULL,\n  updated_at TIMESTAMP(6) NOT NULL,\n\n  CONSTRAINT position_exits_exchange_symbol UNIQUE (exchange, symbol)\n);\n-- +goose Down\nDROP TABLE position_exits;\n",
+	"postgres/20261019040000_profits_exchange.sql":           "-- +goose Up\nALTER TABLE profits\n  DROP CONSTRAINT profits_symbol_trade_id,\n  ADD COLUMN exchange VARCHAR(24) NOT NULL DEFAULT '',\n  ADD CONSTRAINT profits_exchange_symbol_trade_id UNIQUE (exchange, symbol, trade_id);\n\n-- the exchange of the stored profits is filled by the sell trades\nUPDATE profits SET exchange = COALESCE((\n  SELECT trades.exchange FROM trades\n  WHERE trades.symbol = profits.symbol AND trades.id = profits.trade_id AND trades.is_buyer = FALSE\n  LIMIT 1\n), '');\n\n-- +goose Down\nALTER TABLE profits\n  DROP CONSTRAINT profits_exchange_symbol_trade_id,\n  DROP COLUMN exchange,\n  ADD CONSTRAINT profits_symbol_trade_id UNIQUE (symbol, trade_id);\n",
+	"postgres/20261019050000_trades_backfill.sql":            "-- +goose Up\n-- the binance trades synced before the exchange was filled have the empty exchange,\n-- the rows that are synced again with the exchange are kept\nDELETE FROM trades WHERE exchange = '' AND EXISTS (\n  SELECT 1 FROM trades AS t WHERE t.exchange = 'binance' AND t.symbol = trades.symbol AND t.id = trades.id\n);\nUPDATE trades SET exchange = 'binance' WHERE exchange = '';\n\n-- the max trades were stored with the lower case local symbols and fee currencies\nDELETE FROM trades WHERE exchange = 'max' AND symbol <> UPPER(symbol) AND EXISTS (\n  SELECT 1 FROM trades AS t WHERE t.exchange = 'max' AND t.symbol = UPPER(trades.symbol) AND t.id = trades.id\n);\nUPDATE trades SET symbol = UPPER(symbol), fee_currency = UPPER(fee_currency) WHERE exchange = 'max';\n\nDELETE FROM profits WHERE exchange = '' AND EXISTS (\n  SELECT 1 FROM profits AS p WHERE p.exchange = 'binance' AND p.symbol = profits.symbol AND p.trade_id = profits.trade_id\n);\nUPDATE profits SET exchange = 'binance' WHERE exchange = '';\n\n-- the stock checkpoints are rebuilt with the backfilled trades on the next start\nDELETE FROM stock_checkpoints;\n\n-- +goose Down\n-- the backfilled values can not be told apart from the synced values, nothing is reverted\nSELECT 1;\n",
+	"postgres/20261019060000_strategy_states.sql":            "-- +goose Up\nCREATE TABLE strategy_states (\n  gid BIGSERIAL PRIMARY KEY,\n\n  -- state_key identifies the strategy instance, e.g., xmaker:max:binance:BTCUSDT\n  state_key VARCHAR(128) NOT NULL,\n\n  -- state is the JSON encoded state of the strategy\n  state TEXT NOT NULL,\n\n  updated_at TIMESTAMP(6) NOT NULL,\n\n  CONSTRAINT strategy_states_state_key UNIQUE (state_key)\n);\n-- +goose Down\nDROP TABLE strategy_states;\n",
+	"postgres/20261019070000_stock_checkpoints_exchange.sql": "-- +goose Up\n-- the stored checkpoints can not be told apart by the exchange, they are rebuilt from the trades on the next start\nDELETE FROM stock_checkpoints;\n\nALTER TABLE stock_checkpoints\n  DROP CONSTRAINT stock_checkpoints_symbol,\n  ADD COLUMN exchange VARCHAR(24) NOT NULL DEFAULT '',\n  ADD CONSTRAINT stock_checkpoints_exchange_symbol UNIQUE (exchange, symbol);\n\n-- +goose Down\nDELETE FROM stock_checkpoints;\n\nALTER TABLE stock_checkpoints\n  DROP CONSTRAINT stock_checkpoints_exchange_symbol,\n  DROP COLUMN exchange,\n  ADD CONSTRAINT stock_checkpoints_symbol UNIQUE (symbol);\n",
+	"sqlite3/20200721225616_trades.sql":                      "-- +goose Up\nCREATE TABLE `trades` (\n  `gid` INTEGER PRIMARY KEY AUTOINCREMENT,\n\n  `id` INTEGER,\n  `exchange` VARCHAR(24) NOT NULL DEFAULT '',\n  `symbol` VARCHAR(7) NOT NULL,\n  `price` DECIMAL(16, 8) NOT NULL,\n  `quantity` DECIMAL(16, 8) NOT NULL,\n  `quote_quantity` DECIMAL(16, 8) NOT NULL,\n  `fee` DECIMAL(16, 8) NOT NULL,\n  `fee_currency` VARCHAR(4) NOT NULL,\n  `is_buyer` BOOLEAN NOT NULL DEFAULT FALSE,\n  `is_maker` BOOLEAN NOT NULL DEFAULT FALSE,\n  `side` VARCHAR(4) NOT NULL DEFAULT '',\n  `traded_at` DATETIME NOT NULL\n);\n\nCREATE UNIQUE INDEX `trades_id` ON `trades` (`id`);\n-- +goose Down\nDROP TABLE `trades`;\n",
+	"sqlite3/20200819054742_trade_index.sql":                 "-- +goose Up\nCREATE INDEX trades_symbol ON trades(symbol);\nCREATE INDEX trades_symbol_fee_currency ON trades(symbol, fee_currency, traded_at);\nCREATE INDEX trades_traded_at_symbol ON trades(traded_at, symbol);\n\n-- +goose Down\nDROP INDEX trades_symbol;\nDROP INDEX trades_symbol_fee_currency;\nDROP INDEX trades_traded_at_symbol;\n",
+	"sqlite3/20261018220000_stock_checkpoints.sql":           "-- +goose Up\nCREATE TABLE `stock_checkpoints` (\n  `gid` INTEGER PRIMARY KEY AUTOINCREMENT,\n\n  `symbol` VARCHAR(12) NOT NULL,\n  `cost_basis` VARCHAR(24) NOT NULL DEFAULT '',\n\n  -- the gid of the last trade that is processed by the stock manager\n  `last_trade_gid` INTEGER NOT NULL DEFAULT 0,\n\n  -- the JSON encoded stock lots and the pending sells\n  `stocks` TEXT NOT NULL,\n  `pending_sells` TEXT NOT NULL,\n\n  `updated_at` DATETIME NOT NULL\n);\n\nCREATE UNIQUE INDEX `stock_checkpoints_symbol` ON `stock_checkpoints` (`symbol`);\n-- +goose Down\nDROP TABLE `stock_checkpoints`;\n",
+	"sqlite3/20261018230000_profits.sql":                     "-- +goose Up\nCREATE TABLE `profits` (\n  `gid` INTEGER PRIMARY KEY AUTOINCREMENT,\n\n  `symbol` VARCHAR(12) NOT NULL,\n\n  -- the id of the sell trade\n  `trade_id` INTEGER NOT NULL,\n\n  `price` DECIMAL(16, 8) NOT NULL,\n\n  -- the matched quantity of the sell trade\n  `quantity` DECIMAL(16, 8) NOT NULL,\n\n  -- the cost of the consumed stock lots in the quote currency\n  `cost` DECIMAL(16, 8) NOT NULL,\n\n  -- the fee of the sell trade in the quote currency\n  `fee` DECIMAL(16, 8) NOT NULL,\n\n  -- the realized profit in the quote currency, the fee is deducted\n  `profit` DECIMAL(16, 8) NOT NULL,\n\n  -- the quantity weighted average holding time of the consumed stock lots\n  `holding_seconds` INTEGER NOT NULL DEFAULT 0,\n\n  `traded_at` DATETIME NOT NULL\n);\n\nCREATE UNIQUE INDEX `profits_symbol_trade_id` ON `profits` (`symbol`, `trade_id`);\nCREATE INDEX `profits_traded_at_symbol` ON `profits` (`traded_at`, `symbol`);\n-- +goose Down\nDROP TABLE `profits`;\n",
+	"sqlite3/20261019000000_deposits_withdraws.sql":          "-- +goose Up\nCREATE TABLE `deposits` (\n  `gid` INTEGER PRIMARY KEY AUTOINCREMENT,\n\n  `exchange` VARCHAR(24) NOT NULL,\n\n  -- asset is the asset name (currency)\n  `asset` VARCHAR(10) NOT NULL,\n\n  `address` VARCHAR(128) NOT NULL DEFAULT '',\n  `address_tag` VARCHAR(128) NOT NULL DEFAULT '',\n  `amount` DECIMAL(16, 8) NOT NULL,\n\n  -- the transaction id of the deposit, it's the internal id on some exchanges\n  `txn_id` VARCHAR(256) NOT NULL,\n\n  `status` VARCHAR(20) NOT NULL,\n\n  `time` DATETIME NOT NULL\n);\n\nCREATE UNIQUE INDEX `deposits_exchange_txn_id` ON `deposits` (`exchange`, `txn_id`);\nCREATE INDEX `deposits_asset_time` ON `deposits` (`asset`, `time`);\n\nCREATE TABLE `withdraws` (\n  `gid` INTEGER PRIMARY KEY AUTOINCREMENT,\n\n  `exchange` VARCHAR(24) NOT NULL,\n\n  -- the id of the withdraw on the exchange\n  `id` VARCHAR(64) NOT NULL,\n\n  -- asset is the asset name (currency)\n  `asset` VARCHAR(10) NOT NULL,\n\n  `address` VARCHAR(128) NOT NULL DEFAULT '',\n  `address_tag` VARCHAR(128) NOT NULL DEFAULT '',\n  `network` VARCHAR(32) NOT NULL DEFAULT '',\n  `amount` DECIMAL(16, 8) NOT NULL,\n\n  `txn_id` VARCHAR(256) NOT NULL DEFAULT '',\n  `txn_fee` DECIMAL(16, 8) NOT NULL DEFAULT 0,\n\n  `withdraw_order_id` VARCHAR(64) NOT NULL DEFAULT '',\n\n  `status` VARCHAR(20) NOT NULL,\n\n  `time` DATETIME NOT NULL\n);\n\nCREATE UNIQUE INDEX `withdraws_exchange_id` ON `withdraws` (`exchange`, `id`);\nCREATE INDEX `withdraws_asset_time` ON `withdraws` (`asset`, `time`);\n-- +goose Down\nDROP TABLE `deposits`;\nDROP TABLE `withdraws`;\n",
+	"sqlite3/20261019010000_trades_exchange_unique_key.sql":  "-- +goose Up\n-- sqlite does not check the varchar length, only the unique key and the new columns are changed\nDROP INDEX `trades_id`;\nALTER TABLE `trades` ADD COLUMN `order_id` INTEGER NOT NULL DEFAULT 0;\nALTER TABLE `trades` ADD COLUMN `is_margin` BOOLEAN NOT NULL DEFAULT FALSE;\nCREATE UNIQUE INDEX `trades_exchange_symbol_id` ON `trades` (`exchange`, `symbol`, `id`);\nCREATE INDEX `trades_exchange_order_id` ON `trades` (`exchange`, `order_id`);\n\n-- +goose Down\n-- sqlite can not drop columns, the table is rebuilt without the new columns\nDROP INDEX `trades_exchange_symbol_id`;\nDROP INDEX `trades_exchange_order_id`;\nDROP INDEX trades_symbol;\nDROP INDEX trades_symbol_fee_currency;\nDROP INDEX trades_traded_at_symbol;\nALTER TABLE `trades` RENAME TO `trades_old`;\nCREATE TABLE `trades` (\n  `gid` INTEGER PRIMARY KEY AUTOINCREMENT,\n\n  `id` INTEGER,\n  `exchange` VARCHAR(24) NOT NULL DEFAULT '',\n  `symbol` VARCHAR(7) NOT NULL,\n  `price` DECIMAL(16, 8) NOT NULL,\n  `quantity` DECIMAL(16, 8) NOT NULL,\n  `quote_quantity` DECIMAL(16, 8) NOT NULL,\n  `fee` DECIMAL(16, 8) NOT NULL,\n  `fee_currency` VARCHAR(4) NOT NULL,\n  `is_buyer` BOOLEAN NOT NULL DEFAULT FALSE,\n  `is_maker` BOOLEAN NOT NULL DEFAULT FALSE,\n  `side` VARCHAR(4) NOT NULL DEFAULT '',\n  `traded_at` DATETIME NOT NULL\n);\nINSERT INTO `trades` (`gid`, `id`, `exchange`, `symbol`, `price`, `quantity`, `quote_quantity`, `fee`, `fee_currency`, `is_buyer`, `is_maker`, `side`, `traded_at`)\n  SELECT `gid`, `id`, `exchange`, `symbol`, `price`, `quantity`, `quote_quantity`, `fee`, `fee_currency`, `is_buyer`, `is_maker`, `side`, `traded_at` FROM `trades_old`;\nDROP TABLE `trades_old`;\nCREATE UNIQUE INDEX `trades_id` ON `trades` (`id`);\nCREATE INDEX trades_symbol ON trades(symbol);\nCREATE INDEX trades_symbol_fee_currency ON trades(symbol, fee_currency, traded_at);\nCREATE INDEX trades_traded_at_symbol ON trades(traded_at, symbol);\n",
+	"sqlite3/20261019020000_orders.sql":                      "-- +goose Up\nCREATE TABLE `orders` (\n  `gid` INTEGER PRIMARY KEY AUTOINCREMENT,\n\n  `exchange` VARCHAR(24) NOT NULL DEFAULT '',\n\n  -- order_id is the order id returned from the exchange\n  `order_id` INTEGER NOT NULL,\n  `client_order_id` VARCHAR(64) NOT NULL DEFAULT '',\n  `order_type` VARCHAR(16) NOT NULL,\n\n  `symbol` VARCHAR(20) NOT NULL,\n  `status` VARCHAR(20) NOT NULL,\n  `time_in_force` VARCHAR(4) NOT NULL DEFAULT '',\n  `side` VARCHAR(4) NOT NULL,\n\n  `price` DECIMAL(16, 8) NOT NULL,\n  `quantity` DECIMAL(16, 8) NOT NULL,\n  `executed_quantity` DECIMAL(16, 8) NOT NULL DEFAULT 0.0,\n\n  `created_at` DATETIME NOT NULL,\n  `updated_at` DATETIME NOT NULL\n);\n\nCREATE UNIQUE INDEX `orders_exchange_order_id` ON `orders` (`exchange`, `order_id`);\nCREATE INDEX `orders_exchange_symbol_created_at` ON `orders` (`exchange`, `symbol`, `created_at`);\n-- +goose Down\nDROP TABLE `orders`;\n",
+	"sqlite3/20261019030000_position_exits.sql":              "-- +goose Up\nCREATE TABLE `position_exits` (\n  `gid` INTEGER PRIMARY KEY AUTOINCREMENT,\n\n  `exchange` VARCHAR(24) NOT NULL DEFAULT '',\n  `symbol` VARCHAR(20) NOT NULL,\n\n  `entry_price` DECIMAL(16, 8) NOT NULL,\n  `initial_quantity` DECIMAL(16, 8) NOT NULL,\n\n  -- quantity is the remaining quantity of the position\n  `quantity` DECIMAL(16, 8) NOT NULL,\n\n  -- peak_price is the highest price since the entry, it's used by the trailing stop\n  `peak_price` DECIMAL(16, 8) NOT NULL,\n\n  -- take_profits is the number of the triggered take-profit levels\n  `take_profits` INTEGER NOT NULL DEFAULT 0,\n\n  `opened_at` DATETIME NOT NULL,\n  `updated_at` DATETIME NOT NULL\n);\n\nCREATE UNIQUE INDEX `position_exits_exchange_symbol` ON `position_exits` (`exchange`, `symbol`);\n-- +goose Down\nDROP TABLE `position_exits`;\n",
+	"sqlite3/20261019040000_profits_exchange.sql":            "-- +goose Up\nDROP INDEX `profits_symbol_trade_id`;\nALTER TABLE `profits` ADD COLUMN `exchange` VARCHAR(24) NOT NULL DEFAULT '';\nCREATE UNIQUE INDEX `profits_exchange_symbol_trade_id` ON `profits` (`exchange`, `symbol`, `trade_id`);\n\n-- the exchange of the stored profits is filled by the sell trades\nUPDATE `profits` SET `exchange` = COALESCE((\n  SELECT `trades`.`exchange` FROM `trades`\n  WHERE `trades`.`symbol` = `profits`.`symbol` AND `trades`.`id` = `profits`.`trade_id` AND `trades`.`is_buyer` = FALSE\n  LIMIT 1\n), '');\n\n-- +goose Down\n-- sqlite can not drop columns, the table is rebuilt without the exchange column\nDROP INDEX `profits_exchange_symbol_trade_id`;\nDROP INDEX `profits_traded_at_symbol`;\nALTER TABLE `profits` RENAME TO `profits_old`;\nCREATE TABLE `profits` (\n  `gid` INTEGER PRIMARY KEY AUTOINCREMENT,\n  `symbol` VARCHAR(12) NOT NULL,\n  `trade_id` INTEGER NOT NULL,\n  `price` DECIMAL(16, 8) NOT NULL,\n  `quantity` DECIMAL(16, 8) NOT NULL,\n  `cost` DECIMAL(16, 8) NOT NULL,\n  `fee` DECIMAL(16, 8) NOT NULL,\n  `profit` DECIMAL(16, 8) NOT NULL,\n  `holding_seconds` INTEGER NOT NULL DEFAULT 0,\n  `traded_at` DATETIME NOT NULL\n);\nINSERT INTO `profits` (`gid`, `symbol`, `trade_id`, `price`, `quantity`, `cost`, `fee`, `profit`, `holding_seconds`, `traded_at`)\n  SELECT `gid`, `symbol`, `trade_id`, `price`, `quantity`, `cost`, `fee`, `profit`, `holding_seconds`, `traded_at` FROM `profits_old`;\nDROP TABLE `profits_old`;\nCREATE UNIQUE INDEX `profits_symbol_trade_id` ON `profits` (`symbol`, `trade_id`);\nCREATE INDEX `profits_traded_at_symbol` ON `profits` (`traded_at`, `symbol`);\n",
+	"sqlite3/20261019050000_trades_backfill.sql":             "-- +goose Up\n-- the binance trades synced before the exchange was filled have the empty exchange,\n-- the rows that are synced again with the exchange are kept\nDELETE FROM `trades` WHERE `exchange` = '' AND EXISTS (\n  SELECT 1 FROM `trades` AS `t` WHERE `t`.`exchange` = 'binance' AND `t`.`symbol` = `trades`.`symbol` AND `t`.`id` = `trades`.`id`\n);\nUPDATE `trades` SET `exchange` = 'binance' WHERE `exchange` = '';\n\n-- the max trades were stored with the lower case local symbols and fee currencies\nDELETE FROM `trades` WHERE `exchange` = 'max' AND `symbol` <> UPPER(`symbol`) AND EXISTS (\n  SELECT 1 FROM `trades` AS `t` WHERE `t`.`exchange` = 'max' AND `t`.`symbol` = UPPER(`trades`.`symbol`) AND `t`.`id` = `trades`.`id`\n);\nUPDATE `trades` SET `symbol` = UPPER(`symbol`), `fee_currency` = UPPER(`fee_currency`) WHERE `exchange` = 'max';\n\nDELETE FROM `profits` WHERE `exchange` = '' AND EXISTS (\n  SELECT 1 FROM `profits` AS `p` WHERE `p`.`exchange` = 'binance' AND `p`.`symbol` = `profits`.`symbol` AND `p`.`trade_id` = `profits`.`trade_id`\n);\nUPDATE `profits` SET `exchange` = 'binance' WHERE `exchange` = '';\n\n-- the stock checkpoints are rebuilt with the backfilled trades on the next start\nDELETE FROM `stock_checkpoints`;\n\n-- +goose Down\n-- the backfilled values can not be told apart from the synced values, nothing is reverted\nSELECT 1;\n",
+	"sqlite3/20261019060000_strategy_states.sql":             "-- +goose Up\nCREATE TABLE `strategy_states` (\n  `gid` INTEGER PRIMARY KEY AUTOINCREMENT,\n\n  -- state_key identifies the strategy instance, e.g., xmaker:max:binance:BTCUSDT\n  `state_key` VARCHAR(128) NOT NULL,\n\n  -- state is the JSON encoded state of the strategy\n  `state` TEXT NOT NULL,\n\n  `updated_at` DATETIME NOT NULL\n);\n\nCREATE UNIQUE INDEX `strategy_states_state_key` ON `strategy_states` (`state_key`);\n-- +goose Down\nDROP TABLE `strategy_states`;\n",
+	"sqlite3/20261019070000_stock_checkpoints_exchange.sql":  "-- +goose Up\n-- the stored checkpoints can not be told apart by the exchange, they are rebuilt from the trades on the next start\nDELETE FROM `stock_checkpoints`;\n\nDROP INDEX `stock_checkpoints_symbol`;\nALTER TABLE `stock_checkpoints` ADD COLUMN `exchange` VARCHAR(24) NOT NULL DEFAULT '';\nCREATE UNIQUE INDEX `stock_checkpoints_exchange_symbol` ON `stock_checkpoints` (`exchange`, `symbol`);\n\n-- +goose Down\n-- sqlite can not drop columns, the table is rebuilt without the exchange column\nDROP TABLE `stock_checkpoints`;\nCREATE TABLE `stock_checkpoints` (\n  `gid` INTEGER PRIMARY KEY AUTOINCREMENT,\n\n  `symbol` VARCHAR(12) NOT NULL,\n  `cost_basis` VARCHAR(24) NOT NULL DEFAULT '',\n\n  -- the gid of the last trade that is processed by the stock manager\n  `last_trade_gid` INTEGER NOT NULL DEFAULT 0,\n\n  -- the JSON encoded stock lots and the pending sells\n  `stocks` TEXT NOT NULL,\n  `pending_sells` TEXT NOT NULL,\n\n  `updated_at` DATETIME NOT NULL\n);\n\nCREATE UNIQUE INDEX `stock_checkpoints_symbol` ON `stock_checkpoints` (`symbol`);\n",
+}
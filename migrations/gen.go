@@ -0,0 +1,50 @@
+//go:build ignore
+// +build ignore
+
+// gen.go embeds the SQL migration files of all the dialects into assets.go,
+// run "go generate ./migrations" after adding a migration file.
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+	"sort"
+)
+
+func main() {
+	files, err := filepath.Glob("*/*.sql")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	sort.Strings(files)
+
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by \"go run gen.go\"; DO NOT EDIT.\n\n")
+	buf.WriteString("package migrations\n\n")
+	buf.WriteString("var assets = map[string]string{\n")
+
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		fmt.Fprintf(&buf, "\t%q: %q,\n", filepath.ToSlash(file), data)
+	}
+
+	buf.WriteString("}\n")
+
+	source, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile("assets.go", source, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,160 @@
+// Package migrations embeds the goose-format SQL migration files and applies them to the database.
+// The migration files of each SQL dialect are placed in the directory named after the database driver, e.g., mysql/, postgres/ and sqlite3/.
+package migrations
+
+//go:generate go run gen.go
+
+import (
+	"bufio"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Migration is a parsed goose migration file, the version is the timestamp prefix of the file name
+type Migration struct {
+	Version int64
+	Name    string
+
+	// Up and Down are the SQL statements of the "-- +goose Up" and the "-- +goose Down" sections
+	Up   []string
+	Down []string
+}
+
+// Dialects are the supported SQL dialects, they are the same as the database driver names
+var Dialects = []string{"mysql", "postgres", "sqlite3"}
+
+// IsSupportedDialect returns true if the dialect has the migration files
+func IsSupportedDialect(dialect string) bool {
+	for _, d := range Dialects {
+		if d == dialect {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Migrations returns the embedded migrations of the dialect sorted by the version
+func Migrations(dialect string) ([]Migration, error) {
+	if !IsSupportedDialect(dialect) {
+		return nil, fmt.Errorf("unsupported sql dialect: %s", dialect)
+	}
+
+	var migrations []Migration
+	for file, source := range assets {
+		dir, name := path.Split(file)
+		if path.Clean(dir) != dialect {
+			continue
+		}
+
+		migration, err := Parse(name, source)
+		if err != nil {
+			return nil, err
+		}
+
+		migrations = append(migrations, *migration)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// LatestVersion returns the version of the last embedded migration of the dialect
+func LatestVersion(dialect string) (int64, error) {
+	migrations, err := Migrations(dialect)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(migrations) == 0 {
+		return 0, nil
+	}
+
+	return migrations[len(migrations)-1].Version, nil
+}
+
+// Parse parses the goose-format migration, the statements are split by the semicolon at the end of the line.
+// The statements between "-- +goose StatementBegin" and "-- +goose StatementEnd" are not split.
+func Parse(name, source string) (*Migration, error) {
+	idx := strings.Index(name, "_")
+	if idx < 0 {
+		return nil, fmt.Errorf("migration %s: the file name should be VERSION_NAME.sql", name)
+	}
+
+	version, err := strconv.ParseInt(name[:idx], 10, 64)
+	if err != nil {
+		return nil, errors.Wrapf(err, "migration %s: invalid version", name)
+	}
+
+	migration := &Migration{
+		Version: version,
+		Name:    strings.TrimSuffix(name[idx+1:], ".sql"),
+	}
+
+	var section *[]string
+	var statement strings.Builder
+	var inBlock bool
+
+	scanner := bufio.NewScanner(strings.NewReader(source))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case strings.HasPrefix(trimmed, "-- +goose Up"):
+			section = &migration.Up
+			continue
+
+		case strings.HasPrefix(trimmed, "-- +goose Down"):
+			section = &migration.Down
+			continue
+
+		case strings.HasPrefix(trimmed, "-- +goose StatementBegin"):
+			inBlock = true
+			continue
+
+		case strings.HasPrefix(trimmed, "-- +goose StatementEnd"):
+			inBlock = false
+			if section != nil && len(strings.TrimSpace(statement.String())) > 0 {
+				*section = append(*section, strings.TrimSpace(statement.String()))
+			}
+			statement.Reset()
+			continue
+
+		case strings.HasPrefix(trimmed, "--") || len(trimmed) == 0:
+			continue
+		}
+
+		if section == nil {
+			return nil, fmt.Errorf("migration %s: statement found before the \"-- +goose Up\" annotation", name)
+		}
+
+		statement.WriteString(line)
+		statement.WriteString("\n")
+
+		if !inBlock && strings.HasSuffix(trimmed, ";") {
+			*section = append(*section, strings.TrimSpace(statement.String()))
+			statement.Reset()
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(strings.TrimSpace(statement.String())) > 0 {
+		return nil, fmt.Errorf("migration %s: the last statement is not terminated by a semicolon", name)
+	}
+
+	return migration, nil
+}
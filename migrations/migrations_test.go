@@ -0,0 +1,73 @@
+package migrations
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	migration, err := Parse("20200101000000_test.sql", `-- +goose Up
+-- a comment; with a semicolon
+CREATE TABLE a (
+  id INT -- the id
+);
+CREATE INDEX a_id ON a(id);
+
+-- +goose StatementBegin
+CREATE TRIGGER t BEFORE INSERT ON a FOR EACH ROW BEGIN
+  SET NEW.id = 1;
+END;
+-- +goose StatementEnd
+
+-- +goose Down
+DROP TABLE a;
+`)
+	if assert.NoError(t, err) {
+		assert.Equal(t, int64(20200101000000), migration.Version)
+		assert.Equal(t, "test", migration.Name)
+		assert.Len(t, migration.Up, 3)
+		assert.Contains(t, migration.Up[2], "SET NEW.id = 1;\nEND;")
+		assert.Equal(t, []string{"DROP TABLE a;"}, migration.Down)
+	}
+
+	_, err = Parse("test.sql", "-- +goose Up\n")
+	assert.Error(t, err)
+
+	_, err = Parse("20200101000000_test.sql", "-- +goose Up\nCREATE TABLE a (id INT)\n")
+	assert.Error(t, err)
+}
+
+// TestAssets makes sure assets.go is regenerated after the migration files are changed
+func TestAssets(t *testing.T) {
+	files, err := filepath.Glob("*/*.sql")
+	assert.NoError(t, err)
+	assert.Len(t, assets, len(files), "assets.go is out of date, please run go generate ./migrations")
+
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		assert.NoError(t, err)
+		assert.Equal(t, string(data), assets[filepath.ToSlash(file)], "assets.go is out of date, please run go generate ./migrations")
+	}
+
+	for _, dialect := range Dialects {
+		migrations, err := Migrations(dialect)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, migrations, dialect)
+
+		var versions = map[int64]struct{}{}
+		for _, migration := range migrations {
+			_, duplicated := versions[migration.Version]
+			assert.False(t, duplicated, "%s: duplicated migration version %d", dialect, migration.Version)
+			versions[migration.Version] = struct{}{}
+
+			assert.NotEmpty(t, migration.Up)
+			assert.NotEmpty(t, migration.Down)
+		}
+	}
+
+	_, err = Migrations("oracle")
+	assert.Error(t, err)
+}
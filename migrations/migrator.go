@@ -0,0 +1,233 @@
+package migrations
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// ErrSchemaOutdated is returned by Migrator.Check when there are migrations not applied yet
+var ErrSchemaOutdated = errors.New("database schema is out of date, please run \"bbgo migrate up\"")
+
+// VersionTable is the schema version table, it's compatible with the goose command line tool
+const VersionTable = "goose_db_version"
+
+// MigrationStatus is a migration and whether it's applied to the database
+type MigrationStatus struct {
+	Migration
+	Applied bool
+}
+
+// Migrator applies the embedded migrations and tracks the applied versions in the version table,
+// the migrations of the dialect of the database driver are used.
+type Migrator struct {
+	DB *sqlx.DB
+}
+
+func NewMigrator(db *sqlx.DB) *Migrator {
+	return &Migrator{db}
+}
+
+// Dialect returns the SQL dialect of the database
+func (m *Migrator) Dialect() string {
+	return m.DB.DriverName()
+}
+
+func (m *Migrator) ensureVersionTable() error {
+	var err error
+	switch m.Dialect() {
+	case "sqlite3":
+		_, err = m.DB.Exec(`CREATE TABLE IF NOT EXISTS ` + VersionTable + ` (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			version_id INTEGER NOT NULL,
+			is_applied INTEGER NOT NULL,
+			tstamp TIMESTAMP DEFAULT (datetime('now'))
+		)`)
+
+	case "postgres":
+		_, err = m.DB.Exec(`CREATE TABLE IF NOT EXISTS ` + VersionTable + ` (
+			id SERIAL PRIMARY KEY,
+			version_id BIGINT NOT NULL,
+			is_applied BOOLEAN NOT NULL,
+			tstamp TIMESTAMP NULL DEFAULT NOW()
+		)`)
+
+	default:
+		_, err = m.DB.Exec(`CREATE TABLE IF NOT EXISTS ` + VersionTable + ` (
+			id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+			version_id BIGINT NOT NULL,
+			is_applied BOOLEAN NOT NULL,
+			tstamp TIMESTAMP NULL DEFAULT NOW(),
+			PRIMARY KEY (id)
+		)`)
+	}
+
+	return errors.Wrap(err, "create version table error")
+}
+
+// AppliedVersions returns the applied versions, the last record of a version decides if it's applied or rolled back
+func (m *Migrator) AppliedVersions() (map[int64]bool, error) {
+	if err := m.ensureVersionTable(); err != nil {
+		return nil, err
+	}
+
+	rows, err := m.DB.Query(`SELECT version_id, is_applied FROM ` + VersionTable + ` ORDER BY id ASC`)
+	if err != nil {
+		return nil, errors.Wrap(err, "query applied versions error")
+	}
+
+	defer rows.Close()
+
+	var versions = map[int64]bool{}
+	for rows.Next() {
+		var version int64
+		var applied bool
+		if err := rows.Scan(&version, &applied); err != nil {
+			return nil, err
+		}
+
+		if applied {
+			versions[version] = true
+		} else {
+			delete(versions, version)
+		}
+	}
+
+	return versions, rows.Err()
+}
+
+// CurrentVersion returns the latest applied version, zero means no migration is applied
+func (m *Migrator) CurrentVersion() (int64, error) {
+	versions, err := m.AppliedVersions()
+	if err != nil {
+		return 0, err
+	}
+
+	var current int64
+	for version := range versions {
+		if version > current {
+			current = version
+		}
+	}
+
+	return current, nil
+}
+
+// Status returns all the embedded migrations with their applied state
+func (m *Migrator) Status() ([]MigrationStatus, error) {
+	migrations, err := Migrations(m.Dialect())
+	if err != nil {
+		return nil, err
+	}
+
+	versions, err := m.AppliedVersions()
+	if err != nil {
+		return nil, err
+	}
+
+	var status []MigrationStatus
+	for _, migration := range migrations {
+		status = append(status, MigrationStatus{
+			Migration: migration,
+			Applied:   versions[migration.Version],
+		})
+	}
+
+	return status, nil
+}
+
+// Pending returns the migrations that are not applied yet
+func (m *Migrator) Pending() (pending []Migration, err error) {
+	status, err := m.Status()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, s := range status {
+		if !s.Applied {
+			pending = append(pending, s.Migration)
+		}
+	}
+
+	return pending, nil
+}
+
+// Check returns ErrSchemaOutdated if there are pending migrations
+func (m *Migrator) Check() error {
+	pending, err := m.Pending()
+	if err != nil {
+		return err
+	}
+
+	if len(pending) > 0 {
+		return errors.Wrapf(ErrSchemaOutdated, "%d pending migrations, the first one is %d_%s", len(pending), pending[0].Version, pending[0].Name)
+	}
+
+	return nil
+}
+
+// Up applies all the pending migrations in the version order
+func (m *Migrator) Up() (applied []Migration, err error) {
+	pending, err := m.Pending()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, migration := range pending {
+		log.Infof("applying migration %d_%s", migration.Version, migration.Name)
+
+		if err := m.apply(migration.Version, migration.Up, true); err != nil {
+			return applied, errors.Wrapf(err, "migration %d_%s up", migration.Version, migration.Name)
+		}
+
+		applied = append(applied, migration)
+	}
+
+	return applied, nil
+}
+
+// Down rolls back the latest applied migration, nil is returned if no migration is applied
+func (m *Migrator) Down() (*Migration, error) {
+	status, err := m.Status()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := len(status) - 1; i >= 0; i-- {
+		if !status[i].Applied {
+			continue
+		}
+
+		migration := status[i].Migration
+		log.Infof("rolling back migration %d_%s", migration.Version, migration.Name)
+
+		if err := m.apply(migration.Version, migration.Down, false); err != nil {
+			return nil, errors.Wrapf(err, "migration %d_%s down", migration.Version, migration.Name)
+		}
+
+		return &migration, nil
+	}
+
+	return nil, nil
+}
+
+func (m *Migrator) apply(version int64, statements []string, up bool) error {
+	tx, err := m.DB.Begin()
+	if err != nil {
+		return err
+	}
+
+	for _, statement := range statements {
+		if _, err := tx.Exec(statement); err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+	}
+
+	if _, err := tx.Exec(m.DB.Rebind(`INSERT INTO `+VersionTable+` (version_id, is_applied) VALUES (?, ?)`), version, up); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,123 @@
+package migrations
+
+import (
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigrator(t *testing.T) {
+	db, err := sqlx.Connect("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	migrator := NewMigrator(db)
+	assert.Equal(t, ErrSchemaOutdated, errors.Cause(migrator.Check()))
+
+	migrations, err := Migrations("sqlite3")
+	assert.NoError(t, err)
+
+	applied, err := migrator.Up()
+	assert.NoError(t, err)
+	assert.Len(t, applied, len(migrations))
+	assert.NoError(t, migrator.Check())
+
+	latest, err := LatestVersion("sqlite3")
+	assert.NoError(t, err)
+
+	current, err := migrator.CurrentVersion()
+	assert.NoError(t, err)
+	assert.Equal(t, latest, current)
+
+	// every migration can be rolled back and applied again
+	for range migrations {
+		_, err := migrator.Down()
+		assert.NoError(t, err)
+	}
+
+	current, err = migrator.CurrentVersion()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), current)
+
+	applied, err = migrator.Up()
+	assert.NoError(t, err)
+	assert.Len(t, applied, len(migrations))
+
+	// the migrations of the other dialects have the same versions
+	for _, dialect := range Dialects {
+		dialectMigrations, err := Migrations(dialect)
+		assert.NoError(t, err)
+		if assert.Len(t, dialectMigrations, len(migrations), dialect) {
+			for i := range migrations {
+				assert.Equal(t, migrations[i].Version, dialectMigrations[i].Version, dialect)
+			}
+		}
+	}
+}
+
+func TestMigrator_TradesBackfill(t *testing.T) {
+	db, err := sqlx.Connect("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	migrator := NewMigrator(db)
+	_, err = migrator.Up()
+	assert.NoError(t, err)
+
+	// roll back to the version before the backfill, then insert the trades stored by the previous versions
+	for {
+		rolledBack, err := migrator.Down()
+		if !assert.NoError(t, err) || rolledBack == nil || rolledBack.Version == 20261019050000 {
+			break
+		}
+	}
+
+	for _, trade := range []struct {
+		exchange, symbol, feeCurrency string
+		id                            int64
+	}{
+		{"", "BTCUSDT", "BNB", 1},
+		{"", "BTCUSDT", "BNB", 2},
+		{"binance", "BTCUSDT", "BNB", 2},
+		{"max", "btctwd", "max", 1},
+		{"max", "btctwd", "max", 2},
+		{"max", "BTCTWD", "MAX", 2},
+	} {
+		_, err := db.Exec(`INSERT INTO trades (id, exchange, symbol, price, quantity, quote_quantity, fee, fee_currency, traded_at) VALUES (?, ?, ?, 1, 1, 1, 0, ?, CURRENT_TIMESTAMP)`,
+			trade.id, trade.exchange, trade.symbol, trade.feeCurrency)
+		assert.NoError(t, err)
+	}
+
+	_, err = migrator.Up()
+	assert.NoError(t, err)
+
+	var rows []struct {
+		Exchange    string `db:"exchange"`
+		Symbol      string `db:"symbol"`
+		FeeCurrency string `db:"fee_currency"`
+		ID          int64  `db:"id"`
+	}
+	assert.NoError(t, db.Select(&rows, `SELECT exchange, symbol, fee_currency, id FROM trades ORDER BY exchange, id`))
+	if assert.Len(t, rows, 4) {
+		for _, row := range rows {
+			switch row.Exchange {
+			case "binance":
+				assert.Equal(t, "BTCUSDT", row.Symbol)
+			case "max":
+				assert.Equal(t, "BTCTWD", row.Symbol)
+				assert.Equal(t, "MAX", row.FeeCurrency)
+			default:
+				t.Errorf("unexpected exchange %q", row.Exchange)
+			}
+		}
+	}
+}
@@ -0,0 +1,22 @@
+-- +goose Up
+CREATE TABLE `stock_checkpoints` (
+  `gid` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+
+  `symbol` VARCHAR(12) NOT NULL,
+  `cost_basis` VARCHAR(24) NOT NULL DEFAULT '',
+
+  -- the gid of the last trade that is processed by the stock manager
+  `last_trade_gid` BIGINT UNSIGNED NOT NULL DEFAULT 0,
+
+  -- the JSON encoded stock lots and the pending sells
+  `stocks` MEDIUMTEXT NOT NULL,
+  `pending_sells` MEDIUMTEXT NOT NULL,
+
+  `updated_at` DATETIME(6) NOT NULL,
+
+  PRIMARY KEY (`gid`),
+  UNIQUE KEY `symbol` (`symbol`)
+
+) ENGINE=InnoDB;
+-- +goose Down
+DROP TABLE `stock_checkpoints`;
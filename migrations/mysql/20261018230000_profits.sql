@@ -0,0 +1,35 @@
+-- +goose Up
+CREATE TABLE `profits` (
+  `gid` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+
+  `symbol` VARCHAR(12) NOT NULL,
+
+  -- the id of the sell trade
+  `trade_id` BIGINT UNSIGNED NOT NULL,
+
+  `price` DECIMAL(16, 8) UNSIGNED NOT NULL,
+
+  -- the matched quantity of the sell trade
+  `quantity` DECIMAL(16, 8) UNSIGNED NOT NULL,
+
+  -- the cost of the consumed stock lots in the quote currency
+  `cost` DECIMAL(16, 8) UNSIGNED NOT NULL,
+
+  -- the fee of the sell trade in the quote currency
+  `fee` DECIMAL(16, 8) UNSIGNED NOT NULL,
+
+  -- the realized profit in the quote currency, the fee is deducted
+  `profit` DECIMAL(16, 8) NOT NULL,
+
+  -- the quantity weighted average holding time of the consumed stock lots
+  `holding_seconds` BIGINT UNSIGNED NOT NULL DEFAULT 0,
+
+  `traded_at` DATETIME(6) NOT NULL,
+
+  PRIMARY KEY (`gid`),
+  UNIQUE KEY `symbol_trade_id` (`symbol`, `trade_id`),
+  INDEX `profits_traded_at_symbol` (`traded_at`, `symbol`)
+
+) ENGINE=InnoDB;
+-- +goose Down
+DROP TABLE `profits`;
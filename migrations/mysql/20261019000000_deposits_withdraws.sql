@@ -0,0 +1,59 @@
+-- +goose Up
+CREATE TABLE `deposits` (
+  `gid` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+
+  `exchange` VARCHAR(24) NOT NULL,
+
+  -- asset is the asset name (currency)
+  `asset` VARCHAR(10) NOT NULL,
+
+  `address` VARCHAR(128) NOT NULL DEFAULT '',
+  `address_tag` VARCHAR(128) NOT NULL DEFAULT '',
+  `amount` DECIMAL(16, 8) NOT NULL,
+
+  -- the transaction id of the deposit, it's the internal id on some exchanges
+  `txn_id` VARCHAR(256) NOT NULL,
+
+  `status` VARCHAR(20) NOT NULL,
+
+  `time` DATETIME(6) NOT NULL,
+
+  PRIMARY KEY (`gid`),
+  UNIQUE KEY `deposits_exchange_txn_id` (`exchange`, `txn_id`(128)),
+  INDEX `deposits_asset_time` (`asset`, `time`)
+
+) ENGINE=InnoDB;
+
+CREATE TABLE `withdraws` (
+  `gid` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+
+  `exchange` VARCHAR(24) NOT NULL,
+
+  -- the id of the withdraw on the exchange
+  `id` VARCHAR(64) NOT NULL,
+
+  -- asset is the asset name (currency)
+  `asset` VARCHAR(10) NOT NULL,
+
+  `address` VARCHAR(128) NOT NULL DEFAULT '',
+  `address_tag` VARCHAR(128) NOT NULL DEFAULT '',
+  `network` VARCHAR(32) NOT NULL DEFAULT '',
+  `amount` DECIMAL(16, 8) NOT NULL,
+
+  `txn_id` VARCHAR(256) NOT NULL DEFAULT '',
+  `txn_fee` DECIMAL(16, 8) NOT NULL DEFAULT 0,
+
+  `withdraw_order_id` VARCHAR(64) NOT NULL DEFAULT '',
+
+  `status` VARCHAR(20) NOT NULL,
+
+  `time` DATETIME(6) NOT NULL,
+
+  PRIMARY KEY (`gid`),
+  UNIQUE KEY `withdraws_exchange_id` (`exchange`, `id`),
+  INDEX `withdraws_asset_time` (`asset`, `time`)
+
+) ENGINE=InnoDB;
+-- +goose Down
+DROP TABLE `deposits`;
+DROP TABLE `withdraws`;
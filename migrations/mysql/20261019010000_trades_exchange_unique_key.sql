@@ -0,0 +1,19 @@
+-- +goose Up
+ALTER TABLE `trades`
+  DROP INDEX `id`,
+  MODIFY COLUMN `symbol` VARCHAR(20) NOT NULL,
+  MODIFY COLUMN `fee_currency` VARCHAR(10) NOT NULL,
+  ADD COLUMN `order_id` BIGINT UNSIGNED NOT NULL DEFAULT 0 AFTER `exchange`,
+  ADD COLUMN `is_margin` BOOLEAN NOT NULL DEFAULT FALSE AFTER `is_maker`,
+  ADD UNIQUE KEY `trades_exchange_symbol_id` (`exchange`, `symbol`, `id`),
+  ADD INDEX `trades_exchange_order_id` (`exchange`, `order_id`);
+
+-- +goose Down
+ALTER TABLE `trades`
+  DROP INDEX `trades_exchange_symbol_id`,
+  DROP INDEX `trades_exchange_order_id`,
+  DROP COLUMN `order_id`,
+  DROP COLUMN `is_margin`,
+  MODIFY COLUMN `symbol` VARCHAR(7) NOT NULL,
+  MODIFY COLUMN `fee_currency` VARCHAR(4) NOT NULL,
+  ADD UNIQUE KEY `id` (`id`);
@@ -0,0 +1,30 @@
+-- +goose Up
+CREATE TABLE `orders` (
+  `gid` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+
+  `exchange` VARCHAR(24) NOT NULL DEFAULT '',
+
+  -- order_id is the order id returned from the exchange
+  `order_id` BIGINT UNSIGNED NOT NULL,
+  `client_order_id` VARCHAR(64) NOT NULL DEFAULT '',
+  `order_type` VARCHAR(16) NOT NULL,
+
+  `symbol` VARCHAR(20) NOT NULL,
+  `status` VARCHAR(20) NOT NULL,
+  `time_in_force` VARCHAR(4) NOT NULL DEFAULT '',
+  `side` VARCHAR(4) NOT NULL,
+
+  `price` DECIMAL(16, 8) UNSIGNED NOT NULL,
+  `quantity` DECIMAL(16, 8) UNSIGNED NOT NULL,
+  `executed_quantity` DECIMAL(16, 8) UNSIGNED NOT NULL DEFAULT 0.0,
+
+  `created_at` DATETIME(6) NOT NULL,
+  `updated_at` DATETIME(6) NOT NULL,
+
+  PRIMARY KEY (`gid`),
+  UNIQUE KEY `orders_exchange_order_id` (`exchange`, `order_id`),
+  INDEX `orders_exchange_symbol_created_at` (`exchange`, `symbol`, `created_at`)
+
+) ENGINE=InnoDB;
+-- +goose Down
+DROP TABLE `orders`;
@@ -0,0 +1,28 @@
+-- +goose Up
+CREATE TABLE `position_exits` (
+  `gid` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+
+  `exchange` VARCHAR(24) NOT NULL DEFAULT '',
+  `symbol` VARCHAR(20) NOT NULL,
+
+  `entry_price` DECIMAL(16, 8) UNSIGNED NOT NULL,
+  `initial_quantity` DECIMAL(16, 8) UNSIGNED NOT NULL,
+
+  -- quantity is the remaining quantity of the position
+  `quantity` DECIMAL(16, 8) UNSIGNED NOT NULL,
+
+  -- peak_price is the highest price since the entry, it's used by the trailing stop
+  `peak_price` DECIMAL(16, 8) UNSIGNED NOT NULL,
+
+  -- take_profits is the number of the triggered take-profit levels
+  `take_profits` INT UNSIGNED NOT NULL DEFAULT 0,
+
+  `opened_at` DATETIME(6) NOT NULL,
+  `updated_at` DATETIME(6) NOT NULL,
+
+  PRIMARY KEY (`gid`),
+  UNIQUE KEY `position_exits_exchange_symbol` (`exchange`, `symbol`)
+
+) ENGINE=InnoDB;
+-- +goose Down
+DROP TABLE `position_exits`;
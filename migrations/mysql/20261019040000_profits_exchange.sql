@@ -0,0 +1,18 @@
+-- +goose Up
+ALTER TABLE `profits`
+  DROP INDEX `symbol_trade_id`,
+  ADD COLUMN `exchange` VARCHAR(24) NOT NULL DEFAULT '' AFTER `gid`,
+  ADD UNIQUE KEY `profits_exchange_symbol_trade_id` (`exchange`, `symbol`, `trade_id`);
+
+-- the exchange of the stored profits is filled by the sell trades
+UPDATE `profits` SET `exchange` = COALESCE((
+  SELECT `trades`.`exchange` FROM `trades`
+  WHERE `trades`.`symbol` = `profits`.`symbol` AND `trades`.`id` = `profits`.`trade_id` AND `trades`.`is_buyer` = FALSE
+  LIMIT 1
+), '');
+
+-- +goose Down
+ALTER TABLE `profits`
+  DROP INDEX `profits_exchange_symbol_trade_id`,
+  DROP COLUMN `exchange`,
+  ADD UNIQUE KEY `symbol_trade_id` (`symbol`, `trade_id`);
@@ -0,0 +1,26 @@
+-- +goose Up
+-- the binance trades synced before the exchange was filled have the empty exchange,
+-- the rows that are synced again with the exchange are kept
+DELETE `t1` FROM `trades` AS `t1` JOIN `trades` AS `t2`
+  ON `t2`.`exchange` = 'binance' AND `t2`.`symbol` = `t1`.`symbol` AND `t2`.`id` = `t1`.`id`
+WHERE `t1`.`exchange` = '';
+UPDATE `trades` SET `exchange` = 'binance' WHERE `exchange` = '';
+
+-- the max trades were stored with the lower case local symbols and fee currencies,
+-- the symbols are compared in binary since the default collation is case insensitive
+DELETE `t1` FROM `trades` AS `t1` JOIN `trades` AS `t2`
+  ON `t2`.`exchange` = 'max' AND BINARY `t2`.`symbol` = BINARY UPPER(`t1`.`symbol`) AND `t2`.`id` = `t1`.`id` AND `t2`.`gid` <> `t1`.`gid`
+WHERE `t1`.`exchange` = 'max' AND BINARY `t1`.`symbol` <> BINARY UPPER(`t1`.`symbol`);
+UPDATE `trades` SET `symbol` = UPPER(`symbol`), `fee_currency` = UPPER(`fee_currency`) WHERE `exchange` = 'max';
+
+DELETE `p1` FROM `profits` AS `p1` JOIN `profits` AS `p2`
+  ON `p2`.`exchange` = 'binance' AND `p2`.`symbol` = `p1`.`symbol` AND `p2`.`trade_id` = `p1`.`trade_id`
+WHERE `p1`.`exchange` = '';
+UPDATE `profits` SET `exchange` = 'binance' WHERE `exchange` = '';
+
+-- the stock checkpoints are rebuilt with the backfilled trades on the next start
+DELETE FROM `stock_checkpoints`;
+
+-- +goose Down
+-- the backfilled values can not be told apart from the synced values, nothing is reverted
+SELECT 1;
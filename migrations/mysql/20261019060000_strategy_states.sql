@@ -0,0 +1,18 @@
+-- +goose Up
+CREATE TABLE `strategy_states` (
+  `gid` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+
+  -- state_key identifies the strategy instance, e.g., xmaker:max:binance:BTCUSDT
+  `state_key` VARCHAR(128) NOT NULL,
+
+  -- state is the JSON encoded state of the strategy
+  `state` TEXT NOT NULL,
+
+  `updated_at` DATETIME(6) NOT NULL,
+
+  PRIMARY KEY (`gid`),
+  UNIQUE KEY `strategy_states_state_key` (`state_key`)
+
+) ENGINE=InnoDB;
+-- +goose Down
+DROP TABLE `strategy_states`;
@@ -0,0 +1,16 @@
+-- +goose Up
+-- the stored checkpoints can not be told apart by the exchange, they are rebuilt from the trades on the next start
+DELETE FROM `stock_checkpoints`;
+
+ALTER TABLE `stock_checkpoints`
+  DROP INDEX `symbol`,
+  ADD COLUMN `exchange` VARCHAR(24) NOT NULL DEFAULT '' AFTER `gid`,
+  ADD UNIQUE KEY `stock_checkpoints_exchange_symbol` (`exchange`, `symbol`);
+
+-- +goose Down
+DELETE FROM `stock_checkpoints`;
+
+ALTER TABLE `stock_checkpoints`
+  DROP INDEX `stock_checkpoints_exchange_symbol`,
+  DROP COLUMN `exchange`,
+  ADD UNIQUE KEY `symbol` (`symbol`);
@@ -0,0 +1,21 @@
+-- +goose Up
+CREATE TABLE trades (
+  gid BIGSERIAL PRIMARY KEY,
+
+  id BIGINT,
+  exchange VARCHAR(24) NOT NULL DEFAULT '',
+  symbol VARCHAR(7) NOT NULL,
+  price NUMERIC(16, 8) NOT NULL,
+  quantity NUMERIC(16, 8) NOT NULL,
+  quote_quantity NUMERIC(16, 8) NOT NULL,
+  fee NUMERIC(16, 8) NOT NULL,
+  fee_currency VARCHAR(4) NOT NULL,
+  is_buyer BOOLEAN NOT NULL DEFAULT FALSE,
+  is_maker BOOLEAN NOT NULL DEFAULT FALSE,
+  side VARCHAR(4) NOT NULL DEFAULT '',
+  traded_at TIMESTAMP(6) NOT NULL,
+
+  CONSTRAINT trades_id UNIQUE (id)
+);
+-- +goose Down
+DROP TABLE trades;
@@ -0,0 +1,20 @@
+-- +goose Up
+CREATE TABLE stock_checkpoints (
+  gid BIGSERIAL PRIMARY KEY,
+
+  symbol VARCHAR(12) NOT NULL,
+  cost_basis VARCHAR(24) NOT NULL DEFAULT '',
+
+  -- the gid of the last trade that is processed by the stock manager
+  last_trade_gid BIGINT NOT NULL DEFAULT 0,
+
+  -- the JSON encoded stock lots and the pending sells
+  stocks TEXT NOT NULL,
+  pending_sells TEXT NOT NULL,
+
+  updated_at TIMESTAMP(6) NOT NULL,
+
+  CONSTRAINT stock_checkpoints_symbol UNIQUE (symbol)
+);
+-- +goose Down
+DROP TABLE stock_checkpoints;
@@ -0,0 +1,34 @@
+-- +goose Up
+CREATE TABLE profits (
+  gid BIGSERIAL PRIMARY KEY,
+
+  symbol VARCHAR(12) NOT NULL,
+
+  -- the id of the sell trade
+  trade_id BIGINT NOT NULL,
+
+  price NUMERIC(16, 8) NOT NULL,
+
+  -- the matched quantity of the sell trade
+  quantity NUMERIC(16, 8) NOT NULL,
+
+  -- the cost of the consumed stock lots in the quote currency
+  cost NUMERIC(16, 8) NOT NULL,
+
+  -- the fee of the sell trade in the quote currency
+  fee NUMERIC(16, 8) NOT NULL,
+
+  -- the realized profit in the quote currency, the fee is deducted
+  profit NUMERIC(16, 8) NOT NULL,
+
+  -- the quantity weighted average holding time of the consumed stock lots
+  holding_seconds BIGINT NOT NULL DEFAULT 0,
+
+  traded_at TIMESTAMP(6) NOT NULL,
+
+  CONSTRAINT profits_symbol_trade_id UNIQUE (symbol, trade_id)
+);
+
+CREATE INDEX profits_traded_at_symbol ON profits (traded_at, symbol);
+-- +goose Down
+DROP TABLE profits;
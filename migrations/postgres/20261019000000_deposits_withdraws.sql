@@ -0,0 +1,57 @@
+-- +goose Up
+CREATE TABLE deposits (
+  gid BIGSERIAL PRIMARY KEY,
+
+  exchange VARCHAR(24) NOT NULL,
+
+  -- asset is the asset name (currency)
+  asset VARCHAR(10) NOT NULL,
+
+  address VARCHAR(128) NOT NULL DEFAULT '',
+  address_tag VARCHAR(128) NOT NULL DEFAULT '',
+  amount NUMERIC(16, 8) NOT NULL,
+
+  -- the transaction id of the deposit, it's the internal id on some exchanges
+  txn_id VARCHAR(256) NOT NULL,
+
+  status VARCHAR(20) NOT NULL,
+
+  time TIMESTAMP(6) NOT NULL,
+
+  CONSTRAINT deposits_exchange_txn_id UNIQUE (exchange, txn_id)
+);
+
+CREATE INDEX deposits_asset_time ON deposits (asset, time);
+
+CREATE TABLE withdraws (
+  gid BIGSERIAL PRIMARY KEY,
+
+  exchange VARCHAR(24) NOT NULL,
+
+  -- the id of the withdraw on the exchange
+  id VARCHAR(64) NOT NULL,
+
+  -- asset is the asset name (currency)
+  asset VARCHAR(10) NOT NULL,
+
+  address VARCHAR(128) NOT NULL DEFAULT '',
+  address_tag VARCHAR(128) NOT NULL DEFAULT '',
+  network VARCHAR(32) NOT NULL DEFAULT '',
+  amount NUMERIC(16, 8) NOT NULL,
+
+  txn_id VARCHAR(256) NOT NULL DEFAULT '',
+  txn_fee NUMERIC(16, 8) NOT NULL DEFAULT 0,
+
+  withdraw_order_id VARCHAR(64) NOT NULL DEFAULT '',
+
+  status VARCHAR(20) NOT NULL,
+
+  time TIMESTAMP(6) NOT NULL,
+
+  CONSTRAINT withdraws_exchange_id UNIQUE (exchange, id)
+);
+
+CREATE INDEX withdraws_asset_time ON withdraws (asset, time);
+-- +goose Down
+DROP TABLE deposits;
+DROP TABLE withdraws;
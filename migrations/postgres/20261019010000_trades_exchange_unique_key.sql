@@ -0,0 +1,21 @@
+-- +goose Up
+ALTER TABLE trades
+  DROP CONSTRAINT trades_id,
+  ALTER COLUMN symbol TYPE VARCHAR(20),
+  ALTER COLUMN fee_currency TYPE VARCHAR(10),
+  ADD COLUMN order_id BIGINT NOT NULL DEFAULT 0,
+  ADD COLUMN is_margin BOOLEAN NOT NULL DEFAULT FALSE,
+  ADD CONSTRAINT trades_exchange_symbol_id UNIQUE (exchange, symbol, id);
+
+CREATE INDEX trades_exchange_order_id ON trades (exchange, order_id);
+
+-- +goose Down
+DROP INDEX trades_exchange_order_id;
+
+ALTER TABLE trades
+  DROP CONSTRAINT trades_exchange_symbol_id,
+  DROP COLUMN order_id,
+  DROP COLUMN is_margin,
+  ALTER COLUMN symbol TYPE VARCHAR(7),
+  ALTER COLUMN fee_currency TYPE VARCHAR(4),
+  ADD CONSTRAINT trades_id UNIQUE (id);
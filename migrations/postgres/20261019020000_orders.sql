@@ -0,0 +1,29 @@
+-- +goose Up
+CREATE TABLE orders (
+  gid BIGSERIAL PRIMARY KEY,
+
+  exchange VARCHAR(24) NOT NULL DEFAULT '',
+
+  -- order_id is the order id returned from the exchange
+  order_id BIGINT NOT NULL,
+  client_order_id VARCHAR(64) NOT NULL DEFAULT '',
+  order_type VARCHAR(16) NOT NULL,
+
+  symbol VARCHAR(20) NOT NULL,
+  status VARCHAR(20) NOT NULL,
+  time_in_force VARCHAR(4) NOT NULL DEFAULT '',
+  side VARCHAR(4) NOT NULL,
+
+  price NUMERIC(16, 8) NOT NULL,
+  quantity NUMERIC(16, 8) NOT NULL,
+  executed_quantity NUMERIC(16, 8) NOT NULL DEFAULT 0.0,
+
+  created_at TIMESTAMP(6) NOT NULL,
+  updated_at TIMESTAMP(6) NOT NULL,
+
+  CONSTRAINT orders_exchange_order_id UNIQUE (exchange, order_id)
+);
+
+CREATE INDEX orders_exchange_symbol_created_at ON orders (exchange, symbol, created_at);
+-- +goose Down
+DROP TABLE orders;
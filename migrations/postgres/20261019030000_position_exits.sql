@@ -0,0 +1,26 @@
+-- +goose Up
+CREATE TABLE position_exits (
+  gid BIGSERIAL PRIMARY KEY,
+
+  exchange VARCHAR(24) NOT NULL DEFAULT '',
+  symbol VARCHAR(20) NOT NULL,
+
+  entry_price NUMERIC(16, 8) NOT NULL,
+  initial_quantity NUMERIC(16, 8) NOT NULL,
+
+  -- quantity is the remaining quantity of the position
+  quantity NUMERIC(16, 8) NOT NULL,
+
+  -- peak_price is the highest price since the entry, it's used by the trailing stop
+  peak_price NUMERIC(16, 8) NOT NULL,
+
+  -- take_profits is the number of the triggered take-profit levels
+  take_profits INTEGER NOT NULL DEFAULT 0,
+
+  opened_at TIMESTAMP(6) NOT NULL,
+  updated_at TIMESTAMP(6) NOT NULL,
+
+  CONSTRAINT position_exits_exchange_symbol UNIQUE (exchange, symbol)
+);
+-- +goose Down
+DROP TABLE position_exits;
@@ -0,0 +1,16 @@
+-- +goose Up
+CREATE TABLE strategy_states (
+  gid BIGSERIAL PRIMARY KEY,
+
+  -- state_key identifies the strategy instance, e.g., xmaker:max:binance:BTCUSDT
+  state_key VARCHAR(128) NOT NULL,
+
+  -- state is the JSON encoded state of the strategy
+  state TEXT NOT NULL,
+
+  updated_at TIMESTAMP(6) NOT NULL,
+
+  CONSTRAINT strategy_states_state_key UNIQUE (state_key)
+);
+-- +goose Down
+DROP TABLE strategy_states;
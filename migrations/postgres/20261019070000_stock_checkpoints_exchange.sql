@@ -0,0 +1,16 @@
+-- +goose Up
+-- the stored checkpoints can not be told apart by the exchange, they are rebuilt from the trades on the next start
+DELETE FROM stock_checkpoints;
+
+ALTER TABLE stock_checkpoints
+  DROP CONSTRAINT stock_checkpoints_symbol,
+  ADD COLUMN exchange VARCHAR(24) NOT NULL DEFAULT '',
+  ADD CONSTRAINT stock_checkpoints_exchange_symbol UNIQUE (exchange, symbol);
+
+-- +goose Down
+DELETE FROM stock_checkpoints;
+
+ALTER TABLE stock_checkpoints
+  DROP CONSTRAINT stock_checkpoints_exchange_symbol,
+  DROP COLUMN exchange,
+  ADD CONSTRAINT stock_checkpoints_symbol UNIQUE (symbol);
@@ -0,0 +1,21 @@
+-- +goose Up
+CREATE TABLE `trades` (
+  `gid` INTEGER PRIMARY KEY AUTOINCREMENT,
+
+  `id` INTEGER,
+  `exchange` VARCHAR(24) NOT NULL DEFAULT '',
+  `symbol` VARCHAR(7) NOT NULL,
+  `price` DECIMAL(16, 8) NOT NULL,
+  `quantity` DECIMAL(16, 8) NOT NULL,
+  `quote_quantity` DECIMAL(16, 8) NOT NULL,
+  `fee` DECIMAL(16, 8) NOT NULL,
+  `fee_currency` VARCHAR(4) NOT NULL,
+  `is_buyer` BOOLEAN NOT NULL DEFAULT FALSE,
+  `is_maker` BOOLEAN NOT NULL DEFAULT FALSE,
+  `side` VARCHAR(4) NOT NULL DEFAULT '',
+  `traded_at` DATETIME NOT NULL
+);
+
+CREATE UNIQUE INDEX `trades_id` ON `trades` (`id`);
+-- +goose Down
+DROP TABLE `trades`;
@@ -0,0 +1,20 @@
+-- +goose Up
+CREATE TABLE `stock_checkpoints` (
+  `gid` INTEGER PRIMARY KEY AUTOINCREMENT,
+
+  `symbol` VARCHAR(12) NOT NULL,
+  `cost_basis` VARCHAR(24) NOT NULL DEFAULT '',
+
+  -- the gid of the last trade that is processed by the stock manager
+  `last_trade_gid` INTEGER NOT NULL DEFAULT 0,
+
+  -- the JSON encoded stock lots and the pending sells
+  `stocks` TEXT NOT NULL,
+  `pending_sells` TEXT NOT NULL,
+
+  `updated_at` DATETIME NOT NULL
+);
+
+CREATE UNIQUE INDEX `stock_checkpoints_symbol` ON `stock_checkpoints` (`symbol`);
+-- +goose Down
+DROP TABLE `stock_checkpoints`;
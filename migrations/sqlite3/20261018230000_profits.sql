@@ -0,0 +1,33 @@
+-- +goose Up
+CREATE TABLE `profits` (
+  `gid` INTEGER PRIMARY KEY AUTOINCREMENT,
+
+  `symbol` VARCHAR(12) NOT NULL,
+
+  -- the id of the sell trade
+  `trade_id` INTEGER NOT NULL,
+
+  `price` DECIMAL(16, 8) NOT NULL,
+
+  -- the matched quantity of the sell trade
+  `quantity` DECIMAL(16, 8) NOT NULL,
+
+  -- the cost of the consumed stock lots in the quote currency
+  `cost` DECIMAL(16, 8) NOT NULL,
+
+  -- the fee of the sell trade in the quote currency
+  `fee` DECIMAL(16, 8) NOT NULL,
+
+  -- the realized profit in the quote currency, the fee is deducted
+  `profit` DECIMAL(16, 8) NOT NULL,
+
+  -- the quantity weighted average holding time of the consumed stock lots
+  `holding_seconds` INTEGER NOT NULL DEFAULT 0,
+
+  `traded_at` DATETIME NOT NULL
+);
+
+CREATE UNIQUE INDEX `profits_symbol_trade_id` ON `profits` (`symbol`, `trade_id`);
+CREATE INDEX `profits_traded_at_symbol` ON `profits` (`traded_at`, `symbol`);
+-- +goose Down
+DROP TABLE `profits`;
@@ -0,0 +1,39 @@
+-- +goose Up
+-- sqlite does not check the varchar length, only the unique key and the new columns are changed
+DROP INDEX `trades_id`;
+ALTER TABLE `trades` ADD COLUMN `order_id` INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE `trades` ADD COLUMN `is_margin` BOOLEAN NOT NULL DEFAULT FALSE;
+CREATE UNIQUE INDEX `trades_exchange_symbol_id` ON `trades` (`exchange`, `symbol`, `id`);
+CREATE INDEX `trades_exchange_order_id` ON `trades` (`exchange`, `order_id`);
+
+-- +goose Down
+-- sqlite can not drop columns, the table is rebuilt without the new columns
+DROP INDEX `trades_exchange_symbol_id`;
+DROP INDEX `trades_exchange_order_id`;
+DROP INDEX trades_symbol;
+DROP INDEX trades_symbol_fee_currency;
+DROP INDEX trades_traded_at_symbol;
+ALTER TABLE `trades` RENAME TO `trades_old`;
+CREATE TABLE `trades` (
+  `gid` INTEGER PRIMARY KEY AUTOINCREMENT,
+
+  `id` INTEGER,
+  `exchange` VARCHAR(24) NOT NULL DEFAULT '',
+  `symbol` VARCHAR(7) NOT NULL,
+  `price` DECIMAL(16, 8) NOT NULL,
+  `quantity` DECIMAL(16, 8) NOT NULL,
+  `quote_quantity` DECIMAL(16, 8) NOT NULL,
+  `fee` DECIMAL(16, 8) NOT NULL,
+  `fee_currency` VARCHAR(4) NOT NULL,
+  `is_buyer` BOOLEAN NOT NULL DEFAULT FALSE,
+  `is_maker` BOOLEAN NOT NULL DEFAULT FALSE,
+  `side` VARCHAR(4) NOT NULL DEFAULT '',
+  `traded_at` DATETIME NOT NULL
+);
+INSERT INTO `trades` (`gid`, `id`, `exchange`, `symbol`, `price`, `quantity`, `quote_quantity`, `fee`, `fee_currency`, `is_buyer`, `is_maker`, `side`, `traded_at`)
+  SELECT `gid`, `id`, `exchange`, `symbol`, `price`, `quantity`, `quote_quantity`, `fee`, `fee_currency`, `is_buyer`, `is_maker`, `side`, `traded_at` FROM `trades_old`;
+DROP TABLE `trades_old`;
+CREATE UNIQUE INDEX `trades_id` ON `trades` (`id`);
+CREATE INDEX trades_symbol ON trades(symbol);
+CREATE INDEX trades_symbol_fee_currency ON trades(symbol, fee_currency, traded_at);
+CREATE INDEX trades_traded_at_symbol ON trades(traded_at, symbol);
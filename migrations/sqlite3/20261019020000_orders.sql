@@ -0,0 +1,28 @@
+-- +goose Up
+CREATE TABLE `orders` (
+  `gid` INTEGER PRIMARY KEY AUTOINCREMENT,
+
+  `exchange` VARCHAR(24) NOT NULL DEFAULT '',
+
+  -- order_id is the order id returned from the exchange
+  `order_id` INTEGER NOT NULL,
+  `client_order_id` VARCHAR(64) NOT NULL DEFAULT '',
+  `order_type` VARCHAR(16) NOT NULL,
+
+  `symbol` VARCHAR(20) NOT NULL,
+  `status` VARCHAR(20) NOT NULL,
+  `time_in_force` VARCHAR(4) NOT NULL DEFAULT '',
+  `side` VARCHAR(4) NOT NULL,
+
+  `price` DECIMAL(16, 8) NOT NULL,
+  `quantity` DECIMAL(16, 8) NOT NULL,
+  `executed_quantity` DECIMAL(16, 8) NOT NULL DEFAULT 0.0,
+
+  `created_at` DATETIME NOT NULL,
+  `updated_at` DATETIME NOT NULL
+);
+
+CREATE UNIQUE INDEX `orders_exchange_order_id` ON `orders` (`exchange`, `order_id`);
+CREATE INDEX `orders_exchange_symbol_created_at` ON `orders` (`exchange`, `symbol`, `created_at`);
+-- +goose Down
+DROP TABLE `orders`;
@@ -0,0 +1,34 @@
+-- +goose Up
+DROP INDEX `profits_symbol_trade_id`;
+ALTER TABLE `profits` ADD COLUMN `exchange` VARCHAR(24) NOT NULL DEFAULT '';
+CREATE UNIQUE INDEX `profits_exchange_symbol_trade_id` ON `profits` (`exchange`, `symbol`, `trade_id`);
+
+-- the exchange of the stored profits is filled by the sell trades
+UPDATE `profits` SET `exchange` = COALESCE((
+  SELECT `trades`.`exchange` FROM `trades`
+  WHERE `trades`.`symbol` = `profits`.`symbol` AND `trades`.`id` = `profits`.`trade_id` AND `trades`.`is_buyer` = FALSE
+  LIMIT 1
+), '');
+
+-- +goose Down
+-- sqlite can not drop columns, the table is rebuilt without the exchange column
+DROP INDEX `profits_exchange_symbol_trade_id`;
+DROP INDEX `profits_traded_at_symbol`;
+ALTER TABLE `profits` RENAME TO `profits_old`;
+CREATE TABLE `profits` (
+  `gid` INTEGER PRIMARY KEY AUTOINCREMENT,
+  `symbol` VARCHAR(12) NOT NULL,
+  `trade_id` INTEGER NOT NULL,
+  `price` DECIMAL(16, 8) NOT NULL,
+  `quantity` DECIMAL(16, 8) NOT NULL,
+  `cost` DECIMAL(16, 8) NOT NULL,
+  `fee` DECIMAL(16, 8) NOT NULL,
+  `profit` DECIMAL(16, 8) NOT NULL,
+  `holding_seconds` INTEGER NOT NULL DEFAULT 0,
+  `traded_at` DATETIME NOT NULL
+);
+INSERT INTO `profits` (`gid`, `symbol`, `trade_id`, `price`, `quantity`, `cost`, `fee`, `profit`, `holding_seconds`, `traded_at`)
+  SELECT `gid`, `symbol`, `trade_id`, `price`, `quantity`, `cost`, `fee`, `profit`, `holding_seconds`, `traded_at` FROM `profits_old`;
+DROP TABLE `profits_old`;
+CREATE UNIQUE INDEX `profits_symbol_trade_id` ON `profits` (`symbol`, `trade_id`);
+CREATE INDEX `profits_traded_at_symbol` ON `profits` (`traded_at`, `symbol`);
@@ -0,0 +1,25 @@
+-- +goose Up
+-- the binance trades synced before the exchange was filled have the empty exchange,
+-- the rows that are synced again with the exchange are kept
+DELETE FROM `trades` WHERE `exchange` = '' AND EXISTS (
+  SELECT 1 FROM `trades` AS `t` WHERE `t`.`exchange` = 'binance' AND `t`.`symbol` = `trades`.`symbol` AND `t`.`id` = `trades`.`id`
+);
+UPDATE `trades` SET `exchange` = 'binance' WHERE `exchange` = '';
+
+-- the max trades were stored with the lower case local symbols and fee currencies
+DELETE FROM `trades` WHERE `exchange` = 'max' AND `symbol` <> UPPER(`symbol`) AND EXISTS (
+  SELECT 1 FROM `trades` AS `t` WHERE `t`.`exchange` = 'max' AND `t`.`symbol` = UPPER(`trades`.`symbol`) AND `t`.`id` = `trades`.`id`
+);
+UPDATE `trades` SET `symbol` = UPPER(`symbol`), `fee_currency` = UPPER(`fee_currency`) WHERE `exchange` = 'max';
+
+DELETE FROM `profits` WHERE `exchange` = '' AND EXISTS (
+  SELECT 1 FROM `profits` AS `p` WHERE `p`.`exchange` = 'binance' AND `p`.`symbol` = `profits`.`symbol` AND `p`.`trade_id` = `profits`.`trade_id`
+);
+UPDATE `profits` SET `exchange` = 'binance' WHERE `exchange` = '';
+
+-- the stock checkpoints are rebuilt with the backfilled trades on the next start
+DELETE FROM `stock_checkpoints`;
+
+-- +goose Down
+-- the backfilled values can not be told apart from the synced values, nothing is reverted
+SELECT 1;
@@ -0,0 +1,16 @@
+-- +goose Up
+CREATE TABLE `strategy_states` (
+  `gid` INTEGER PRIMARY KEY AUTOINCREMENT,
+
+  -- state_key identifies the strategy instance, e.g., xmaker:max:binance:BTCUSDT
+  `state_key` VARCHAR(128) NOT NULL,
+
+  -- state is the JSON encoded state of the strategy
+  `state` TEXT NOT NULL,
+
+  `updated_at` DATETIME NOT NULL
+);
+
+CREATE UNIQUE INDEX `strategy_states_state_key` ON `strategy_states` (`state_key`);
+-- +goose Down
+DROP TABLE `strategy_states`;
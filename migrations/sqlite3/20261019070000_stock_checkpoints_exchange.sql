@@ -0,0 +1,28 @@
+-- +goose Up
+-- the stored checkpoints can not be told apart by the exchange, they are rebuilt from the trades on the next start
+DELETE FROM `stock_checkpoints`;
+
+DROP INDEX `stock_checkpoints_symbol`;
+ALTER TABLE `stock_checkpoints` ADD COLUMN `exchange` VARCHAR(24) NOT NULL DEFAULT '';
+CREATE UNIQUE INDEX `stock_checkpoints_exchange_symbol` ON `stock_checkpoints` (`exchange`, `symbol`);
+
+-- +goose Down
+-- sqlite can not drop columns, the table is rebuilt without the exchange column
+DROP TABLE `stock_checkpoints`;
+CREATE TABLE `stock_checkpoints` (
+  `gid` INTEGER PRIMARY KEY AUTOINCREMENT,
+
+  `symbol` VARCHAR(12) NOT NULL,
+  `cost_basis` VARCHAR(24) NOT NULL DEFAULT '',
+
+  -- the gid of the last trade that is processed by the stock manager
+  `last_trade_gid` INTEGER NOT NULL DEFAULT 0,
+
+  -- the JSON encoded stock lots and the pending sells
+  `stocks` TEXT NOT NULL,
+  `pending_sells` TEXT NOT NULL,
+
+  `updated_at` DATETIME NOT NULL
+);
+
+CREATE UNIQUE INDEX `stock_checkpoints_symbol` ON `stock_checkpoints` (`symbol`);
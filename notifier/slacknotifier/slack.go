@@ -122,3 +122,15 @@ func (n *Notifier) NotifyPnL(report *accounting.ProfitAndLossReport) {
 		logrus.WithError(err).Errorf("slack send error")
 	}
 }
+
+func (n *Notifier) NotifyReport(report accounting.Report) {
+	attachment := report.SlackAttachment()
+
+	_, _, err := n.client.PostMessageContext(context.Background(), n.PnlChannel,
+		slack.MsgOptionText(":bookmark_tabs: "+attachment.Title, true),
+		slack.MsgOptionAttachments(attachment))
+
+	if err != nil {
+		logrus.WithError(err).Errorf("slack send error")
+	}
+}
@@ -0,0 +1,131 @@
+package record
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/types"
+)
+
+type EventType string
+
+const (
+	EventTrade           = EventType("trade")
+	EventOrderUpdate     = EventType("orderUpdate")
+	EventBalanceSnapshot = EventType("balanceSnapshot")
+	EventBalanceUpdate   = EventType("balanceUpdate")
+	EventKLineClosed     = EventType("kLineClosed")
+	EventBookSnapshot    = EventType("bookSnapshot")
+	EventBookUpdate      = EventType("bookUpdate")
+)
+
+// Event is one line of the recorded file, the payload is kept raw so that
+// it can be decoded into the matching type when it's being replayed.
+type Event struct {
+	Time time.Time       `json:"time"`
+	Type EventType       `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+// Recorder writes the stream events as gzip compressed JSON lines
+type Recorder struct {
+	mu sync.Mutex
+
+	writer  io.Writer
+	gz      *gzip.Writer
+	encoder *json.Encoder
+}
+
+func NewRecorder(writer io.Writer) *Recorder {
+	gz := gzip.NewWriter(writer)
+	return &Recorder{
+		writer:  writer,
+		gz:      gz,
+		encoder: json.NewEncoder(gz),
+	}
+}
+
+// CreateRecorder creates the record file and returns the recorder that writes to it
+func CreateRecorder(filename string) (*Recorder, error) {
+	file, err := os.Create(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewRecorder(file), nil
+}
+
+func (r *Recorder) BindStream(stream types.Stream) {
+	stream.OnTrade(func(trade *types.Trade) {
+		r.record(EventTrade, trade)
+	})
+
+	stream.OnOrderUpdate(func(order types.Order) {
+		r.record(EventOrderUpdate, order)
+	})
+
+	stream.OnBalanceSnapshot(func(balances map[string]types.Balance) {
+		r.record(EventBalanceSnapshot, balances)
+	})
+
+	stream.OnBalanceUpdate(func(balances map[string]types.Balance) {
+		r.record(EventBalanceUpdate, balances)
+	})
+
+	stream.OnKLineClosed(func(kline types.KLine) {
+		r.record(EventKLineClosed, kline)
+	})
+
+	stream.OnBookSnapshot(func(book types.OrderBook) {
+		r.record(EventBookSnapshot, book)
+	})
+
+	stream.OnBookUpdate(func(book types.OrderBook) {
+		r.record(EventBookUpdate, book)
+	})
+}
+
+func (r *Recorder) record(eventType EventType, payload interface{}) {
+	if err := r.Write(time.Now(), eventType, payload); err != nil {
+		log.WithError(err).Errorf("[recorder] can not write %s event", eventType)
+	}
+}
+
+// Write appends an event with the given time to the record
+func (r *Recorder) Write(t time.Time, eventType EventType, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.encoder.Encode(Event{
+		Time: t,
+		Type: eventType,
+		Data: data,
+	})
+}
+
+// Close flushes the compressed data and closes the underlying writer if it's closable
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.gz.Close(); err != nil {
+		return err
+	}
+
+	if closer, ok := r.writer.(io.Closer); ok {
+		return closer.Close()
+	}
+
+	return nil
+}
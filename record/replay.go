@@ -0,0 +1,185 @@
+package record
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/types"
+)
+
+const (
+	// SpeedUnlimited replays the events as fast as possible
+	SpeedUnlimited = 0.0
+
+	// SpeedOriginal replays the events with the recorded intervals
+	SpeedOriginal = 1.0
+)
+
+func init() {
+	_ = types.Stream(&ReplayStream{})
+}
+
+// ReplayStream re-emits the recorded events through the standard stream callbacks,
+// so that it can be used in the place of an exchange stream.
+type ReplayStream struct {
+	types.StandardStream
+
+	// Speed is the replay speed multiplier, 2.0 means twice as fast as the original speed.
+	// Zero means replaying without any delay.
+	Speed float64
+
+	reader io.Reader
+	done   chan struct{}
+	cancel context.CancelFunc
+}
+
+func NewReplayStream(reader io.Reader, speed float64) *ReplayStream {
+	return &ReplayStream{
+		Speed:  speed,
+		reader: reader,
+		done:   make(chan struct{}),
+	}
+}
+
+// OpenReplayStream opens the record file written by the Recorder
+func OpenReplayStream(filename string, speed float64) (*ReplayStream, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewReplayStream(file, speed), nil
+}
+
+// Done returns a channel that is closed when all the recorded events are emitted
+func (s *ReplayStream) Done() <-chan struct{} {
+	return s.done
+}
+
+func (s *ReplayStream) Connect(ctx context.Context) error {
+	gz, err := gzip.NewReader(s.reader)
+	if err != nil {
+		return err
+	}
+
+	ctx, s.cancel = context.WithCancel(ctx)
+	go s.replay(ctx, gz)
+	return nil
+}
+
+func (s *ReplayStream) replay(ctx context.Context, reader io.ReadCloser) {
+	defer close(s.done)
+	defer reader.Close()
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	var lastTime time.Time
+	for scanner.Scan() {
+		var event Event
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			log.WithError(err).Error("[replay] event decode error")
+			continue
+		}
+
+		if !lastTime.IsZero() && s.Speed > 0 {
+			delay := time.Duration(float64(event.Time.Sub(lastTime)) / s.Speed)
+			if delay > 0 {
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(delay):
+				}
+			}
+		}
+		lastTime = event.Time
+
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		if err := s.emit(event); err != nil {
+			log.WithError(err).Errorf("[replay] %s event decode error", event.Type)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		log.WithError(err).Error("[replay] record read error")
+	}
+}
+
+func (s *ReplayStream) emit(event Event) error {
+	switch event.Type {
+
+	case EventTrade:
+		var trade types.Trade
+		if err := json.Unmarshal(event.Data, &trade); err != nil {
+			return err
+		}
+		s.EmitTrade(&trade)
+
+	case EventOrderUpdate:
+		var order types.Order
+		if err := json.Unmarshal(event.Data, &order); err != nil {
+			return err
+		}
+		s.EmitOrderUpdate(order)
+
+	case EventBalanceSnapshot, EventBalanceUpdate:
+		var balances map[string]types.Balance
+		if err := json.Unmarshal(event.Data, &balances); err != nil {
+			return err
+		}
+
+		if event.Type == EventBalanceSnapshot {
+			s.EmitBalanceSnapshot(balances)
+		} else {
+			s.EmitBalanceUpdate(balances)
+		}
+
+	case EventKLineClosed:
+		var kline types.KLine
+		if err := json.Unmarshal(event.Data, &kline); err != nil {
+			return err
+		}
+		s.EmitKLineClosed(kline)
+
+	case EventBookSnapshot, EventBookUpdate:
+		var book types.OrderBook
+		if err := json.Unmarshal(event.Data, &book); err != nil {
+			return err
+		}
+
+		if event.Type == EventBookSnapshot {
+			s.EmitBookSnapshot(book)
+		} else {
+			s.EmitBookUpdate(book)
+		}
+
+	default:
+		log.Warnf("[replay] unknown event type %s", event.Type)
+	}
+
+	return nil
+}
+
+func (s *ReplayStream) Close() error {
+	if s.cancel != nil {
+		s.cancel()
+	}
+
+	if closer, ok := s.reader.(io.Closer); ok {
+		return closer.Close()
+	}
+
+	return nil
+}
@@ -0,0 +1,73 @@
+package record
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/fixedpoint"
+	"github.com/c9s/bbgo/types"
+)
+
+func TestRecorderAndReplayStream(t *testing.T) {
+	var buf bytes.Buffer
+	recorder := NewRecorder(&buf)
+
+	now := time.Now()
+	book := types.OrderBook{
+		Symbol: "BTCUSDT",
+		Bids:   types.PriceVolumeSlice{{Price: fixedpoint.NewFromFloat(9000.0), Volume: fixedpoint.NewFromFloat(1.0)}},
+		Asks:   types.PriceVolumeSlice{{Price: fixedpoint.NewFromFloat(9001.0), Volume: fixedpoint.NewFromFloat(2.0)}},
+	}
+
+	assert.NoError(t, recorder.Write(now, EventBookSnapshot, book))
+	assert.NoError(t, recorder.Write(now.Add(time.Second), EventKLineClosed, types.KLine{Symbol: "BTCUSDT", Interval: "1m", Close: 9000.5}))
+	assert.NoError(t, recorder.Write(now.Add(2*time.Second), EventTrade, &types.Trade{ID: 1, Symbol: "BTCUSDT", Price: 9001.0, Quantity: 0.1}))
+	assert.NoError(t, recorder.Write(now.Add(3*time.Second), EventOrderUpdate, types.Order{
+		SubmitOrder: types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeBuy, Type: types.OrderTypeLimit, Quantity: 0.1, Price: 9001.0, ClientOrderID: "bbgo-1"},
+		OrderID:     100,
+		Status:      types.OrderStatusFilled,
+	}))
+	assert.NoError(t, recorder.Close())
+
+	stream := NewReplayStream(&buf, SpeedUnlimited)
+
+	var books []types.OrderBook
+	var klines []types.KLine
+	var trades []types.Trade
+	var orders []types.Order
+	stream.OnBookSnapshot(func(book types.OrderBook) { books = append(books, book) })
+	stream.OnKLineClosed(func(kline types.KLine) { klines = append(klines, kline) })
+	stream.OnTrade(func(trade *types.Trade) { trades = append(trades, *trade) })
+	stream.OnOrderUpdate(func(order types.Order) { orders = append(orders, order) })
+
+	assert.NoError(t, stream.Connect(context.Background()))
+
+	select {
+	case <-stream.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("replay timeout")
+	}
+
+	if assert.Len(t, books, 1) {
+		assert.Equal(t, book.Bids, books[0].Bids)
+		assert.Equal(t, book.Asks, books[0].Asks)
+	}
+
+	if assert.Len(t, klines, 1) {
+		assert.Equal(t, 9000.5, klines[0].Close)
+	}
+
+	if assert.Len(t, trades, 1) {
+		assert.Equal(t, int64(1), trades[0].ID)
+	}
+
+	if assert.Len(t, orders, 1) {
+		assert.Equal(t, uint64(100), orders[0].OrderID)
+		assert.Equal(t, "bbgo-1", orders[0].ClientOrderID)
+		assert.Equal(t, types.OrderStatusFilled, orders[0].Status)
+	}
+}
@@ -0,0 +1,27 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/c9s/bbgo/migrations"
+)
+
+// newTestDB creates an in-memory sqlite database with all the migrations applied
+func newTestDB(t *testing.T) *sqlx.DB {
+	db, err := sqlx.Connect("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// every connection of the in-memory database is a new database
+	db.SetMaxOpenConns(1)
+
+	if _, err := migrations.NewMigrator(db).Up(); err != nil {
+		t.Fatal(err)
+	}
+
+	return db
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+
+	"github.com/c9s/bbgo/types"
+)
+
+type DepositService struct {
+	DB *sqlx.DB
+}
+
+func NewDepositService(db *sqlx.DB) *DepositService {
+	return &DepositService{db}
+}
+
+// Insert inserts the deposit, the status is updated if the deposit is already inserted
+func (s *DepositService) Insert(deposit types.Deposit) error {
+	_, err := s.DB.NamedExec(`
+			INSERT INTO deposits (exchange, asset, address, address_tag, amount, txn_id, status, time)
+			VALUES (:exchange, :asset, :address, :address_tag, :amount, :txn_id, :status, :time) `+
+		onConflictUpdate(s.DB, []string{"exchange", "txn_id"}, "status"),
+		deposit)
+	return err
+}
+
+// QueryLast queries the last deposit of the exchange from the database
+func (s *DepositService) QueryLast(exchange string) (*types.Deposit, error) {
+	rows, err := s.DB.NamedQuery(`SELECT * FROM deposits WHERE exchange = :exchange ORDER BY time DESC LIMIT 1`, map[string]interface{}{
+		"exchange": exchange,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "query last deposit error")
+	}
+
+	defer rows.Close()
+
+	if rows.Next() {
+		var deposit types.Deposit
+		err = rows.StructScan(&deposit)
+		return &deposit, err
+	}
+
+	return nil, rows.Err()
+}
+
+// QueryFirstPending queries the first deposit of the exchange that is not credited or rejected yet
+func (s *DepositService) QueryFirstPending(exchange string) (*types.Deposit, error) {
+	rows, err := s.DB.NamedQuery(`SELECT * FROM deposits WHERE exchange = :exchange AND status = :status ORDER BY time ASC LIMIT 1`, map[string]interface{}{
+		"exchange": exchange,
+		"status":   types.DepositPending,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "query pending deposit error")
+	}
+
+	defer rows.Close()
+
+	if rows.Next() {
+		var deposit types.Deposit
+		err = rows.StructScan(&deposit)
+		return &deposit, err
+	}
+
+	return nil, rows.Err()
+}
+
+// Query queries the deposits of the exchange in the time range [since, until)
+func (s *DepositService) Query(exchange string, since, until time.Time) ([]types.Deposit, error) {
+	rows, err := s.DB.NamedQuery(`SELECT * FROM deposits WHERE exchange = :exchange AND time >= :since AND time < :until ORDER BY time ASC`, map[string]interface{}{
+		"exchange": exchange,
+		"since":    since,
+		"until":    until,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var deposits []types.Deposit
+	for rows.Next() {
+		var deposit types.Deposit
+		if err := rows.StructScan(&deposit); err != nil {
+			return nil, err
+		}
+
+		deposits = append(deposits, deposit)
+	}
+
+	return deposits, rows.Err()
+}
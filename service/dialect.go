@@ -0,0 +1,28 @@
+package service
+
+import (
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// onConflictUpdate returns the upsert clause of the database dialect, the update columns are replaced
+// with the inserted values when the insert conflicts with the unique key of the conflict columns.
+func onConflictUpdate(db *sqlx.DB, conflictColumns []string, updateColumns ...string) string {
+	var assignments []string
+
+	switch db.DriverName() {
+	case "mysql":
+		for _, column := range updateColumns {
+			assignments = append(assignments, column+" = VALUES("+column+")")
+		}
+
+		return "ON DUPLICATE KEY UPDATE " + strings.Join(assignments, ", ")
+	}
+
+	for _, column := range updateColumns {
+		assignments = append(assignments, column+" = excluded."+column)
+	}
+
+	return "ON CONFLICT (" + strings.Join(conflictColumns, ", ") + ") DO UPDATE SET " + strings.Join(assignments, ", ")
+}
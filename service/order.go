@@ -0,0 +1,216 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/types"
+)
+
+// OrderSync backfills the historical orders of the exchange into the database,
+// the stored orders that are not closed yet are queried again so that their status is updated.
+type OrderSync struct {
+	Service *OrderService
+}
+
+func (s *OrderSync) Sync(ctx context.Context, exchange types.Exchange, symbol string, startTime time.Time) error {
+	querier, ok := exchange.(types.OrderHistoryQuerier)
+	if !ok {
+		log.Warnf("exchange %s does not support querying the order history, skip syncing orders", exchange.Name())
+		return nil
+	}
+
+	lastOrder, err := s.Service.QueryLast(exchange.Name(), symbol)
+	if err != nil {
+		return err
+	}
+
+	var lastID uint64 = 0
+	if lastOrder != nil {
+		lastID = lastOrder.OrderID
+		startTime = lastOrder.CreationTime
+
+		log.Infof("found last order, start from lastID = %d since %s", lastID, startTime)
+	}
+
+	// the orders after the first open order are queried again, the open orders may be filled or canceled after they are synced
+	firstOpenOrder, err := s.Service.QueryFirstOpen(exchange.Name(), symbol)
+	if err != nil {
+		return err
+	}
+
+	if firstOpenOrder != nil && firstOpenOrder.OrderID <= lastID {
+		lastID = firstOpenOrder.OrderID - 1
+		startTime = firstOpenOrder.CreationTime
+
+		log.Infof("found open order %d, start from lastID = %d since %s", firstOpenOrder.OrderID, lastID, startTime)
+	}
+
+	orders, err := querier.QueryOrderHistory(ctx, symbol, startTime, lastID)
+	if err != nil {
+		return err
+	}
+
+	for _, order := range orders {
+		if err := s.Service.Insert(order); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type OrderService struct {
+	DB *sqlx.DB
+}
+
+func NewOrderService(db *sqlx.DB) *OrderService {
+	return &OrderService{db}
+}
+
+// orderStatusRank ranks the order status by the order life cycle, the closed orders have the highest rank
+func orderStatusRank(status types.OrderStatus) int {
+	switch status {
+	case types.OrderStatusNew:
+		return 0
+	case types.OrderStatusPartiallyFilled:
+		return 1
+	}
+
+	return 2
+}
+
+// isOrderStale returns true if the order is older than the stored order, e.g., a NEW order event that is received
+// after the order is filled, the stale order must not overwrite the stored order.
+func isOrderStale(order, stored types.Order) bool {
+	rank, storedRank := orderStatusRank(order.Status), orderStatusRank(stored.Status)
+	if rank != storedRank {
+		return rank < storedRank
+	}
+
+	if order.ExecutedQuantity != stored.ExecutedQuantity {
+		return order.ExecutedQuantity < stored.ExecutedQuantity
+	}
+
+	return order.UpdateTime.Before(stored.UpdateTime)
+}
+
+// Insert inserts the order, the status and the executed quantity are updated if the order is already inserted
+// and the order is not older than the stored order.
+func (s *OrderService) Insert(order types.Order) error {
+	if order.UpdateTime.IsZero() {
+		order.UpdateTime = order.CreationTime
+	}
+
+	stored, err := s.QueryByOrderID(order.Exchange, order.OrderID)
+	if err != nil {
+		return err
+	}
+
+	if stored != nil && isOrderStale(order, *stored) {
+		log.Debugf("skip the stale %s order %d update, status %s, stored status %s", order.Exchange, order.OrderID, order.Status, stored.Status)
+		return nil
+	}
+
+	_, err = s.DB.NamedExec(`
+			INSERT INTO orders (exchange, order_id, client_order_id, order_type, symbol, status, time_in_force, side, price, quantity, executed_quantity, created_at, updated_at)
+			VALUES (:exchange, :order_id, :client_order_id, :order_type, :symbol, :status, :time_in_force, :side, :price, :quantity, :executed_quantity, :created_at, :updated_at) `+
+		onConflictUpdate(s.DB, []string{"exchange", "order_id"}, "status", "executed_quantity", "updated_at"),
+		order)
+	return err
+}
+
+// QueryLast queries the last order of the exchange and the symbol from the database
+func (s *OrderService) QueryLast(exchange string, symbol string) (*types.Order, error) {
+	rows, err := s.DB.NamedQuery(`SELECT * FROM orders WHERE exchange = :exchange AND symbol = :symbol ORDER BY order_id DESC LIMIT 1`, map[string]interface{}{
+		"exchange": exchange,
+		"symbol":   symbol,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "query last order error")
+	}
+
+	defer rows.Close()
+
+	if rows.Next() {
+		var order types.Order
+		err = rows.StructScan(&order)
+		return &order, err
+	}
+
+	return nil, rows.Err()
+}
+
+// QueryFirstOpen queries the open order (NEW or PARTIALLY_FILLED) of the lowest order ID, nil is returned if there is no open order
+func (s *OrderService) QueryFirstOpen(exchange string, symbol string) (*types.Order, error) {
+	rows, err := s.DB.NamedQuery(`SELECT * FROM orders WHERE exchange = :exchange AND symbol = :symbol AND status IN (:new, :partially_filled) ORDER BY order_id ASC LIMIT 1`, map[string]interface{}{
+		"exchange":         exchange,
+		"symbol":           symbol,
+		"new":              types.OrderStatusNew,
+		"partially_filled": types.OrderStatusPartiallyFilled,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "query first open order error")
+	}
+
+	defer rows.Close()
+
+	if rows.Next() {
+		var order types.Order
+		err = rows.StructScan(&order)
+		return &order, err
+	}
+
+	return nil, rows.Err()
+}
+
+// QueryByOrderID queries the order by the exchange order ID, nil is returned if the order is not found
+func (s *OrderService) QueryByOrderID(exchange string, orderID uint64) (*types.Order, error) {
+	rows, err := s.DB.NamedQuery(`SELECT * FROM orders WHERE exchange = :exchange AND order_id = :order_id`, map[string]interface{}{
+		"exchange": exchange,
+		"order_id": orderID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	if rows.Next() {
+		var order types.Order
+		err = rows.StructScan(&order)
+		return &order, err
+	}
+
+	return nil, rows.Err()
+}
+
+// Query queries the orders of the exchange and the symbol created since the given time
+func (s *OrderService) Query(exchange string, symbol string, since time.Time) ([]types.Order, error) {
+	rows, err := s.DB.NamedQuery(`SELECT * FROM orders WHERE exchange = :exchange AND symbol = :symbol AND created_at >= :since ORDER BY gid ASC`, map[string]interface{}{
+		"exchange": exchange,
+		"symbol":   symbol,
+		"since":    since,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var orders []types.Order
+	for rows.Next() {
+		var order types.Order
+		if err := rows.StructScan(&order); err != nil {
+			return nil, err
+		}
+
+		orders = append(orders, order)
+	}
+
+	return orders, rows.Err()
+}
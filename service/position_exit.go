@@ -0,0 +1,85 @@
+package service
+
+import (
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+// PositionExit is the state of the position watched by the exit manager, it's saved after every change
+// so that the trailing stop and the take-profit ladder continue after restarts
+type PositionExit struct {
+	GID      int64  `db:"gid"`
+	Exchange string `db:"exchange"`
+	Symbol   string `db:"symbol"`
+
+	EntryPrice      float64 `db:"entry_price"`
+	InitialQuantity float64 `db:"initial_quantity"`
+
+	// Quantity is the remaining quantity of the position
+	Quantity float64 `db:"quantity"`
+
+	// PeakPrice is the highest price since the entry
+	PeakPrice float64 `db:"peak_price"`
+
+	// TakeProfits is the number of the triggered take-profit levels
+	TakeProfits int `db:"take_profits"`
+
+	OpenedAt  time.Time `db:"opened_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+type PositionExitService struct {
+	DB *sqlx.DB
+}
+
+func NewPositionExitService(db *sqlx.DB) *PositionExitService {
+	return &PositionExitService{db}
+}
+
+// Query returns nil if the position of the exchange and the symbol is not found
+func (s *PositionExitService) Query(exchange, symbol string) (*PositionExit, error) {
+	rows, err := s.DB.NamedQuery(`SELECT * FROM position_exits WHERE exchange = :exchange AND symbol = :symbol LIMIT 1`, map[string]interface{}{
+		"exchange": exchange,
+		"symbol":   symbol,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "query position exit error")
+	}
+
+	defer rows.Close()
+
+	if !rows.Next() {
+		return nil, rows.Err()
+	}
+
+	var position PositionExit
+	if err := rows.StructScan(&position); err != nil {
+		return nil, err
+	}
+
+	return &position, nil
+}
+
+// Save inserts or replaces the position of the exchange and the symbol
+func (s *PositionExitService) Save(position PositionExit) error {
+	if position.UpdatedAt.IsZero() {
+		position.UpdatedAt = time.Now()
+	}
+
+	_, err := s.DB.NamedExec(`
+			INSERT INTO position_exits (exchange, symbol, entry_price, initial_quantity, quantity, peak_price, take_profits, opened_at, updated_at)
+			VALUES (:exchange, :symbol, :entry_price, :initial_quantity, :quantity, :peak_price, :take_profits, :opened_at, :updated_at) `+
+		onConflictUpdate(s.DB, []string{"exchange", "symbol"}, "entry_price", "initial_quantity", "quantity", "peak_price", "take_profits", "opened_at", "updated_at"),
+		position)
+	return err
+}
+
+func (s *PositionExitService) Delete(exchange, symbol string) error {
+	_, err := s.DB.NamedExec(`DELETE FROM position_exits WHERE exchange = :exchange AND symbol = :symbol`, map[string]interface{}{
+		"exchange": exchange,
+		"symbol":   symbol,
+	})
+	return err
+}
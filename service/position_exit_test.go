@@ -0,0 +1,62 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPositionExitService(t *testing.T) {
+	db := newTestDB(t)
+	defer db.Close()
+
+	service := NewPositionExitService(db)
+
+	position, err := service.Query("binance", "BTCUSDT")
+	assert.NoError(t, err)
+	assert.Nil(t, position)
+
+	openedAt := time.Date(2020, 10, 1, 0, 0, 0, 0, time.UTC)
+	assert.NoError(t, service.Save(PositionExit{
+		Exchange:        "binance",
+		Symbol:          "BTCUSDT",
+		EntryPrice:      10000.0,
+		InitialQuantity: 1.0,
+		Quantity:        1.0,
+		PeakPrice:       10000.0,
+		OpenedAt:        openedAt,
+	}))
+
+	// the position of the exchange and the symbol is replaced
+	assert.NoError(t, service.Save(PositionExit{
+		Exchange:        "binance",
+		Symbol:          "BTCUSDT",
+		EntryPrice:      10000.0,
+		InitialQuantity: 1.0,
+		Quantity:        0.5,
+		PeakPrice:       11000.0,
+		TakeProfits:     1,
+		OpenedAt:        openedAt,
+	}))
+
+	assert.NoError(t, service.Save(PositionExit{Exchange: "max", Symbol: "BTCUSDT", EntryPrice: 9000.0, Quantity: 0.1, OpenedAt: openedAt}))
+
+	position, err = service.Query("binance", "BTCUSDT")
+	if assert.NoError(t, err) && assert.NotNil(t, position) {
+		assert.Equal(t, 0.5, position.Quantity)
+		assert.Equal(t, 11000.0, position.PeakPrice)
+		assert.Equal(t, 1, position.TakeProfits)
+		assert.True(t, openedAt.Equal(position.OpenedAt))
+	}
+
+	assert.NoError(t, service.Delete("binance", "BTCUSDT"))
+
+	position, err = service.Query("binance", "BTCUSDT")
+	assert.NoError(t, err)
+	assert.Nil(t, position)
+
+	position, err = service.Query("max", "BTCUSDT")
+	assert.NoError(t, err)
+	assert.NotNil(t, position)
+}
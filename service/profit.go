@@ -0,0 +1,63 @@
+package service
+
+import (
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/c9s/bbgo/types"
+)
+
+type ProfitService struct {
+	DB *sqlx.DB
+}
+
+func NewProfitService(db *sqlx.DB) *ProfitService {
+	return &ProfitService{db}
+}
+
+// Insert inserts the profit of the sell trade, the stored profit of the same trade is replaced,
+// so that replaying the same trades is idempotent.
+func (s *ProfitService) Insert(profit types.Profit) error {
+	_, err := s.DB.NamedExec(`
+			INSERT INTO profits (exchange, symbol, trade_id, price, quantity, cost, fee, profit, holding_seconds, traded_at)
+			VALUES (:exchange, :symbol, :trade_id, :price, :quantity, :cost, :fee, :profit, :holding_seconds, :traded_at) `+
+		onConflictUpdate(s.DB, []string{"exchange", "symbol", "trade_id"}, "price", "quantity", "cost", "fee", "profit", "holding_seconds", "traded_at"),
+		profit)
+	return err
+}
+
+// Query queries the profits of the exchange and the symbol traded since the given time
+func (s *ProfitService) Query(exchange string, symbol string, since time.Time) ([]types.Profit, error) {
+	rows, err := s.DB.NamedQuery(`SELECT * FROM profits WHERE exchange = :exchange AND symbol = :symbol AND traded_at >= :since ORDER BY traded_at ASC`, map[string]interface{}{
+		"exchange": exchange,
+		"symbol":   symbol,
+		"since":    since,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var profits []types.Profit
+	for rows.Next() {
+		var profit types.Profit
+		if err := rows.StructScan(&profit); err != nil {
+			return nil, err
+		}
+
+		profits = append(profits, profit)
+	}
+
+	return profits, rows.Err()
+}
+
+// Delete deletes the profits of the exchange and the symbol, e.g., the profits are recorded again after the trades are repaired
+func (s *ProfitService) Delete(exchange string, symbol string) error {
+	_, err := s.DB.NamedExec(`DELETE FROM profits WHERE exchange = :exchange AND symbol = :symbol`, map[string]interface{}{
+		"exchange": exchange,
+		"symbol":   symbol,
+	})
+	return err
+}
@@ -0,0 +1,292 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/types"
+	"github.com/c9s/bbgo/util"
+)
+
+// TradeMismatch is a stored trade that is different from the exchange trade of the same id
+type TradeMismatch struct {
+	Stored types.Trade
+	Remote types.Trade
+
+	// Fields are the names of the different fields, e.g., price, quantity and fee
+	Fields []string
+}
+
+// TradeReconciliation is the difference between the stored trades and the exchange trades in a time range
+type TradeReconciliation struct {
+	Exchange string
+	Symbol   string
+	Since    time.Time
+	Until    time.Time
+
+	// Matched is the number of the stored trades that are the same as the exchange trades
+	Matched int
+
+	// Missing are the exchange trades that are not in the database
+	Missing []types.Trade
+
+	// Extra are the stored trades that are not returned by the exchange
+	Extra []types.Trade
+
+	Mismatched []TradeMismatch
+}
+
+// OK returns true if there is no difference
+func (r TradeReconciliation) OK() bool {
+	return len(r.Missing) == 0 && len(r.Extra) == 0 && len(r.Mismatched) == 0
+}
+
+func (r TradeReconciliation) Print() {
+	log.Infof("%s %s trades from %s to %s: %d matched, %d missing, %d extra, %d mismatched",
+		r.Exchange, r.Symbol, r.Since.Format(time.RFC822), r.Until.Format(time.RFC822),
+		r.Matched, len(r.Missing), len(r.Extra), len(r.Mismatched))
+
+	for _, trade := range r.Missing {
+		log.Warnf(" - missing trade %d at %s: %s price %f quantity %f fee %f %s",
+			trade.ID, trade.Time, trade.Side, trade.Price, trade.Quantity, trade.Fee, trade.FeeCurrency)
+	}
+
+	for _, trade := range r.Extra {
+		log.Warnf(" - extra trade %d at %s: %s price %f quantity %f fee %f %s",
+			trade.ID, trade.Time, trade.Side, trade.Price, trade.Quantity, trade.Fee, trade.FeeCurrency)
+	}
+
+	for _, m := range r.Mismatched {
+		log.Warnf(" - mismatched trade %d at %s %v: stored price %f quantity %f fee %f %s, exchange price %f quantity %f fee %f %s",
+			m.Remote.ID, m.Remote.Time, m.Fields,
+			m.Stored.Price, m.Stored.Quantity, m.Stored.Fee, m.Stored.FeeCurrency,
+			m.Remote.Price, m.Remote.Quantity, m.Remote.Fee, m.Remote.FeeCurrency)
+	}
+}
+
+// TradeReconciler finds the missing, the extra and the mismatched trades by comparing the stored trades with the exchange trades,
+// the differences cause the profit and loss drift.
+type TradeReconciler struct {
+	Service *TradeService
+
+	// StockService and ProfitService are optional, the stock checkpoint and the profits of the symbol are deleted
+	// when the trades are repaired since they are built from the wrong trades.
+	StockService  *StockService
+	ProfitService *ProfitService
+}
+
+// Reconcile compares the stored trades with the exchange trades that are traded in the time range [since, until)
+func (r *TradeReconciler) Reconcile(ctx context.Context, exchange types.Exchange, symbol string, since, until time.Time) (*TradeReconciliation, error) {
+	remoteTrades, err := queryTradesInRange(ctx, exchange, symbol, since, until)
+	if err != nil {
+		return nil, err
+	}
+
+	storedTrades, err := r.Service.QueryRange(exchange.Name(), symbol, since, until)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &TradeReconciliation{
+		Exchange: exchange.Name(),
+		Symbol:   symbol,
+		Since:    since,
+		Until:    until,
+	}
+
+	var stored = make(map[int64]types.Trade, len(storedTrades))
+	for _, trade := range storedTrades {
+		stored[trade.ID] = trade
+	}
+
+	var remote = make(map[int64]struct{}, len(remoteTrades))
+	for _, trade := range remoteTrades {
+		remote[trade.ID] = struct{}{}
+
+		storedTrade, ok := stored[trade.ID]
+		if !ok {
+			report.Missing = append(report.Missing, trade)
+			continue
+		}
+
+		if fields := diffTrade(storedTrade, trade); len(fields) > 0 {
+			report.Mismatched = append(report.Mismatched, TradeMismatch{Stored: storedTrade, Remote: trade, Fields: fields})
+			continue
+		}
+
+		report.Matched++
+	}
+
+	for _, trade := range storedTrades {
+		if _, ok := remote[trade.ID]; !ok {
+			report.Extra = append(report.Extra, trade)
+		}
+	}
+
+	return report, nil
+}
+
+// Repair makes the stored trades the same as the exchange trades, the missing trades are inserted and
+// the mismatched trades are updated. The extra trades are only deleted when deleteExtra is true,
+// since the exchange may not return the trades of a delisted market or a merged account.
+// The stock checkpoint and the profits of the symbol are invalidated after the trades are changed,
+// they are rebuilt from all the trades when the stocks are loaded next time.
+func (r *TradeReconciler) Repair(report *TradeReconciliation, deleteExtra bool) error {
+	for _, trade := range report.Missing {
+		if err := r.Service.Insert(trade); err != nil {
+			return err
+		}
+	}
+
+	for _, m := range report.Mismatched {
+		if err := r.Service.Update(m.Remote); err != nil {
+			return err
+		}
+	}
+
+	var numDeleted = 0
+	if deleteExtra {
+		for _, trade := range report.Extra {
+			if err := r.Service.Delete(trade); err != nil {
+				return err
+			}
+		}
+
+		numDeleted = len(report.Extra)
+	} else if len(report.Extra) > 0 {
+		log.Warnf("%s %s has %d extra trades, they are kept since deleting the extra trades is not enabled",
+			report.Exchange, report.Symbol, len(report.Extra))
+	}
+
+	log.Infof("%s %s trades repaired: %d inserted, %d updated, %d deleted",
+		report.Exchange, report.Symbol, len(report.Missing), len(report.Mismatched), numDeleted)
+
+	if len(report.Missing) == 0 && len(report.Mismatched) == 0 && numDeleted == 0 {
+		return nil
+	}
+
+	return r.invalidate(report.Exchange, report.Symbol)
+}
+
+// minTradeTime is the earliest valid trade time, the binance stream trades were once parsed with the
+// millisecond transaction time as nanoseconds, so they are stored with a traded_at right after the unix epoch.
+var minTradeTime = time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// RepairTradeTimes re-queries the stored trades that are traded before minTradeTime by the trade id from the exchange
+// and updates them with the exchange trades. The stock checkpoint and the profits of the symbol are invalidated
+// if any trade is repaired, the number of the repaired trades is returned.
+func (r *TradeReconciler) RepairTradeTimes(ctx context.Context, exchange types.Exchange, symbol string) (int, error) {
+	storedTrades, err := r.Service.QueryRange(exchange.Name(), symbol, time.Unix(0, 0), minTradeTime)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(storedTrades) == 0 {
+		return 0, nil
+	}
+
+	log.Warnf("%s %s has %d trades with the invalid trade time, repairing them from the exchange",
+		exchange.Name(), symbol, len(storedTrades))
+
+	var cursor int64
+	var invalid = make(map[int64]struct{}, len(storedTrades))
+	for _, trade := range storedTrades {
+		invalid[trade.ID] = struct{}{}
+		if cursor == 0 || trade.ID < cursor {
+			cursor = trade.ID
+		}
+	}
+
+	var repaired int
+	for len(invalid) > 0 {
+		// the trades are queried from the trade id, including the trade of the id
+		trades, err := exchange.QueryTrades(ctx, symbol, &types.TradeQueryOptions{
+			LastTradeID: cursor,
+			Limit:       1000,
+		})
+		if err != nil {
+			return repaired, err
+		}
+
+		if len(trades) == 0 {
+			break
+		}
+
+		for _, trade := range trades {
+			if _, ok := invalid[trade.ID]; !ok {
+				continue
+			}
+
+			if err := r.Service.Update(trade); err != nil {
+				return repaired, err
+			}
+
+			delete(invalid, trade.ID)
+			repaired++
+		}
+
+		lastID := trades[len(trades)-1].ID
+		if lastID < cursor {
+			break
+		}
+
+		cursor = lastID + 1
+	}
+
+	if len(invalid) > 0 {
+		log.Warnf("%s %s has %d trades with the invalid trade time that are not found on the exchange",
+			exchange.Name(), symbol, len(invalid))
+	}
+
+	if repaired == 0 {
+		return 0, nil
+	}
+
+	log.Infof("%s %s trade times repaired: %d updated", exchange.Name(), symbol, repaired)
+	return repaired, r.invalidate(exchange.Name(), symbol)
+}
+
+// invalidate deletes the stock checkpoint and the profits of the symbol
+func (r *TradeReconciler) invalidate(exchange, symbol string) error {
+	if r.StockService != nil {
+		if err := r.StockService.DeleteCheckpoint(exchange, symbol); err != nil {
+			return err
+		}
+	}
+
+	if r.ProfitService != nil {
+		if err := r.ProfitService.Delete(exchange, symbol); err != nil {
+			return err
+		}
+	}
+
+	log.Infof("%s %s stock checkpoint and profits are invalidated", exchange, symbol)
+	return nil
+}
+
+// diffTrade returns the names of the fields that are different, the amounts are compared in the precision of the database
+func diffTrade(a, b types.Trade) (fields []string) {
+	if util.NotZero(a.Price - b.Price) {
+		fields = append(fields, "price")
+	}
+
+	if util.NotZero(a.Quantity - b.Quantity) {
+		fields = append(fields, "quantity")
+	}
+
+	if util.NotZero(a.Fee - b.Fee) {
+		fields = append(fields, "fee")
+	}
+
+	if a.FeeCurrency != b.FeeCurrency {
+		fields = append(fields, "fee_currency")
+	}
+
+	if a.Side != b.Side {
+		fields = append(fields, "side")
+	}
+
+	return fields
+}
@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/types"
+)
+
+func TestTradeReconciler(t *testing.T) {
+	db := newTestDB(t)
+	defer db.Close()
+
+	since := time.Date(2020, 8, 1, 0, 0, 0, 0, time.UTC)
+	until := since.Add(24 * time.Hour)
+
+	exchange := &testTradeHistoryExchange{}
+	for i := 1; i <= 4; i++ {
+		exchange.trades = append(exchange.trades, newTestTrade("binance", int64(i), "BTCUSDT", since.Add(time.Duration(i)*time.Hour)))
+	}
+
+	service := NewTradeService(db)
+
+	// trade 2 is missing, trade 3 has the wrong fee and trade 100 is not on the exchange
+	assert.NoError(t, service.Insert(exchange.trades[0]))
+	wrongTrade := exchange.trades[2]
+	wrongTrade.Fee = 0.1
+	wrongTrade.Quantity = 1.0
+	assert.NoError(t, service.Insert(wrongTrade))
+	assert.NoError(t, service.Insert(exchange.trades[3]))
+	assert.NoError(t, service.Insert(newTestTrade("binance", 100, "BTCUSDT", since.Add(5*time.Hour))))
+
+	// out of the range
+	assert.NoError(t, service.Insert(newTestTrade("binance", 200, "BTCUSDT", until)))
+
+	stockService := NewStockService(db)
+	assert.NoError(t, stockService.SaveCheckpoint(StockCheckpoint{Exchange: "binance", Symbol: "BTCUSDT", CostBasis: "fifo", LastTradeGID: 5}))
+
+	profitService := NewProfitService(db)
+	assert.NoError(t, profitService.Insert(types.Profit{Exchange: "binance", Symbol: "BTCUSDT", TradeID: 3, Time: since}))
+
+	reconciler := &TradeReconciler{Service: service, StockService: stockService, ProfitService: profitService}
+	report, err := reconciler.Reconcile(context.Background(), exchange, "BTCUSDT", since, until)
+	if assert.NoError(t, err) {
+		assert.False(t, report.OK())
+		assert.Equal(t, 2, report.Matched)
+		if assert.Len(t, report.Missing, 1) {
+			assert.Equal(t, int64(2), report.Missing[0].ID)
+		}
+		if assert.Len(t, report.Extra, 1) {
+			assert.Equal(t, int64(100), report.Extra[0].ID)
+		}
+		if assert.Len(t, report.Mismatched, 1) {
+			assert.Equal(t, int64(3), report.Mismatched[0].Remote.ID)
+			assert.Equal(t, []string{"quantity", "fee"}, report.Mismatched[0].Fields)
+		}
+	}
+
+	// the extra trades are kept without the explicit flag
+	assert.NoError(t, reconciler.Repair(report, false))
+
+	report, err = reconciler.Reconcile(context.Background(), exchange, "BTCUSDT", since, until)
+	if assert.NoError(t, err) {
+		assert.False(t, report.OK())
+		assert.Equal(t, 4, report.Matched)
+		assert.Len(t, report.Extra, 1)
+	}
+
+	// the checkpoint and the profits built from the wrong trades are invalidated
+	checkpoint, err := stockService.QueryCheckpoint("binance", "BTCUSDT")
+	assert.NoError(t, err)
+	assert.Nil(t, checkpoint)
+
+	profits, err := profitService.Query("binance", "BTCUSDT", since)
+	assert.NoError(t, err)
+	assert.Len(t, profits, 0)
+
+	assert.NoError(t, reconciler.Repair(report, true))
+
+	report, err = reconciler.Reconcile(context.Background(), exchange, "BTCUSDT", since, until)
+	if assert.NoError(t, err) {
+		assert.True(t, report.OK())
+		assert.Equal(t, 4, report.Matched)
+	}
+
+	// the trades out of the range are not touched
+	trades, err := service.Query("binance", "BTCUSDT")
+	assert.NoError(t, err)
+	assert.Len(t, trades, 5)
+}
+
+func TestTradeReconciler_RepairTradeTimes(t *testing.T) {
+	db := newTestDB(t)
+	defer db.Close()
+
+	since := time.Date(2020, 8, 1, 0, 0, 0, 0, time.UTC)
+
+	exchange := &testTradeHistoryExchange{}
+	for i := 1; i <= 1500; i++ {
+		exchange.trades = append(exchange.trades, newTestTrade("binance", int64(i), "BTCUSDT", since.Add(time.Duration(i)*time.Minute)))
+	}
+
+	service := NewTradeService(db)
+
+	// the stream trades 2 and 1200 were stored with the transaction time parsed as nanoseconds
+	for _, id := range []int64{1, 2, 1200} {
+		trade := exchange.trades[id-1]
+		if id != 1 {
+			trade.Time = time.Unix(0, trade.Time.UnixNano()/int64(time.Millisecond)/1000000)
+		}
+		assert.NoError(t, service.Insert(trade))
+	}
+
+	stockService := NewStockService(db)
+	assert.NoError(t, stockService.SaveCheckpoint(StockCheckpoint{Exchange: "binance", Symbol: "BTCUSDT", CostBasis: "fifo", LastTradeGID: 3}))
+
+	reconciler := &TradeReconciler{Service: service, StockService: stockService}
+	repaired, err := reconciler.RepairTradeTimes(context.Background(), exchange, "BTCUSDT")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, repaired)
+
+	trades, err := service.Query("binance", "BTCUSDT")
+	if assert.NoError(t, err) && assert.Len(t, trades, 3) {
+		for i, id := range []int64{1, 2, 1200} {
+			assert.Equal(t, id, trades[i].ID)
+			assert.Equal(t, exchange.trades[id-1].Time, trades[i].Time.UTC())
+		}
+	}
+
+	// the checkpoint built from the trades with the invalid time is invalidated
+	checkpoint, err := stockService.QueryCheckpoint("binance", "BTCUSDT")
+	assert.NoError(t, err)
+	assert.Nil(t, checkpoint)
+
+	// nothing to repair
+	repaired, err = reconciler.RepairTradeTimes(context.Background(), exchange, "BTCUSDT")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, repaired)
+}
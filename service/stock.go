@@ -0,0 +1,121 @@
+package service
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+
+	"github.com/c9s/bbgo/types"
+)
+
+// StockCheckpoint is the snapshot of the stock lots after the trades up to LastTradeGID are processed
+type StockCheckpoint struct {
+	Exchange     string
+	Symbol       string
+	CostBasis    string
+	LastTradeGID int64
+	Stocks       []types.Trade
+	PendingSells []types.Trade
+	UpdatedAt    time.Time
+}
+
+type stockCheckpointRow struct {
+	GID          int64     `db:"gid"`
+	Exchange     string    `db:"exchange"`
+	Symbol       string    `db:"symbol"`
+	CostBasis    string    `db:"cost_basis"`
+	LastTradeGID int64     `db:"last_trade_gid"`
+	Stocks       string    `db:"stocks"`
+	PendingSells string    `db:"pending_sells"`
+	UpdatedAt    time.Time `db:"updated_at"`
+}
+
+type StockService struct {
+	DB *sqlx.DB
+}
+
+func NewStockService(db *sqlx.DB) *StockService {
+	return &StockService{db}
+}
+
+// QueryCheckpoint returns nil if the checkpoint of the exchange symbol is not found
+func (s *StockService) QueryCheckpoint(exchange, symbol string) (*StockCheckpoint, error) {
+	rows, err := s.DB.NamedQuery(`SELECT * FROM stock_checkpoints WHERE exchange = :exchange AND symbol = :symbol LIMIT 1`, map[string]interface{}{
+		"exchange": exchange,
+		"symbol":   symbol,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "query stock checkpoint error")
+	}
+
+	defer rows.Close()
+
+	if !rows.Next() {
+		return nil, rows.Err()
+	}
+
+	var row stockCheckpointRow
+	if err := rows.StructScan(&row); err != nil {
+		return nil, err
+	}
+
+	checkpoint := &StockCheckpoint{
+		Exchange:     row.Exchange,
+		Symbol:       row.Symbol,
+		CostBasis:    row.CostBasis,
+		LastTradeGID: row.LastTradeGID,
+		UpdatedAt:    row.UpdatedAt,
+	}
+
+	if err := json.Unmarshal([]byte(row.Stocks), &checkpoint.Stocks); err != nil {
+		return nil, errors.Wrapf(err, "%s %s stock checkpoint decode error", exchange, symbol)
+	}
+
+	if err := json.Unmarshal([]byte(row.PendingSells), &checkpoint.PendingSells); err != nil {
+		return nil, errors.Wrapf(err, "%s %s stock checkpoint decode error", exchange, symbol)
+	}
+
+	return checkpoint, nil
+}
+
+// SaveCheckpoint inserts or replaces the checkpoint of the exchange symbol
+func (s *StockService) SaveCheckpoint(checkpoint StockCheckpoint) error {
+	stocks, err := json.Marshal(checkpoint.Stocks)
+	if err != nil {
+		return err
+	}
+
+	pendingSells, err := json.Marshal(checkpoint.PendingSells)
+	if err != nil {
+		return err
+	}
+
+	if checkpoint.UpdatedAt.IsZero() {
+		checkpoint.UpdatedAt = time.Now()
+	}
+
+	_, err = s.DB.NamedExec(`
+			INSERT INTO stock_checkpoints (exchange, symbol, cost_basis, last_trade_gid, stocks, pending_sells, updated_at)
+			VALUES (:exchange, :symbol, :cost_basis, :last_trade_gid, :stocks, :pending_sells, :updated_at) `+
+		onConflictUpdate(s.DB, []string{"exchange", "symbol"}, "cost_basis", "last_trade_gid", "stocks", "pending_sells", "updated_at"),
+		stockCheckpointRow{
+			Exchange:     checkpoint.Exchange,
+			Symbol:       checkpoint.Symbol,
+			CostBasis:    checkpoint.CostBasis,
+			LastTradeGID: checkpoint.LastTradeGID,
+			Stocks:       string(stocks),
+			PendingSells: string(pendingSells),
+			UpdatedAt:    checkpoint.UpdatedAt,
+		})
+	return err
+}
+
+func (s *StockService) DeleteCheckpoint(exchange, symbol string) error {
+	_, err := s.DB.NamedExec(`DELETE FROM stock_checkpoints WHERE exchange = :exchange AND symbol = :symbol`, map[string]interface{}{
+		"exchange": exchange,
+		"symbol":   symbol,
+	})
+	return err
+}
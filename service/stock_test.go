@@ -0,0 +1,99 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/types"
+)
+
+func TestStockService(t *testing.T) {
+	db := newTestDB(t)
+	defer db.Close()
+
+	service := NewStockService(db)
+
+	checkpoint, err := service.QueryCheckpoint("binance", "BTCUSDT")
+	assert.NoError(t, err)
+	assert.Nil(t, checkpoint)
+
+	assert.NoError(t, service.SaveCheckpoint(StockCheckpoint{
+		Exchange:     "binance",
+		Symbol:       "BTCUSDT",
+		CostBasis:    "fifo",
+		LastTradeGID: 10,
+		Stocks:       []types.Trade{{ID: 1, Symbol: "BTCUSDT", Price: 9000.0, Quantity: 0.1}},
+	}))
+
+	// the checkpoint of the symbol is replaced
+	assert.NoError(t, service.SaveCheckpoint(StockCheckpoint{
+		Exchange:     "binance",
+		Symbol:       "BTCUSDT",
+		CostBasis:    "fifo",
+		LastTradeGID: 20,
+		Stocks:       []types.Trade{{ID: 2, Symbol: "BTCUSDT", Price: 9100.0, Quantity: 0.2}},
+		PendingSells: []types.Trade{{ID: 3, Symbol: "BTCUSDT", Price: 9200.0, Quantity: 0.3}},
+	}))
+
+	// the checkpoint of the same symbol on the other exchange is another checkpoint
+	assert.NoError(t, service.SaveCheckpoint(StockCheckpoint{
+		Exchange:     "max",
+		Symbol:       "BTCUSDT",
+		CostBasis:    "fifo",
+		LastTradeGID: 30,
+	}))
+
+	checkpoint, err = service.QueryCheckpoint("binance", "BTCUSDT")
+	if assert.NoError(t, err) && assert.NotNil(t, checkpoint) {
+		assert.Equal(t, "binance", checkpoint.Exchange)
+		assert.Equal(t, int64(20), checkpoint.LastTradeGID)
+		assert.Len(t, checkpoint.Stocks, 1)
+		assert.Equal(t, 9100.0, checkpoint.Stocks[0].Price)
+		assert.Len(t, checkpoint.PendingSells, 1)
+	}
+
+	assert.NoError(t, service.DeleteCheckpoint("binance", "BTCUSDT"))
+
+	checkpoint, err = service.QueryCheckpoint("binance", "BTCUSDT")
+	assert.NoError(t, err)
+	assert.Nil(t, checkpoint)
+
+	checkpoint, err = service.QueryCheckpoint("max", "BTCUSDT")
+	if assert.NoError(t, err) && assert.NotNil(t, checkpoint) {
+		assert.Equal(t, int64(30), checkpoint.LastTradeGID)
+	}
+}
+
+func TestProfitService(t *testing.T) {
+	db := newTestDB(t)
+	defer db.Close()
+
+	service := NewProfitService(db)
+	now := time.Now().UTC().Truncate(time.Second)
+
+	assert.NoError(t, service.Insert(types.Profit{Exchange: "binance", Symbol: "BTCUSDT", TradeID: 1, Price: 9000.0, Quantity: 0.1, Cost: 800.0, Fee: 0.9, Profit: 99.1, HoldingSeconds: 60, Time: now}))
+	assert.NoError(t, service.Insert(types.Profit{Exchange: "binance", Symbol: "BTCUSDT", TradeID: 2, Price: 8000.0, Quantity: 0.1, Cost: 850.0, Fee: 0.8, Profit: -50.8, Time: now.Add(time.Hour)}))
+
+	// the same trade id of the other exchange is another profit
+	assert.NoError(t, service.Insert(types.Profit{Exchange: "max", Symbol: "BTCUSDT", TradeID: 2, Price: 8000.0, Quantity: 0.1, Cost: 700.0, Profit: 100.0, Time: now}))
+
+	// replaying the same trade replaces the stored profit
+	assert.NoError(t, service.Insert(types.Profit{Exchange: "binance", Symbol: "BTCUSDT", TradeID: 2, Price: 8000.0, Quantity: 0.1, Cost: 850.0, Fee: 0.4, Profit: -50.4, Time: now.Add(time.Hour)}))
+
+	profits, err := service.Query("binance", "BTCUSDT", now)
+	if assert.NoError(t, err) && assert.Len(t, profits, 2) {
+		assert.Equal(t, 99.1, profits[0].Profit)
+		assert.Equal(t, time.Minute, profits[0].HoldingTime())
+		assert.Equal(t, -50.4, profits[1].Profit)
+	}
+
+	profits, err = service.Query("binance", "BTCUSDT", now.Add(time.Minute))
+	assert.NoError(t, err)
+	assert.Len(t, profits, 1)
+
+	profits, err = service.Query("max", "BTCUSDT", now)
+	assert.NoError(t, err)
+	assert.Len(t, profits, 1)
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+// StrategyStateService stores the JSON encoded states of the strategies by the state key,
+// e.g., the position of the market maker that must survive restarts
+type StrategyStateService struct {
+	DB *sqlx.DB
+}
+
+func NewStrategyStateService(db *sqlx.DB) *StrategyStateService {
+	return &StrategyStateService{db}
+}
+
+// Load decodes the state of the key into v, false is returned if the state is not found
+func (s *StrategyStateService) Load(key string, v interface{}) (bool, error) {
+	rows, err := s.DB.NamedQuery(`SELECT state FROM strategy_states WHERE state_key = :state_key LIMIT 1`, map[string]interface{}{
+		"state_key": key,
+	})
+	if err != nil {
+		return false, errors.Wrap(err, "query strategy state error")
+	}
+
+	defer rows.Close()
+
+	if !rows.Next() {
+		return false, rows.Err()
+	}
+
+	var state string
+	if err := rows.Scan(&state); err != nil {
+		return false, err
+	}
+
+	if err := json.Unmarshal([]byte(state), v); err != nil {
+		return false, errors.Wrapf(err, "strategy state %s decode error", key)
+	}
+
+	return true, nil
+}
+
+// Save inserts or replaces the state of the key with the JSON encoded v
+func (s *StrategyStateService) Save(key string, v interface{}) error {
+	state, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.DB.NamedExec(`
+			INSERT INTO strategy_states (state_key, state, updated_at)
+			VALUES (:state_key, :state, :updated_at) `+
+		onConflictUpdate(s.DB, []string{"state_key"}, "state", "updated_at"),
+		map[string]interface{}{
+			"state_key":  key,
+			"state":      string(state),
+			"updated_at": time.Now(),
+		})
+	return err
+}
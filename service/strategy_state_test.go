@@ -0,0 +1,35 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStrategyStateService(t *testing.T) {
+	db := newTestDB(t)
+	defer db.Close()
+
+	type state struct {
+		Position  float64 `json:"position"`
+		Uncovered float64 `json:"uncovered"`
+	}
+
+	service := NewStrategyStateService(db)
+
+	var loaded state
+	found, err := service.Load("xmaker:max:binance:BTCUSDT", &loaded)
+	assert.NoError(t, err)
+	assert.False(t, found)
+
+	assert.NoError(t, service.Save("xmaker:max:binance:BTCUSDT", state{Position: 0.5, Uncovered: 0.1}))
+
+	// the state of the key is replaced
+	assert.NoError(t, service.Save("xmaker:max:binance:BTCUSDT", state{Position: 0.3, Uncovered: -0.1}))
+	assert.NoError(t, service.Save("xmaker:max:binance:ETHUSDT", state{Position: 2.0}))
+
+	found, err = service.Load("xmaker:max:binance:BTCUSDT", &loaded)
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, state{Position: 0.3, Uncovered: -0.1}, loaded)
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"sort"
 	"time"
 
 	"github.com/jmoiron/sqlx"
@@ -16,7 +17,7 @@ type TradeSync struct {
 }
 
 func (s *TradeSync) Sync(ctx context.Context, exchange types.Exchange, symbol string, startTime time.Time) error {
-	lastTrade, err := s.Service.QueryLast(symbol)
+	lastTrade, err := s.Service.QueryLast(exchange.Name(), symbol)
 	if err != nil {
 		return err
 	}
@@ -47,6 +48,126 @@ func (s *TradeSync) Sync(ctx context.Context, exchange types.Exchange, symbol st
 	return nil
 }
 
+// TradeVerification is the comparison of the stored trades and the exchange trades of a day
+type TradeVerification struct {
+	// Day is the start of the UTC day
+	Day time.Time
+
+	// Stored and Remote are the numbers of the trades in the database and on the exchange
+	Stored int
+	Remote int
+
+	// Missing are the exchange trades that are not in the database
+	Missing []types.Trade
+
+	// Extra are the stored trades that are not returned by the exchange
+	Extra []types.Trade
+}
+
+// OK returns true if the stored trades of the day are the same as the exchange trades
+func (v TradeVerification) OK() bool {
+	return len(v.Missing) == 0 && len(v.Extra) == 0
+}
+
+// Verify compares the stored trades and the exchange trades day by day from since to until,
+// the gaps are found by the trade ids since the last trade cursor of Sync can not detect them.
+// The missing trades are inserted into the database when backfill is true.
+func (s *TradeSync) Verify(ctx context.Context, exchange types.Exchange, symbol string, since, until time.Time, backfill bool) ([]TradeVerification, error) {
+	remoteTrades, err := queryTradesInRange(ctx, exchange, symbol, since, until)
+	if err != nil {
+		return nil, err
+	}
+
+	storedTrades, err := s.Service.QueryRange(exchange.Name(), symbol, since, until)
+	if err != nil {
+		return nil, err
+	}
+
+	var stored = make(map[int64]types.Trade, len(storedTrades))
+	for _, trade := range storedTrades {
+		stored[trade.ID] = trade
+	}
+
+	var verifications []TradeVerification
+	var days = map[int64]int{}
+	var verificationOf = func(t time.Time) *TradeVerification {
+		day := t.UTC().Truncate(24 * time.Hour)
+		i, ok := days[day.Unix()]
+		if !ok {
+			i = len(verifications)
+			days[day.Unix()] = i
+			verifications = append(verifications, TradeVerification{Day: day})
+		}
+
+		return &verifications[i]
+	}
+
+	var remote = make(map[int64]struct{}, len(remoteTrades))
+	for _, trade := range remoteTrades {
+		remote[trade.ID] = struct{}{}
+
+		v := verificationOf(trade.Time)
+		v.Remote++
+		if _, ok := stored[trade.ID]; !ok {
+			v.Missing = append(v.Missing, trade)
+		}
+	}
+
+	for _, trade := range storedTrades {
+		v := verificationOf(trade.Time)
+		v.Stored++
+		if _, ok := remote[trade.ID]; !ok {
+			v.Extra = append(v.Extra, trade)
+		}
+	}
+
+	sort.Slice(verifications, func(i, j int) bool {
+		return verifications[i].Day.Before(verifications[j].Day)
+	})
+
+	if !backfill {
+		return verifications, nil
+	}
+
+	for _, v := range verifications {
+		if len(v.Missing) > 0 {
+			log.Infof("backfilling %d missing %s trades of %s", len(v.Missing), symbol, v.Day.Format("2006-01-02"))
+		}
+
+		for _, trade := range v.Missing {
+			if err := s.Service.Insert(trade); err != nil {
+				return verifications, err
+			}
+		}
+	}
+
+	return verifications, nil
+}
+
+// queryTradesInRange queries the exchange trades that are traded in the time range [since, until)
+func queryTradesInRange(ctx context.Context, exchange types.Exchange, symbol string, since, until time.Time) ([]types.Trade, error) {
+	trades, err := exchange.BatchQueryTrades(ctx, symbol, &types.TradeQueryOptions{
+		StartTime: &since,
+		EndTime:   &until,
+		Limit:     200,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// the exchange may return the trades out of the range
+	var tradesInRange = trades[:0]
+	for _, trade := range trades {
+		if trade.Time.Before(since) || !trade.Time.Before(until) {
+			continue
+		}
+
+		tradesInRange = append(tradesInRange, trade)
+	}
+
+	return tradesInRange, nil
+}
+
 type TradeService struct {
 	DB *sqlx.DB
 }
@@ -55,12 +176,13 @@ func NewTradeService(db *sqlx.DB) *TradeService {
 	return &TradeService{db}
 }
 
-// QueryLast queries the last trade from the database
-func (s *TradeService) QueryLast(symbol string) (*types.Trade, error) {
-	log.Infof("querying last trade symbol = %s", symbol)
+// QueryLast queries the last trade of the exchange from the database
+func (s *TradeService) QueryLast(exchange string, symbol string) (*types.Trade, error) {
+	log.Infof("querying last trade exchange = %s symbol = %s", exchange, symbol)
 
-	rows, err := s.DB.NamedQuery(`SELECT * FROM trades WHERE symbol = :symbol ORDER BY gid DESC LIMIT 1`, map[string]interface{}{
-		"symbol": symbol,
+	rows, err := s.DB.NamedQuery(`SELECT * FROM trades WHERE exchange = :exchange AND symbol = :symbol ORDER BY gid DESC LIMIT 1`, map[string]interface{}{
+		"exchange": exchange,
+		"symbol":   symbol,
 	})
 	if err != nil {
 		return nil, errors.Wrap(err, "query last trade error")
@@ -81,9 +203,10 @@ func (s *TradeService) QueryLast(symbol string) (*types.Trade, error) {
 	return nil, rows.Err()
 }
 
-func (s *TradeService) QueryForTradingFeeCurrency(symbol string, feeCurrency string) ([]types.Trade, error) {
-	rows, err := s.DB.NamedQuery(`SELECT * FROM trades WHERE symbol = :symbol OR fee_currency = :fee_currency ORDER BY traded_at ASC`, map[string]interface{}{
-		"symbol": symbol,
+func (s *TradeService) QueryForTradingFeeCurrency(exchange string, symbol string, feeCurrency string) ([]types.Trade, error) {
+	rows, err := s.DB.NamedQuery(`SELECT * FROM trades WHERE exchange = :exchange AND (symbol = :symbol OR fee_currency = :fee_currency) ORDER BY traded_at ASC, id ASC`, map[string]interface{}{
+		"exchange":     exchange,
+		"symbol":       symbol,
 		"fee_currency": feeCurrency,
 	})
 	if err != nil {
@@ -95,9 +218,10 @@ func (s *TradeService) QueryForTradingFeeCurrency(symbol string, feeCurrency str
 	return s.scanRows(rows)
 }
 
-func (s *TradeService) Query(symbol string) ([]types.Trade, error) {
-	rows, err := s.DB.NamedQuery(`SELECT * FROM trades WHERE symbol = :symbol ORDER BY gid ASC`, map[string]interface{}{
-		"symbol": symbol,
+func (s *TradeService) Query(exchange string, symbol string) ([]types.Trade, error) {
+	rows, err := s.DB.NamedQuery(`SELECT * FROM trades WHERE exchange = :exchange AND symbol = :symbol ORDER BY traded_at ASC, id ASC`, map[string]interface{}{
+		"exchange": exchange,
+		"symbol":   symbol,
 	})
 	if err != nil {
 		return nil, err
@@ -108,6 +232,95 @@ func (s *TradeService) Query(symbol string) ([]types.Trade, error) {
 	return s.scanRows(rows)
 }
 
+// QueryRange queries the trades of the exchange and the symbol that are traded in the time range [since, until)
+func (s *TradeService) QueryRange(exchange string, symbol string, since, until time.Time) ([]types.Trade, error) {
+	rows, err := s.DB.NamedQuery(`SELECT * FROM trades WHERE exchange = :exchange AND symbol = :symbol AND traded_at >= :since AND traded_at < :until ORDER BY traded_at ASC, gid ASC`, map[string]interface{}{
+		"exchange": exchange,
+		"symbol":   symbol,
+		"since":    since,
+		"until":    until,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	return s.scanRows(rows)
+}
+
+// QueryByID returns the stored trade of the exchange, the symbol and the trade id, nil is returned if it's not found
+func (s *TradeService) QueryByID(exchange string, symbol string, id int64) (*types.Trade, error) {
+	rows, err := s.DB.NamedQuery(`SELECT * FROM trades WHERE exchange = :exchange AND symbol = :symbol AND id = :id LIMIT 1`, map[string]interface{}{
+		"exchange": exchange,
+		"symbol":   symbol,
+		"id":       id,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	if rows.Next() {
+		var trade types.Trade
+		err = rows.StructScan(&trade)
+		return &trade, err
+	}
+
+	return nil, rows.Err()
+}
+
+// QueryAfterGID queries the trades of the exchange and the symbol that are inserted after the given gid,
+// the trades are ordered by the trade time so that they are replayed in the traded order.
+func (s *TradeService) QueryAfterGID(exchange string, symbol string, gid int64) ([]types.Trade, error) {
+	rows, err := s.DB.NamedQuery(`SELECT * FROM trades WHERE exchange = :exchange AND symbol = :symbol AND gid > :gid ORDER BY traded_at ASC, id ASC`, map[string]interface{}{
+		"exchange": exchange,
+		"symbol":   symbol,
+		"gid":      gid,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	return s.scanRows(rows)
+}
+
+// QueryForTradingFeeCurrencyAfterGID is the same as QueryForTradingFeeCurrency but only the trades inserted after the given gid are returned
+func (s *TradeService) QueryForTradingFeeCurrencyAfterGID(exchange string, symbol string, feeCurrency string, gid int64) ([]types.Trade, error) {
+	rows, err := s.DB.NamedQuery(`SELECT * FROM trades WHERE exchange = :exchange AND (symbol = :symbol OR fee_currency = :fee_currency) AND gid > :gid ORDER BY traded_at ASC, id ASC`, map[string]interface{}{
+		"exchange":     exchange,
+		"symbol":       symbol,
+		"fee_currency": feeCurrency,
+		"gid":          gid,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	return s.scanRows(rows)
+}
+
+// Update updates the stored trade of the same exchange, symbol and id to the given trade
+func (s *TradeService) Update(trade types.Trade) error {
+	_, err := s.DB.NamedExec(`
+			UPDATE trades SET order_id = :order_id, price = :price, quantity = :quantity, quote_quantity = :quote_quantity, side = :side,
+				is_buyer = :is_buyer, is_maker = :is_maker, is_margin = :is_margin, fee = :fee, fee_currency = :fee_currency, traded_at = :traded_at
+			WHERE exchange = :exchange AND symbol = :symbol AND id = :id`,
+		trade)
+	return err
+}
+
+// Delete deletes the stored trade of the same exchange, symbol and id
+func (s *TradeService) Delete(trade types.Trade) error {
+	_, err := s.DB.NamedExec(`DELETE FROM trades WHERE exchange = :exchange AND symbol = :symbol AND id = :id`, trade)
+	return err
+}
+
 func (s *TradeService) scanRows(rows *sqlx.Rows)  (trades []types.Trade, err error) {
 	for rows.Next() {
 		var trade types.Trade
@@ -123,10 +336,15 @@ func (s *TradeService) scanRows(rows *sqlx.Rows)  (trades []types.Trade, err err
 
 
 
+// Insert inserts the trade, the trade that is already stored (the same exchange, symbol and id) is updated
+// with the columns from the exchange, so that syncing the same trades again is idempotent and
+// the corrected trades replace the stored ones.
 func (s *TradeService) Insert(trade types.Trade) error {
 	_, err := s.DB.NamedExec(`
-			INSERT INTO trades (id, exchange, symbol, price, quantity, quote_quantity, side, is_buyer, is_maker, fee, fee_currency, traded_at)
-			VALUES (:id, :exchange, :symbol, :price, :quantity, :quote_quantity, :side, :is_buyer, :is_maker, :fee, :fee_currency, :traded_at)`,
+			INSERT INTO trades (id, exchange, order_id, symbol, price, quantity, quote_quantity, side, is_buyer, is_maker, is_margin, fee, fee_currency, traded_at)
+			VALUES (:id, :exchange, :order_id, :symbol, :price, :quantity, :quote_quantity, :side, :is_buyer, :is_maker, :is_margin, :fee, :fee_currency, :traded_at) `+
+		onConflictUpdate(s.DB, []string{"exchange", "symbol", "id"},
+			"order_id", "price", "quantity", "quote_quantity", "side", "is_buyer", "is_maker", "is_margin", "fee", "fee_currency", "traded_at"),
 		trade)
 	return err
 }
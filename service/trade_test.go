@@ -0,0 +1,334 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/types"
+)
+
+func newTestTrade(exchange string, id int64, symbol string, t time.Time) types.Trade {
+	return types.Trade{
+		ID:            id,
+		OrderID:       uint64(id * 10),
+		Exchange:      exchange,
+		Symbol:        symbol,
+		Price:         9000.0,
+		Quantity:      0.1,
+		QuoteQuantity: 900.0,
+		Side:          "BUY",
+		IsBuyer:       true,
+		Time:          t,
+		Fee:           0.0001,
+		FeeCurrency:   "BNB",
+	}
+}
+
+func TestTradeService(t *testing.T) {
+	db := newTestDB(t)
+	defer db.Close()
+
+	service := NewTradeService(db)
+	now := time.Now().UTC().Truncate(time.Second)
+
+	assert.NoError(t, service.Insert(newTestTrade("binance", 1, "BTCUSDT", now)))
+	assert.NoError(t, service.Insert(newTestTrade("binance", 2, "BTCUSDT", now.Add(time.Minute))))
+	assert.NoError(t, service.Insert(newTestTrade("binance", 3, "BNBUSDT", now.Add(2*time.Minute))))
+
+	// the same trade id on the other exchange or the other symbol is allowed
+	assert.NoError(t, service.Insert(newTestTrade("max", 1, "BTCUSDT", now)))
+	assert.NoError(t, service.Insert(newTestTrade("binance", 1, "MATICUSDT", now)))
+
+	// syncing the same trade again updates the stored trade
+	trade := newTestTrade("binance", 1, "BTCUSDT", now)
+	trade.Fee = 0.0002
+	assert.NoError(t, service.Insert(trade))
+
+	lastTrade, err := service.QueryLast("binance", "BTCUSDT")
+	if assert.NoError(t, err) && assert.NotNil(t, lastTrade) {
+		assert.Equal(t, int64(2), lastTrade.ID)
+		assert.Equal(t, uint64(20), lastTrade.OrderID)
+		assert.True(t, lastTrade.IsBuyer)
+		assert.Equal(t, 9000.0, lastTrade.Price)
+		assert.True(t, now.Add(time.Minute).Equal(lastTrade.Time))
+	}
+
+	lastTrade, err = service.QueryLast("binance", "ETHUSDT")
+	assert.NoError(t, err)
+	assert.Nil(t, lastTrade)
+
+	trades, err := service.Query("binance", "BTCUSDT")
+	if assert.NoError(t, err) && assert.Len(t, trades, 2) {
+		assert.Equal(t, 0.0002, trades[0].Fee)
+	}
+
+	trades, err = service.Query("max", "BTCUSDT")
+	assert.NoError(t, err)
+	assert.Len(t, trades, 1)
+
+	trades, err = service.QueryForTradingFeeCurrency("binance", "BNBUSDT", "BNB")
+	assert.NoError(t, err)
+	assert.Len(t, trades, 4)
+
+	trades, err = service.QueryAfterGID("binance", "BTCUSDT", trades[0].GID)
+	assert.NoError(t, err)
+	assert.Len(t, trades, 1)
+
+	trades, err = service.QueryForTradingFeeCurrencyAfterGID("binance", "BNBUSDT", "BNB", 0)
+	assert.NoError(t, err)
+	assert.Len(t, trades, 4)
+
+	stored, err := service.QueryByID("binance", "BTCUSDT", 2)
+	if assert.NoError(t, err) && assert.NotNil(t, stored) {
+		assert.True(t, stored.GID > 0)
+		assert.Equal(t, uint64(20), stored.OrderID)
+	}
+
+	stored, err = service.QueryByID("binance", "BTCUSDT", 100)
+	assert.NoError(t, err)
+	assert.Nil(t, stored)
+
+	// the corrected trade from the exchange replaces every column of the stored trade
+	corrected := newTestTrade("binance", 2, "BTCUSDT", now.Add(2*time.Minute))
+	corrected.Price = 9100.0
+	corrected.Quantity = 0.2
+	corrected.QuoteQuantity = 1820.0
+	corrected.Side = "SELL"
+	corrected.IsBuyer = false
+	corrected.IsMaker = true
+	assert.NoError(t, service.Insert(corrected))
+
+	stored, err = service.QueryByID("binance", "BTCUSDT", 2)
+	if assert.NoError(t, err) && assert.NotNil(t, stored) {
+		assert.Equal(t, 9100.0, stored.Price)
+		assert.Equal(t, 0.2, stored.Quantity)
+		assert.Equal(t, 1820.0, stored.QuoteQuantity)
+		assert.Equal(t, "SELL", stored.Side)
+		assert.False(t, stored.IsBuyer)
+		assert.True(t, stored.IsMaker)
+		assert.True(t, now.Add(2*time.Minute).Equal(stored.Time))
+	}
+
+	// the trade synced later is replayed in the traded order
+	assert.NoError(t, service.Insert(newTestTrade("binance", 0, "BTCUSDT", now.Add(-time.Minute))))
+	trades, err = service.QueryAfterGID("binance", "BTCUSDT", 0)
+	if assert.NoError(t, err) && assert.Len(t, trades, 3) {
+		assert.Equal(t, []int64{0, 1, 2}, []int64{trades[0].ID, trades[1].ID, trades[2].ID})
+	}
+}
+
+func TestOrderService(t *testing.T) {
+	db := newTestDB(t)
+	defer db.Close()
+
+	service := NewOrderService(db)
+	now := time.Now().UTC().Truncate(time.Second)
+
+	order := types.Order{
+		SubmitOrder: types.SubmitOrder{
+			Symbol:        "BTCUSDT",
+			Side:          types.SideTypeBuy,
+			Type:          types.OrderTypeLimit,
+			Quantity:      0.1,
+			Price:         9000.0,
+			TimeInForce:   "GTC",
+			ClientOrderID: "bbgo-1",
+		},
+		OrderID:      100,
+		Exchange:     "binance",
+		Status:       types.OrderStatusNew,
+		CreationTime: now,
+	}
+	assert.NoError(t, service.Insert(order))
+
+	// the order events update the stored order
+	order.Status = types.OrderStatusPartiallyFilled
+	order.ExecutedQuantity = 0.05
+	order.UpdateTime = now.Add(time.Second)
+	assert.NoError(t, service.Insert(order))
+
+	stored, err := service.QueryByOrderID("binance", 100)
+	if assert.NoError(t, err) && assert.NotNil(t, stored) {
+		assert.Equal(t, types.OrderStatusPartiallyFilled, stored.Status)
+		assert.Equal(t, 0.05, stored.ExecutedQuantity)
+		assert.Equal(t, "bbgo-1", stored.ClientOrderID)
+		assert.Equal(t, types.OrderTypeLimit, stored.Type)
+	}
+
+	order.Status = types.OrderStatusFilled
+	order.ExecutedQuantity = 0.1
+	order.UpdateTime = now.Add(2 * time.Second)
+	assert.NoError(t, service.Insert(order))
+
+	// the stale order event does not overwrite the filled order
+	order.Status = types.OrderStatusPartiallyFilled
+	order.ExecutedQuantity = 0.05
+	order.UpdateTime = now.Add(time.Second)
+	assert.NoError(t, service.Insert(order))
+
+	stored, err = service.QueryByOrderID("binance", 100)
+	if assert.NoError(t, err) && assert.NotNil(t, stored) {
+		assert.Equal(t, types.OrderStatusFilled, stored.Status)
+		assert.Equal(t, 0.1, stored.ExecutedQuantity)
+	}
+
+	order.Status = types.OrderStatusFilled
+	order.ExecutedQuantity = 0.1
+
+	order.OrderID = 101
+	order.CreationTime = now.Add(time.Minute)
+	assert.NoError(t, service.Insert(order))
+
+	lastOrder, err := service.QueryLast("binance", "BTCUSDT")
+	if assert.NoError(t, err) && assert.NotNil(t, lastOrder) {
+		assert.Equal(t, uint64(101), lastOrder.OrderID)
+	}
+
+	orders, err := service.Query("binance", "BTCUSDT", now)
+	if assert.NoError(t, err) && assert.Len(t, orders, 2) {
+		assert.Equal(t, types.OrderStatusFilled, orders[0].Status)
+	}
+
+	orders, err = service.Query("binance", "BTCUSDT", now.Add(time.Second))
+	assert.NoError(t, err)
+	assert.Len(t, orders, 1)
+
+	stored, err = service.QueryByOrderID("max", 100)
+	assert.NoError(t, err)
+	assert.Nil(t, stored)
+}
+
+type testOrderHistoryExchange struct {
+	types.Exchange
+	orders []types.Order
+}
+
+func (e *testOrderHistoryExchange) Name() string {
+	return "binance"
+}
+
+func (e *testOrderHistoryExchange) QueryOrderHistory(ctx context.Context, symbol string, since time.Time, lastOrderID uint64) (orders []types.Order, err error) {
+	for _, o := range e.orders {
+		if o.OrderID > lastOrderID && !o.CreationTime.Before(since) {
+			orders = append(orders, o)
+		}
+	}
+
+	return orders, nil
+}
+
+func TestOrderSync(t *testing.T) {
+	db := newTestDB(t)
+	defer db.Close()
+
+	now := time.Now().UTC().Truncate(time.Second)
+	exchange := &testOrderHistoryExchange{}
+	for i := 1; i <= 3; i++ {
+		exchange.orders = append(exchange.orders, types.Order{
+			SubmitOrder:  types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeSell, Type: types.OrderTypeMarket, Quantity: 1.0},
+			OrderID:      uint64(i),
+			Exchange:     "binance",
+			Status:       types.OrderStatusFilled,
+			CreationTime: now.Add(time.Duration(i) * time.Minute),
+		})
+	}
+
+	sync := &OrderSync{Service: NewOrderService(db)}
+	assert.NoError(t, sync.Sync(context.Background(), exchange, "BTCUSDT", now))
+	assert.NoError(t, sync.Sync(context.Background(), exchange, "BTCUSDT", now))
+
+	orders, err := sync.Service.Query("binance", "BTCUSDT", now)
+	assert.NoError(t, err)
+	assert.Len(t, orders, 3)
+
+	// the open order that is filled after it's synced is queried again
+	_, err = db.Exec(`UPDATE orders SET status = 'NEW' WHERE order_id = 2`)
+	assert.NoError(t, err)
+	assert.NoError(t, sync.Sync(context.Background(), exchange, "BTCUSDT", now))
+
+	order, err := sync.Service.QueryByOrderID("binance", 2)
+	if assert.NoError(t, err) && assert.NotNil(t, order) {
+		assert.Equal(t, types.OrderStatusFilled, order.Status)
+	}
+}
+
+type testTradeHistoryExchange struct {
+	types.Exchange
+	trades []types.Trade
+}
+
+func (e *testTradeHistoryExchange) Name() string {
+	return "binance"
+}
+
+func (e *testTradeHistoryExchange) BatchQueryTrades(ctx context.Context, symbol string, options *types.TradeQueryOptions) (trades []types.Trade, err error) {
+	for _, t := range e.trades {
+		if t.ID > options.LastTradeID && !t.Time.Before(*options.StartTime) {
+			trades = append(trades, t)
+		}
+	}
+
+	return trades, nil
+}
+
+// QueryTrades returns the trades from the trade id like the binance trade query
+func (e *testTradeHistoryExchange) QueryTrades(ctx context.Context, symbol string, options *types.TradeQueryOptions) (trades []types.Trade, err error) {
+	for _, t := range e.trades {
+		if t.ID >= options.LastTradeID && int64(len(trades)) < options.Limit {
+			trades = append(trades, t)
+		}
+	}
+
+	return trades, nil
+}
+
+func TestTradeSync_Verify(t *testing.T) {
+	db := newTestDB(t)
+	defer db.Close()
+
+	day := time.Date(2020, 8, 1, 0, 0, 0, 0, time.UTC)
+	exchange := &testTradeHistoryExchange{}
+	for i := 1; i <= 6; i++ {
+		exchange.trades = append(exchange.trades, newTestTrade("binance", int64(i), "BTCUSDT", day.Add(time.Duration(i)*8*time.Hour)))
+	}
+
+	service := NewTradeService(db)
+	sync := &TradeSync{Service: service}
+
+	// trade 3 is lost and trade 100 is not on the exchange
+	for _, trade := range exchange.trades {
+		if trade.ID != 3 {
+			assert.NoError(t, service.Insert(trade))
+		}
+	}
+	assert.NoError(t, service.Insert(newTestTrade("binance", 100, "BTCUSDT", day.Add(36*time.Hour))))
+
+	verifications, err := sync.Verify(context.Background(), exchange, "BTCUSDT", day, day.Add(72*time.Hour), false)
+	assert.NoError(t, err)
+	if assert.Len(t, verifications, 3) {
+		assert.True(t, verifications[0].OK())
+		assert.Equal(t, day.Add(24*time.Hour), verifications[1].Day)
+		assert.Equal(t, 3, verifications[1].Remote)
+		assert.Equal(t, 3, verifications[1].Stored)
+		if assert.Len(t, verifications[1].Missing, 1) {
+			assert.Equal(t, int64(3), verifications[1].Missing[0].ID)
+		}
+		if assert.Len(t, verifications[1].Extra, 1) {
+			assert.Equal(t, int64(100), verifications[1].Extra[0].ID)
+		}
+		assert.True(t, verifications[2].OK())
+	}
+
+	_, err = sync.Verify(context.Background(), exchange, "BTCUSDT", day, day.Add(72*time.Hour), true)
+	assert.NoError(t, err)
+
+	verifications, err = sync.Verify(context.Background(), exchange, "BTCUSDT", day, day.Add(72*time.Hour), false)
+	assert.NoError(t, err)
+	if assert.Len(t, verifications, 3) {
+		assert.Len(t, verifications[1].Missing, 0)
+		assert.Len(t, verifications[1].Extra, 1)
+	}
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/types"
+)
+
+// TransferSync syncs the deposits and the withdraws of the exchange into the database
+type TransferSync struct {
+	DepositService  *DepositService
+	WithdrawService *WithdrawService
+}
+
+// Sync queries the transfers since the last synced record, or the given start time if nothing is synced.
+// The pending records are synced again until they are finalized.
+// The exchange name is the exchange of the records, e.g., binance or max
+func (s *TransferSync) Sync(ctx context.Context, exchangeName string, exchange types.Exchange, startTime time.Time) error {
+	now := time.Now()
+
+	if querier, ok := exchange.(types.DepositHistoryQuerier); ok {
+		since := startTime
+
+		lastDeposit, err := s.DepositService.QueryLast(exchangeName)
+		if err != nil {
+			return err
+		}
+
+		if lastDeposit != nil {
+			since = lastDeposit.Time
+		}
+
+		pendingDeposit, err := s.DepositService.QueryFirstPending(exchangeName)
+		if err != nil {
+			return err
+		}
+
+		if pendingDeposit != nil && pendingDeposit.Time.Before(since) {
+			since = pendingDeposit.Time
+		}
+
+		log.Infof("syncing %s deposits since %s", exchangeName, since)
+
+		deposits, err := querier.QueryDepositHistory(ctx, "", since, now)
+		if err != nil {
+			return err
+		}
+
+		for _, deposit := range deposits {
+			if err := s.DepositService.Insert(deposit); err != nil {
+				return err
+			}
+		}
+	}
+
+	if querier, ok := exchange.(types.WithdrawHistoryQuerier); ok {
+		since := startTime
+
+		lastWithdraw, err := s.WithdrawService.QueryLast(exchangeName)
+		if err != nil {
+			return err
+		}
+
+		if lastWithdraw != nil {
+			since = lastWithdraw.ApplyTime
+		}
+
+		pendingWithdraw, err := s.WithdrawService.QueryFirstPending(exchangeName)
+		if err != nil {
+			return err
+		}
+
+		if pendingWithdraw != nil && pendingWithdraw.ApplyTime.Before(since) {
+			since = pendingWithdraw.ApplyTime
+		}
+
+		log.Infof("syncing %s withdraws since %s", exchangeName, since)
+
+		withdraws, err := querier.QueryWithdrawHistory(ctx, "", since, now)
+		if err != nil {
+			return err
+		}
+
+		for _, withdraw := range withdraws {
+			if err := s.WithdrawService.Insert(withdraw); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/types"
+)
+
+func TestDepositService(t *testing.T) {
+	db := newTestDB(t)
+	defer db.Close()
+
+	service := NewDepositService(db)
+	now := time.Now().UTC().Truncate(time.Second)
+
+	deposit := types.Deposit{Exchange: "max", Asset: "BTC", Amount: 1.0, TransactionID: "tx1", Status: types.DepositPending, Time: now}
+	assert.NoError(t, service.Insert(deposit))
+	assert.NoError(t, service.Insert(types.Deposit{Exchange: "max", Asset: "USDT", Amount: 100.0, TransactionID: "tx2", Status: types.DepositSuccess, Time: now.Add(time.Hour)}))
+
+	pending, err := service.QueryFirstPending("max")
+	if assert.NoError(t, err) && assert.NotNil(t, pending) {
+		assert.Equal(t, "tx1", pending.TransactionID)
+	}
+
+	// the status is updated by the next sync
+	deposit.Status = types.DepositSuccess
+	assert.NoError(t, service.Insert(deposit))
+
+	pending, err = service.QueryFirstPending("max")
+	assert.NoError(t, err)
+	assert.Nil(t, pending)
+
+	last, err := service.QueryLast("max")
+	if assert.NoError(t, err) && assert.NotNil(t, last) {
+		assert.Equal(t, "tx2", last.TransactionID)
+	}
+
+	deposits, err := service.Query("max", now, now.Add(2*time.Hour))
+	if assert.NoError(t, err) && assert.Len(t, deposits, 2) {
+		assert.True(t, deposits[0].IsCredited())
+	}
+
+	deposits, err = service.Query("binance", now, now.Add(2*time.Hour))
+	assert.NoError(t, err)
+	assert.Len(t, deposits, 0)
+}
+
+func TestWithdrawService(t *testing.T) {
+	db := newTestDB(t)
+	defer db.Close()
+
+	service := NewWithdrawService(db)
+	now := time.Now().UTC().Truncate(time.Second)
+
+	withdraw := types.Withdraw{Exchange: "binance", ID: "w1", Asset: "BTC", Amount: 0.5, Status: types.WithdrawProcessing, ApplyTime: now}
+	assert.NoError(t, service.Insert(withdraw))
+
+	pending, err := service.QueryFirstPending("binance")
+	if assert.NoError(t, err) && assert.NotNil(t, pending) {
+		assert.Equal(t, "w1", pending.ID)
+	}
+
+	withdraw.Status = types.WithdrawCompleted
+	withdraw.TransactionID = "tx1"
+	withdraw.TransactionFee = 0.0005
+	assert.NoError(t, service.Insert(withdraw))
+
+	last, err := service.QueryLast("binance")
+	if assert.NoError(t, err) && assert.NotNil(t, last) {
+		assert.Equal(t, types.WithdrawCompleted, last.Status)
+		assert.Equal(t, "tx1", last.TransactionID)
+		assert.Equal(t, 0.0005, last.TransactionFee)
+	}
+
+	pending, err = service.QueryFirstPending("binance")
+	assert.NoError(t, err)
+	assert.Nil(t, pending)
+
+	withdraws, err := service.Query("binance", now, now.Add(time.Second))
+	assert.NoError(t, err)
+	assert.Len(t, withdraws, 1)
+}
+
+type testTransferExchange struct {
+	types.Exchange
+	deposits  []types.Deposit
+	withdraws []types.Withdraw
+}
+
+func (e *testTransferExchange) QueryDepositHistory(ctx context.Context, asset string, since, until time.Time) ([]types.Deposit, error) {
+	return e.deposits, nil
+}
+
+func (e *testTransferExchange) QueryWithdrawHistory(ctx context.Context, asset string, since, until time.Time) ([]types.Withdraw, error) {
+	return e.withdraws, nil
+}
+
+func TestTransferSync(t *testing.T) {
+	db := newTestDB(t)
+	defer db.Close()
+
+	now := time.Now().UTC().Truncate(time.Second)
+	exchange := &testTransferExchange{
+		deposits:  []types.Deposit{{Exchange: "max", Asset: "BTC", Amount: 1.0, TransactionID: "tx1", Status: types.DepositSuccess, Time: now}},
+		withdraws: []types.Withdraw{{Exchange: "max", ID: "w1", Asset: "BTC", Amount: 0.5, Status: types.WithdrawCompleted, ApplyTime: now}},
+	}
+
+	sync := &TransferSync{DepositService: NewDepositService(db), WithdrawService: NewWithdrawService(db)}
+	assert.NoError(t, sync.Sync(context.Background(), "max", exchange, now.Add(-time.Hour)))
+	assert.NoError(t, sync.Sync(context.Background(), "max", exchange, now.Add(-time.Hour)))
+
+	deposits, err := sync.DepositService.Query("max", now, now.Add(time.Second))
+	assert.NoError(t, err)
+	assert.Len(t, deposits, 1)
+
+	withdraws, err := sync.WithdrawService.Query("max", now, now.Add(time.Second))
+	assert.NoError(t, err)
+	assert.Len(t, withdraws, 1)
+}
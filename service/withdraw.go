@@ -0,0 +1,97 @@
+package service
+
+import (
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+
+	"github.com/c9s/bbgo/types"
+)
+
+type WithdrawService struct {
+	DB *sqlx.DB
+}
+
+func NewWithdrawService(db *sqlx.DB) *WithdrawService {
+	return &WithdrawService{db}
+}
+
+// Insert inserts the withdraw, the status and the transaction are updated if the withdraw is already inserted
+func (s *WithdrawService) Insert(withdraw types.Withdraw) error {
+	_, err := s.DB.NamedExec(`
+			INSERT INTO withdraws (exchange, id, asset, address, address_tag, network, amount, txn_id, txn_fee, withdraw_order_id, status, time)
+			VALUES (:exchange, :id, :asset, :address, :address_tag, :network, :amount, :txn_id, :txn_fee, :withdraw_order_id, :status, :time) `+
+		onConflictUpdate(s.DB, []string{"exchange", "id"}, "status", "txn_id", "txn_fee"),
+		withdraw)
+	return err
+}
+
+// QueryLast queries the last withdraw of the exchange from the database
+func (s *WithdrawService) QueryLast(exchange string) (*types.Withdraw, error) {
+	rows, err := s.DB.NamedQuery(`SELECT * FROM withdraws WHERE exchange = :exchange ORDER BY time DESC LIMIT 1`, map[string]interface{}{
+		"exchange": exchange,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "query last withdraw error")
+	}
+
+	defer rows.Close()
+
+	if rows.Next() {
+		var withdraw types.Withdraw
+		err = rows.StructScan(&withdraw)
+		return &withdraw, err
+	}
+
+	return nil, rows.Err()
+}
+
+// QueryFirstPending queries the first withdraw of the exchange that is not completed or cancelled yet
+func (s *WithdrawService) QueryFirstPending(exchange string) (*types.Withdraw, error) {
+	rows, err := s.DB.NamedQuery(`SELECT * FROM withdraws WHERE exchange = :exchange AND status IN (:email_sent, :awaiting_approval, :processing) ORDER BY time ASC LIMIT 1`, map[string]interface{}{
+		"exchange":          exchange,
+		"email_sent":        types.WithdrawEmailSent,
+		"awaiting_approval": types.WithdrawAwaitingApproval,
+		"processing":        types.WithdrawProcessing,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "query pending withdraw error")
+	}
+
+	defer rows.Close()
+
+	if rows.Next() {
+		var withdraw types.Withdraw
+		err = rows.StructScan(&withdraw)
+		return &withdraw, err
+	}
+
+	return nil, rows.Err()
+}
+
+// Query queries the withdraws of the exchange in the time range [since, until)
+func (s *WithdrawService) Query(exchange string, since, until time.Time) ([]types.Withdraw, error) {
+	rows, err := s.DB.NamedQuery(`SELECT * FROM withdraws WHERE exchange = :exchange AND time >= :since AND time < :until ORDER BY time ASC`, map[string]interface{}{
+		"exchange": exchange,
+		"since":    since,
+		"until":    until,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var withdraws []types.Withdraw
+	for rows.Next() {
+		var withdraw types.Withdraw
+		if err := rows.StructScan(&withdraw); err != nil {
+			return nil, err
+		}
+
+		withdraws = append(withdraws, withdraw)
+	}
+
+	return withdraws, rows.Err()
+}
@@ -0,0 +1,352 @@
+package dca
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/bbgo"
+	"github.com/c9s/bbgo/types"
+)
+
+const ID = "dca"
+
+// priceInterval is the kline interval that keeps the price of the scheduled buys
+const priceInterval = "1m"
+
+func init() {
+	bbgo.RegisterStrategy(ID, &Strategy{})
+}
+
+// MovingAverageMultiplier multiplies the buy amount when the price is below the moving average of the interval
+type MovingAverageMultiplier struct {
+	Interval   string  `json:"interval" yaml:"interval"`
+	Period     int     `json:"period" yaml:"period"`
+	Multiplier float64 `json:"multiplier" yaml:"multiplier"`
+}
+
+// Strategy buys a fixed quote amount of the symbol at the cron schedule or at every N closed klines of the interval,
+// until the total budget is spent. The orders are market orders submitted through the trader, so the order
+// processor adjusts the quantity by the quote balance and the risk controls. The scheduled buys are priced by
+// the close price of the last 1m kline.
+//
+// The orders are tagged by the client order id, the spent amount is counted from the trades of the own orders
+// and kept in the state store.
+type Strategy struct {
+	// Amount is the quote amount of every buy
+	Amount float64 `json:"amount" yaml:"amount"`
+
+	// Schedule is the cron schedule of the buys, e.g., "0 9 * * 1" buys at 09:00 every Monday
+	Schedule string `json:"schedule" yaml:"schedule"`
+
+	// Interval and EveryKLines buy at every N closed klines of the interval when the schedule is not set
+	Interval    string `json:"interval" yaml:"interval"`
+	EveryKLines int    `json:"everyKLines" yaml:"everyKLines"`
+
+	// Budget is the total quote amount to spend, no limit when it's zero
+	Budget float64 `json:"budget" yaml:"budget"`
+
+	// BelowMovingAverage buys more when the price is below the moving average
+	BelowMovingAverage *MovingAverageMultiplier `json:"belowMovingAverage,omitempty" yaml:"belowMovingAverage,omitempty"`
+
+	mu sync.Mutex
+
+	tradingContext *bbgo.Context
+	trader         types.Trader
+	stateStore     bbgo.StateStore
+
+	movingAverage *bbgo.MovingAverageIndicator
+
+	// ownTrades tells the trades of the dca orders
+	ownTrades *bbgo.OwnTradeFilter
+
+	numKLines    int
+	lastPrice    float64
+	spent        float64
+	budgetNotify bool
+
+	cancel context.CancelFunc
+}
+
+func (s *Strategy) Validate() error {
+	if s.Amount <= 0 {
+		return fmt.Errorf("dca: amount must be positive")
+	}
+
+	if len(s.Schedule) > 0 {
+		if _, err := bbgo.ParseSchedule(s.Schedule); err != nil {
+			return fmt.Errorf("dca: invalid schedule %q: %s", s.Schedule, err.Error())
+		}
+	} else if len(s.Interval) == 0 || s.EveryKLines <= 0 {
+		return fmt.Errorf("dca: either schedule or interval with everyKLines is required")
+	}
+
+	if s.Budget < 0 {
+		return fmt.Errorf("dca: budget can not be negative")
+	}
+
+	if ma := s.BelowMovingAverage; ma != nil {
+		if len(ma.Interval) == 0 || ma.Period <= 0 || ma.Multiplier <= 0 {
+			return fmt.Errorf("dca: belowMovingAverage requires interval, a positive period and a positive multiplier")
+		}
+	}
+
+	return nil
+}
+
+// Spent returns the quote amount of the buy trades
+func (s *Strategy) Spent() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.spent
+}
+
+// state is the spent amount of the strategy that is kept in the state store
+type state struct {
+	Spent float64 `json:"spent"`
+}
+
+func (s *Strategy) SetStateStore(store bbgo.StateStore) {
+	s.stateStore = store
+}
+
+func (s *Strategy) stateKey() string {
+	return ID + ":" + s.tradingContext.Symbol
+}
+
+func (s *Strategy) loadState() error {
+	if s.stateStore == nil {
+		return nil
+	}
+
+	var st state
+	found, err := s.stateStore.Load(s.stateKey(), &st)
+	if err != nil || !found {
+		return err
+	}
+
+	log.Infof("dca: restored the %s spent amount %f", s.tradingContext.Symbol, st.Spent)
+
+	s.mu.Lock()
+	s.spent = st.Spent
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *Strategy) saveState() {
+	if s.stateStore == nil {
+		return
+	}
+
+	if err := s.stateStore.Save(s.stateKey(), state{Spent: s.Spent()}); err != nil {
+		log.WithError(err).Errorf("dca: can not save the %s spent amount", s.tradingContext.Symbol)
+	}
+}
+
+func (s *Strategy) OnLoad(tradingContext *bbgo.Context, trader types.Trader) error {
+	if err := s.Validate(); err != nil {
+		return err
+	}
+
+	s.tradingContext = tradingContext
+	s.trader = trader
+	s.ownTrades = bbgo.NewOwnTradeFilter(ID)
+
+	if err := s.loadState(); err != nil {
+		return err
+	}
+
+	if ma := s.BelowMovingAverage; ma != nil {
+		if tradingContext.MarketDataStore == nil {
+			return fmt.Errorf("dca: market data store is not available for the moving average")
+		}
+
+		s.movingAverage = bbgo.NewMovingAverageIndicator(ma.Period)
+		s.movingAverage.SubscribeStore(tradingContext.MarketDataStore)
+	}
+
+	return nil
+}
+
+func (s *Strategy) OnNewStream(stream types.Stream) error {
+	symbol := s.tradingContext.Symbol
+
+	var intervals = map[string]struct{}{}
+	if len(s.Schedule) == 0 {
+		intervals[s.Interval] = struct{}{}
+	} else {
+		intervals[priceInterval] = struct{}{}
+	}
+
+	if ma := s.BelowMovingAverage; ma != nil {
+		intervals[ma.Interval] = struct{}{}
+	}
+
+	for interval := range intervals {
+		stream.Subscribe(types.KLineChannel, symbol, types.SubscribeOptions{Interval: interval})
+	}
+
+	stream.OnKLineClosed(s.handleKLineClosed)
+	stream.OnOrderUpdate(s.handleOrderUpdate)
+	stream.OnTrade(s.handleTrade)
+
+	if len(s.Schedule) > 0 {
+		ctx, cancel := context.WithCancel(context.Background())
+		s.cancel = cancel
+
+		scheduler := bbgo.NewScheduler(nil)
+		if err := scheduler.AddJob(ID+" "+symbol, s.Schedule, s.scheduledBuy); err != nil {
+			cancel()
+			return err
+		}
+
+		scheduler.Run(ctx)
+	}
+
+	return nil
+}
+
+// OnShutdown stops the scheduled buys
+func (s *Strategy) OnShutdown(ctx context.Context) error {
+	if s.cancel != nil {
+		s.cancel()
+	}
+
+	return nil
+}
+
+// scheduledBuy buys at the close price of the last kline
+func (s *Strategy) scheduledBuy(ctx context.Context) {
+	s.mu.Lock()
+	price := s.lastPrice
+	s.mu.Unlock()
+
+	s.buy(ctx, price)
+}
+
+func (s *Strategy) handleKLineClosed(kline types.KLine) {
+	if kline.Symbol != s.tradingContext.Symbol {
+		return
+	}
+
+	s.mu.Lock()
+	s.lastPrice = kline.Close
+	s.mu.Unlock()
+
+	if len(s.Schedule) > 0 || kline.Interval != s.Interval {
+		return
+	}
+
+	s.mu.Lock()
+	s.numKLines++
+	due := s.numKLines%s.EveryKLines == 0
+	s.mu.Unlock()
+
+	if due {
+		s.buy(context.Background(), kline.Close)
+	}
+}
+
+// handleOrderUpdate adds the trades of the dca order that arrived before the order update
+func (s *Strategy) handleOrderUpdate(order types.Order) {
+	if order.Symbol != s.tradingContext.Symbol {
+		return
+	}
+
+	_, trades := s.ownTrades.HandleOrderUpdate(order)
+	for _, trade := range trades {
+		s.addSpent(trade)
+	}
+}
+
+func (s *Strategy) handleTrade(trade *types.Trade) {
+	if trade.Symbol != s.tradingContext.Symbol || !trade.IsBuyer {
+		return
+	}
+
+	if s.ownTrades.HandleTrade(*trade) {
+		s.addSpent(*trade)
+	}
+}
+
+// addSpent adds the quote amount of the buy trade to the spent amount
+func (s *Strategy) addSpent(trade types.Trade) {
+	amount := trade.QuoteQuantity
+	if amount == 0 {
+		amount = trade.Price * trade.Quantity
+	}
+
+	s.mu.Lock()
+	s.spent += amount
+	s.mu.Unlock()
+
+	s.saveState()
+}
+
+// buyAmount returns the quote amount of the next buy, the amount is multiplied when the price is below
+// the moving average and limited by the remaining budget
+func (s *Strategy) buyAmount(price float64) float64 {
+	amount := s.Amount
+	if ma := s.BelowMovingAverage; ma != nil {
+		if value, ok := s.movingAverage.Last(bbgo.Interval(ma.Interval)); ok && price < value.Value {
+			amount *= ma.Multiplier
+		}
+	}
+
+	if s.Budget > 0 {
+		s.mu.Lock()
+		remaining := s.Budget - s.spent
+		s.mu.Unlock()
+
+		amount = math.Min(amount, remaining)
+	}
+
+	return amount
+}
+
+func (s *Strategy) buy(ctx context.Context, price float64) {
+	symbol := s.tradingContext.Symbol
+	if price <= 0 {
+		log.Warnf("dca: %s price is not available yet, buy is skipped", symbol)
+		return
+	}
+
+	market := s.tradingContext.Market
+	amount := s.buyAmount(price)
+	if amount < market.MinAmount {
+		if s.Budget > 0 {
+			s.notifyBudgetSpent()
+		}
+		return
+	}
+
+	log.Infof("dca: buying %s with %f %s at %f", symbol, amount, market.QuoteCurrency, price)
+	s.trader.SubmitOrder(ctx, &types.SubmitOrder{
+		Symbol:        symbol,
+		Side:          types.SideTypeBuy,
+		Type:          types.OrderTypeMarket,
+		Market:        market,
+		Quantity:      amount / price,
+		ClientOrderID: bbgo.NewClientOrderID(ID),
+	})
+}
+
+func (s *Strategy) notifyBudgetSpent() {
+	s.mu.Lock()
+	notified := s.budgetNotify
+	s.budgetNotify = true
+	spent := s.spent
+	s.mu.Unlock()
+
+	if notified {
+		return
+	}
+
+	log.Infof("dca: %s budget %f is spent, no more buys", s.tradingContext.Symbol, s.Budget)
+	if notifier, ok := s.trader.(bbgo.Notifier); ok {
+		notifier.Notify(":moneybag: %s dca budget %f is spent (%f), no more buys", s.tradingContext.Symbol, s.Budget, spent)
+	}
+}
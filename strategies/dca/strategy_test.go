@@ -0,0 +1,194 @@
+package dca
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/bbgo"
+	"github.com/c9s/bbgo/strategies/strategytest"
+	"github.com/c9s/bbgo/types"
+)
+
+func newTestStrategy(t *testing.T, strategy *Strategy, store bbgo.StateStore) (*strategytest.Trader, *bbgo.BackTestStream) {
+	// the current price of the context is not updated without the kline subscriptions, so it's not set
+	tradingContext := strategytest.NewContext(0.0)
+	trader := &strategytest.Trader{}
+
+	strategy.SetStateStore(store)
+	assert.NoError(t, strategy.OnLoad(tradingContext, trader))
+
+	stream := &bbgo.BackTestStream{}
+	tradingContext.MarketDataStore.BindPrivateStream(stream)
+	assert.NoError(t, strategy.OnNewStream(stream))
+	return trader, stream
+}
+
+// emitFill emits the order update of the submitted order and its trade
+func emitFill(stream *bbgo.BackTestStream, trader *strategytest.Trader, i int, trade types.Trade) {
+	order := trader.Orders[i]
+	trade.Symbol = order.Symbol
+	trade.OrderID = uint64(i + 1)
+	trade.IsBuyer = true
+
+	stream.EmitOrderUpdate(types.Order{SubmitOrder: order, OrderID: trade.OrderID, Status: types.OrderStatusFilled})
+	stream.EmitTrade(&trade)
+}
+
+func TestStrategy_Validate(t *testing.T) {
+	strategytest.AssertValidate(t, []strategytest.ValidateCase{
+		{Name: "no amount", Strategy: &Strategy{Schedule: "0 9 * * *"}},
+		{Name: "no schedule or interval", Strategy: &Strategy{Amount: 100.0}},
+		{Name: "invalid schedule", Strategy: &Strategy{Amount: 100.0, Schedule: "0 25 * * *"}},
+		{Name: "invalid moving average", Strategy: &Strategy{Amount: 100.0, Interval: "1h", EveryKLines: 1, BelowMovingAverage: &MovingAverageMultiplier{Interval: "1d"}}},
+		{Name: "schedule", Strategy: &Strategy{Amount: 100.0, Schedule: "0 9 * * *"}, Valid: true},
+		{Name: "interval", Strategy: &Strategy{Amount: 100.0, Interval: "1h", EveryKLines: 4, Budget: 1000.0}, Valid: true},
+	})
+}
+
+func TestStrategy_EveryKLines(t *testing.T) {
+	strategy := &Strategy{Amount: 100.0, Interval: "1h", EveryKLines: 2, Budget: 250.0}
+	trader, stream := newTestStrategy(t, strategy, bbgo.NewMemoryStateStore())
+
+	if assert.Len(t, stream.Subscriptions, 1) {
+		assert.Equal(t, types.KLineChannel, stream.Subscriptions[0].Channel)
+		assert.Equal(t, "1h", stream.Subscriptions[0].Options.Interval)
+	}
+
+	for i := 0; i < 4; i++ {
+		stream.EmitKLineClosed(types.KLine{Symbol: "BTCUSDT", Interval: "1h", Close: 10000.0})
+	}
+
+	// the other interval and the other symbol are ignored
+	stream.EmitKLineClosed(types.KLine{Symbol: "BTCUSDT", Interval: "1m", Close: 10000.0})
+	stream.EmitKLineClosed(types.KLine{Symbol: "ETHUSDT", Interval: "1h", Close: 200.0})
+
+	if assert.Len(t, trader.Orders, 2) {
+		assert.Equal(t, types.SideTypeBuy, trader.Orders[0].Side)
+		assert.Equal(t, types.OrderTypeMarket, trader.Orders[0].Type)
+		assert.InDelta(t, 0.01, trader.Orders[0].Quantity, 1e-9)
+		assert.True(t, bbgo.HasClientOrderIDPrefix(trader.Orders[0].ClientOrderID, ID))
+	}
+
+	emitFill(stream, trader, 0, types.Trade{ID: 1, Price: 10000.0, Quantity: 0.01})
+	emitFill(stream, trader, 1, types.Trade{ID: 2, Price: 10000.0, Quantity: 0.01, QuoteQuantity: 100.0})
+
+	// the sell trades and the trades of the foreign orders are not counted
+	stream.EmitTrade(&types.Trade{ID: 3, Symbol: "BTCUSDT", OrderID: 1, Price: 10000.0, Quantity: 0.01, IsBuyer: false})
+	stream.EmitOrderUpdate(types.Order{SubmitOrder: types.SubmitOrder{Symbol: "BTCUSDT"}, OrderID: 100, Status: types.OrderStatusFilled})
+	stream.EmitTrade(&types.Trade{ID: 4, Symbol: "BTCUSDT", OrderID: 100, Price: 10000.0, Quantity: 0.01, IsBuyer: true})
+	assert.Equal(t, 200.0, strategy.Spent())
+
+	// only 50 of the budget is left
+	for i := 0; i < 2; i++ {
+		stream.EmitKLineClosed(types.KLine{Symbol: "BTCUSDT", Interval: "1h", Close: 10000.0})
+	}
+
+	if assert.Len(t, trader.Orders, 3) {
+		assert.InDelta(t, 0.005, trader.Orders[2].Quantity, 1e-9)
+	}
+
+	// the budget is spent, it's notified once
+	emitFill(stream, trader, 2, types.Trade{ID: 5, Price: 10000.0, Quantity: 0.005})
+	for i := 0; i < 4; i++ {
+		stream.EmitKLineClosed(types.KLine{Symbol: "BTCUSDT", Interval: "1h", Close: 10000.0})
+	}
+
+	assert.Len(t, trader.Orders, 3)
+	assert.Len(t, trader.Messages, 1)
+}
+
+func TestStrategy_BudgetSpentAcrossRestart(t *testing.T) {
+	store := bbgo.NewMemoryStateStore()
+
+	strategy := &Strategy{Amount: 100.0, Interval: "1h", EveryKLines: 1, Budget: 250.0}
+	trader, stream := newTestStrategy(t, strategy, store)
+
+	stream.EmitKLineClosed(types.KLine{Symbol: "BTCUSDT", Interval: "1h", Close: 10000.0})
+	stream.EmitKLineClosed(types.KLine{Symbol: "BTCUSDT", Interval: "1h", Close: 10000.0})
+	emitFill(stream, trader, 0, types.Trade{ID: 1, Price: 10000.0, Quantity: 0.01})
+
+	// the trade that arrives before the order update is counted once the order is known
+	stream.EmitTrade(&types.Trade{ID: 2, Symbol: "BTCUSDT", OrderID: 2, Price: 10000.0, Quantity: 0.01, IsBuyer: true})
+	assert.Equal(t, 100.0, strategy.Spent())
+	stream.EmitOrderUpdate(types.Order{SubmitOrder: trader.Orders[1], OrderID: 2, Status: types.OrderStatusFilled})
+	assert.Equal(t, 200.0, strategy.Spent())
+
+	// the restarted strategy spends the last 50 of the budget
+	restarted := &Strategy{Amount: 100.0, Interval: "1h", EveryKLines: 1, Budget: 250.0}
+	trader, stream = newTestStrategy(t, restarted, store)
+	assert.Equal(t, 200.0, restarted.Spent())
+
+	stream.EmitKLineClosed(types.KLine{Symbol: "BTCUSDT", Interval: "1h", Close: 10000.0})
+	if assert.Len(t, trader.Orders, 1) {
+		assert.InDelta(t, 0.005, trader.Orders[0].Quantity, 1e-9)
+	}
+
+	emitFill(stream, trader, 0, types.Trade{ID: 3, Price: 10000.0, Quantity: 0.005})
+	assert.Equal(t, 250.0, restarted.Spent())
+
+	// the strategy restarted after the budget is spent doesn't buy, and it notifies once
+	restarted = &Strategy{Amount: 100.0, Interval: "1h", EveryKLines: 1, Budget: 250.0}
+	trader, stream = newTestStrategy(t, restarted, store)
+	for i := 0; i < 3; i++ {
+		stream.EmitKLineClosed(types.KLine{Symbol: "BTCUSDT", Interval: "1h", Close: 10000.0})
+	}
+
+	assert.Len(t, trader.Orders, 0)
+	assert.Len(t, trader.Messages, 1)
+}
+
+func TestStrategy_BelowMovingAverage(t *testing.T) {
+	strategy := &Strategy{
+		Amount:             100.0,
+		Interval:           "1h",
+		EveryKLines:        1,
+		BelowMovingAverage: &MovingAverageMultiplier{Interval: "1d", Period: 2, Multiplier: 2.0},
+	}
+	trader, stream := newTestStrategy(t, strategy, bbgo.NewMemoryStateStore())
+	assert.Len(t, stream.Subscriptions, 2)
+
+	// no moving average yet
+	stream.EmitKLineClosed(types.KLine{Symbol: "BTCUSDT", Interval: "1h", Close: 9000.0})
+
+	stream.EmitKLineClosed(types.KLine{Symbol: "BTCUSDT", Interval: "1d", Close: 10000.0})
+	stream.EmitKLineClosed(types.KLine{Symbol: "BTCUSDT", Interval: "1d", Close: 11000.0})
+
+	// below the moving average 10500
+	stream.EmitKLineClosed(types.KLine{Symbol: "BTCUSDT", Interval: "1h", Close: 9000.0})
+
+	// above the moving average
+	stream.EmitKLineClosed(types.KLine{Symbol: "BTCUSDT", Interval: "1h", Close: 12000.0})
+
+	if assert.Len(t, trader.Orders, 3) {
+		assert.InDelta(t, 100.0/9000.0, trader.Orders[0].Quantity, 1e-9)
+		assert.InDelta(t, 200.0/9000.0, trader.Orders[1].Quantity, 1e-9)
+		assert.InDelta(t, 100.0/12000.0, trader.Orders[2].Quantity, 1e-9)
+	}
+}
+
+func TestStrategy_Schedule(t *testing.T) {
+	strategy := &Strategy{Amount: 100.0, Schedule: "0 9 * * *"}
+	trader, stream := newTestStrategy(t, strategy, bbgo.NewMemoryStateStore())
+
+	// the 1m klines keep the price of the scheduled buys
+	if assert.Len(t, stream.Subscriptions, 1) {
+		assert.Equal(t, priceInterval, stream.Subscriptions[0].Options.Interval)
+	}
+
+	// no price yet, the buy is skipped
+	strategy.scheduledBuy(context.Background())
+	assert.Len(t, trader.Orders, 0)
+
+	// the klines don't trigger the scheduled buys
+	stream.EmitKLineClosed(types.KLine{Symbol: "BTCUSDT", Interval: priceInterval, Close: 10000.0})
+	assert.Len(t, trader.Orders, 0)
+
+	strategy.scheduledBuy(context.Background())
+	if assert.Len(t, trader.Orders, 1) {
+		assert.InDelta(t, 0.01, trader.Orders[0].Quantity, 1e-9)
+	}
+
+	assert.NoError(t, strategy.OnShutdown(context.Background()))
+}
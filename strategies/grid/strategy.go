@@ -0,0 +1,278 @@
+package grid
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+
+	"github.com/adshao/go-binance"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/bbgo"
+	"github.com/c9s/bbgo/types"
+)
+
+const ID = "grid"
+
+func init() {
+	bbgo.RegisterStrategy(ID, &Strategy{})
+}
+
+// OrderManager is implemented by the traders that can query and cancel the open orders of the trader symbol, e.g., bbgo.Trader
+type OrderManager interface {
+	QueryOpenOrders(ctx context.Context) ([]types.Order, error)
+	CancelOrders(ctx context.Context, orders ...types.Order) error
+}
+
+// pairedSellPrefix is the client order id prefix of the sell orders that replace the filled grid buy orders,
+// it starts with the grid prefix, so the paired sell orders are grid orders too.
+const pairedSellPrefix = ID + "-pair"
+
+// Strategy places the buy orders below the current price and the sell orders above the current price on the grid
+// between the lower price and the upper price. When a grid order is filled, the opposite order is placed one grid step away,
+// so every sell order that replaces a filled grid buy order earns the grid step. The sell orders are checked by
+// the StockManager based profit check of the order processor, so the stocks are never sold below their cost.
+//
+// The grid orders are tagged by the client order id, so the other orders of the symbol are left untouched,
+// the grid levels that already have the open grid orders are not placed again on restart
+// and only the grid orders are canceled on shutdown.
+type Strategy struct {
+	UpperPrice float64 `json:"upperPrice" yaml:"upperPrice"`
+	LowerPrice float64 `json:"lowerPrice" yaml:"lowerPrice"`
+
+	// GridNumber is the number of the grid steps between the lower price and the upper price
+	GridNumber int `json:"gridNumber" yaml:"gridNumber"`
+
+	// Quantity is the base quantity of every grid order
+	Quantity float64 `json:"quantity" yaml:"quantity"`
+
+	mu sync.Mutex
+
+	tradingContext *bbgo.Context
+	trader         types.Trader
+
+	// profit is the quote currency profit of the filled paired sell orders, the fees are deducted
+	profit     float64
+	arbitrages int
+}
+
+func (s *Strategy) Validate() error {
+	if s.LowerPrice <= 0 || s.UpperPrice <= s.LowerPrice {
+		return fmt.Errorf("grid: invalid price range %f - %f", s.LowerPrice, s.UpperPrice)
+	}
+
+	if s.GridNumber < 2 {
+		return fmt.Errorf("grid: gridNumber %d must be at least 2", s.GridNumber)
+	}
+
+	if s.Quantity <= 0 {
+		return fmt.Errorf("grid: quantity must be positive")
+	}
+
+	return nil
+}
+
+// GridStep is the price difference between the grid levels
+func (s *Strategy) GridStep() float64 {
+	return (s.UpperPrice - s.LowerPrice) / float64(s.GridNumber)
+}
+
+// Levels returns the grid prices from the lower price to the upper price
+func (s *Strategy) Levels() (levels []float64) {
+	step := s.GridStep()
+	for i := 0; i <= s.GridNumber; i++ {
+		levels = append(levels, s.LowerPrice+step*float64(i))
+	}
+
+	return levels
+}
+
+// Profit returns the grid profit and the number of the filled paired sell orders
+func (s *Strategy) Profit() (float64, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.profit, s.arbitrages
+}
+
+func (s *Strategy) OnLoad(tradingContext *bbgo.Context, trader types.Trader) error {
+	if err := s.Validate(); err != nil {
+		return err
+	}
+
+	s.tradingContext = tradingContext
+	s.trader = trader
+	return nil
+}
+
+func (s *Strategy) OnNewStream(stream types.Stream) error {
+	stream.OnOrderUpdate(s.handleOrderUpdate)
+	return s.placeGrid(context.Background())
+}
+
+func (s *Strategy) orderManager() (OrderManager, error) {
+	manager, ok := s.trader.(OrderManager)
+	if !ok {
+		return nil, fmt.Errorf("grid: trader %T can not query and cancel the open orders", s.trader)
+	}
+
+	return manager, nil
+}
+
+// openGridOrders queries the open orders of the symbol that are placed by the grid
+func (s *Strategy) openGridOrders(ctx context.Context) ([]types.Order, error) {
+	manager, err := s.orderManager()
+	if err != nil {
+		return nil, err
+	}
+
+	orders, err := manager.QueryOpenOrders(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var gridOrders []types.Order
+	for _, order := range orders {
+		if bbgo.HasClientOrderIDPrefix(order.ClientOrderID, ID) {
+			gridOrders = append(gridOrders, order)
+		}
+	}
+
+	return gridOrders, nil
+}
+
+// OnShutdown cancels the grid orders, the other orders of the symbol are not canceled
+func (s *Strategy) OnShutdown(ctx context.Context) error {
+	orders, err := s.openGridOrders(ctx)
+	if err != nil {
+		return err
+	}
+
+	manager, err := s.orderManager()
+	if err != nil {
+		return err
+	}
+
+	log.Infof("grid: canceling %d grid orders of %s", len(orders), s.tradingContext.Symbol)
+	return manager.CancelOrders(ctx, orders...)
+}
+
+// placeGrid places the buy orders below the current price and the sell orders above the current price,
+// the level that is closest to the current price and the levels that have the open grid orders are skipped.
+func (s *Strategy) placeGrid(ctx context.Context) error {
+	openOrders, err := s.openGridOrders(ctx)
+	if err != nil {
+		return err
+	}
+
+	var placed = make(map[int]struct{}, len(openOrders))
+	for _, order := range openOrders {
+		if level, ok := s.levelIndex(order.Price); ok {
+			placed[level] = struct{}{}
+		}
+	}
+
+	if len(placed) > 0 {
+		log.Infof("grid: found %d open grid orders of %s, the levels are not placed again", len(placed), s.tradingContext.Symbol)
+	}
+
+	currentPrice := s.tradingContext.CurrentPrice
+	halfStep := s.GridStep() / 2.0
+
+	for level, price := range s.Levels() {
+		if _, ok := placed[level]; ok || math.Abs(price-currentPrice) < halfStep {
+			continue
+		}
+
+		side := types.SideTypeBuy
+		if price > currentPrice {
+			side = types.SideTypeSell
+		}
+
+		s.submitOrder(ctx, side, price, ID)
+	}
+
+	return nil
+}
+
+func (s *Strategy) submitOrder(ctx context.Context, side types.SideType, price float64, clientOrderIDPrefix string) {
+	market := s.tradingContext.Market
+	s.trader.SubmitOrder(ctx, &types.SubmitOrder{
+		Symbol:        s.tradingContext.Symbol,
+		Side:          side,
+		Type:          types.OrderTypeLimit,
+		Market:        market,
+		Quantity:      s.Quantity,
+		Price:         price,
+		PriceString:   strconv.FormatFloat(price, 'f', market.PricePrecision, 64),
+		TimeInForce:   binance.TimeInForceTypeGTC,
+		ClientOrderID: bbgo.NewClientOrderID(clientOrderIDPrefix),
+	})
+}
+
+// levelIndex returns the index of the grid level of the price in the precision of the market
+func (s *Strategy) levelIndex(price float64) (int, bool) {
+	tick := math.Pow10(-s.tradingContext.Market.PricePrecision)
+	for i, level := range s.Levels() {
+		if math.Abs(price-level) < tick {
+			return i, true
+		}
+	}
+
+	return 0, false
+}
+
+// handleOrderUpdate replaces the filled grid order with the opposite order one grid step away,
+// the grid profit is only counted for the paired sell orders, the sell orders placed by the initial grid
+// sell the existing stocks.
+func (s *Strategy) handleOrderUpdate(order types.Order) {
+	if order.Symbol != s.tradingContext.Symbol || order.Status != types.OrderStatusFilled || !bbgo.HasClientOrderIDPrefix(order.ClientOrderID, ID) {
+		return
+	}
+
+	if _, ok := s.levelIndex(order.Price); !ok {
+		return
+	}
+
+	step := s.GridStep()
+	ctx := context.Background()
+
+	switch order.Side {
+	case types.SideTypeBuy:
+		if price := order.Price + step; price <= s.UpperPrice+step/2.0 {
+			s.submitOrder(ctx, types.SideTypeSell, price, pairedSellPrefix)
+		}
+
+	case types.SideTypeSell:
+		buyPrice := order.Price - step
+		if bbgo.HasClientOrderIDPrefix(order.ClientOrderID, pairedSellPrefix) {
+			s.recordProfit(order, buyPrice)
+		}
+
+		if buyPrice >= s.LowerPrice-step/2.0 {
+			s.submitOrder(ctx, types.SideTypeBuy, buyPrice, ID)
+		}
+	}
+}
+
+// recordProfit records the grid profit of the paired sell order, the sell order is paired with the buy order one step below
+func (s *Strategy) recordProfit(order types.Order, buyPrice float64) {
+	feeRates := s.tradingContext.FeeRates()
+	fee := (buyPrice + order.Price) * order.ExecutedQuantity * feeRates.MakerFeeRate
+	profit := (order.Price-buyPrice)*order.ExecutedQuantity - fee
+
+	s.mu.Lock()
+	s.profit += profit
+	s.arbitrages++
+	totalProfit, arbitrages := s.profit, s.arbitrages
+	s.mu.Unlock()
+
+	log.Infof("grid: %s sell order filled at %f, profit %f, total grid profit %f in %d arbitrages",
+		order.Symbol, order.Price, profit, totalProfit, arbitrages)
+
+	if notifier, ok := s.trader.(bbgo.Notifier); ok {
+		notifier.Notify(":chart_with_upwards_trend: %s grid profit %f, total %f in %d arbitrages",
+			order.Symbol, profit, totalProfit, arbitrages)
+	}
+}
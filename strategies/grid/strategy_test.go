@@ -0,0 +1,137 @@
+package grid
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/bbgo"
+	"github.com/c9s/bbgo/strategies/strategytest"
+	"github.com/c9s/bbgo/types"
+)
+
+func newTestStrategy(t *testing.T) (*Strategy, *strategytest.Trader) {
+	strategy := &Strategy{UpperPrice: 10000.0, LowerPrice: 9000.0, GridNumber: 10, Quantity: 0.01}
+	trader := &strategytest.Trader{}
+	assert.NoError(t, strategy.OnLoad(strategytest.NewContext(9520.0), trader))
+	return strategy, trader
+}
+
+func TestStrategy_Validate(t *testing.T) {
+	strategytest.AssertValidate(t, []strategytest.ValidateCase{
+		{Name: "inverted price range", Strategy: &Strategy{UpperPrice: 9000.0, LowerPrice: 10000.0, GridNumber: 10, Quantity: 0.01}},
+		{Name: "one grid", Strategy: &Strategy{UpperPrice: 10000.0, LowerPrice: 9000.0, GridNumber: 1, Quantity: 0.01}},
+		{Name: "no quantity", Strategy: &Strategy{UpperPrice: 10000.0, LowerPrice: 9000.0, GridNumber: 10}},
+		{Name: "valid", Strategy: &Strategy{UpperPrice: 10000.0, LowerPrice: 9000.0, GridNumber: 10, Quantity: 0.01}, Valid: true},
+	})
+}
+
+func TestStrategy_PlaceGrid(t *testing.T) {
+	strategy, trader := newTestStrategy(t)
+	assert.NoError(t, strategy.OnNewStream(&bbgo.BackTestStream{}))
+
+	// 11 levels, the level 9500 is the closest to the current price
+	if assert.Len(t, trader.Orders, 10) {
+		var buys, sells int
+		for _, order := range trader.Orders {
+			assert.Equal(t, types.OrderTypeLimit, order.Type)
+			assert.Equal(t, 0.01, order.Quantity)
+			assert.NotEqual(t, 9500.0, order.Price)
+			assert.True(t, bbgo.HasClientOrderIDPrefix(order.ClientOrderID, ID))
+
+			if order.Side == types.SideTypeBuy {
+				assert.True(t, order.Price < 9520.0)
+				buys++
+			} else {
+				assert.True(t, order.Price > 9520.0)
+				sells++
+			}
+		}
+
+		assert.Equal(t, 5, buys)
+		assert.Equal(t, 5, sells)
+		assert.Equal(t, "9000.00", trader.Orders[0].PriceString)
+	}
+
+	// the grid is not placed again on restart, only the level of the filled order is placed
+	trader.OpenOrders = trader.OpenOrders[1:]
+	restarted := &Strategy{UpperPrice: 10000.0, LowerPrice: 9000.0, GridNumber: 10, Quantity: 0.01}
+	assert.NoError(t, restarted.OnLoad(strategytest.NewContext(9520.0), trader))
+	assert.NoError(t, restarted.OnNewStream(&bbgo.BackTestStream{}))
+	if assert.Len(t, trader.Orders, 11) {
+		assert.Equal(t, 9000.0, trader.Orders[10].Price)
+	}
+}
+
+func TestStrategy_HandleOrderUpdate(t *testing.T) {
+	strategy, trader := newTestStrategy(t)
+
+	newFilledOrder := func(side types.SideType, price float64, clientOrderIDPrefix string) types.Order {
+		return types.Order{
+			SubmitOrder: types.SubmitOrder{
+				Symbol:        "BTCUSDT",
+				Side:          side,
+				Type:          types.OrderTypeLimit,
+				Price:         price,
+				Quantity:      0.01,
+				ClientOrderID: bbgo.NewClientOrderID(clientOrderIDPrefix),
+			},
+			Status:           types.OrderStatusFilled,
+			ExecutedQuantity: 0.01,
+		}
+	}
+
+	// the filled buy order is replaced with the paired sell order one step above
+	strategy.handleOrderUpdate(newFilledOrder(types.SideTypeBuy, 9400.0, ID))
+	if assert.Len(t, trader.Orders, 1) {
+		assert.Equal(t, types.SideTypeSell, trader.Orders[0].Side)
+		assert.Equal(t, 9500.0, trader.Orders[0].Price)
+		assert.True(t, bbgo.HasClientOrderIDPrefix(trader.Orders[0].ClientOrderID, pairedSellPrefix))
+	}
+
+	// the filled paired sell order earns the grid step and is replaced with the buy order one step below
+	sell := newFilledOrder(types.SideTypeSell, 9500.0, ID)
+	sell.ClientOrderID = trader.Orders[0].ClientOrderID
+	strategy.handleOrderUpdate(sell)
+	if assert.Len(t, trader.Orders, 2) {
+		assert.Equal(t, types.SideTypeBuy, trader.Orders[1].Side)
+		assert.Equal(t, 9400.0, trader.Orders[1].Price)
+	}
+
+	profit, arbitrages := strategy.Profit()
+	assert.Equal(t, 1, arbitrages)
+	assert.InDelta(t, 100.0*0.01-(9400.0+9500.0)*0.01*0.001, profit, 1e-8)
+	assert.Len(t, trader.Messages, 1)
+
+	// the sell order of the initial grid is not paired with a filled buy, no profit is counted
+	strategy.handleOrderUpdate(newFilledOrder(types.SideTypeSell, 9700.0, ID))
+	assert.Len(t, trader.Orders, 3)
+	_, arbitrages = strategy.Profit()
+	assert.Equal(t, 1, arbitrages)
+
+	// no order above the upper price or below the lower price
+	strategy.handleOrderUpdate(newFilledOrder(types.SideTypeBuy, 10000.0, ID))
+	strategy.handleOrderUpdate(newFilledOrder(types.SideTypeSell, 9000.0, ID))
+	assert.Len(t, trader.Orders, 3)
+
+	// the orders that are not on the grid, not filled or not placed by the grid are ignored
+	strategy.handleOrderUpdate(newFilledOrder(types.SideTypeBuy, 9450.0, ID))
+	newOrder := newFilledOrder(types.SideTypeBuy, 9400.0, ID)
+	newOrder.Status = types.OrderStatusNew
+	strategy.handleOrderUpdate(newOrder)
+	strategy.handleOrderUpdate(newFilledOrder(types.SideTypeBuy, 9400.0, "manual"))
+	assert.Len(t, trader.Orders, 3)
+}
+
+func TestStrategy_OnShutdown(t *testing.T) {
+	strategy, trader := newTestStrategy(t)
+	assert.NoError(t, strategy.OnNewStream(&bbgo.BackTestStream{}))
+
+	foreign := types.Order{SubmitOrder: types.SubmitOrder{Symbol: "BTCUSDT", Price: 9000.0}, OrderID: 100}
+	trader.OpenOrders = append(trader.OpenOrders, foreign)
+
+	assert.NoError(t, strategy.OnShutdown(context.Background()))
+	assert.Len(t, trader.Canceled, 10)
+	assert.Equal(t, []types.Order{foreign}, trader.OpenOrders)
+}
@@ -0,0 +1,415 @@
+package rebalance
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/adshao/go-binance"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/bbgo"
+	"github.com/c9s/bbgo/types"
+)
+
+const ID = "rebalance"
+
+const defaultCheckInterval = time.Minute
+
+func init() {
+	bbgo.RegisterStrategy(ID, &Strategy{})
+}
+
+// AssetWeight is the value and the weight of a currency in the portfolio
+type AssetWeight struct {
+	Currency     string
+	Value        float64
+	Weight       float64
+	TargetWeight float64
+}
+
+// Drift is the difference between the current weight and the target weight
+func (w AssetWeight) Drift() float64 {
+	return w.Weight - w.TargetWeight
+}
+
+// Strategy keeps the account of the session at the target weights, e.g., 50% BTC, 30% ETH and 20% USDT.
+// The balances are valued in the quote currency by the ticker prices, when the drift of any currency exceeds
+// the threshold, the minimal set of the currencies that brings every weight back within the threshold is traded
+// back to the target weights against the quote currency with one order for each currency.
+// The orders below the min notional of the market are skipped.
+//
+// The orders are tagged by the client order id, the unfilled limit orders of the last rebalance are replaced
+// when the rebalance is triggered again, the other open orders of the session are left untouched.
+//
+// The rebalance runs at the cron schedule, or the drift is checked at every check interval when the schedule is not set.
+// The currencies that are not in the target weights are not part of the portfolio.
+type Strategy struct {
+	Session string `json:"session" yaml:"session"`
+
+	// QuoteCurrency is the currency that the other currencies are valued in and traded against, "USDT" by default
+	QuoteCurrency string `json:"quoteCurrency" yaml:"quoteCurrency"`
+
+	// TargetWeights are the target weights of the currencies including the quote currency, the sum must be 1
+	TargetWeights map[string]float64 `json:"targetWeights" yaml:"targetWeights"`
+
+	// Threshold is the weight drift that triggers the rebalance, e.g., 0.05 for 5%
+	Threshold float64 `json:"threshold" yaml:"threshold"`
+
+	// OrderType is "market" (default) or "limit", the limit orders are priced at the last price
+	OrderType string `json:"orderType" yaml:"orderType"`
+
+	// Schedule is the cron schedule of the rebalance, e.g., "0 0 * * *" rebalances at midnight
+	Schedule string `json:"schedule" yaml:"schedule"`
+
+	// CheckInterval is the interval of the drift check when the schedule is not set, 1m by default
+	CheckInterval time.Duration `json:"checkInterval" yaml:"checkInterval"`
+
+	session *bbgo.ExchangeSession
+}
+
+func (s *Strategy) Validate() error {
+	if len(s.Session) == 0 {
+		return fmt.Errorf("rebalance: session is required")
+	}
+
+	if len(s.TargetWeights) < 2 {
+		return fmt.Errorf("rebalance: at least 2 target weights are required")
+	}
+
+	var sum float64
+	for currency, weight := range s.TargetWeights {
+		if weight < 0 {
+			return fmt.Errorf("rebalance: %s target weight can not be negative", currency)
+		}
+
+		sum += weight
+	}
+
+	if math.Abs(sum-1.0) > 1e-6 {
+		return fmt.Errorf("rebalance: the sum of the target weights %f is not 1", sum)
+	}
+
+	if s.Threshold <= 0 || s.Threshold >= 1 {
+		return fmt.Errorf("rebalance: threshold must be between 0 and 1")
+	}
+
+	switch s.OrderType {
+	case "", "market", "limit":
+	default:
+		return fmt.Errorf("rebalance: unknown order type %s", s.OrderType)
+	}
+
+	if len(s.Schedule) > 0 {
+		if _, err := bbgo.ParseSchedule(s.Schedule); err != nil {
+			return fmt.Errorf("rebalance: invalid schedule %q: %s", s.Schedule, err.Error())
+		}
+	}
+
+	if s.CheckInterval < 0 {
+		return fmt.Errorf("rebalance: checkInterval can not be negative")
+	}
+
+	return nil
+}
+
+func (s *Strategy) setDefaults() {
+	if len(s.QuoteCurrency) == 0 {
+		s.QuoteCurrency = "USDT"
+	}
+
+	if s.CheckInterval == 0 {
+		s.CheckInterval = defaultCheckInterval
+	}
+}
+
+// symbols returns the markets of the currencies against the quote currency
+func (s *Strategy) symbols() (symbols []string) {
+	for currency := range s.TargetWeights {
+		if currency != s.QuoteCurrency {
+			symbols = append(symbols, currency+s.QuoteCurrency)
+		}
+	}
+
+	sort.Strings(symbols)
+	return symbols
+}
+
+func (s *Strategy) CrossSubscribe(sessions map[string]*bbgo.ExchangeSession) error {
+	if err := s.Validate(); err != nil {
+		return err
+	}
+
+	s.setDefaults()
+
+	session, ok := sessions[s.Session]
+	if !ok {
+		return fmt.Errorf("rebalance: session %s not found", s.Session)
+	}
+
+	for _, symbol := range s.symbols() {
+		if _, ok := session.Markets[symbol]; !ok {
+			return fmt.Errorf("rebalance: %s market of session %s not found", symbol, s.Session)
+		}
+	}
+
+	s.session = session
+	return nil
+}
+
+func (s *Strategy) CrossRun(ctx context.Context, sessions map[string]*bbgo.ExchangeSession) error {
+	if len(s.Schedule) > 0 {
+		scheduler := bbgo.NewScheduler(nil)
+		if err := scheduler.AddJob(ID, s.Schedule, s.rebalance); err != nil {
+			return err
+		}
+
+		scheduler.Run(ctx)
+		return nil
+	}
+
+	go func() {
+		ticker := time.NewTicker(s.CheckInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+
+			case <-ticker.C:
+				s.rebalance(ctx)
+			}
+		}
+	}()
+
+	return nil
+}
+
+func (s *Strategy) rebalance(ctx context.Context) {
+	prices, err := bbgo.QueryLastPrices(ctx, s.session.Exchange, s.symbols()...)
+	if err != nil {
+		log.WithError(err).Error("rebalance: can not query the prices")
+		return
+	}
+
+	balances := s.session.Account.Snapshot()
+	orders := s.rebalanceOrders(balances, prices)
+	if len(orders) == 0 {
+		return
+	}
+
+	if s.OrderType == "limit" {
+		// the unfilled orders of the last rebalance are replaced
+		canceled, err := s.cancelOwnOrders(ctx)
+		if err != nil {
+			log.WithError(err).Error("rebalance: can not cancel the open orders")
+			return
+		}
+
+		if len(canceled) > 0 {
+			// the balances locked by the canceled sell orders are available for the new orders
+			for _, order := range canceled {
+				if order.Side != types.SideTypeSell {
+					continue
+				}
+
+				currency := s.session.Markets[order.Symbol].BaseCurrency
+				remaining := order.Quantity - order.ExecutedQuantity
+
+				balance := balances[currency]
+				balance.Available += remaining
+				balance.Locked = math.Max(0, balance.Locked-remaining)
+				balances[currency] = balance
+			}
+
+			orders = s.rebalanceOrders(balances, prices)
+		}
+	}
+
+	for _, order := range orders {
+		order := order
+		if err := s.session.OrderExecutor.SubmitOrder(ctx, &order); err != nil {
+			log.WithError(err).Errorf("rebalance: can not submit the %s %s order", order.Symbol, order.Side)
+		}
+	}
+}
+
+// cancelOwnOrders cancels the open orders of the rebalance and returns the canceled orders
+func (s *Strategy) cancelOwnOrders(ctx context.Context) ([]types.Order, error) {
+	querier, ok := s.session.Exchange.(bbgo.OpenOrderQuerier)
+	if !ok {
+		return nil, nil
+	}
+
+	var canceled []types.Order
+
+	for _, symbol := range s.symbols() {
+		openOrders, err := querier.QueryOpenOrders(ctx, symbol)
+		if err != nil {
+			return canceled, err
+		}
+
+		var orders []types.Order
+		for _, order := range openOrders {
+			if bbgo.HasClientOrderIDPrefix(order.ClientOrderID, ID) {
+				orders = append(orders, order)
+			}
+		}
+
+		if len(orders) == 0 {
+			continue
+		}
+
+		if err := s.session.OrderExecutor.CancelOrders(ctx, orders...); err != nil {
+			return canceled, err
+		}
+
+		canceled = append(canceled, orders...)
+	}
+
+	return canceled, nil
+}
+
+// Weights returns the total value and the weights of the currencies in the target weights,
+// the balances (available + locked) are valued in the quote currency by the prices of the markets
+func (s *Strategy) Weights(balances map[string]types.Balance, prices map[string]float64) (total float64, weights []AssetWeight) {
+	for currency, targetWeight := range s.TargetWeights {
+		balance := balances[currency]
+		value := balance.Available + balance.Locked
+		if currency != s.QuoteCurrency {
+			value *= prices[currency+s.QuoteCurrency]
+		}
+
+		total += value
+		weights = append(weights, AssetWeight{Currency: currency, Value: value, TargetWeight: targetWeight})
+	}
+
+	if total > 0 {
+		for i := range weights {
+			weights[i].Weight = weights[i].Value / total
+		}
+	}
+
+	sort.Slice(weights, func(i, j int) bool {
+		return weights[i].Currency < weights[j].Currency
+	})
+
+	return total, weights
+}
+
+// tradedCurrencies returns the minimal set of the currencies to trade back to the target weights. The currencies over
+// the threshold are traded, the trades move their drifts into the quote currency, then the currencies that move
+// the quote currency back within the threshold are added by the largest drift first.
+func (s *Strategy) tradedCurrencies(weights []AssetWeight) map[string]bool {
+	var traded = make(map[string]bool)
+	var quoteDrift float64
+	var candidates []AssetWeight
+
+	for _, w := range weights {
+		if w.Currency == s.QuoteCurrency {
+			quoteDrift += w.Drift()
+		}
+	}
+
+	for _, w := range weights {
+		if w.Currency == s.QuoteCurrency {
+			continue
+		}
+
+		if math.Abs(w.Drift()) >= s.Threshold {
+			traded[w.Currency] = true
+			quoteDrift += w.Drift()
+		} else {
+			candidates = append(candidates, w)
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return math.Abs(candidates[i].Drift()) > math.Abs(candidates[j].Drift())
+	})
+
+	for _, w := range candidates {
+		if math.Abs(quoteDrift) < s.Threshold {
+			break
+		}
+
+		// only the drift of the opposite direction moves the quote currency toward its target weight
+		if w.Drift()*quoteDrift < 0 {
+			traded[w.Currency] = true
+			quoteDrift += w.Drift()
+		}
+	}
+
+	return traded
+}
+
+// rebalanceOrders returns the orders that trade the minimal set of the currencies back to the target weights if the
+// drift of any currency exceeds the threshold, the sell orders come first so that the quote currency is available
+// for the buy orders
+func (s *Strategy) rebalanceOrders(balances map[string]types.Balance, prices map[string]float64) (orders []types.SubmitOrder) {
+	total, weights := s.Weights(balances, prices)
+	if total == 0 {
+		return nil
+	}
+
+	var maxDrift float64
+	for _, w := range weights {
+		maxDrift = math.Max(maxDrift, math.Abs(w.Drift()))
+	}
+
+	if maxDrift < s.Threshold {
+		return nil
+	}
+
+	log.Infof("rebalance: max weight drift %f >= threshold %f, total value %f %s", maxDrift, s.Threshold, total, s.QuoteCurrency)
+
+	traded := s.tradedCurrencies(weights)
+	for _, w := range weights {
+		if !traded[w.Currency] {
+			continue
+		}
+
+		symbol := w.Currency + s.QuoteCurrency
+		market := s.session.Markets[symbol]
+		price := prices[symbol]
+
+		diff := total*w.TargetWeight - w.Value
+		quantity := math.Abs(diff) / price
+
+		side := types.SideTypeBuy
+		if diff < 0 {
+			side = types.SideTypeSell
+			quantity = math.Min(quantity, balances[w.Currency].Available)
+		}
+
+		if quantity < market.MinQuantity || quantity*price < market.MinNotional {
+			continue
+		}
+
+		order := types.SubmitOrder{
+			Symbol:        symbol,
+			Side:          side,
+			Type:          types.OrderTypeMarket,
+			Market:        market,
+			Quantity:      quantity,
+			ClientOrderID: bbgo.NewClientOrderID(ID),
+		}
+
+		if s.OrderType == "limit" {
+			order.Type = types.OrderTypeLimit
+			order.Price = price
+			order.TimeInForce = binance.TimeInForceTypeGTC
+		}
+
+		orders = append(orders, order)
+	}
+
+	sort.SliceStable(orders, func(i, j int) bool {
+		return orders[i].Side == types.SideTypeSell && orders[j].Side == types.SideTypeBuy
+	})
+
+	return orders
+}
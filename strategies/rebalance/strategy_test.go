@@ -0,0 +1,217 @@
+package rebalance
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/bbgo"
+	"github.com/c9s/bbgo/strategies/strategytest"
+	"github.com/c9s/bbgo/types"
+)
+
+var testWeights = map[string]float64{"BTC": 0.5, "ETH": 0.3, "USDT": 0.2}
+
+func newTestStrategy(t *testing.T, strategy *Strategy, balances map[string]types.Balance) *strategytest.Exchange {
+	exchange := &strategytest.Exchange{ExchangeName: "binance", Tickers: map[string]types.Ticker{
+		"BTCUSDT": {Symbol: "BTCUSDT", Last: 10000.0},
+		"ETHUSDT": {Symbol: "ETHUSDT", Last: 200.0},
+	}}
+
+	session := strategytest.NewSession("binance", exchange, types.MarketBTCUSDT, types.MarketETHUSDT)
+	session.Account = &bbgo.Account{Balances: balances}
+
+	assert.NoError(t, strategy.CrossSubscribe(map[string]*bbgo.ExchangeSession{"binance": session}))
+	return exchange
+}
+
+func TestStrategy_Validate(t *testing.T) {
+	strategytest.AssertValidate(t, []strategytest.ValidateCase{
+		{Name: "no session", Strategy: &Strategy{TargetWeights: testWeights, Threshold: 0.05}},
+		{Name: "weight sum is not 1", Strategy: &Strategy{Session: "binance", TargetWeights: map[string]float64{"BTC": 0.5, "USDT": 0.4}, Threshold: 0.05}},
+		{Name: "no threshold", Strategy: &Strategy{Session: "binance", TargetWeights: testWeights}},
+		{Name: "unknown order type", Strategy: &Strategy{Session: "binance", TargetWeights: testWeights, Threshold: 0.05, OrderType: "stop"}},
+		{Name: "invalid schedule", Strategy: &Strategy{Session: "binance", TargetWeights: testWeights, Threshold: 0.05, Schedule: "* *"}},
+		{Name: "valid", Strategy: &Strategy{Session: "binance", TargetWeights: testWeights, Threshold: 0.05, Schedule: "0 0 * * *"}, Valid: true},
+	})
+}
+
+func TestStrategy_CrossSubscribe(t *testing.T) {
+	strategy := &Strategy{Session: "binance", TargetWeights: testWeights, Threshold: 0.05}
+	newTestStrategy(t, strategy, nil)
+	assert.Equal(t, "USDT", strategy.QuoteCurrency)
+	assert.Equal(t, defaultCheckInterval, strategy.CheckInterval)
+	assert.Equal(t, []string{"BTCUSDT", "ETHUSDT"}, strategy.symbols())
+
+	// the market of LTC is not found
+	err := (&Strategy{Session: "binance", TargetWeights: map[string]float64{"LTC": 0.5, "USDT": 0.5}, Threshold: 0.05}).
+		CrossSubscribe(map[string]*bbgo.ExchangeSession{"binance": strategy.session})
+	assert.Error(t, err)
+}
+
+func TestStrategy_Weights(t *testing.T) {
+	strategy := &Strategy{Session: "binance", TargetWeights: testWeights, Threshold: 0.05}
+	newTestStrategy(t, strategy, nil)
+
+	total, weights := strategy.Weights(map[string]types.Balance{
+		"BTC":  {Currency: "BTC", Available: 0.8, Locked: 0.2},
+		"ETH":  {Currency: "ETH", Available: 10.0},
+		"USDT": {Currency: "USDT", Available: 8000.0},
+		"BNB":  {Currency: "BNB", Available: 100.0},
+	}, map[string]float64{"BTCUSDT": 10000.0, "ETHUSDT": 200.0})
+
+	assert.Equal(t, 20000.0, total)
+	if assert.Len(t, weights, 3) {
+		assert.Equal(t, AssetWeight{Currency: "BTC", Value: 10000.0, Weight: 0.5, TargetWeight: 0.5}, weights[0])
+		assert.InDelta(t, -0.2, weights[1].Drift(), 1e-9)
+		assert.InDelta(t, 0.2, weights[2].Drift(), 1e-9)
+	}
+}
+
+func TestStrategy_Rebalance(t *testing.T) {
+	strategy := &Strategy{Session: "binance", TargetWeights: testWeights, Threshold: 0.05}
+	exchange := newTestStrategy(t, strategy, map[string]types.Balance{
+		"BTC":  {Currency: "BTC", Available: 1.2},
+		"ETH":  {Currency: "ETH", Available: 10.0},
+		"USDT": {Currency: "USDT", Available: 8000.0},
+	})
+
+	strategy.rebalance(context.Background())
+
+	// total 22000: buying 23 ETH to 6600 moves USDT to 3400, BTC 12000 is within the threshold
+	orders := exchange.SubmittedOrders()
+	if assert.Len(t, orders, 1) {
+		assert.Equal(t, "ETHUSDT", orders[0].Symbol)
+		assert.Equal(t, types.SideTypeBuy, orders[0].Side)
+		assert.Equal(t, types.OrderTypeMarket, orders[0].Type)
+		assert.InDelta(t, 23.0, orders[0].Quantity, 1e-9)
+		assert.True(t, bbgo.HasClientOrderIDPrefix(orders[0].ClientOrderID, ID))
+	}
+}
+
+func TestStrategy_RebalanceMinNotional(t *testing.T) {
+	strategy := &Strategy{Session: "binance", TargetWeights: testWeights, Threshold: 0.05}
+	exchange := newTestStrategy(t, strategy, map[string]types.Balance{
+		"BTC":  {Currency: "BTC", Available: 1.2},
+		"ETH":  {Currency: "ETH", Available: 10.0},
+		"USDT": {Currency: "USDT", Available: 8000.0},
+	})
+
+	market := types.MarketETHUSDT
+	market.MinNotional = 5000.0
+	strategy.session.Markets["ETHUSDT"] = market
+
+	// the ETH buy of 4600 is below the min notional, BTC is within the threshold, so nothing is traded
+	strategy.rebalance(context.Background())
+	assert.Len(t, exchange.SubmittedOrders(), 0)
+
+	// ETH drops to 100, total 21000: BTC 12000 sells 1500 and the ETH buy of 5300 reaches the min notional
+	exchange.Tickers["ETHUSDT"] = types.Ticker{Symbol: "ETHUSDT", Last: 100.0}
+	strategy.rebalance(context.Background())
+
+	orders := exchange.SubmittedOrders()
+	if assert.Len(t, orders, 2) {
+		assert.Equal(t, "BTCUSDT", orders[0].Symbol)
+		assert.Equal(t, types.SideTypeSell, orders[0].Side)
+		assert.InDelta(t, 0.15, orders[0].Quantity, 1e-9)
+
+		assert.Equal(t, "ETHUSDT", orders[1].Symbol)
+		assert.Equal(t, types.SideTypeBuy, orders[1].Side)
+		assert.InDelta(t, 53.0, orders[1].Quantity, 1e-9)
+	}
+}
+
+func TestStrategy_TradedCurrencies(t *testing.T) {
+	strategy := &Strategy{Session: "binance", TargetWeights: testWeights, Threshold: 0.05}
+	newTestStrategy(t, strategy, nil)
+
+	// only USDT exceeds the threshold, selling BTC of the largest drift moves USDT back within the threshold
+	traded := strategy.tradedCurrencies([]AssetWeight{
+		{Currency: "BTC", Weight: 0.54, TargetWeight: 0.5},
+		{Currency: "ETH", Weight: 0.33, TargetWeight: 0.3},
+		{Currency: "USDT", Weight: 0.13, TargetWeight: 0.2},
+	})
+	assert.Equal(t, map[string]bool{"BTC": true}, traded)
+
+	// selling the overweight BTC leaves USDT overweight by 0.08, buying the underweight ETH is enough
+	traded = strategy.tradedCurrencies([]AssetWeight{
+		{Currency: "BTC", Weight: 0.5, TargetWeight: 0.4},
+		{Currency: "ETH", Weight: 0.16, TargetWeight: 0.2},
+		{Currency: "BNB", Weight: 0.16, TargetWeight: 0.2},
+		{Currency: "USDT", Weight: 0.18, TargetWeight: 0.2},
+	})
+	assert.Equal(t, map[string]bool{"BTC": true, "ETH": true}, traded)
+}
+
+func TestStrategy_RebalanceOrders(t *testing.T) {
+	strategy := &Strategy{Session: "binance", TargetWeights: testWeights, Threshold: 0.05, OrderType: "limit"}
+	newTestStrategy(t, strategy, nil)
+	prices := map[string]float64{"BTCUSDT": 10000.0, "ETHUSDT": 200.0}
+
+	// the drift is below the threshold
+	orders := strategy.rebalanceOrders(map[string]types.Balance{
+		"BTC":  {Currency: "BTC", Available: 1.05},
+		"ETH":  {Currency: "ETH", Available: 30.0},
+		"USDT": {Currency: "USDT", Available: 3500.0},
+	}, prices)
+	assert.Len(t, orders, 0)
+
+	// the BTC order 0.0005 BTC is below the min notional
+	orders = strategy.rebalanceOrders(map[string]types.Balance{
+		"BTC":  {Currency: "BTC", Available: 0.9995},
+		"ETH":  {Currency: "ETH", Available: 10.0},
+		"USDT": {Currency: "USDT", Available: 8005.0},
+	}, prices)
+
+	if assert.Len(t, orders, 1) {
+		assert.Equal(t, "ETHUSDT", orders[0].Symbol)
+		assert.Equal(t, types.OrderTypeLimit, orders[0].Type)
+		assert.Equal(t, 200.0, orders[0].Price)
+	}
+}
+
+func TestStrategy_CancelOwnOrders(t *testing.T) {
+	strategy := &Strategy{Session: "binance", TargetWeights: testWeights, Threshold: 0.05, OrderType: "limit"}
+	balances := map[string]types.Balance{
+		"BTC":  {Currency: "BTC", Available: 0.1, Locked: 0.9},
+		"ETH":  {Currency: "ETH", Available: 30.0},
+		"USDT": {Currency: "USDT", Available: 4000.0},
+	}
+	exchange := newTestStrategy(t, strategy, balances)
+
+	own := types.Order{
+		SubmitOrder:      types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeSell, Quantity: 1.0, ClientOrderID: bbgo.NewClientOrderID(ID)},
+		OrderID:          1,
+		ExecutedQuantity: 0.1,
+	}
+	foreign := types.Order{SubmitOrder: types.SubmitOrder{Symbol: "ETHUSDT", Side: types.SideTypeBuy, Quantity: 1.0}, OrderID: 2}
+	exchange.OpenOrders = []types.Order{own, foreign}
+
+	// no order is canceled when the weights are within the threshold
+	strategy.rebalance(context.Background())
+	assert.Len(t, exchange.CanceledOrders(), 0)
+	assert.Len(t, exchange.SubmittedOrders(), 0)
+
+	// ETH drops to 100, the own sell order is replaced, the foreign order is left untouched
+	exchange.Tickers["ETHUSDT"] = types.Ticker{Symbol: "ETHUSDT", Last: 100.0}
+	strategy.rebalance(context.Background())
+
+	if canceled := exchange.CanceledOrders(); assert.Len(t, canceled, 1) {
+		assert.Equal(t, uint64(1), canceled[0].OrderID)
+	}
+	assert.Equal(t, []types.Order{foreign}, exchange.OpenOrders)
+
+	// total 17000: BTC 10000 sells 1500, ETH 3000 buys 2100, USDT 4000 is within the threshold.
+	// the BTC locked by the canceled sell order is available for the new sell order
+	orders := exchange.SubmittedOrders()
+	if assert.Len(t, orders, 2) {
+		assert.Equal(t, "BTCUSDT", orders[0].Symbol)
+		assert.Equal(t, types.SideTypeSell, orders[0].Side)
+		assert.InDelta(t, 0.15, orders[0].Quantity, 1e-9)
+
+		assert.Equal(t, "ETHUSDT", orders[1].Symbol)
+		assert.Equal(t, types.SideTypeBuy, orders[1].Side)
+		assert.InDelta(t, 21.0, orders[1].Quantity, 1e-9)
+	}
+}
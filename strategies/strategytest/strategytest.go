@@ -0,0 +1,202 @@
+// Package strategytest provides the fake trader, the fake exchange and the test helpers of the strategy tests
+package strategytest
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/accounting"
+	"github.com/c9s/bbgo/bbgo"
+	"github.com/c9s/bbgo/types"
+)
+
+// Trader records the submitted orders and the notifications of the single symbol strategies,
+// the submitted limit orders are kept as the open orders until they are canceled.
+type Trader struct {
+	mu sync.Mutex
+
+	Orders     []types.SubmitOrder
+	OpenOrders []types.Order
+	Canceled   []types.Order
+	Messages   []string
+}
+
+func (t *Trader) SubmitOrder(ctx context.Context, order *types.SubmitOrder) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.Orders = append(t.Orders, *order)
+	if order.Type == types.OrderTypeLimit {
+		t.OpenOrders = append(t.OpenOrders, types.Order{
+			SubmitOrder: *order,
+			OrderID:     uint64(len(t.Orders)),
+			Status:      types.OrderStatusNew,
+		})
+	}
+}
+
+func (t *Trader) QueryOpenOrders(ctx context.Context) ([]types.Order, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]types.Order{}, t.OpenOrders...), nil
+}
+
+func (t *Trader) CancelOrders(ctx context.Context, orders ...types.Order) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.Canceled = append(t.Canceled, orders...)
+	t.OpenOrders = removeOrders(t.OpenOrders, orders)
+	return nil
+}
+
+func (t *Trader) Notify(msg string, args ...interface{}) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.Messages = append(t.Messages, msg)
+}
+
+func (t *Trader) NotifyTrade(trade *types.Trade) {}
+
+func (t *Trader) NotifyPnL(report *accounting.ProfitAndLossReport) {}
+
+func (t *Trader) NotifyReport(report accounting.Report) {}
+
+// SubmittedOrders returns a copy of the submitted orders
+func (t *Trader) SubmittedOrders() []types.SubmitOrder {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]types.SubmitOrder{}, t.Orders...)
+}
+
+// Exchange records the submitted orders and the canceled orders of the cross exchange strategies,
+// the methods that are not implemented panic since types.Exchange is embedded.
+type Exchange struct {
+	types.Exchange
+
+	mu sync.Mutex
+
+	ExchangeName string
+	Tickers      map[string]types.Ticker
+	Orders       []types.SubmitOrder
+	OpenOrders   []types.Order
+	Canceled     []types.Order
+}
+
+func (e *Exchange) Name() string {
+	return e.ExchangeName
+}
+
+func (e *Exchange) QueryTickers(ctx context.Context, symbols ...string) (map[string]types.Ticker, error) {
+	return e.Tickers, nil
+}
+
+func (e *Exchange) SubmitOrder(ctx context.Context, order *types.SubmitOrder) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.Orders = append(e.Orders, *order)
+	return nil
+}
+
+func (e *Exchange) QueryOpenOrders(ctx context.Context, symbol string) (orders []types.Order, err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, order := range e.OpenOrders {
+		if order.Symbol == symbol {
+			orders = append(orders, order)
+		}
+	}
+
+	return orders, nil
+}
+
+func (e *Exchange) CancelOrders(ctx context.Context, orders ...types.Order) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.Canceled = append(e.Canceled, orders...)
+	e.OpenOrders = removeOrders(e.OpenOrders, orders)
+	return nil
+}
+
+// SubmittedOrders returns a copy of the submitted orders
+func (e *Exchange) SubmittedOrders() []types.SubmitOrder {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]types.SubmitOrder{}, e.Orders...)
+}
+
+// CanceledOrders returns a copy of the canceled orders
+func (e *Exchange) CanceledOrders() []types.Order {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]types.Order{}, e.Canceled...)
+}
+
+func removeOrders(orders []types.Order, removed []types.Order) []types.Order {
+	var ids = make(map[uint64]struct{}, len(removed))
+	for _, order := range removed {
+		ids[order.OrderID] = struct{}{}
+	}
+
+	var remaining []types.Order
+	for _, order := range orders {
+		if _, ok := ids[order.OrderID]; !ok {
+			remaining = append(remaining, order)
+		}
+	}
+
+	return remaining
+}
+
+// NewContext returns the BTCUSDT trading context of the current price with the 0.1% fee rates
+func NewContext(currentPrice float64) *bbgo.Context {
+	return &bbgo.Context{
+		Symbol:          "BTCUSDT",
+		Market:          types.MarketBTCUSDT,
+		CurrentPrice:    currentPrice,
+		FeeSchedule:     types.NewFeeSchedule(0.001, 0.001),
+		MarketDataStore: bbgo.NewMarketDataStore(),
+	}
+}
+
+// NewSession returns the session of the exchange with the markets, a back test stream and the order executor
+func NewSession(name string, exchange types.Exchange, markets ...types.Market) *bbgo.ExchangeSession {
+	session := bbgo.NewExchangeSession(name, exchange)
+	session.Markets = make(map[string]types.Market, len(markets))
+	for _, market := range markets {
+		session.Markets[market.Symbol] = market
+	}
+
+	session.Stream = &bbgo.BackTestStream{}
+	session.OrderExecutor = &bbgo.ExchangeOrderExecutor{Session: session}
+	return session
+}
+
+// Validator is implemented by the strategies that validate their config
+type Validator interface {
+	Validate() error
+}
+
+// ValidateCase is a strategy config and whether the config is valid
+type ValidateCase struct {
+	Name     string
+	Strategy Validator
+	Valid    bool
+}
+
+// AssertValidate asserts the validation result of every case
+func AssertValidate(t *testing.T, cases []ValidateCase) {
+	for _, c := range cases {
+		err := c.Strategy.Validate()
+		if c.Valid {
+			assert.NoError(t, err, c.Name)
+		} else {
+			assert.Error(t, err, c.Name)
+		}
+	}
+}
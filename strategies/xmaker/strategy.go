@@ -0,0 +1,432 @@
+package xmaker
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/adshao/go-binance"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/bbgo"
+	"github.com/c9s/bbgo/fixedpoint"
+	"github.com/c9s/bbgo/types"
+)
+
+const ID = "xmaker"
+
+const defaultUpdateInterval = time.Second
+
+func init() {
+	bbgo.RegisterStrategy(ID, &Strategy{})
+}
+
+// Strategy quotes the order book of the hedge session on the maker session, e.g., quoting the binance book on max.
+// The bid and ask layers are priced by the hedge book depth of the layer quantity with the margin applied,
+// so every maker fill can be hedged on the hedge session with a market order at a better price.
+//
+// The quotes are tagged by the client order id, only the fills of the own quotes are hedged. The quotes are replaced
+// on every refresh and canceled on shutdown, the position and the uncovered position are kept in the state store.
+type Strategy struct {
+	Symbol string `json:"symbol" yaml:"symbol"`
+
+	// MakerSession is the session that the quotes are placed on, "max" by default
+	MakerSession string `json:"makerSession" yaml:"makerSession"`
+
+	// HedgeSession is the session that the order book is taken from and the fills are hedged on, "binance" by default
+	HedgeSession string `json:"hedgeSession" yaml:"hedgeSession"`
+
+	// Margin is the price ratio added to the hedge book price, e.g., 0.003 quotes the bids 0.3% below the hedge bids
+	Margin float64 `json:"margin" yaml:"margin"`
+
+	// NumLayers is the number of the bid layers and the ask layers, 1 by default
+	NumLayers int `json:"numLayers" yaml:"numLayers"`
+
+	// Quantity is the base quantity of every layer
+	Quantity float64 `json:"quantity" yaml:"quantity"`
+
+	// MaxPosition is the max base position accumulated by the maker fills in either direction, no limit when it's zero
+	MaxPosition float64 `json:"maxPosition" yaml:"maxPosition"`
+
+	// UpdateInterval throttles the quote refresh, the book updates in the interval trigger one refresh
+	UpdateInterval time.Duration `json:"updateInterval" yaml:"updateInterval"`
+
+	makerSession *bbgo.ExchangeSession
+	hedgeSession *bbgo.ExchangeSession
+
+	book *types.StreamOrderBook
+
+	mu sync.Mutex
+
+	stateStore bbgo.StateStore
+
+	// activeOrders are the open quotes on the maker session by the order id
+	activeOrders map[uint64]types.Order
+
+	// ownTrades tells the maker trades of the quotes
+	ownTrades *bbgo.OwnTradeFilter
+
+	// position is the base position accumulated by the maker fills, positive for long
+	position float64
+
+	// uncovered is the base position of the maker fills that are not hedged yet
+	uncovered float64
+
+	// hedgeMu makes sure only one hedge order is submitted for the uncovered position
+	hedgeMu sync.Mutex
+}
+
+func (s *Strategy) Validate() error {
+	if len(s.Symbol) == 0 {
+		return fmt.Errorf("xmaker: symbol is required")
+	}
+
+	if s.Margin <= 0 {
+		return fmt.Errorf("xmaker: margin must be positive")
+	}
+
+	if s.Quantity <= 0 {
+		return fmt.Errorf("xmaker: quantity must be positive")
+	}
+
+	if s.NumLayers < 0 || s.MaxPosition < 0 || s.UpdateInterval < 0 {
+		return fmt.Errorf("xmaker: numLayers, maxPosition and updateInterval can not be negative")
+	}
+
+	return nil
+}
+
+func (s *Strategy) setDefaults() {
+	if len(s.MakerSession) == 0 {
+		s.MakerSession = "max"
+	}
+
+	if len(s.HedgeSession) == 0 {
+		s.HedgeSession = "binance"
+	}
+
+	if s.NumLayers == 0 {
+		s.NumLayers = 1
+	}
+
+	if s.UpdateInterval == 0 {
+		s.UpdateInterval = defaultUpdateInterval
+	}
+}
+
+// state is the position of the strategy that is kept in the state store
+type state struct {
+	Position  float64 `json:"position"`
+	Uncovered float64 `json:"uncovered"`
+}
+
+func (s *Strategy) SetStateStore(store bbgo.StateStore) {
+	s.stateStore = store
+}
+
+func (s *Strategy) stateKey() string {
+	return fmt.Sprintf("%s:%s:%s:%s", ID, s.MakerSession, s.HedgeSession, s.Symbol)
+}
+
+func (s *Strategy) loadState() error {
+	if s.stateStore == nil {
+		return nil
+	}
+
+	var st state
+	found, err := s.stateStore.Load(s.stateKey(), &st)
+	if err != nil || !found {
+		return err
+	}
+
+	log.Infof("xmaker: restored the %s position %f, uncovered %f", s.Symbol, st.Position, st.Uncovered)
+
+	s.mu.Lock()
+	s.position, s.uncovered = st.Position, st.Uncovered
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *Strategy) saveState() {
+	if s.stateStore == nil {
+		return
+	}
+
+	position, uncovered := s.Position()
+	if err := s.stateStore.Save(s.stateKey(), state{Position: position, Uncovered: uncovered}); err != nil {
+		log.WithError(err).Errorf("xmaker: can not save the %s position", s.Symbol)
+	}
+}
+
+// Position returns the base position accumulated by the maker fills and the part that is not hedged yet
+func (s *Strategy) Position() (position, uncovered float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.position, s.uncovered
+}
+
+func (s *Strategy) CrossSubscribe(sessions map[string]*bbgo.ExchangeSession) error {
+	if err := s.Validate(); err != nil {
+		return err
+	}
+
+	s.setDefaults()
+
+	var ok bool
+	if s.makerSession, ok = sessions[s.MakerSession]; !ok {
+		return fmt.Errorf("xmaker: maker session %s not found", s.MakerSession)
+	}
+
+	if s.hedgeSession, ok = sessions[s.HedgeSession]; !ok {
+		return fmt.Errorf("xmaker: hedge session %s not found", s.HedgeSession)
+	}
+
+	for _, session := range []*bbgo.ExchangeSession{s.makerSession, s.hedgeSession} {
+		if _, ok := session.Markets[s.Symbol]; !ok {
+			return fmt.Errorf("xmaker: %s market of session %s not found", s.Symbol, session.Name)
+		}
+	}
+
+	s.hedgeSession.Stream.Subscribe(types.BookChannel, s.Symbol, types.SubscribeOptions{})
+	return nil
+}
+
+func (s *Strategy) CrossRun(ctx context.Context, sessions map[string]*bbgo.ExchangeSession) error {
+	s.activeOrders = make(map[uint64]types.Order)
+	s.ownTrades = bbgo.NewOwnTradeFilter(ID)
+
+	if err := s.loadState(); err != nil {
+		return err
+	}
+
+	s.book = types.NewStreamBook(s.Symbol)
+	s.book.BindStream(s.hedgeSession.Stream)
+
+	s.makerSession.Stream.OnOrderUpdate(func(order types.Order) {
+		s.handleOrderUpdate(ctx, order)
+	})
+	s.makerSession.Stream.OnTrade(func(trade *types.Trade) {
+		s.handleTrade(ctx, trade)
+	})
+
+	// hedge the uncovered position restored from the state store
+	s.hedge(ctx)
+
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+
+			case <-s.book.C:
+				// collect the book updates of the interval into one refresh
+				s.book.C.Drain(s.UpdateInterval, s.UpdateInterval)
+				if ctx.Err() != nil {
+					return
+				}
+
+				s.updateQuote(ctx)
+			}
+		}
+	}()
+
+	return nil
+}
+
+// OnShutdown cancels the quotes on the maker session
+func (s *Strategy) OnShutdown(ctx context.Context) error {
+	log.Infof("xmaker: canceling the %s quotes on %s", s.Symbol, s.MakerSession)
+	return s.cancelQuotes(ctx)
+}
+
+// handleOrderUpdate tracks the open quotes, the trades of the quote that arrived before the order update are handled
+// once the quote is known
+func (s *Strategy) handleOrderUpdate(ctx context.Context, order types.Order) {
+	if order.Symbol != s.Symbol {
+		return
+	}
+
+	own, trades := s.ownTrades.HandleOrderUpdate(order)
+	if !own {
+		return
+	}
+
+	s.mu.Lock()
+	switch order.Status {
+	case types.OrderStatusNew, types.OrderStatusPartiallyFilled:
+		s.activeOrders[order.OrderID] = order
+
+	default:
+		delete(s.activeOrders, order.OrderID)
+	}
+	s.mu.Unlock()
+
+	for _, trade := range trades {
+		s.addFill(ctx, trade)
+	}
+}
+
+func (s *Strategy) cancelQuotes(ctx context.Context) error {
+	s.mu.Lock()
+	var orders []types.Order
+	for _, order := range s.activeOrders {
+		orders = append(orders, order)
+	}
+	s.mu.Unlock()
+
+	if len(orders) == 0 {
+		return nil
+	}
+
+	return s.makerSession.OrderExecutor.CancelOrders(ctx, orders...)
+}
+
+// updateQuote replaces the quotes with the layers priced from the current hedge book. The canceled quotes can still
+// be filled until the cancels are confirmed by the order updates, so their remaining quantity takes the position room.
+func (s *Strategy) updateQuote(ctx context.Context) {
+	if err := s.cancelQuotes(ctx); err != nil {
+		log.WithError(err).Errorf("xmaker: can not cancel the %s quotes", s.Symbol)
+		return
+	}
+
+	book := s.book.Get()
+	if len(book.Bids) == 0 || len(book.Asks) == 0 {
+		return
+	}
+
+	market := s.makerSession.Markets[s.Symbol]
+
+	// the remaining room of the position limit on each side
+	bidRoom, askRoom := math.Inf(1), math.Inf(1)
+	if s.MaxPosition > 0 {
+		s.mu.Lock()
+		bidRoom = s.MaxPosition - s.position
+		askRoom = s.MaxPosition + s.position
+
+		for _, order := range s.activeOrders {
+			remaining := order.Quantity - order.ExecutedQuantity
+			if order.Side == types.SideTypeBuy {
+				bidRoom -= remaining
+			} else {
+				askRoom -= remaining
+			}
+		}
+		s.mu.Unlock()
+	}
+
+	for _, order := range s.quotes(book, market, bidRoom, askRoom) {
+		order := order
+		if err := s.makerSession.OrderExecutor.SubmitOrder(ctx, &order); err != nil {
+			log.WithError(err).Errorf("xmaker: can not submit the %s %s quote", s.Symbol, order.Side)
+		}
+	}
+}
+
+// quotes returns the bid and the ask layers, the layer i is priced by the hedge book price at the depth of
+// (i + 1) * quantity with the margin applied. The layers that exceed the book depth or the position room are skipped.
+func (s *Strategy) quotes(book types.OrderBook, market types.Market, bidRoom, askRoom float64) (orders []types.SubmitOrder) {
+	pow := math.Pow10(market.PricePrecision)
+
+	for i := 0; i < s.NumLayers; i++ {
+		depth := fixedpoint.NewFromFloat(s.Quantity * float64(i+1))
+
+		if idx := book.Bids.IndexByVolumeDepth(depth); idx >= 0 && bidRoom >= s.Quantity {
+			// round down the bid price to keep the margin
+			price := math.Floor(book.Bids[idx].Price.Float64()*(1.0-s.Margin)*pow) / pow
+			orders = append(orders, s.newQuote(market, types.SideTypeBuy, price))
+			bidRoom -= s.Quantity
+		}
+
+		if idx := book.Asks.IndexByVolumeDepth(depth); idx >= 0 && askRoom >= s.Quantity {
+			// round up the ask price to keep the margin
+			price := math.Ceil(book.Asks[idx].Price.Float64()*(1.0+s.Margin)*pow) / pow
+			orders = append(orders, s.newQuote(market, types.SideTypeSell, price))
+			askRoom -= s.Quantity
+		}
+	}
+
+	return orders
+}
+
+func (s *Strategy) newQuote(market types.Market, side types.SideType, price float64) types.SubmitOrder {
+	return types.SubmitOrder{
+		Symbol:        s.Symbol,
+		Side:          side,
+		Type:          types.OrderTypeLimit,
+		Market:        market,
+		Quantity:      s.Quantity,
+		Price:         price,
+		TimeInForce:   binance.TimeInForceTypeGTC,
+		ClientOrderID: bbgo.NewClientOrderID(ID),
+	}
+}
+
+// handleTrade hedges the fill of the own quote, the trade of the unknown order is kept until the order update of
+// the order tells whether it's an own quote
+func (s *Strategy) handleTrade(ctx context.Context, trade *types.Trade) {
+	if trade.Symbol != s.Symbol {
+		return
+	}
+
+	if s.ownTrades.HandleTrade(*trade) {
+		s.addFill(ctx, *trade)
+	}
+}
+
+// addFill adds the maker fill to the position and hedges the uncovered position
+func (s *Strategy) addFill(ctx context.Context, trade types.Trade) {
+	quantity := trade.Quantity
+	if !trade.IsBuyer {
+		quantity = -quantity
+	}
+
+	s.mu.Lock()
+	s.position += quantity
+	s.uncovered += quantity
+	s.mu.Unlock()
+
+	s.saveState()
+
+	log.Infof("xmaker: %s maker fill %f at %f, hedging", s.Symbol, quantity, trade.Price)
+	s.hedge(ctx)
+}
+
+// hedge submits the market order that covers the uncovered position on the hedge session, the position that is
+// smaller than the min quantity of the hedge market is kept until the next fill
+func (s *Strategy) hedge(ctx context.Context) {
+	s.hedgeMu.Lock()
+	defer s.hedgeMu.Unlock()
+
+	_, uncovered := s.Position()
+
+	side := types.SideTypeSell
+	quantity := uncovered
+	if uncovered < 0 {
+		side = types.SideTypeBuy
+		quantity = -uncovered
+	}
+
+	market := s.hedgeSession.Markets[s.Symbol]
+	if quantity < market.MinQuantity || quantity == 0 {
+		return
+	}
+
+	if err := s.hedgeSession.OrderExecutor.SubmitOrder(ctx, &types.SubmitOrder{
+		Symbol:   s.Symbol,
+		Side:     side,
+		Type:     types.OrderTypeMarket,
+		Market:   market,
+		Quantity: quantity,
+	}); err != nil {
+		log.WithError(err).Errorf("xmaker: can not hedge the uncovered %s position %f", s.Symbol, uncovered)
+		return
+	}
+
+	s.mu.Lock()
+	s.uncovered -= uncovered
+	s.mu.Unlock()
+
+	s.saveState()
+}
@@ -0,0 +1,283 @@
+package xmaker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/bbgo"
+	"github.com/c9s/bbgo/fixedpoint"
+	"github.com/c9s/bbgo/strategies/strategytest"
+	"github.com/c9s/bbgo/types"
+)
+
+func newTestStrategy(t *testing.T, strategy *Strategy) (maker, hedge *strategytest.Exchange, sessions map[string]*bbgo.ExchangeSession) {
+	maker, hedge = &strategytest.Exchange{ExchangeName: "max"}, &strategytest.Exchange{ExchangeName: "binance"}
+	sessions = map[string]*bbgo.ExchangeSession{
+		"max":     strategytest.NewSession("max", maker, types.MarketBTCUSDT),
+		"binance": strategytest.NewSession("binance", hedge, types.MarketBTCUSDT),
+	}
+
+	assert.NoError(t, strategy.CrossSubscribe(sessions))
+	return maker, hedge, sessions
+}
+
+func pv(price, volume float64) types.PriceVolume {
+	return types.PriceVolume{Price: fixedpoint.NewFromFloat(price), Volume: fixedpoint.NewFromFloat(volume)}
+}
+
+var testBook = types.OrderBook{
+	Symbol: "BTCUSDT",
+	Bids:   types.PriceVolumeSlice{pv(9000.0, 0.5), pv(8990.0, 1.0)},
+	Asks:   types.PriceVolumeSlice{pv(9010.0, 0.5), pv(9020.0, 1.0)},
+}
+
+// quoteUpdate returns the order update of the own quote
+func quoteUpdate(orderID uint64, side types.SideType, status types.OrderStatus) types.Order {
+	return types.Order{
+		SubmitOrder: types.SubmitOrder{
+			Symbol:        "BTCUSDT",
+			Side:          side,
+			Quantity:      0.1,
+			ClientOrderID: bbgo.NewClientOrderID(ID),
+		},
+		OrderID: orderID,
+		Status:  status,
+	}
+}
+
+func TestStrategy_Validate(t *testing.T) {
+	strategytest.AssertValidate(t, []strategytest.ValidateCase{
+		{Name: "no symbol", Strategy: &Strategy{Margin: 0.001, Quantity: 0.1}},
+		{Name: "no margin", Strategy: &Strategy{Symbol: "BTCUSDT", Quantity: 0.1}},
+		{Name: "negative max position", Strategy: &Strategy{Symbol: "BTCUSDT", Margin: 0.001, Quantity: 0.1, MaxPosition: -1.0}},
+		{Name: "valid", Strategy: &Strategy{Symbol: "BTCUSDT", Margin: 0.001, Quantity: 0.1}, Valid: true},
+	})
+}
+
+func TestStrategy_CrossSubscribe(t *testing.T) {
+	strategy := &Strategy{Symbol: "BTCUSDT", Margin: 0.001, Quantity: 0.1}
+	_, _, sessions := newTestStrategy(t, strategy)
+
+	assert.Equal(t, 1, strategy.NumLayers)
+	assert.Equal(t, defaultUpdateInterval, strategy.UpdateInterval)
+
+	hedgeStream := sessions["binance"].Stream.(*bbgo.BackTestStream)
+	if assert.Len(t, hedgeStream.Subscriptions, 1) {
+		assert.Equal(t, types.BookChannel, hedgeStream.Subscriptions[0].Channel)
+	}
+
+	assert.Error(t, (&Strategy{Symbol: "BTCUSDT", Margin: 0.001, Quantity: 0.1, MakerSession: "ftx"}).CrossSubscribe(sessions))
+	assert.Error(t, (&Strategy{Symbol: "ETHUSDT", Margin: 0.001, Quantity: 0.1}).CrossSubscribe(sessions))
+}
+
+func TestStrategy_Quotes(t *testing.T) {
+	strategy := &Strategy{Symbol: "BTCUSDT", Margin: 0.001, Quantity: 0.4, NumLayers: 4}
+	newTestStrategy(t, strategy)
+
+	orders := strategy.quotes(testBook, types.MarketBTCUSDT, 100.0, 100.0)
+
+	// the fourth layer needs the depth of 1.6 that the book doesn't have
+	if assert.Len(t, orders, 6) {
+		assert.Equal(t, types.SideTypeBuy, orders[0].Side)
+		assert.Equal(t, 8991.0, orders[0].Price)
+		assert.Equal(t, types.SideTypeSell, orders[1].Side)
+		assert.Equal(t, 9019.01, orders[1].Price)
+
+		// the second layer is priced at the depth of 0.8
+		assert.Equal(t, 8981.01, orders[2].Price)
+		assert.Equal(t, 9029.02, orders[3].Price)
+		assert.Equal(t, 8981.01, orders[4].Price)
+		assert.Equal(t, 9029.02, orders[5].Price)
+
+		for _, order := range orders {
+			assert.Equal(t, types.OrderTypeLimit, order.Type)
+			assert.Equal(t, 0.4, order.Quantity)
+		}
+	}
+
+	// only one bid layer fits in the position room
+	orders = strategy.quotes(testBook, types.MarketBTCUSDT, 0.5, 100.0)
+	assert.Len(t, orders, 4)
+
+	orders = strategy.quotes(testBook, types.MarketBTCUSDT, 0.0, 0.0)
+	assert.Len(t, orders, 0)
+}
+
+func TestStrategy_UpdateQuote(t *testing.T) {
+	strategy := &Strategy{Symbol: "BTCUSDT", Margin: 0.001, Quantity: 0.1, MaxPosition: 0.15, UpdateInterval: 10 * time.Millisecond}
+	maker, _, sessions := newTestStrategy(t, strategy)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	assert.NoError(t, strategy.CrossRun(ctx, sessions))
+
+	// the book snapshot triggers the refresh
+	sessions["binance"].Stream.(*bbgo.BackTestStream).EmitBookSnapshot(testBook)
+	assert.Eventually(t, func() bool {
+		return len(maker.SubmittedOrders()) == 2
+	}, time.Second, 5*time.Millisecond)
+
+	for _, order := range maker.SubmittedOrders() {
+		assert.True(t, bbgo.HasClientOrderIDPrefix(order.ClientOrderID, ID))
+	}
+
+	makerStream := sessions["max"].Stream.(*bbgo.BackTestStream)
+	makerStream.EmitOrderUpdate(quoteUpdate(1, types.SideTypeBuy, types.OrderStatusNew))
+	makerStream.EmitOrderUpdate(quoteUpdate(2, types.SideTypeSell, types.OrderStatusNew))
+	makerStream.EmitOrderUpdate(quoteUpdate(2, types.SideTypeSell, types.OrderStatusFilled))
+
+	// the foreign orders are not canceled
+	makerStream.EmitOrderUpdate(types.Order{SubmitOrder: types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeBuy, Quantity: 1.0}, OrderID: 3, Status: types.OrderStatusNew})
+
+	// the sell fill of 0.1 leaves the ask room of 0.05, the bid room of 0.25 is taken by the canceled bid
+	// of 0.1 that is not confirmed yet, so only one bid is placed
+	strategy.mu.Lock()
+	strategy.position = -0.1
+	strategy.mu.Unlock()
+
+	strategy.updateQuote(ctx)
+
+	canceled := maker.CanceledOrders()
+	if assert.Len(t, canceled, 1) {
+		assert.Equal(t, uint64(1), canceled[0].OrderID)
+	}
+
+	orders := maker.SubmittedOrders()
+	if assert.Len(t, orders, 3) {
+		assert.Equal(t, types.SideTypeBuy, orders[2].Side)
+	}
+
+	// the canceled bid is still resting, the new bid takes another 0.1 of the room,
+	// so no bid is placed until the cancel is confirmed
+	makerStream.EmitOrderUpdate(quoteUpdate(4, types.SideTypeBuy, types.OrderStatusNew))
+	strategy.updateQuote(ctx)
+	assert.Len(t, maker.SubmittedOrders(), 3)
+
+	makerStream.EmitOrderUpdate(quoteUpdate(1, types.SideTypeBuy, types.OrderStatusCanceled))
+	strategy.updateQuote(ctx)
+	assert.Len(t, maker.SubmittedOrders(), 4)
+
+	cancel()
+	assert.NoError(t, strategy.OnShutdown(context.Background()))
+	for _, order := range maker.CanceledOrders() {
+		assert.NotEqual(t, uint64(3), order.OrderID)
+	}
+}
+
+func TestStrategy_Hedge(t *testing.T) {
+	strategy := &Strategy{Symbol: "BTCUSDT", Margin: 0.001, Quantity: 0.1}
+	_, hedge, sessions := newTestStrategy(t, strategy)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	assert.NoError(t, strategy.CrossRun(ctx, sessions))
+
+	makerStream := sessions["max"].Stream.(*bbgo.BackTestStream)
+	makerStream.EmitOrderUpdate(quoteUpdate(1, types.SideTypeBuy, types.OrderStatusFilled))
+	makerStream.EmitTrade(&types.Trade{Symbol: "BTCUSDT", OrderID: 1, Price: 8991.0, Quantity: 0.1, IsBuyer: true})
+	makerStream.EmitTrade(&types.Trade{Symbol: "ETHUSDT", OrderID: 1, Price: 200.0, Quantity: 1.0, IsBuyer: true})
+
+	// the fills of the foreign orders are not hedged
+	makerStream.EmitOrderUpdate(types.Order{SubmitOrder: types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeBuy}, OrderID: 2, Status: types.OrderStatusFilled})
+	makerStream.EmitTrade(&types.Trade{Symbol: "BTCUSDT", OrderID: 2, Price: 8991.0, Quantity: 1.0, IsBuyer: true})
+	makerStream.EmitTrade(&types.Trade{Symbol: "BTCUSDT", OrderID: 3, Price: 8991.0, Quantity: 1.0, IsBuyer: true})
+	makerStream.EmitOrderUpdate(types.Order{SubmitOrder: types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeBuy}, OrderID: 3, Status: types.OrderStatusFilled})
+
+	// the trade that arrives before the order update of the own quote is hedged once the quote is known
+	makerStream.EmitTrade(&types.Trade{Symbol: "BTCUSDT", OrderID: 4, Price: 9019.01, Quantity: 0.3, IsBuyer: false})
+	assert.Len(t, hedge.SubmittedOrders(), 1)
+	makerStream.EmitOrderUpdate(quoteUpdate(4, types.SideTypeSell, types.OrderStatusFilled))
+
+	orders := hedge.SubmittedOrders()
+	if assert.Len(t, orders, 2) {
+		assert.Equal(t, types.SideTypeSell, orders[0].Side)
+		assert.Equal(t, types.OrderTypeMarket, orders[0].Type)
+		assert.Equal(t, 0.1, orders[0].Quantity)
+
+		assert.Equal(t, types.SideTypeBuy, orders[1].Side)
+		assert.InDelta(t, 0.3, orders[1].Quantity, 1e-9)
+	}
+
+	position, uncovered := strategy.Position()
+	assert.InDelta(t, -0.2, position, 1e-9)
+	assert.InDelta(t, 0.0, uncovered, 1e-9)
+}
+
+func TestStrategy_HedgePartialFill(t *testing.T) {
+	strategy := &Strategy{Symbol: "BTCUSDT", Margin: 0.001, Quantity: 0.1}
+	_, hedge, sessions := newTestStrategy(t, strategy)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	assert.NoError(t, strategy.CrossRun(ctx, sessions))
+
+	makerStream := sessions["max"].Stream.(*bbgo.BackTestStream)
+	makerStream.EmitOrderUpdate(quoteUpdate(1, types.SideTypeBuy, types.OrderStatusNew))
+
+	// the first fill of the bid is hedged once, the repeated order update of the partial fill is not hedged again
+	makerStream.EmitTrade(&types.Trade{ID: 1, Symbol: "BTCUSDT", OrderID: 1, Price: 8991.0, Quantity: 0.04, IsBuyer: true})
+	makerStream.EmitOrderUpdate(quoteUpdate(1, types.SideTypeBuy, types.OrderStatusPartiallyFilled))
+	makerStream.EmitOrderUpdate(quoteUpdate(1, types.SideTypeBuy, types.OrderStatusPartiallyFilled))
+
+	if orders := hedge.SubmittedOrders(); assert.Len(t, orders, 1) {
+		assert.Equal(t, types.SideTypeSell, orders[0].Side)
+		assert.InDelta(t, 0.04, orders[0].Quantity, 1e-9)
+	}
+
+	// the rest of the bid is hedged by its own fill
+	makerStream.EmitTrade(&types.Trade{ID: 2, Symbol: "BTCUSDT", OrderID: 1, Price: 8991.0, Quantity: 0.06, IsBuyer: true})
+	makerStream.EmitOrderUpdate(quoteUpdate(1, types.SideTypeBuy, types.OrderStatusFilled))
+
+	if orders := hedge.SubmittedOrders(); assert.Len(t, orders, 2) {
+		assert.Equal(t, types.SideTypeSell, orders[1].Side)
+		assert.InDelta(t, 0.06, orders[1].Quantity, 1e-9)
+	}
+
+	position, uncovered := strategy.Position()
+	assert.InDelta(t, 0.1, position, 1e-9)
+	assert.InDelta(t, 0.0, uncovered, 1e-9)
+}
+
+func TestStrategy_RestoreState(t *testing.T) {
+	store := bbgo.NewMemoryStateStore()
+
+	strategy := &Strategy{Symbol: "BTCUSDT", Margin: 0.001, Quantity: 0.1}
+	strategy.SetStateStore(store)
+	_, hedge, sessions := newTestStrategy(t, strategy)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	assert.NoError(t, strategy.CrossRun(ctx, sessions))
+
+	// the fill below the min quantity of the hedge market is kept uncovered
+	hedgeMarket := types.MarketBTCUSDT
+	hedgeMarket.MinQuantity = 1.0
+	sessions["binance"].Markets["BTCUSDT"] = hedgeMarket
+
+	makerStream := sessions["max"].Stream.(*bbgo.BackTestStream)
+	makerStream.EmitOrderUpdate(quoteUpdate(1, types.SideTypeBuy, types.OrderStatusFilled))
+	makerStream.EmitTrade(&types.Trade{Symbol: "BTCUSDT", OrderID: 1, Price: 8991.0, Quantity: 0.1, IsBuyer: true})
+	assert.Len(t, hedge.SubmittedOrders(), 0)
+
+	// the restarted strategy restores the position and hedges the uncovered position
+	restarted := &Strategy{Symbol: "BTCUSDT", Margin: 0.001, Quantity: 0.1}
+	restarted.SetStateStore(store)
+	_, hedge, sessions = newTestStrategy(t, restarted)
+	assert.NoError(t, restarted.CrossRun(ctx, sessions))
+
+	if orders := hedge.SubmittedOrders(); assert.Len(t, orders, 1) {
+		assert.Equal(t, types.SideTypeSell, orders[0].Side)
+		assert.Equal(t, 0.1, orders[0].Quantity)
+	}
+
+	position, uncovered := restarted.Position()
+	assert.Equal(t, 0.1, position)
+	assert.Equal(t, 0.0, uncovered)
+}
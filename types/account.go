@@ -24,3 +24,8 @@ func NewAccount() *Account {
 		Balances: make(map[string]Balance),
 	}
 }
+
+// FeeSchedule converts the account commissions (in basis points) to the fee schedule
+func (a *Account) FeeSchedule() *FeeSchedule {
+	return NewFeeSchedule(float64(a.MakerCommission)*0.0001, float64(a.TakerCommission)*0.0001)
+}
@@ -1,8 +1,33 @@
 package types
 
-import "github.com/leekchan/accounting"
+import (
+	"strconv"
+
+	"github.com/leekchan/accounting"
+)
 
 var USD = accounting.Accounting{Symbol: "$ ", Precision: 2}
 var BTC = accounting.Accounting{Symbol: "BTC ", Precision: 2}
 var BNB = accounting.Accounting{Symbol: "BNB ", Precision: 4}
+var TWD = accounting.Accounting{Symbol: "NT$ ", Precision: 0}
+
+// FormatMoney formats the amount with the currency symbol, unknown currencies are formatted with the currency code suffix
+func FormatMoney(currency string, val float64) string {
+	switch currency {
+
+	case "USD", "USDT":
+		return USD.FormatMoneyFloat64(val)
+
+	case "BTC":
+		return BTC.FormatMoneyFloat64(val)
+
+	case "BNB":
+		return BNB.FormatMoneyFloat64(val)
+
+	case "TWD":
+		return TWD.FormatMoneyFloat64(val)
+
+	}
 
+	return strconv.FormatFloat(val, 'f', 8, 64) + " " + currency
+}
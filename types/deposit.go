@@ -0,0 +1,31 @@
+package types
+
+import "time"
+
+type DepositStatus string
+
+const (
+	DepositPending  = DepositStatus("pending")
+	DepositRejected = DepositStatus("rejected")
+
+	// DepositCredited means the deposit is credited to the account but can not be withdrawn yet
+	DepositCredited = DepositStatus("credited")
+	DepositSuccess  = DepositStatus("success")
+)
+
+type Deposit struct {
+	GID           int64         `json:"gid" db:"gid"`
+	Exchange      string        `json:"exchange" db:"exchange"`
+	Time          time.Time     `json:"time" db:"time"`
+	Amount        float64       `json:"amount" db:"amount"`
+	Asset         string        `json:"asset" db:"asset"`
+	Address       string        `json:"address" db:"address"`
+	AddressTag    string        `json:"addressTag" db:"address_tag"`
+	TransactionID string        `json:"txId" db:"txn_id"`
+	Status        DepositStatus `json:"status" db:"status"`
+}
+
+// IsCredited returns true if the deposit amount is added to the account balance
+func (d Deposit) IsCredited() bool {
+	return d.Status == DepositCredited || d.Status == DepositSuccess
+}
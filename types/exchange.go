@@ -6,6 +6,9 @@ import (
 )
 
 type Exchange interface {
+	// Name is the exchange name of the trades and the orders, e.g., binance or max
+	Name() string
+
 	PlatformFeeCurrency() string
 
 	NewStream() Stream
@@ -28,3 +31,25 @@ type TradeQueryOptions struct {
 	Limit       int64
 	LastTradeID int64
 }
+
+// DepositHistoryQuerier is implemented by the exchanges that can query the deposit history
+type DepositHistoryQuerier interface {
+	QueryDepositHistory(ctx context.Context, asset string, since, until time.Time) ([]Deposit, error)
+}
+
+// WithdrawHistoryQuerier is implemented by the exchanges that can query the withdraw history
+type WithdrawHistoryQuerier interface {
+	QueryWithdrawHistory(ctx context.Context, asset string, since, until time.Time) ([]Withdraw, error)
+}
+
+// TickerQuerier is implemented by the exchanges that can query the tickers of the markets,
+// the tickers of all the markets are returned if no symbol is given.
+type TickerQuerier interface {
+	QueryTickers(ctx context.Context, symbols ...string) (map[string]Ticker, error)
+}
+
+// OrderHistoryQuerier is implemented by the exchanges that can query the historical orders (open and closed),
+// the orders after lastOrderID are returned, or the orders created since the given time if lastOrderID is zero.
+type OrderHistoryQuerier interface {
+	QueryOrderHistory(ctx context.Context, symbol string, since time.Time, lastOrderID uint64) ([]Order, error)
+}
@@ -0,0 +1,73 @@
+package types
+
+// FeeRates are the maker and taker fee rates, 0.001 means 0.1%
+type FeeRates struct {
+	MakerFeeRate float64 `json:"makerFeeRate" yaml:"makerFeeRate"`
+	TakerFeeRate float64 `json:"takerFeeRate" yaml:"takerFeeRate"`
+}
+
+// FeeRate returns the taker fee rate for the market orders and the maker fee rate for the other order types
+func (r FeeRates) FeeRate(orderType OrderType) float64 {
+	if orderType == OrderTypeMarket {
+		return r.TakerFeeRate
+	}
+
+	return r.MakerFeeRate
+}
+
+// FeeSchedule is the fee rates of an exchange account
+type FeeSchedule struct {
+	// Default is used when the symbol has no specific fee rates
+	Default FeeRates `json:"default"`
+
+	// Symbols is the symbol to fee rates map
+	Symbols map[string]FeeRates `json:"symbols,omitempty"`
+
+	// DiscountCurrency is the currency that gives the fee discount when the fee is paid by it, e.g., BNB
+	DiscountCurrency string `json:"discountCurrency,omitempty"`
+
+	// DiscountRate is the ratio taken off the fee, 0.25 means 25% off
+	DiscountRate float64 `json:"discountRate,omitempty"`
+
+	// DiscountEnabled is true when the fee is being paid by the discount currency
+	DiscountEnabled bool `json:"discountEnabled,omitempty"`
+}
+
+// NewFeeSchedule creates a fee schedule with the same rates for all the symbols
+func NewFeeSchedule(makerFeeRate, takerFeeRate float64) *FeeSchedule {
+	return &FeeSchedule{
+		Default: FeeRates{
+			MakerFeeRate: makerFeeRate,
+			TakerFeeRate: takerFeeRate,
+		},
+	}
+}
+
+// SetSymbolFeeRates sets the fee rates of the symbol that differ from the default rates
+func (s *FeeSchedule) SetSymbolFeeRates(symbol string, rates FeeRates) {
+	if s.Symbols == nil {
+		s.Symbols = make(map[string]FeeRates)
+	}
+
+	s.Symbols[symbol] = rates
+}
+
+// FeeRates returns the fee rates of the symbol with the discount applied,
+// the discount only applies to the paid fees, the rebates (negative rates) are not discounted.
+func (s *FeeSchedule) FeeRates(symbol string) FeeRates {
+	rates, ok := s.Symbols[symbol]
+	if !ok {
+		rates = s.Default
+	}
+
+	if s.DiscountEnabled && s.DiscountRate > 0 {
+		if rates.MakerFeeRate > 0 {
+			rates.MakerFeeRate *= 1.0 - s.DiscountRate
+		}
+		if rates.TakerFeeRate > 0 {
+			rates.TakerFeeRate *= 1.0 - s.DiscountRate
+		}
+	}
+
+	return rates
+}
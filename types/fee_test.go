@@ -0,0 +1,35 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFeeSchedule_FeeRates(t *testing.T) {
+	account := &Account{MakerCommission: 10, TakerCommission: 10}
+	schedule := account.FeeSchedule()
+	schedule.Symbols = map[string]FeeRates{
+		"BTCUSDT": {MakerFeeRate: 0.0, TakerFeeRate: 0.0004},
+	}
+
+	rates := schedule.FeeRates("ETHUSDT")
+	assert.InDelta(t, 0.001, rates.MakerFeeRate, 1e-9)
+	assert.InDelta(t, 0.001, rates.FeeRate(OrderTypeMarket), 1e-9)
+
+	rates = schedule.FeeRates("BTCUSDT")
+	assert.InDelta(t, 0.0, rates.FeeRate(OrderTypeLimit), 1e-9)
+	assert.InDelta(t, 0.0004, rates.FeeRate(OrderTypeMarket), 1e-9)
+
+	schedule.DiscountCurrency = "BNB"
+	schedule.DiscountRate = 0.25
+	schedule.DiscountEnabled = true
+	rates = schedule.FeeRates("ETHUSDT")
+	assert.InDelta(t, 0.00075, rates.TakerFeeRate, 1e-9)
+
+	// the rebate is not discounted
+	schedule.SetSymbolFeeRates("ETHBTC", FeeRates{MakerFeeRate: -0.0001, TakerFeeRate: 0.001})
+	rates = schedule.FeeRates("ETHBTC")
+	assert.InDelta(t, -0.0001, rates.MakerFeeRate, 1e-9)
+	assert.InDelta(t, 0.00075, rates.TakerFeeRate, 1e-9)
+}
@@ -21,14 +21,8 @@ func (m Market) FormatPrice(val float64) string {
 
 	switch m.QuoteCurrency {
 
-	case "USD", "USDT":
-		return USD.FormatMoneyFloat64(val)
-
-	case "BTC":
-		return BTC.FormatMoneyFloat64(val)
-
-	case "BNB":
-		return BNB.FormatMoneyFloat64(val)
+	case "USD", "USDT", "BTC", "BNB", "TWD":
+		return FormatMoney(m.QuoteCurrency, val)
 
 	}
 
@@ -80,10 +74,88 @@ var MarketBNBUSDT = Market{
 	MinNotional:     10.0,
 }
 
+var MarketBNBBTC = Market{
+	Symbol:          "BNBBTC",
+	BaseCurrency:    "BNB",
+	QuoteCurrency:   "BTC",
+	PricePrecision:  7,
+	VolumePrecision: 2,
+	MinQuantity:     0.01,
+	MinLot:          0.01,
+	MinAmount:       0.0001,
+	MinNotional:     0.0001,
+}
+
+var MarketBTCTWD = Market{
+	Symbol:          "BTCTWD",
+	BaseCurrency:    "BTC",
+	QuoteCurrency:   "TWD",
+	PricePrecision:  1,
+	VolumePrecision: 4,
+	MinQuantity:     0.0001,
+	MinLot:          0.0001,
+	MinAmount:       250.0,
+	MinNotional:     250.0,
+}
+
+var MarketETHTWD = Market{
+	Symbol:          "ETHTWD",
+	BaseCurrency:    "ETH",
+	QuoteCurrency:   "TWD",
+	PricePrecision:  1,
+	VolumePrecision: 4,
+	MinQuantity:     0.01,
+	MinLot:          0.01,
+	MinAmount:       250.0,
+	MinNotional:     250.0,
+}
+
+var MarketUSDTTWD = Market{
+	Symbol:          "USDTTWD",
+	BaseCurrency:    "USDT",
+	QuoteCurrency:   "TWD",
+	PricePrecision:  3,
+	VolumePrecision: 2,
+	MinQuantity:     8.0,
+	MinLot:          0.01,
+	MinAmount:       250.0,
+	MinNotional:     250.0,
+}
+
+var MarketMAXTWD = Market{
+	Symbol:          "MAXTWD",
+	BaseCurrency:    "MAX",
+	QuoteCurrency:   "TWD",
+	PricePrecision:  4,
+	VolumePrecision: 2,
+	MinQuantity:     1.0,
+	MinLot:          0.01,
+	MinAmount:       250.0,
+	MinNotional:     250.0,
+}
+
+var MarketMAXUSDT = Market{
+	Symbol:          "MAXUSDT",
+	BaseCurrency:    "MAX",
+	QuoteCurrency:   "USDT",
+	PricePrecision:  4,
+	VolumePrecision: 2,
+	MinQuantity:     1.0,
+	MinLot:          0.01,
+	MinAmount:       8.0,
+	MinNotional:     8.0,
+}
+
 var Markets = map[string]Market{
 	"ETHUSDT": MarketETHUSDT,
 	"BNBUSDT": MarketBNBUSDT,
 	"BTCUSDT": MarketBTCUSDT,
+	"BNBBTC":  MarketBNBBTC,
+	"BTCTWD":  MarketBTCTWD,
+	"ETHTWD":  MarketETHTWD,
+	"USDTTWD": MarketUSDTTWD,
+	"MAXTWD":  MarketMAXTWD,
+	"MAXUSDT": MarketMAXUSDT,
 }
 
 func FindMarket(symbol string) (m Market, ok bool) {
@@ -1,6 +1,8 @@
 package types
 
 import (
+	"time"
+
 	"github.com/adshao/go-binance"
 	"github.com/slack-go/slack"
 )
@@ -13,19 +15,49 @@ const (
 	OrderTypeMarket OrderType = "MARKET"
 )
 
+type OrderStatus string
+
+const (
+	OrderStatusNew             OrderStatus = "NEW"
+	OrderStatusPartiallyFilled OrderStatus = "PARTIALLY_FILLED"
+	OrderStatusFilled          OrderStatus = "FILLED"
+	OrderStatusCanceled        OrderStatus = "CANCELED"
+	OrderStatusRejected        OrderStatus = "REJECTED"
+	OrderStatusExpired         OrderStatus = "EXPIRED"
+)
+
 type SubmitOrder struct {
-	Symbol   string
-	Side     SideType
-	Type     OrderType
-	Quantity float64
-	Price    float64
+	Symbol   string    `db:"symbol"`
+	Side     SideType  `db:"side"`
+	Type     OrderType `db:"order_type"`
+	Quantity float64   `db:"quantity"`
+	Price    float64   `db:"price"`
+
+	Market Market `db:"-"`
+
+	PriceString    string `db:"-"`
+	QuantityString string `db:"-"`
+
+	TimeInForce binance.TimeInForceType `db:"time_in_force"`
+
+	// ClientOrderID is the client order id of the order, the strategies tag their orders with it,
+	// so that their orders can be told apart from the other orders of the symbol.
+	ClientOrderID string `db:"client_order_id"`
+}
 
-	Market Market
+// Order is the order returned from the exchange
+type Order struct {
+	SubmitOrder
 
-	PriceString    string
-	QuantityString string
+	// GID is the global ID of the stored order
+	GID int64 `db:"gid"`
 
-	TimeInForce binance.TimeInForceType
+	OrderID          uint64      `db:"order_id"`
+	Exchange         string      `db:"exchange"`
+	Status           OrderStatus `db:"status"`
+	ExecutedQuantity float64     `db:"executed_quantity"`
+	CreationTime     time.Time   `db:"created_at"`
+	UpdateTime       time.Time   `db:"updated_at"`
 }
 
 func (o *SubmitOrder) SlackAttachment() slack.Attachment {
@@ -0,0 +1,38 @@
+package types
+
+import "time"
+
+// Profit is the realized profit of a sell trade
+type Profit struct {
+	GID int64 `json:"gid" db:"gid"`
+
+	Exchange string `json:"exchange" db:"exchange"`
+
+	Symbol string `json:"symbol" db:"symbol"`
+
+	// TradeID is the ID of the sell trade
+	TradeID int64 `json:"tradeID" db:"trade_id"`
+
+	Price float64 `json:"price" db:"price"`
+
+	// Quantity is the quantity matched with the bought stocks
+	Quantity float64 `json:"quantity" db:"quantity"`
+
+	// Cost is the cost of the matched stocks in the quote currency
+	Cost float64 `json:"cost" db:"cost"`
+
+	// Fee is the fee of the sell trade in the quote currency
+	Fee float64 `json:"fee" db:"fee"`
+
+	// Profit is the realized profit in the quote currency, the fee is deducted
+	Profit float64 `json:"profit" db:"profit"`
+
+	// HoldingSeconds is the quantity weighted average holding time of the matched stocks
+	HoldingSeconds int64 `json:"holdingSeconds" db:"holding_seconds"`
+
+	Time time.Time `json:"tradedAt" db:"traded_at"`
+}
+
+func (p Profit) HoldingTime() time.Duration {
+	return time.Duration(p.HoldingSeconds) * time.Second
+}
@@ -12,6 +12,16 @@ func (stream *StandardStream) EmitTrade(trade *Trade) {
 	}
 }
 
+func (stream *StandardStream) OnOrderUpdate(cb func(order Order)) {
+	stream.orderUpdateCallbacks = append(stream.orderUpdateCallbacks, cb)
+}
+
+func (stream *StandardStream) EmitOrderUpdate(order Order) {
+	for _, cb := range stream.orderUpdateCallbacks {
+		cb(order)
+	}
+}
+
 func (stream *StandardStream) OnBalanceSnapshot(cb func(balances map[string]Balance)) {
 	stream.balanceSnapshotCallbacks = append(stream.balanceSnapshotCallbacks, cb)
 }
@@ -65,6 +75,8 @@ func (stream *StandardStream) EmitBookSnapshot(book OrderBook) {
 type StandardStreamEventHub interface {
 	OnTrade(cb func(trade *Trade))
 
+	OnOrderUpdate(cb func(order Order))
+
 	OnBalanceSnapshot(cb func(balances map[string]Balance))
 
 	OnBalanceUpdate(cb func(balances map[string]Balance))
@@ -26,6 +26,9 @@ type StandardStream struct {
 	// private trade callbacks
 	tradeCallbacks []func(trade *Trade)
 
+	// private order update callbacks, including the new, filled and canceled orders
+	orderUpdateCallbacks []func(order Order)
+
 	// balance snapshot callbacks
 	balanceSnapshotCallbacks []func(balances map[string]Balance)
 
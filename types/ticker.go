@@ -0,0 +1,14 @@
+package types
+
+import "time"
+
+// Ticker is the latest prices of the market
+type Ticker struct {
+	Symbol string
+	Time   time.Time
+
+	// Buy is the best bid price and Sell is the best ask price
+	Buy  float64
+	Sell float64
+	Last float64
+}
@@ -13,8 +13,9 @@ type Trade struct {
 	// GID is the global ID
 	GID int64 `json:"gid" db:"gid"`
 
-	// ID is the source trade ID
+	// ID is the source trade ID, it's unique in the exchange and the symbol
 	ID            int64   `json:"id" db:"id"`
+	OrderID       uint64  `json:"orderID" db:"order_id"`
 	Exchange      string  `json:"exchange" db:"exchange"`
 	Price         float64 `json:"price" db:"price"`
 	Quantity      float64 `json:"quantity" db:"quantity"`
@@ -24,6 +25,7 @@ type Trade struct {
 	Side        string    `json:"side" db:"side"`
 	IsBuyer     bool      `json:"isBuyer" db:"is_buyer"`
 	IsMaker     bool      `json:"isMaker" db:"is_maker"`
+	IsMargin    bool      `json:"isMargin" db:"is_margin"`
 	Time        time.Time `json:"tradedAt" db:"traded_at"`
 	Fee         float64   `json:"fee" db:"fee"`
 	FeeCurrency string    `json:"feeCurrency" db:"fee_currency"`
@@ -56,6 +58,7 @@ func (trade Trade) SlackAttachment() slack.Attachment {
 			{Title: "Amount", Value: market.FormatPrice(trade.QuoteQuantity)},
 			{Title: "Fee", Value: util.FormatFloat(trade.Fee, 4), Short: true},
 			{Title: "FeeCurrency", Value: trade.FeeCurrency, Short: true},
+			{Title: "Exchange", Value: trade.Exchange, Short: true},
 		},
 		// Footer:     tradingCtx.TradeStartTime.Format(time.RFC822),
 		// FooterIcon: "",
@@ -0,0 +1,42 @@
+package types
+
+import "time"
+
+type WithdrawStatus string
+
+const (
+	WithdrawEmailSent        = WithdrawStatus("email_sent")
+	WithdrawCancelled        = WithdrawStatus("cancelled")
+	WithdrawAwaitingApproval = WithdrawStatus("awaiting_approval")
+	WithdrawRejected         = WithdrawStatus("rejected")
+	WithdrawProcessing       = WithdrawStatus("processing")
+	WithdrawFailure          = WithdrawStatus("failure")
+	WithdrawCompleted        = WithdrawStatus("completed")
+)
+
+type Withdraw struct {
+	GID        int64          `json:"gid" db:"gid"`
+	Exchange   string         `json:"exchange" db:"exchange"`
+	ID         string         `json:"id" db:"id"`
+	Asset      string         `json:"asset" db:"asset"`
+	Amount     float64        `json:"amount" db:"amount"`
+	Address    string         `json:"address" db:"address"`
+	AddressTag string         `json:"addressTag" db:"address_tag"`
+	Status     WithdrawStatus `json:"status" db:"status"`
+
+	TransactionID   string    `json:"txId" db:"txn_id"`
+	TransactionFee  float64   `json:"transactionFee" db:"txn_fee"`
+	WithdrawOrderID string    `json:"withdrawOrderId" db:"withdraw_order_id"`
+	ApplyTime       time.Time `json:"applyTime" db:"time"`
+	Network         string    `json:"network" db:"network"`
+}
+
+// IsDebited returns true if the withdraw amount (and the transaction fee) is taken from the account balance
+func (w Withdraw) IsDebited() bool {
+	switch w.Status {
+	case WithdrawAwaitingApproval, WithdrawProcessing, WithdrawCompleted:
+		return true
+	}
+
+	return false
+}